@@ -11,8 +11,14 @@ import (
 	"valancis-backend/config"
 	"valancis-backend/internal/delivery/http/middleware"
 	v1 "valancis-backend/internal/delivery/http/v1"
+	"valancis-backend/internal/domain"
 	"valancis-backend/internal/infrastructure/cache"
+	"valancis-backend/internal/infrastructure/courier"
 	"valancis-backend/internal/infrastructure/facebook"
+	"valancis-backend/internal/infrastructure/mail"
+	"valancis-backend/internal/infrastructure/payment"
+	"valancis-backend/internal/infrastructure/pdf"
+	"valancis-backend/internal/infrastructure/sms"
 	sqlcrepo "valancis-backend/internal/repository/sqlc"
 	"valancis-backend/internal/usecase"
 	"valancis-backend/pkg/logger"
@@ -45,6 +51,19 @@ func main() {
 	searchRepo := sqlcrepo.NewSearchRepository(pgxPool)
 	txManager := sqlcrepo.NewTransactionManager(pgxPool)
 	couponRepo := sqlcrepo.NewCouponRepository(pgxPool)
+	paymentRepo := sqlcrepo.NewPaymentRepository(pgxPool)
+	idempotencyRepo := sqlcrepo.NewIdempotencyRepository(pgxPool)
+	reservationRepo := sqlcrepo.NewStockReservationRepository(pgxPool)
+	emailOutboxRepo := sqlcrepo.NewEmailOutboxRepository(pgxPool)
+	orderOTPRepo := sqlcrepo.NewOrderOTPRepository(pgxPool)
+	shipmentRepo := sqlcrepo.NewShipmentRepository(pgxPool)
+	returnRepo := sqlcrepo.NewReturnRepository(pgxPool)
+	exchangeRepo := sqlcrepo.NewExchangeRepository(pgxPool)
+	draftOrderRepo := sqlcrepo.NewDraftOrderRepository(pgxPool)
+	invoiceRepo := sqlcrepo.NewInvoiceRepository(pgxPool)
+	preorderRepo := sqlcrepo.NewPreorderRepository(pgxPool)
+	ledgerRepo := sqlcrepo.NewPaymentLedgerRepository(pgxPool)
+	blocklistRepo := sqlcrepo.NewBlocklistRepository(pgxPool)
 
 	// Initialize Cache (In-Memory)
 	// Default expiration 30m, cleanup every 60m
@@ -55,17 +74,6 @@ func main() {
 
 	// --- Modules Initialization ---
 
-	// Auth Module
-	authUC := usecase.NewAuthUsecase(
-		userRepo,
-		cfg.GoogleClientID,
-		cfg.GoogleClientSecret,
-		cfg.GoogleTokenInfoURL,
-		cfg.AccessTokenExpiry,
-		cfg.RefreshTokenExpiry,
-	)
-	authHandler := v1.NewAuthHandler(authUC)
-
 	// --- Storage Module (R2) ---
 	r2Storage, err := storage.NewR2Storage(
 		context.Background(),
@@ -91,10 +99,135 @@ func main() {
 	// Facebook CAPI Client (Marketing / Analytics)
 	capiClient := facebook.NewCAPIClient(cfg.FacebookPixelID, cfg.FacebookAccessToken, cfg.FacebookAPIVersion)
 
+	// Notifications: order emails are queued in the outbox and delivered in the background
+	var mailer domain.Mailer = mail.NewLogMailer()
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+	} else {
+		log.Warn().Msg("SMTP_HOST not set: customer emails are logged, not sent")
+	}
+	orderNotifier := usecase.NewOrderNotifier(emailOutboxRepo, orderRepo, cfg.FrontendURL)
+	emailDispatcher := usecase.NewEmailDispatcher(context.Background(), emailOutboxRepo, mailer, cfg.EmailDispatchInterval)
+
+	// SMS: COD orders are confirmed with a one-time code sent to the shipping phone
+	var smsProvider domain.SMSProvider = sms.NewStubProvider()
+	if cfg.SMSAPIURL != "" {
+		smsProvider = sms.NewHTTPProvider(cfg.SMSAPIURL, cfg.SMSAPIKey, cfg.SMSSenderID)
+	} else {
+		log.Warn().Msg("SMS_API_URL not set: SMS messages are logged, not sent")
+	}
+	phoneVerifier := usecase.NewPhoneVerifier(orderOTPRepo, orderRepo, txManager, smsProvider, cfg.OrderOTPTTL, cfg.OrderOTPMaxAttempts)
+
+	// Blocklist: known fraudsters are stopped (or made to prepay) at cart and checkout
+	blocklistUC := usecase.NewBlocklistUsecase(blocklistRepo, orderRepo, txManager, cfg.BlocklistFakeOrderTTL)
+	blocklistHandler := v1.NewBlocklistHandler(blocklistUC)
+
 	// Order Module
-	orderUC := usecase.NewOrderUsecase(orderRepo, productRepo, configRepo, couponRepo, txManager, capiClient)
-	orderHandler := v1.NewOrderHandler(orderUC, cfg.MaxCartQuantity)
-	adminOrderHandler := v1.NewAdminOrderHandler(orderUC)
+	orderUC := usecase.NewOrderUsecase(orderRepo, productRepo, configRepo, couponRepo, reservationRepo, txManager, userRepo, orderNotifier, phoneVerifier, blocklistUC, capiClient, preorderRepo, ledgerRepo, shipmentRepo, cfg.StockReservationTTL, cfg.MaxCartQuantity, domain.RiskPolicy{
+		HighScore:      cfg.RiskHighScore,
+		Deposit:        domain.Taka(cfg.RiskDepositTaka),
+		VelocityWindow: cfg.RiskVelocityWindow,
+		VelocityLimit:  cfg.RiskVelocityLimit,
+		FirstOrderHigh: domain.Taka(cfg.RiskFirstOrderTaka),
+	})
+	trustedProxies, err := utils.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+	orderHandler := v1.NewOrderHandler(orderUC, cfg.MaxCartQuantity, trustedProxies)
+	adminOrderHandler := v1.NewAdminOrderHandler(orderUC, blocklistUC)
+
+	// Auth Module (merges guest carts into the user's cart on sign-in)
+	authUC := usecase.NewAuthUsecase(
+		userRepo,
+		orderUC,
+		cfg.GoogleClientID,
+		cfg.GoogleClientSecret,
+		cfg.GoogleTokenInfoURL,
+		cfg.AccessTokenExpiry,
+		cfg.RefreshTokenExpiry,
+	)
+	authHandler := v1.NewAuthHandler(authUC)
+
+	// Payment Gateways (SSLCommerz, bKash). PAYMENT_FAKE_GATEWAY serves both offline.
+	var gateways []domain.PaymentGateway
+	if cfg.PaymentFakeGateway {
+		log.Warn().Msg("Payment gateways running in FAKE mode — no real money is moved")
+		gateways = append(gateways,
+			payment.NewFakeGateway(domain.PaymentMethodSSLCommerz, ""),
+			payment.NewFakeGateway(domain.PaymentMethodBKash, ""),
+		)
+	} else {
+		if gw := payment.NewSSLCommerzGateway(cfg.SSLCommerzStoreID, cfg.SSLCommerzStorePass, cfg.SSLCommerzSandbox); gw != nil {
+			gateways = append(gateways, gw)
+		}
+		if gw := payment.NewBKashGateway(cfg.BKashBaseURL, cfg.BKashAppKey, cfg.BKashAppSecret, cfg.BKashUsername, cfg.BKashPassword); gw != nil {
+			gateways = append(gateways, gw)
+		}
+	}
+	paymentUC := usecase.NewPaymentUsecase(paymentRepo, orderRepo, reservationRepo, preorderRepo, ledgerRepo, orderUC, txManager, cfg.StockReservationTTL, cfg.APIBaseURL, cfg.FrontendURL, gateways...)
+	paymentHandler := v1.NewPaymentHandler(paymentUC)
+
+	// Couriers (Pathao, Steadfast, RedX). COURIER_FAKE serves all three offline.
+	var couriers []domain.CourierProvider
+	if cfg.CourierFake {
+		log.Warn().Msg("Couriers running in FAKE mode — no parcels are booked")
+		couriers = append(couriers,
+			courier.NewFakeCourier(domain.CourierPathao, cfg.CourierFakeSecret),
+			courier.NewFakeCourier(domain.CourierSteadfast, cfg.CourierFakeSecret),
+			courier.NewFakeCourier(domain.CourierRedX, cfg.CourierFakeSecret),
+		)
+	} else {
+		if c := courier.NewPathaoCourier(cfg.PathaoBaseURL, cfg.PathaoClientID, cfg.PathaoClientSecret, cfg.PathaoUsername, cfg.PathaoPassword, cfg.PathaoStoreID, cfg.PathaoWebhookSecret); c != nil {
+			couriers = append(couriers, c)
+		}
+		if c := courier.NewSteadfastCourier(cfg.SteadfastBaseURL, cfg.SteadfastAPIKey, cfg.SteadfastSecretKey, cfg.SteadfastWebhookToken); c != nil {
+			couriers = append(couriers, c)
+		}
+		if c := courier.NewRedXCourier(cfg.RedXBaseURL, cfg.RedXAPIToken, cfg.RedXWebhookToken); c != nil {
+			couriers = append(couriers, c)
+		}
+	}
+	shippingUC := usecase.NewShippingUsecase(shipmentRepo, orderRepo, orderUC, txManager, couriers...)
+	shippingHandler := v1.NewShippingHandler(shippingUC)
+
+	// COD settlement: courier payout statements reconciled against delivered orders
+	settlementUC := usecase.NewSettlementUsecase(orderRepo, shipmentRepo, ledgerRepo, orderUC, courier.SettlementMappings()...)
+	settlementHandler := v1.NewSettlementHandler(settlementUC, cfg.MaxUploadSizeMB)
+
+	// Returns (RMA): customer return requests, admin review, restock and refund
+	returnUC := usecase.NewReturnUsecase(returnRepo, exchangeRepo, orderRepo, productRepo, orderUC, txManager, r2Storage)
+	returnHandler := v1.NewReturnHandler(returnUC, cfg.MaxUploadSizeMB)
+
+	// Exchanges: size/colour swaps of delivered items with a replacement shipment
+	exchangeUC := usecase.NewExchangeUsecase(exchangeRepo, returnRepo, orderRepo, productRepo, orderUC, shippingUC, txManager)
+	exchangeHandler := v1.NewExchangeHandler(exchangeUC)
+
+	// Draft orders: admin-built phone / Messenger orders with a customer pay/confirm link
+	draftOrderUC := usecase.NewDraftOrderUsecase(draftOrderRepo, orderUC, productRepo, configRepo, userRepo, txManager, orderNotifier, cfg.FrontendURL)
+	draftOrderHandler := v1.NewDraftOrderHandler(draftOrderUC)
+
+	// Documents: invoices, packing slips and 4x6 shipping labels as PDFs
+	pdfRenderer, err := pdf.NewRenderer(pdf.Store{Name: cfg.StoreName, Address: cfg.StoreAddress, Phone: cfg.StorePhone}, cfg.PDFFontPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize PDF renderer")
+	}
+	var invoiceStorage *storage.R2Storage
+	if cfg.InvoiceArchive {
+		invoiceStorage = r2Storage
+	}
+	documentUC := usecase.NewDocumentUsecase(orderRepo, invoiceRepo, pdfRenderer, invoiceStorage)
+	documentHandler := v1.NewDocumentHandler(documentUC)
+
+	// Pre-orders: batch releases, balance requests and the customer's balance pay link
+	preorderUC := usecase.NewPreorderUsecase(preorderRepo, orderRepo, productRepo, ledgerRepo, txManager, orderNotifier, cfg.FrontendURL)
+	preorderHandler := v1.NewPreorderHandler(preorderUC, paymentUC)
+
+	// Stock Reservations: expire holds of unpaid gateway orders in the background
+	reservationSweeper := usecase.NewStockReservationSweeper(context.Background(), reservationRepo, orderRepo, cfg.StockReservationSweepInterval)
+
+	// Guest Carts: delete carts abandoned past their token lifetime
+	guestCartSweeper := usecase.NewGuestCartSweeper(context.Background(), orderRepo, cfg.GuestCartTTL, cfg.GuestCartSweepInterval)
 
 	// Content Module
 	contentRepo := sqlcrepo.NewContentRepository(pgxPool)
@@ -164,6 +297,9 @@ func main() {
 		return middleware.AuthMiddleware(middleware.AdminMiddleware(h))
 	}
 
+	// Idempotency-Key support for mutating order endpoints (stored responses are replayed)
+	idempotency := middleware.NewIdempotency(context.Background(), idempotencyRepo, cfg.IdempotencyKeyTTL, time.Hour)
+
 	// Admin Content
 	mux.Handle("PUT /api/v1/admin/content/{key}", adminMiddleware(contentHandler.UpsertContent))
 
@@ -181,6 +317,11 @@ func main() {
 	mux.Handle("GET /api/v1/admin/inventory/logs", adminMiddleware(adminCatalogHandler.GetInventoryLogs))
 	mux.Handle("GET /api/v1/admin/inventory/variants", adminMiddleware(adminCatalogHandler.GetVariantList))
 	mux.Handle("GET /api/v1/admin/products/stats", adminMiddleware(adminCatalogHandler.GetProductStats))
+	mux.Handle("GET /api/v1/admin/products/{id}/preorder-releases", adminMiddleware(preorderHandler.ListReleases))
+	mux.Handle("POST /api/v1/admin/products/{id}/preorder-releases", adminMiddleware(idempotency.Wrap(preorderHandler.ReleasePreorder)))
+	mux.Handle("GET /api/v1/admin/preorder-releases/{id}", adminMiddleware(preorderHandler.GetRelease))
+	mux.Handle("POST /api/v1/admin/preorder-balances/{id}/payments", adminMiddleware(idempotency.Wrap(preorderHandler.RecordBalancePayment)))
+	mux.Handle("POST /api/v1/admin/preorder-balances/{id}/cancel", adminMiddleware(preorderHandler.CancelBalanceRequest))
 
 	mux.Handle("GET /api/v1/admin/categories", adminMiddleware(adminCatalogHandler.GetAllCategories))
 	mux.Handle("GET /api/v1/admin/categories/tree", adminMiddleware(http.HandlerFunc(catalogHandler.GetCategories)))
@@ -201,28 +342,92 @@ func main() {
 	mux.Handle("PATCH /api/v1/admin/orders/{id}/status", adminMiddleware(adminOrderHandler.UpdateStatus))
 	mux.Handle("PATCH /api/v1/admin/orders/{id}/payment-status", adminMiddleware(adminOrderHandler.UpdatePaymentStatus))
 	mux.Handle("PATCH /api/v1/admin/orders/{id}/shipping-zone", adminMiddleware(adminOrderHandler.UpdateShippingZone))
-	mux.Handle("POST /api/v1/admin/orders/{id}/verify-payment", adminMiddleware(adminOrderHandler.VerifyPayment))
-	mux.Handle("POST /api/v1/admin/orders/{id}/refund", adminMiddleware(adminOrderHandler.RefundOrder))
+	mux.Handle("PATCH /api/v1/admin/orders/{id}/items", adminMiddleware(adminOrderHandler.EditOrder))
+	mux.Handle("POST /api/v1/admin/orders/{id}/verify-payment", adminMiddleware(idempotency.Wrap(adminOrderHandler.VerifyPayment)))
+	mux.Handle("POST /api/v1/admin/orders/{id}/refund", adminMiddleware(idempotency.Wrap(adminOrderHandler.RefundOrder)))
+	mux.Handle("GET /api/v1/admin/orders/{id}/refunds", adminMiddleware(adminOrderHandler.GetRefunds))
+	mux.Handle("GET /api/v1/admin/orders/{id}/payments", adminMiddleware(adminOrderHandler.GetPayments))
 	mux.Handle("GET /api/v1/admin/orders/{id}/history", adminMiddleware(adminOrderHandler.GetOrderHistory))
+	mux.Handle("GET /api/v1/admin/orders/{id}/blocklist", adminMiddleware(blocklistHandler.GetOrderEntries))
+	mux.Handle("POST /api/v1/admin/orders/{id}/blocklist", adminMiddleware(blocklistHandler.BlockOrderCustomer))
+	mux.Handle("GET /api/v1/admin/orders/{id}/shipments", adminMiddleware(shippingHandler.ListShipments))
+	mux.Handle("POST /api/v1/admin/orders/{id}/shipments", adminMiddleware(idempotency.Wrap(shippingHandler.BookShipment)))
+	mux.Handle("PATCH /api/v1/admin/shipments/{id}/status", adminMiddleware(shippingHandler.UpdateShipmentStatus))
+	mux.Handle("GET /api/v1/admin/orders/{id}/returns", adminMiddleware(returnHandler.ListOrderReturns))
+	mux.Handle("GET /api/v1/admin/returns", adminMiddleware(returnHandler.ListReturns))
+	mux.Handle("GET /api/v1/admin/returns/{id}", adminMiddleware(returnHandler.GetReturn))
+	mux.Handle("POST /api/v1/admin/returns/{id}/approve", adminMiddleware(returnHandler.ApproveReturn))
+	mux.Handle("POST /api/v1/admin/returns/{id}/reject", adminMiddleware(returnHandler.RejectReturn))
+	mux.Handle("POST /api/v1/admin/returns/{id}/receive", adminMiddleware(idempotency.Wrap(returnHandler.ReceiveReturn)))
+	mux.Handle("POST /api/v1/admin/returns/{id}/refund", adminMiddleware(idempotency.Wrap(returnHandler.RefundReturn)))
+	mux.Handle("GET /api/v1/admin/orders/{id}/exchanges", adminMiddleware(exchangeHandler.ListExchanges))
+	mux.Handle("POST /api/v1/admin/orders/{id}/exchanges", adminMiddleware(idempotency.Wrap(exchangeHandler.CreateExchange)))
+	mux.Handle("POST /api/v1/admin/exchanges/{id}/shipment", adminMiddleware(idempotency.Wrap(exchangeHandler.BookShipment)))
+	mux.Handle("POST /api/v1/admin/exchanges/{id}/receive", adminMiddleware(exchangeHandler.ReceiveExchange))
+	mux.Handle("GET /api/v1/admin/orders/{id}/documents/{type}", adminMiddleware(documentHandler.GetOrderDocument))
+	mux.Handle("POST /api/v1/admin/orders/documents", adminMiddleware(documentHandler.PrintDocuments))
+	mux.Handle("GET /api/v1/admin/draft-orders", adminMiddleware(draftOrderHandler.ListDrafts))
+	mux.Handle("POST /api/v1/admin/draft-orders", adminMiddleware(draftOrderHandler.CreateDraft))
+	mux.Handle("GET /api/v1/admin/draft-orders/{id}", adminMiddleware(draftOrderHandler.GetDraft))
+	mux.Handle("PUT /api/v1/admin/draft-orders/{id}", adminMiddleware(draftOrderHandler.UpdateDraft))
+	mux.Handle("POST /api/v1/admin/draft-orders/{id}/send", adminMiddleware(draftOrderHandler.SendDraft))
+	mux.Handle("POST /api/v1/admin/draft-orders/{id}/finalize", adminMiddleware(idempotency.Wrap(draftOrderHandler.FinalizeDraft)))
+	mux.Handle("POST /api/v1/admin/draft-orders/{id}/cancel", adminMiddleware(draftOrderHandler.CancelDraft))
+	mux.Handle("GET /api/v1/admin/couriers", adminMiddleware(shippingHandler.ListCouriers))
+	mux.Handle("GET /api/v1/admin/cod-settlements/mappings", adminMiddleware(settlementHandler.ListMappings))
+	mux.Handle("POST /api/v1/admin/cod-settlements", adminMiddleware(idempotency.Wrap(settlementHandler.ImportStatement)))
 	mux.Handle("GET /api/v1/admin/users", adminMiddleware(authHandler.ListUsers))
+	mux.Handle("GET /api/v1/admin/blocklist", adminMiddleware(blocklistHandler.ListEntries))
+	mux.Handle("POST /api/v1/admin/blocklist", adminMiddleware(blocklistHandler.CreateEntry))
+	mux.Handle("GET /api/v1/admin/blocklist/{id}", adminMiddleware(blocklistHandler.GetEntry))
+	mux.Handle("PUT /api/v1/admin/blocklist/{id}", adminMiddleware(blocklistHandler.UpdateEntry))
+	mux.Handle("DELETE /api/v1/admin/blocklist/{id}", adminMiddleware(blocklistHandler.DeleteEntry))
 
 	// Admin Coupons
-	// couponUC := usecase.NewCouponUsecase(couponRepo)
-	// adminCouponHandler := v1.NewAdminCouponHandler(couponUC)
-	// mux.Handle("GET /api/v1/admin/coupons", adminMiddleware(adminCouponHandler.ListCoupons))
-	// mux.Handle("GET /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.GetCoupon))
-	// mux.Handle("POST /api/v1/admin/coupons", adminMiddleware(adminCouponHandler.CreateCoupon))
-	// mux.Handle("PUT /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.UpdateCoupon))
-	// mux.Handle("DELETE /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.DeleteCoupon))
+	couponUC := usecase.NewCouponUsecase(couponRepo, txManager)
+	adminCouponHandler := v1.NewAdminCouponHandler(couponUC)
+	mux.Handle("GET /api/v1/admin/coupons", adminMiddleware(adminCouponHandler.ListCoupons))
+	mux.Handle("GET /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.GetCoupon))
+	mux.Handle("POST /api/v1/admin/coupons", adminMiddleware(adminCouponHandler.CreateCoupon))
+	mux.Handle("PUT /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.UpdateCoupon))
+	mux.Handle("DELETE /api/v1/admin/coupons/{id}", adminMiddleware(adminCouponHandler.DeleteCoupon))
 
 	// Cart & Order (Protected)
-	mux.Handle("GET /api/v1/cart", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.GetCart)))
-	mux.Handle("POST /api/v1/cart", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.AddToCart)))
-	mux.Handle("PUT /api/v1/cart", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.UpdateCart)))
-	mux.Handle("DELETE /api/v1/cart/{productId}", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.RemoveFromCart)))
-	// mux.Handle("POST /api/v1/cart/coupon", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.ApplyCoupon)))
-	mux.Handle("POST /api/v1/checkout", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.Checkout)))
+	// Cart routes also serve guests: without a valid login the signed cart token picks the cart
+	mux.Handle("GET /api/v1/cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(orderHandler.GetCart)))
+	mux.Handle("POST /api/v1/cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(orderHandler.AddToCart)))
+	mux.Handle("PUT /api/v1/cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(orderHandler.UpdateCart)))
+	mux.Handle("DELETE /api/v1/cart/{productId}", middleware.OptionalAuthMiddleware(http.HandlerFunc(orderHandler.RemoveFromCart)))
+	mux.Handle("POST /api/v1/cart/coupon", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.ApplyCoupon)))
+	mux.Handle("POST /api/v1/checkout", middleware.AuthMiddleware(idempotency.Wrap(orderHandler.Checkout)))
+	mux.HandleFunc("POST /api/v1/checkout/guest", idempotency.WrapGuest(v1.GuestCartScope, orderHandler.GuestCheckout)) // Public — guest cart token
+	mux.HandleFunc("GET /api/v1/track/{token}", orderHandler.TrackOrder)                                                // Public — tracking token
+	mux.HandleFunc("POST /api/v1/track/{token}/confirm-phone", orderHandler.ConfirmTrackedOrderPhone)
+	mux.HandleFunc("POST /api/v1/track/{token}/confirm-phone/resend", orderHandler.ResendTrackedOrderCode)
+	mux.HandleFunc("GET /api/v1/draft-orders/{token}", draftOrderHandler.GetLinkedDraft) // Public — signed draft link
+	mux.HandleFunc("POST /api/v1/draft-orders/{token}/confirm", draftOrderHandler.ConfirmLinkedDraft)
+	mux.HandleFunc("GET /api/v1/preorder-balances/{token}", preorderHandler.GetLinkedBalance) // Public — signed balance link
+	mux.HandleFunc("POST /api/v1/preorder-balances/{token}/payments", preorderHandler.PayLinkedBalance)
 	mux.Handle("GET /api/v1/orders", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.GetMyOrders)))
+	mux.Handle("GET /api/v1/orders/{id}", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.GetMyOrder)))
+	mux.Handle("GET /api/v1/orders/{id}/timeline", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.GetMyOrderTimeline)))
+	mux.Handle("GET /api/v1/orders/{id}/invoice", middleware.AuthMiddleware(http.HandlerFunc(documentHandler.GetMyInvoice)))
+	mux.Handle("POST /api/v1/orders/{id}/cancel", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.CancelMyOrder)))
+	mux.Handle("POST /api/v1/orders/{id}/confirm-phone", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.ConfirmMyOrderPhone)))
+	mux.Handle("POST /api/v1/orders/{id}/confirm-phone/resend", middleware.AuthMiddleware(http.HandlerFunc(orderHandler.ResendMyOrderCode)))
+	mux.Handle("POST /api/v1/orders/{id}/payments", middleware.AuthMiddleware(http.HandlerFunc(paymentHandler.StartPayment)))
+	mux.Handle("GET /api/v1/orders/{id}/payments", middleware.AuthMiddleware(http.HandlerFunc(paymentHandler.ListPayments)))
+	mux.Handle("POST /api/v1/orders/{id}/returns", middleware.AuthMiddleware(http.HandlerFunc(returnHandler.RequestReturn)))
+	mux.Handle("GET /api/v1/orders/{id}/returns", middleware.AuthMiddleware(http.HandlerFunc(returnHandler.ListMyReturns)))
+	mux.Handle("POST /api/v1/returns/photos", middleware.AuthMiddleware(http.HandlerFunc(returnHandler.UploadPhoto)))
+
+	// Payment Gateway Callbacks (Public — authenticated by gateway signature/verification)
+	mux.HandleFunc("POST /api/v1/payments/{provider}/ipn", paymentHandler.IPN)
+	mux.HandleFunc("GET /api/v1/payments/{provider}/callback", paymentHandler.Callback)
+	mux.HandleFunc("POST /api/v1/payments/{provider}/callback", paymentHandler.Callback)
+
+	// Courier Webhooks (Public — authenticated by courier secret/signature)
+	mux.HandleFunc("POST /api/v1/couriers/{provider}/webhook", shippingHandler.Webhook)
 
 	// Wishlist Module
 	wishlistRepo := sqlcrepo.NewWishlistRepository(pgxPool)
@@ -295,6 +500,10 @@ func main() {
 
 	// L9: Graceful shutdown - stop rate limiter cleanup goroutine
 	rateLimiter.Shutdown()
+	idempotency.Shutdown()
+	reservationSweeper.Shutdown()
+	guestCartSweeper.Shutdown()
+	emailDispatcher.Shutdown()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
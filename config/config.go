@@ -41,11 +41,83 @@ type Config struct {
 	R2UploadTimeout time.Duration
 	// Business Rules
 	MaxCartQuantity int
+	// Idempotency-Key retention for checkout/refund/verify-payment
+	IdempotencyKeyTTL time.Duration
+	// Stock held for online-payment orders, and how often expired holds are released
+	StockReservationTTL           time.Duration
+	StockReservationSweepInterval time.Duration
+	// Guest carts untouched this long are deleted, checked every sweep interval
+	GuestCartTTL           time.Duration
+	GuestCartSweepInterval time.Duration
 
 	// Marketing / Analytics (L9)
 	FacebookPixelID     string
 	FacebookAccessToken string
 	FacebookAPIVersion  string
+
+	// Payment Gateways
+	APIBaseURL          string // Public URL of this API, used for gateway callbacks
+	SSLCommerzStoreID   string
+	SSLCommerzStorePass string
+	SSLCommerzSandbox   bool
+	BKashBaseURL        string
+	BKashAppKey         string
+	BKashAppSecret      string
+	BKashUsername       string
+	BKashPassword       string
+	PaymentFakeGateway  bool // Serve every gateway with the in-process fake (offline dev/testing)
+
+	// Transactional Email (log-only when SMTP_HOST is empty)
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	EmailFrom             string
+	EmailDispatchInterval time.Duration
+
+	// SMS + COD phone confirmation (log-only stub when SMS_API_URL is empty)
+	SMSAPIURL           string
+	SMSAPIKey           string
+	SMSSenderID         string
+	OrderOTPTTL         time.Duration
+	OrderOTPMaxAttempts int
+
+	// COD fraud risk scoring (score 0-100; high-risk COD orders pay an advance deposit)
+	RiskHighScore      int
+	RiskDepositTaka    int64 // 0 = the order's shipping fee
+	RiskVelocityWindow time.Duration
+	RiskVelocityLimit  int
+	RiskFirstOrderTaka int64 // First-order value that counts as high
+	// Blocklist entries added from fake orders expire after this (0 = never)
+	BlocklistFakeOrderTTL time.Duration
+	// Comma-separated proxy addresses/CIDR ranges whose X-Forwarded-For hops are trusted
+	// (empty = none; the client IP is the connection's address)
+	TrustedProxies string
+
+	// Couriers (an adapter is enabled once its credentials are set)
+	PathaoBaseURL         string
+	PathaoClientID        string
+	PathaoClientSecret    string
+	PathaoUsername        string
+	PathaoPassword        string
+	PathaoStoreID         int
+	PathaoWebhookSecret   string
+	SteadfastBaseURL      string
+	SteadfastAPIKey       string
+	SteadfastSecretKey    string
+	SteadfastWebhookToken string
+	RedXBaseURL           string
+	RedXAPIToken          string
+	RedXWebhookToken      string
+	CourierFake           bool   // Serve every courier with the in-process fake (offline dev/testing)
+	CourierFakeSecret     string // Signs fake courier webhooks; random per process when empty
+
+	// Printed documents (invoices, packing slips, shipping labels)
+	StoreName      string
+	StoreAddress   string // Printed on invoices and as the label sender
+	StorePhone     string
+	PDFFontPath    string // UTF-8 TrueType font for Bangla text; built-in Latin fonts when empty
+	InvoiceArchive bool   // Keep a copy of each issued invoice in R2
 }
 
 func LoadConfig() *Config {
@@ -103,10 +175,82 @@ func LoadConfig() *Config {
 		// Business rules: 1000 max cart quantity
 		MaxCartQuantity: getIntEnv("MAX_CART_QUANTITY", 1000),
 
+		// Idempotency keys are replayable for 24h by default
+		IdempotencyKeyTTL: getDurationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+
+		// Gateway orders hold stock for 30m; expired holds are swept every minute
+		StockReservationTTL:           getDurationEnv("STOCK_RESERVATION_TTL", 30*time.Minute),
+		StockReservationSweepInterval: getDurationEnv("STOCK_RESERVATION_SWEEP_INTERVAL", time.Minute),
+
+		// Guest carts live as long as their 30-day cookie; idle ones are swept hourly
+		GuestCartTTL:           getDurationEnv("GUEST_CART_TTL", 30*24*time.Hour),
+		GuestCartSweepInterval: getDurationEnv("GUEST_CART_SWEEP_INTERVAL", time.Hour),
+
 		// Marketing
 		FacebookPixelID:     getEnv("FACEBOOK_PIXEL_ID", ""),
 		FacebookAccessToken: getEnv("FACEBOOK_ACCESS_TOKEN", ""),
 		FacebookAPIVersion:  getEnv("FACEBOOK_API_VERSION", "v19.0"),
+
+		// Payment Gateways (sandbox by default)
+		APIBaseURL:          getEnv("API_BASE_URL", "http://localhost:8080"),
+		SSLCommerzStoreID:   getEnv("SSLCOMMERZ_STORE_ID", ""),
+		SSLCommerzStorePass: getEnv("SSLCOMMERZ_STORE_PASSWORD", ""),
+		SSLCommerzSandbox:   getBoolEnv("SSLCOMMERZ_SANDBOX", true),
+		BKashBaseURL:        getEnv("BKASH_BASE_URL", "https://tokenized.sandbox.bka.sh/v1.2.0-beta"),
+		BKashAppKey:         getEnv("BKASH_APP_KEY", ""),
+		BKashAppSecret:      getEnv("BKASH_APP_SECRET", ""),
+		BKashUsername:       getEnv("BKASH_USERNAME", ""),
+		BKashPassword:       getEnv("BKASH_PASSWORD", ""),
+		PaymentFakeGateway:  getBoolEnv("PAYMENT_FAKE_GATEWAY", false),
+
+		// Email outbox is drained every 30s
+		SMTPHost:              getEnv("SMTP_HOST", ""),
+		SMTPPort:              getIntEnv("SMTP_PORT", 587),
+		SMTPUsername:          getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
+		EmailFrom:             getEnv("EMAIL_FROM", "Valancis <no-reply@valancis.com>"),
+		EmailDispatchInterval: getDurationEnv("EMAIL_DISPATCH_INTERVAL", 30*time.Second),
+
+		// COD confirmation codes are valid for 10 minutes, 5 guesses each
+		SMSAPIURL:           getEnv("SMS_API_URL", ""),
+		SMSAPIKey:           getEnv("SMS_API_KEY", ""),
+		SMSSenderID:         getEnv("SMS_SENDER_ID", ""),
+		OrderOTPTTL:         getDurationEnv("ORDER_OTP_TTL", 10*time.Minute),
+		OrderOTPMaxAttempts: getIntEnv("ORDER_OTP_MAX_ATTEMPTS", 5),
+
+		// Risk: COD orders scoring 60+ pay the shipping fee upfront; 3+ orders a day is velocity
+		RiskHighScore:      getIntEnv("RISK_HIGH_SCORE", 60),
+		RiskDepositTaka:    getInt64Env("RISK_DEPOSIT_TAKA", 0),
+		RiskVelocityWindow: getDurationEnv("RISK_VELOCITY_WINDOW", 24*time.Hour),
+		RiskVelocityLimit:  getIntEnv("RISK_VELOCITY_LIMIT", 3),
+		RiskFirstOrderTaka: getInt64Env("RISK_FIRST_ORDER_TAKA", 10000),
+
+		// Fake-order customers stay blocked for a year (phone numbers get recycled)
+		BlocklistFakeOrderTTL: getDurationEnv("BLOCKLIST_FAKE_ORDER_TTL", 365*24*time.Hour),
+		TrustedProxies:        getEnv("TRUSTED_PROXIES", ""),
+
+		PathaoBaseURL:         getEnv("PATHAO_BASE_URL", "https://courier-api-sandbox.pathao.com"),
+		PathaoClientID:        getEnv("PATHAO_CLIENT_ID", ""),
+		PathaoClientSecret:    getEnv("PATHAO_CLIENT_SECRET", ""),
+		PathaoUsername:        getEnv("PATHAO_USERNAME", ""),
+		PathaoPassword:        getEnv("PATHAO_PASSWORD", ""),
+		PathaoStoreID:         getIntEnv("PATHAO_STORE_ID", 0),
+		PathaoWebhookSecret:   getEnv("PATHAO_WEBHOOK_SECRET", ""),
+		SteadfastBaseURL:      getEnv("STEADFAST_BASE_URL", "https://portal.packzy.com/api/v1"),
+		SteadfastAPIKey:       getEnv("STEADFAST_API_KEY", ""),
+		SteadfastSecretKey:    getEnv("STEADFAST_SECRET_KEY", ""),
+		SteadfastWebhookToken: getEnv("STEADFAST_WEBHOOK_TOKEN", ""),
+		RedXBaseURL:           getEnv("REDX_BASE_URL", "https://sandbox.redx.com.bd/v1.0.0-beta"),
+		RedXAPIToken:          getEnv("REDX_API_TOKEN", ""),
+		RedXWebhookToken:      getEnv("REDX_WEBHOOK_TOKEN", ""),
+		CourierFake:           getBoolEnv("COURIER_FAKE", false),
+		CourierFakeSecret:     getEnv("COURIER_FAKE_SECRET", ""),
+
+		StoreName:      getEnv("STORE_NAME", "Valancis"),
+		StoreAddress:   getEnv("STORE_ADDRESS", ""),
+		StorePhone:     getEnv("STORE_PHONE", ""),
+		PDFFontPath:    getEnv("PDF_FONT_PATH", ""),
+		InvoiceArchive: getBoolEnv("INVOICE_ARCHIVE", false),
 	}
 
 	cfg.Validate()
@@ -161,3 +305,13 @@ func getInt64Env(key string, fallback int64) int64 {
 	}
 	return fallback
 }
+
+func getBoolEnv(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		log.Printf("Invalid bool for %s, using fallback", key)
+	}
+	return fallback
+}
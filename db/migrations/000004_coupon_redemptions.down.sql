@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "coupon_redemptions";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "coupon_code";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "discount_amount";
@@ -0,0 +1,18 @@
+ALTER TABLE "orders" ADD COLUMN "discount_amount" numeric(12, 2) DEFAULT '0' NOT NULL;
+ALTER TABLE "orders" ADD COLUMN "coupon_code" varchar(50);
+
+CREATE TABLE "coupon_redemptions" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"coupon_id" uuid NOT NULL,
+	"order_id" uuid NOT NULL CONSTRAINT "coupon_redemptions_order_id_key" UNIQUE,
+	"user_id" uuid NOT NULL,
+	"code" varchar(50) NOT NULL,
+	"discount_amount" numeric(12, 2) NOT NULL,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "coupon_redemptions_discount_amount_check" CHECK ((discount_amount >= (0)::numeric))
+);
+ALTER TABLE "coupon_redemptions" ADD CONSTRAINT "coupon_redemptions_coupon_id_fkey" FOREIGN KEY ("coupon_id") REFERENCES "coupons"("id") ON DELETE RESTRICT;
+ALTER TABLE "coupon_redemptions" ADD CONSTRAINT "coupon_redemptions_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_redemptions" ADD CONSTRAINT "coupon_redemptions_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_coupon_redemptions_coupon_id" ON "coupon_redemptions" ("coupon_id");
+CREATE INDEX "idx_coupon_redemptions_user_id" ON "coupon_redemptions" ("user_id");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "idx_coupon_redemptions_coupon_user";
+DROP TABLE IF EXISTS "coupon_collections";
+DROP TABLE IF EXISTS "coupon_categories";
+DROP TABLE IF EXISTS "coupon_products";
+ALTER TABLE "coupons" DROP COLUMN IF EXISTS "first_order_only";
+ALTER TABLE "coupons" DROP COLUMN IF EXISTS "max_discount";
+ALTER TABLE "coupons" DROP COLUMN IF EXISTS "per_user_limit";
@@ -0,0 +1,26 @@
+ALTER TABLE "coupons" ADD COLUMN "per_user_limit" integer DEFAULT 0 NOT NULL;
+ALTER TABLE "coupons" ADD COLUMN "max_discount" numeric(12, 2);
+ALTER TABLE "coupons" ADD COLUMN "first_order_only" boolean DEFAULT false NOT NULL;
+
+CREATE TABLE "coupon_products" (
+	"coupon_id" uuid,
+	"product_id" uuid,
+	CONSTRAINT "coupon_products_pkey" PRIMARY KEY("coupon_id","product_id")
+);
+CREATE TABLE "coupon_categories" (
+	"coupon_id" uuid,
+	"category_id" uuid,
+	CONSTRAINT "coupon_categories_pkey" PRIMARY KEY("coupon_id","category_id")
+);
+CREATE TABLE "coupon_collections" (
+	"coupon_id" uuid,
+	"collection_id" uuid,
+	CONSTRAINT "coupon_collections_pkey" PRIMARY KEY("coupon_id","collection_id")
+);
+ALTER TABLE "coupon_products" ADD CONSTRAINT "coupon_products_coupon_id_fkey" FOREIGN KEY ("coupon_id") REFERENCES "coupons"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_products" ADD CONSTRAINT "coupon_products_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_categories" ADD CONSTRAINT "coupon_categories_coupon_id_fkey" FOREIGN KEY ("coupon_id") REFERENCES "coupons"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_categories" ADD CONSTRAINT "coupon_categories_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_collections" ADD CONSTRAINT "coupon_collections_coupon_id_fkey" FOREIGN KEY ("coupon_id") REFERENCES "coupons"("id") ON DELETE CASCADE;
+ALTER TABLE "coupon_collections" ADD CONSTRAINT "coupon_collections_collection_id_fkey" FOREIGN KEY ("collection_id") REFERENCES "collections"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_coupon_redemptions_coupon_user" ON "coupon_redemptions" ("coupon_id", "user_id");
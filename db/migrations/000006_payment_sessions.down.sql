@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "payment_callbacks";
+DROP TABLE IF EXISTS "payment_sessions";
@@ -0,0 +1,31 @@
+CREATE TABLE "payment_sessions" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"provider" varchar(30) NOT NULL,
+	"amount" numeric(12, 2) NOT NULL,
+	"currency" varchar(3) DEFAULT 'BDT' NOT NULL,
+	"status" varchar(20) DEFAULT 'initiated' NOT NULL,
+	"gateway_ref" varchar(255),
+	"redirect_url" text,
+	"transaction_id" varchar(255),
+	"completed_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "payment_sessions_amount_check" CHECK ((amount > (0)::numeric)),
+	CONSTRAINT "payment_sessions_status_check" CHECK (((status)::text = ANY ((ARRAY['initiated'::character varying, 'succeeded'::character varying, 'failed'::character varying, 'cancelled'::character varying])::text[])))
+);
+-- Every verified gateway notification, keyed so a replayed callback is a no-op
+CREATE TABLE "payment_callbacks" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"provider" varchar(30) NOT NULL,
+	"event_key" varchar(255) NOT NULL,
+	"session_id" uuid NOT NULL,
+	"status" varchar(20) NOT NULL,
+	"payload" jsonb DEFAULT '{}' NOT NULL,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "payment_callbacks_provider_event_key_key" UNIQUE("provider","event_key")
+);
+ALTER TABLE "payment_sessions" ADD CONSTRAINT "payment_sessions_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "payment_callbacks" ADD CONSTRAINT "payment_callbacks_session_id_fkey" FOREIGN KEY ("session_id") REFERENCES "payment_sessions"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_payment_sessions_order_id" ON "payment_sessions" ("order_id");
+CREATE INDEX "idx_payment_sessions_gateway_ref" ON "payment_sessions" ("provider", "gateway_ref");
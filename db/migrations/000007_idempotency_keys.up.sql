@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+	"user_id" uuid NOT NULL,
+	"key" varchar(255) NOT NULL,
+	"request_hash" varchar(64) NOT NULL,
+	"status_code" integer,
+	"content_type" varchar(100),
+	"response_body" bytea,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	CONSTRAINT "idempotency_keys_pkey" PRIMARY KEY("user_id","key")
+);
+CREATE INDEX "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_inventory_logs_reference_id";
+DROP TABLE IF EXISTS "stock_reservations";
@@ -0,0 +1,23 @@
+-- Time-limited stock holds for orders awaiting online payment.
+-- active: counted against availability until expires_at
+-- expired: timed out and no longer counted; renewed on the next payment attempt
+-- committed: turned into a permanent stock deduction (see inventory_logs)
+-- released: hold dropped (order cancelled) without touching stock
+CREATE TABLE "stock_reservations" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"variant_id" uuid NOT NULL,
+	"quantity" integer NOT NULL,
+	"status" varchar(20) DEFAULT 'active' NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "stock_reservations_quantity_check" CHECK ((quantity > 0)),
+	CONSTRAINT "stock_reservations_status_check" CHECK (((status)::text = ANY ((ARRAY['active'::character varying, 'expired'::character varying, 'committed'::character varying, 'released'::character varying])::text[])))
+);
+ALTER TABLE "stock_reservations" ADD CONSTRAINT "stock_reservations_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "stock_reservations" ADD CONSTRAINT "stock_reservations_variant_id_fkey" FOREIGN KEY ("variant_id") REFERENCES "variants"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_stock_reservations_order_id" ON "stock_reservations" ("order_id");
+CREATE INDEX "idx_stock_reservations_active_variant" ON "stock_reservations" ("variant_id") WHERE status = 'active';
+CREATE INDEX "idx_stock_reservations_active_expiry" ON "stock_reservations" ("expires_at") WHERE status = 'active';
+CREATE INDEX "idx_inventory_logs_reference_id" ON "inventory_logs" ("reference_id");
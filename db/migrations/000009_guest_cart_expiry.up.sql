@@ -0,0 +1,2 @@
+-- Guest carts idle past the TTL are swept; this keeps the scan to ownerless carts.
+CREATE INDEX "idx_carts_guest_updated_at" ON "carts" ("updated_at") WHERE user_id IS NULL;
@@ -0,0 +1,3 @@
+-- Fails while several guest users share an email
+DROP INDEX IF EXISTS "users_email_key";
+ALTER TABLE "users" ADD CONSTRAINT "users_email_key" UNIQUE ("email");
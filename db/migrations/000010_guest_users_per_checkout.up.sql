@@ -0,0 +1,4 @@
+-- Every guest checkout gets its own guest user, so guests may share an email. Accounts
+-- stay unique per email; signing in never takes over a guest's orders.
+ALTER TABLE "users" DROP CONSTRAINT "users_email_key";
+CREATE UNIQUE INDEX "users_email_key" ON "users" ("email") WHERE role <> 'guest';
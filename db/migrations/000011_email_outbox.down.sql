@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "email_outbox";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "locale";
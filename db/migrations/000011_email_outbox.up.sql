@@ -0,0 +1,29 @@
+-- Language customer notifications for an order are written in ('en' or 'bn').
+ALTER TABLE "orders" ADD COLUMN "locale" varchar(5) DEFAULT 'en' NOT NULL;
+
+-- Transactional email outbox. Rows are written in the same transaction as the change
+-- they announce, so a rollback never sends an email; a background dispatcher delivers them.
+-- pending: waiting for (re)delivery at next_attempt_at
+-- sending: claimed by a dispatcher until next_attempt_at (lease), then retried
+-- sent / failed: final (failed after the maximum number of attempts)
+CREATE TABLE "email_outbox" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid,
+	"template" varchar(50) NOT NULL,
+	"locale" varchar(5) DEFAULT 'en' NOT NULL,
+	"to_address" text NOT NULL,
+	"subject" text NOT NULL,
+	"html_body" text NOT NULL,
+	"text_body" text NOT NULL,
+	"status" varchar(20) DEFAULT 'pending' NOT NULL,
+	"attempts" integer DEFAULT 0 NOT NULL,
+	"last_error" text,
+	"next_attempt_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"sent_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "email_outbox_status_check" CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'sending'::character varying, 'sent'::character varying, 'failed'::character varying])::text[])))
+);
+ALTER TABLE "email_outbox" ADD CONSTRAINT "email_outbox_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE SET NULL;
+CREATE INDEX "idx_email_outbox_order_id" ON "email_outbox" ("order_id");
+CREATE INDEX "idx_email_outbox_due" ON "email_outbox" ("next_attempt_at") WHERE status IN ('pending', 'sending');
@@ -0,0 +1,15 @@
+-- One-time codes sent by SMS to confirm the phone number of COD orders.
+-- Only the latest code of an order is valid; each allows max_attempts guesses until expires_at.
+CREATE TABLE "order_otps" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"phone" varchar(20) NOT NULL,
+	"code_hash" text NOT NULL,
+	"attempts" integer DEFAULT 0 NOT NULL,
+	"max_attempts" integer NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"verified_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+ALTER TABLE "order_otps" ADD CONSTRAINT "order_otps_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_order_otps_order_id" ON "order_otps" ("order_id", "created_at" DESC);
@@ -0,0 +1,35 @@
+-- Courier consignments booked for orders (Pathao, Steadfast, RedX, ...)
+CREATE TABLE "shipments" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"provider" varchar(30) NOT NULL,
+	"consignment_id" varchar(100) NOT NULL,
+	"tracking_code" varchar(100),
+	"tracking_url" text,
+	"label_url" text,
+	"cod_amount" numeric(12, 2) DEFAULT '0' NOT NULL,
+	"status" varchar(20) DEFAULT 'booked' NOT NULL,
+	"courier_status" varchar(100),
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "shipments_provider_consignment_id_key" UNIQUE("provider","consignment_id"),
+	CONSTRAINT "shipments_cod_amount_check" CHECK ((cod_amount >= (0)::numeric)),
+	CONSTRAINT "shipments_status_check" CHECK (((status)::text = ANY ((ARRAY['booked'::character varying, 'picked_up'::character varying, 'in_transit'::character varying, 'delivered'::character varying, 'returned'::character varying, 'cancelled'::character varying])::text[])))
+);
+-- Every verified courier webhook, keyed so a replayed notification is a no-op
+CREATE TABLE "courier_events" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"provider" varchar(30) NOT NULL,
+	"event_key" varchar(255) NOT NULL,
+	"shipment_id" uuid NOT NULL,
+	"status" varchar(20),
+	"courier_status" varchar(100) NOT NULL,
+	"payload" jsonb DEFAULT '{}' NOT NULL,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "courier_events_provider_event_key_key" UNIQUE("provider","event_key")
+);
+ALTER TABLE "shipments" ADD CONSTRAINT "shipments_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "courier_events" ADD CONSTRAINT "courier_events_shipment_id_fkey" FOREIGN KEY ("shipment_id") REFERENCES "shipments"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_shipments_order_id" ON "shipments" ("order_id");
+-- At most one consignment in flight per order
+CREATE UNIQUE INDEX "idx_shipments_active_order" ON "shipments" ("order_id") WHERE status IN ('booked', 'picked_up', 'in_transit');
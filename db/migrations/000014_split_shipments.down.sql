@@ -0,0 +1,4 @@
+ALTER TABLE "order_history" DROP CONSTRAINT IF EXISTS "order_history_shipment_id_fkey";
+ALTER TABLE "order_history" DROP COLUMN IF EXISTS "shipment_id";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_shipments_active_order" ON "shipments" ("order_id") WHERE status IN ('booked', 'picked_up', 'in_transit');
+DROP TABLE IF EXISTS "shipment_items";
@@ -0,0 +1,24 @@
+-- Which order item quantities travel in each shipment (an order may ship in parts)
+CREATE TABLE "shipment_items" (
+	"shipment_id" uuid NOT NULL,
+	"order_item_id" uuid NOT NULL,
+	"quantity" integer NOT NULL,
+	CONSTRAINT "shipment_items_pkey" PRIMARY KEY("shipment_id","order_item_id"),
+	CONSTRAINT "shipment_items_quantity_check" CHECK ((quantity > 0))
+);
+ALTER TABLE "shipment_items" ADD CONSTRAINT "shipment_items_shipment_id_fkey" FOREIGN KEY ("shipment_id") REFERENCES "shipments"("id") ON DELETE CASCADE;
+ALTER TABLE "shipment_items" ADD CONSTRAINT "shipment_items_order_item_id_fkey" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_shipment_items_order_item_id" ON "shipment_items" ("order_item_id");
+
+-- Shipments booked so far carried the whole order
+INSERT INTO "shipment_items" ("shipment_id", "order_item_id", "quantity")
+SELECT s.id, oi.id, oi.quantity
+FROM "shipments" s
+JOIN "order_items" oi ON oi.order_id = s.order_id;
+
+-- Several shipments of one order can now be in flight at the same time
+DROP INDEX IF EXISTS "idx_shipments_active_order";
+
+-- Shipment events in the order history point at their shipment
+ALTER TABLE "order_history" ADD COLUMN "shipment_id" uuid;
+ALTER TABLE "order_history" ADD CONSTRAINT "order_history_shipment_id_fkey" FOREIGN KEY ("shipment_id") REFERENCES "shipments"("id") ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "order_return_items";
+DROP TABLE IF EXISTS "order_returns";
@@ -0,0 +1,38 @@
+-- Customer return requests (RMA) against delivered order items
+CREATE TABLE "order_returns" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"user_id" uuid,
+	"status" varchar(20) DEFAULT 'requested' NOT NULL,
+	"reason" varchar(30) NOT NULL,
+	"note" text,
+	"photos" text[] DEFAULT '{}' NOT NULL,
+	"admin_note" text,
+	"refund_amount" numeric(12, 2) DEFAULT '0' NOT NULL,
+	"reviewed_by" uuid,
+	"received_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "order_returns_refund_amount_check" CHECK ((refund_amount >= (0)::numeric)),
+	CONSTRAINT "order_returns_status_check" CHECK (((status)::text = ANY ((ARRAY['requested'::character varying, 'approved'::character varying, 'rejected'::character varying, 'received'::character varying, 'refunded'::character varying])::text[]))),
+	CONSTRAINT "order_returns_reason_check" CHECK (((reason)::text = ANY ((ARRAY['damaged'::character varying, 'defective'::character varying, 'wrong_item'::character varying, 'size_fit'::character varying, 'not_as_described'::character varying, 'changed_mind'::character varying, 'other'::character varying])::text[])))
+);
+-- Requested, approved and received quantity of each order item in a return
+CREATE TABLE "order_return_items" (
+	"return_id" uuid NOT NULL,
+	"order_item_id" uuid NOT NULL,
+	"quantity" integer NOT NULL,
+	"approved_quantity" integer DEFAULT 0 NOT NULL,
+	"received_quantity" integer DEFAULT 0 NOT NULL,
+	CONSTRAINT "order_return_items_pkey" PRIMARY KEY("return_id","order_item_id"),
+	CONSTRAINT "order_return_items_quantity_check" CHECK ((quantity > 0)),
+	CONSTRAINT "order_return_items_approved_quantity_check" CHECK ((approved_quantity >= 0 AND approved_quantity <= quantity)),
+	CONSTRAINT "order_return_items_received_quantity_check" CHECK ((received_quantity >= 0 AND received_quantity <= approved_quantity))
+);
+ALTER TABLE "order_returns" ADD CONSTRAINT "order_returns_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "order_returns" ADD CONSTRAINT "order_returns_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL;
+ALTER TABLE "order_returns" ADD CONSTRAINT "order_returns_reviewed_by_fkey" FOREIGN KEY ("reviewed_by") REFERENCES "users"("id") ON DELETE SET NULL;
+ALTER TABLE "order_return_items" ADD CONSTRAINT "order_return_items_return_id_fkey" FOREIGN KEY ("return_id") REFERENCES "order_returns"("id") ON DELETE CASCADE;
+ALTER TABLE "order_return_items" ADD CONSTRAINT "order_return_items_order_item_id_fkey" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_order_returns_order_id" ON "order_returns" ("order_id");
+CREATE INDEX "idx_order_returns_status_created_at" ON "order_returns" ("status", "created_at" DESC);
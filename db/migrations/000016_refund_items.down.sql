@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "refund_items";
+ALTER TABLE "refunds" DROP CONSTRAINT IF EXISTS "refunds_shipping_amount_check";
+ALTER TABLE "refunds" DROP CONSTRAINT IF EXISTS "refunds_method_check";
+ALTER TABLE "refunds" DROP COLUMN IF EXISTS "shipping_amount";
+ALTER TABLE "refunds" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE "refunds" DROP COLUMN IF EXISTS "method";
@@ -0,0 +1,18 @@
+-- How each refund was paid out, and which part of the shipping fee it covers
+ALTER TABLE "refunds" ADD COLUMN "method" varchar(20) DEFAULT 'cash' NOT NULL;
+ALTER TABLE "refunds" ADD COLUMN "reference" varchar(100);
+ALTER TABLE "refunds" ADD COLUMN "shipping_amount" numeric(12, 2) DEFAULT '0' NOT NULL;
+ALTER TABLE "refunds" ADD CONSTRAINT "refunds_method_check" CHECK (((method)::text = ANY ((ARRAY['cash'::character varying, 'bkash'::character varying, 'store_credit'::character varying])::text[])));
+ALTER TABLE "refunds" ADD CONSTRAINT "refunds_shipping_amount_check" CHECK ((shipping_amount >= (0)::numeric));
+-- Order item quantities a refund pays back (and restocks when restock_items is set)
+CREATE TABLE "refund_items" (
+	"refund_id" uuid NOT NULL,
+	"order_item_id" uuid NOT NULL,
+	"quantity" integer NOT NULL,
+	"amount" numeric(12, 2) NOT NULL,
+	CONSTRAINT "refund_items_pkey" PRIMARY KEY("refund_id","order_item_id"),
+	CONSTRAINT "refund_items_quantity_check" CHECK ((quantity > 0)),
+	CONSTRAINT "refund_items_amount_check" CHECK ((amount >= (0)::numeric))
+);
+ALTER TABLE "refund_items" ADD CONSTRAINT "refund_items_refund_id_fkey" FOREIGN KEY ("refund_id") REFERENCES "refunds"("id") ON DELETE CASCADE;
+ALTER TABLE "refund_items" ADD CONSTRAINT "refund_items_order_item_id_fkey" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE "shipments" DROP CONSTRAINT IF EXISTS "shipments_exchange_id_fkey";
+ALTER TABLE "shipments" DROP COLUMN IF EXISTS "exchange_id";
+DROP TABLE IF EXISTS "order_exchanges";
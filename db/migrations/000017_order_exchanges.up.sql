@@ -0,0 +1,31 @@
+-- Size/colour swaps of delivered order items: the replacement ships while the
+-- original comes back
+CREATE TABLE "order_exchanges" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"order_item_id" uuid NOT NULL,
+	"old_variant_id" uuid,
+	"new_variant_id" uuid,
+	"new_variant_name" varchar(255) NOT NULL,
+	"quantity" integer NOT NULL,
+	"unit_price" numeric(12, 2) NOT NULL,
+	"price_difference" numeric(12, 2) DEFAULT '0' NOT NULL,
+	"status" varchar(20) DEFAULT 'awaiting_return' NOT NULL,
+	"note" text,
+	"created_by" uuid,
+	"received_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "order_exchanges_quantity_check" CHECK ((quantity > 0)),
+	CONSTRAINT "order_exchanges_status_check" CHECK (((status)::text = ANY ((ARRAY['awaiting_return'::character varying, 'received'::character varying])::text[])))
+);
+ALTER TABLE "order_exchanges" ADD CONSTRAINT "order_exchanges_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "order_exchanges" ADD CONSTRAINT "order_exchanges_order_item_id_fkey" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id") ON DELETE CASCADE;
+ALTER TABLE "order_exchanges" ADD CONSTRAINT "order_exchanges_old_variant_id_fkey" FOREIGN KEY ("old_variant_id") REFERENCES "variants"("id") ON DELETE SET NULL;
+ALTER TABLE "order_exchanges" ADD CONSTRAINT "order_exchanges_new_variant_id_fkey" FOREIGN KEY ("new_variant_id") REFERENCES "variants"("id") ON DELETE SET NULL;
+ALTER TABLE "order_exchanges" ADD CONSTRAINT "order_exchanges_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
+CREATE INDEX "idx_order_exchanges_order_id" ON "order_exchanges" ("order_id");
+
+-- Replacement shipments point at their exchange (they carry no order items)
+ALTER TABLE "shipments" ADD COLUMN "exchange_id" uuid;
+ALTER TABLE "shipments" ADD CONSTRAINT "shipments_exchange_id_fkey" FOREIGN KEY ("exchange_id") REFERENCES "order_exchanges"("id") ON DELETE SET NULL;
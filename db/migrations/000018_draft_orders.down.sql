@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "draft_order_items";
+DROP TABLE IF EXISTS "draft_orders";
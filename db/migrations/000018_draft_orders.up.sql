@@ -0,0 +1,40 @@
+-- Admin-built draft orders (phone / Messenger orders), finalised into real orders by an
+-- admin or by the customer through a signed pay/confirm link
+CREATE TABLE "draft_orders" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"user_id" uuid NOT NULL,
+	"status" varchar(20) DEFAULT 'draft' NOT NULL,
+	"shipping_address" jsonb DEFAULT '{}' NOT NULL,
+	"payment_method" varchar(100) DEFAULT 'cod' NOT NULL,
+	"discount_amount" numeric(12, 2) DEFAULT '0' NOT NULL,
+	"discount_reason" text,
+	"note" text,
+	"locale" varchar(5) DEFAULT 'en' NOT NULL,
+	"order_id" uuid,
+	"created_by" uuid,
+	"sent_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "draft_orders_discount_amount_check" CHECK ((discount_amount >= (0)::numeric)),
+	CONSTRAINT "draft_orders_status_check" CHECK (((status)::text = ANY ((ARRAY['draft'::character varying, 'sent'::character varying, 'completed'::character varying, 'cancelled'::character varying])::text[])))
+);
+-- Variants picked for a draft; price_override replaces the current price when set
+CREATE TABLE "draft_order_items" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"draft_id" uuid NOT NULL,
+	"product_id" uuid NOT NULL,
+	"variant_id" uuid NOT NULL,
+	"quantity" integer NOT NULL,
+	"price_override" numeric(10, 2),
+	"override_reason" text,
+	CONSTRAINT "draft_order_items_quantity_check" CHECK ((quantity > 0)),
+	CONSTRAINT "draft_order_items_price_override_check" CHECK ((price_override > (0)::numeric))
+);
+ALTER TABLE "draft_orders" ADD CONSTRAINT "draft_orders_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE;
+ALTER TABLE "draft_orders" ADD CONSTRAINT "draft_orders_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE SET NULL;
+ALTER TABLE "draft_orders" ADD CONSTRAINT "draft_orders_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
+ALTER TABLE "draft_order_items" ADD CONSTRAINT "draft_order_items_draft_id_fkey" FOREIGN KEY ("draft_id") REFERENCES "draft_orders"("id") ON DELETE CASCADE;
+ALTER TABLE "draft_order_items" ADD CONSTRAINT "draft_order_items_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE;
+ALTER TABLE "draft_order_items" ADD CONSTRAINT "draft_order_items_variant_id_fkey" FOREIGN KEY ("variant_id") REFERENCES "variants"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_draft_orders_status_created_at" ON "draft_orders" ("status", "created_at" DESC);
+CREATE INDEX "idx_draft_order_items_draft_id" ON "draft_order_items" ("draft_id");
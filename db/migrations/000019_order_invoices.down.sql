@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "order_invoices";
+DROP SEQUENCE IF EXISTS "invoice_number_seq";
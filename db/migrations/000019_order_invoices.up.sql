@@ -0,0 +1,13 @@
+-- Invoice numbers: issued once per order from a sequence, so reprints keep their number
+CREATE SEQUENCE "invoice_number_seq" START WITH 1;
+
+CREATE TABLE "order_invoices" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"invoice_number" varchar(32) NOT NULL,
+	"pdf_url" text,
+	"issued_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "order_invoices_order_id_key" UNIQUE ("order_id"),
+	CONSTRAINT "order_invoices_invoice_number_key" UNIQUE ("invoice_number")
+);
+ALTER TABLE "order_invoices" ADD CONSTRAINT "order_invoices_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE RESTRICT;
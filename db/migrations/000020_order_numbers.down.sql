@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_orders_order_number_trgm";
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "orders_order_number_key";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "order_number";
+DROP TABLE IF EXISTS "order_number_counters";
@@ -0,0 +1,27 @@
+-- Human-readable order numbers (VAL-26-000123), gap-free per year: each year's counter
+-- row is advanced in the transaction that inserts the order, so a rolled-back checkout
+-- gives its number back
+CREATE TABLE "order_number_counters" (
+	"year" integer PRIMARY KEY,
+	"last_number" integer NOT NULL
+);
+
+ALTER TABLE "orders" ADD COLUMN "order_number" varchar(20);
+
+-- Number existing orders by creation time
+WITH numbered AS (
+	SELECT id, EXTRACT(YEAR FROM created_at)::int AS year,
+		row_number() OVER (PARTITION BY EXTRACT(YEAR FROM created_at) ORDER BY created_at, id) AS n
+	FROM orders
+)
+UPDATE orders o
+SET order_number = 'VAL-' || lpad((numbered.year % 100)::text, 2, '0') || '-' || lpad(numbered.n::text, GREATEST(6, length(numbered.n::text)), '0')
+FROM numbered
+WHERE numbered.id = o.id;
+
+INSERT INTO "order_number_counters" ("year", "last_number")
+SELECT EXTRACT(YEAR FROM created_at)::int, COUNT(*) FROM orders GROUP BY 1;
+
+ALTER TABLE "orders" ALTER COLUMN "order_number" SET NOT NULL;
+ALTER TABLE "orders" ADD CONSTRAINT "orders_order_number_key" UNIQUE ("order_number");
+CREATE INDEX "idx_orders_order_number_trgm" ON "orders" USING gin ("order_number" gin_trgm_ops);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "preorder_balance_requests";
+DROP INDEX IF EXISTS "idx_order_items_open_preorder";
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "preorder_release_id";
+DROP TABLE IF EXISTS "preorder_releases";
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "products_preorder_limit_check";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "preorder_limit";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "preorder_release_date";
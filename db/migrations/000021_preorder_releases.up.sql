@@ -0,0 +1,41 @@
+-- Pre-order scheduling: when a pre-order product is expected and how many units may be
+-- pre-ordered before its next release (NULL = no cap)
+ALTER TABLE "products" ADD COLUMN "preorder_release_date" timestamp;
+ALTER TABLE "products" ADD COLUMN "preorder_limit" integer;
+ALTER TABLE "products" ADD CONSTRAINT "products_preorder_limit_check" CHECK ((preorder_limit >= 0));
+-- A released batch of a pre-order product: its open pre-ordered items become due
+CREATE TABLE "preorder_releases" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"product_id" uuid NOT NULL,
+	"note" text,
+	"released_by" uuid,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+-- Items not yet covered by a release count against the product's pre-order cap
+ALTER TABLE "order_items" ADD COLUMN "preorder_release_id" uuid;
+-- The outstanding balance of a released pre-order, payable through a signed link or
+-- recorded by an admin; at most one pending request per order
+CREATE TABLE "preorder_balance_requests" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"release_id" uuid NOT NULL,
+	"amount" numeric(12, 2) NOT NULL,
+	"status" varchar(20) DEFAULT 'pending' NOT NULL,
+	"paid_amount" numeric(12, 2) DEFAULT '0' NOT NULL,
+	"payment_method" varchar(100),
+	"reference" text,
+	"paid_at" timestamp,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "preorder_balance_requests_amount_check" CHECK ((amount > (0)::numeric)),
+	CONSTRAINT "preorder_balance_requests_status_check" CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'paid'::character varying, 'cancelled'::character varying])::text[])))
+);
+ALTER TABLE "preorder_releases" ADD CONSTRAINT "preorder_releases_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE;
+ALTER TABLE "preorder_releases" ADD CONSTRAINT "preorder_releases_released_by_fkey" FOREIGN KEY ("released_by") REFERENCES "users"("id") ON DELETE SET NULL;
+ALTER TABLE "order_items" ADD CONSTRAINT "order_items_preorder_release_id_fkey" FOREIGN KEY ("preorder_release_id") REFERENCES "preorder_releases"("id") ON DELETE SET NULL;
+ALTER TABLE "preorder_balance_requests" ADD CONSTRAINT "preorder_balance_requests_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "preorder_balance_requests" ADD CONSTRAINT "preorder_balance_requests_release_id_fkey" FOREIGN KEY ("release_id") REFERENCES "preorder_releases"("id") ON DELETE CASCADE;
+CREATE INDEX "idx_preorder_releases_product_id" ON "preorder_releases" ("product_id", "created_at" DESC);
+CREATE INDEX "idx_order_items_open_preorder" ON "order_items" ("product_id") WHERE preorder_release_id IS NULL;
+CREATE INDEX "idx_preorder_balance_requests_release_id" ON "preorder_balance_requests" ("release_id");
+CREATE UNIQUE INDEX "idx_preorder_balance_requests_pending_order" ON "preorder_balance_requests" ("order_id") WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- paid_amount keeps the verified total; unverified claims are not restored
+DROP TABLE IF EXISTS "payments";
@@ -0,0 +1,56 @@
+-- Payment ledger: one row per money movement of an order. Money in (deposits, advance
+-- payments, gateway captures, pre-order balances, COD collections) and money out
+-- (refunds) are recorded here; orders.paid_amount and orders.refunded_amount are the
+-- sums of the verified rows, kept in step by the application.
+CREATE TABLE "payments" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"order_id" uuid NOT NULL,
+	"direction" varchar(3) NOT NULL,
+	"amount" numeric(12, 2) NOT NULL,
+	"method" varchar(100) NOT NULL,
+	"provider" varchar(50),
+	"transaction_id" varchar(100),
+	"sender_number" varchar(20),
+	"status" varchar(20) DEFAULT 'pending' NOT NULL,
+	"session_id" uuid,
+	"refund_id" uuid,
+	"note" text,
+	"verified_by" uuid,
+	"verified_at" timestamp,
+	"created_by" uuid,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "payments_direction_check" CHECK (((direction)::text = ANY ((ARRAY['in'::character varying, 'out'::character varying])::text[]))),
+	CONSTRAINT "payments_amount_check" CHECK ((amount > (0)::numeric)),
+	CONSTRAINT "payments_status_check" CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'verified'::character varying, 'rejected'::character varying])::text[])))
+);
+ALTER TABLE "payments" ADD CONSTRAINT "payments_order_id_fkey" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE CASCADE;
+ALTER TABLE "payments" ADD CONSTRAINT "payments_session_id_fkey" FOREIGN KEY ("session_id") REFERENCES "payment_sessions"("id") ON DELETE SET NULL;
+ALTER TABLE "payments" ADD CONSTRAINT "payments_refund_id_fkey" FOREIGN KEY ("refund_id") REFERENCES "refunds"("id") ON DELETE SET NULL;
+ALTER TABLE "payments" ADD CONSTRAINT "payments_verified_by_fkey" FOREIGN KEY ("verified_by") REFERENCES "users"("id") ON DELETE SET NULL;
+ALTER TABLE "payments" ADD CONSTRAINT "payments_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
+CREATE INDEX "idx_payments_order_id" ON "payments" ("order_id", "created_at");
+CREATE INDEX "idx_payments_pending" ON "payments" ("order_id") WHERE status = 'pending';
+
+-- Backfill: the amount each order has been credited so far, verified unless its
+-- payment is still awaiting verification
+INSERT INTO "payments" ("order_id", "direction", "amount", "method", "provider", "transaction_id", "sender_number", "status", "verified_at", "note", "created_at")
+SELECT o.id, 'in', o.paid_amount, COALESCE(o.payment_method, 'cod'),
+	NULLIF(o.payment_details->>'provider', ''),
+	NULLIF(o.payment_details->>'transaction_id', ''),
+	NULLIF(o.payment_details->>'sender_number', ''),
+	CASE WHEN o.payment_status IN ('pending', 'pending_verification', 'failed') THEN 'pending' ELSE 'verified' END,
+	CASE WHEN o.payment_status IN ('pending', 'pending_verification', 'failed') THEN NULL ELSE o.updated_at END,
+	'Recorded before the payment ledger',
+	o.created_at
+FROM orders o
+WHERE o.paid_amount > 0;
+
+-- Backfill: every refund paid out
+INSERT INTO "payments" ("order_id", "direction", "amount", "method", "transaction_id", "status", "refund_id", "verified_by", "verified_at", "created_by", "created_at")
+SELECT r.order_id, 'out', r.amount, r.method, r.reference, 'verified', r.id, r.created_by, r.created_at, r.created_by, r.created_at
+FROM refunds r
+WHERE r.amount > 0;
+
+UPDATE orders o
+SET paid_amount = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = o.id AND p.direction = 'in' AND p.status = 'verified'), 0),
+	refunded_amount = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = o.id AND p.direction = 'out' AND p.status = 'verified'), 0);
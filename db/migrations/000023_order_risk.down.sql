@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS "idx_orders_risk_score";
+DROP INDEX IF EXISTS "idx_orders_ip_address";
+DROP INDEX IF EXISTS "idx_orders_customer_phone";
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "orders_risk_score_check";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "risk_reasons";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "risk_score";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "ip_address";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "customer_phone";
@@ -0,0 +1,18 @@
+-- COD fraud risk: who placed an order (normalized phone, client IP) and how risky it
+-- looked at checkout. risk_score is NULL for orders placed before scoring existed.
+ALTER TABLE "orders" ADD COLUMN "customer_phone" varchar(20);
+ALTER TABLE "orders" ADD COLUMN "ip_address" varchar(45);
+ALTER TABLE "orders" ADD COLUMN "risk_score" integer;
+ALTER TABLE "orders" ADD COLUMN "risk_reasons" jsonb DEFAULT '[]'::jsonb NOT NULL;
+ALTER TABLE "orders" ADD CONSTRAINT "orders_risk_score_check" CHECK (((risk_score >= 0) AND (risk_score <= 100)));
+-- Past orders count towards the phone history of new ones (same rules as utils.NormalizePhone)
+UPDATE "orders" o
+SET "customer_phone" = p.phone
+FROM (
+	SELECT id, regexp_replace(regexp_replace(shipping_address->>'phone', '[\s\-()+]', '', 'g'), '^880', '0') AS phone
+	FROM "orders"
+) p
+WHERE p.id = o.id AND p.phone ~ '^01[3-9][0-9]{8}$';
+CREATE INDEX "idx_orders_customer_phone" ON "orders" ("customer_phone", "created_at") WHERE (customer_phone IS NOT NULL);
+CREATE INDEX "idx_orders_ip_address" ON "orders" ("ip_address", "created_at") WHERE (ip_address IS NOT NULL);
+CREATE INDEX "idx_orders_risk_score" ON "orders" ("risk_score") WHERE (risk_score IS NOT NULL);
@@ -0,0 +1,20 @@
+-- Customer blocklist: phone numbers, emails, user IDs and IP ranges that may not order
+-- (action 'block') or may only order fully prepaid (action 'prepay'). Values are stored
+-- normalized: phones as 01XXXXXXXXX, emails lower-cased, IPs as CIDR ranges.
+CREATE TABLE "blocklist_entries" (
+	"id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+	"kind" varchar(10) NOT NULL,
+	"value" varchar(255) NOT NULL,
+	"action" varchar(10) DEFAULT 'block' NOT NULL,
+	"reason" text NOT NULL,
+	"expires_at" timestamp,
+	"source_order_id" uuid,
+	"created_by" uuid,
+	"created_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	"updated_at" timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT "blocklist_entries_kind_check" CHECK (((kind)::text = ANY ((ARRAY['phone'::character varying, 'email'::character varying, 'user'::character varying, 'ip'::character varying])::text[]))),
+	CONSTRAINT "blocklist_entries_action_check" CHECK (((action)::text = ANY ((ARRAY['block'::character varying, 'prepay'::character varying])::text[]))),
+	CONSTRAINT "blocklist_entries_kind_value_key" UNIQUE ("kind", "value")
+);
+ALTER TABLE "blocklist_entries" ADD CONSTRAINT "blocklist_entries_source_order_id_fkey" FOREIGN KEY ("source_order_id") REFERENCES "orders"("id") ON DELETE SET NULL;
+ALTER TABLE "blocklist_entries" ADD CONSTRAINT "blocklist_entries_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
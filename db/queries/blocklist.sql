@@ -0,0 +1,50 @@
+-- name: UpsertBlocklistEntry :one
+-- Adds an entry, or renews the existing one for the same kind and value.
+INSERT INTO blocklist_entries (kind, value, action, reason, expires_at, source_order_id, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (kind, value) DO UPDATE
+SET action = EXCLUDED.action,
+    reason = EXCLUDED.reason,
+    expires_at = EXCLUDED.expires_at,
+    source_order_id = COALESCE(EXCLUDED.source_order_id, blocklist_entries.source_order_id),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetBlocklistEntry :one
+SELECT * FROM blocklist_entries WHERE id = $1;
+
+-- name: UpdateBlocklistEntry :one
+UPDATE blocklist_entries
+SET action = $2, reason = $3, expires_at = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBlocklistEntry :execrows
+DELETE FROM blocklist_entries WHERE id = $1;
+
+-- name: ListBlocklistEntries :many
+SELECT * FROM blocklist_entries
+WHERE (sqlc.narg('kind')::text IS NULL OR kind = sqlc.narg('kind')::text)
+  AND (sqlc.narg('search')::text IS NULL OR value ILIKE '%' || sqlc.narg('search')::text || '%' OR reason ILIKE '%' || sqlc.narg('search')::text || '%')
+  AND (sqlc.narg('active')::boolean IS NULL OR (expires_at IS NULL OR expires_at > NOW()) = sqlc.narg('active')::boolean)
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountBlocklistEntries :one
+SELECT COUNT(*) FROM blocklist_entries
+WHERE (sqlc.narg('kind')::text IS NULL OR kind = sqlc.narg('kind')::text)
+  AND (sqlc.narg('search')::text IS NULL OR value ILIKE '%' || sqlc.narg('search')::text || '%' OR reason ILIKE '%' || sqlc.narg('search')::text || '%')
+  AND (sqlc.narg('active')::boolean IS NULL OR (expires_at IS NULL OR expires_at > NOW()) = sqlc.narg('active')::boolean);
+
+-- name: MatchBlocklistEntries :many
+-- Unexpired entries matching any of the identifiers, 'block' entries first. IP entries
+-- match when their range contains the address.
+SELECT * FROM blocklist_entries
+WHERE (expires_at IS NULL OR expires_at > NOW())
+  AND (
+       (kind = 'phone' AND value = sqlc.narg('phone')::text)
+    OR (kind = 'email' AND value = sqlc.narg('email')::text)
+    OR (kind = 'user' AND value = sqlc.narg('user_id')::text)
+    OR (CASE WHEN kind = 'ip' THEN value::cidr >>= sqlc.narg('ip_address')::inet ELSE false END)
+  )
+ORDER BY CASE action WHEN 'block' THEN 0 ELSE 1 END, created_at DESC;
@@ -1,6 +1,6 @@
 -- name: CreateCoupon :one
-INSERT INTO coupons (code, type, value, min_spend, usage_limit, start_at, expires_at, is_active)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO coupons (code, type, value, min_spend, usage_limit, start_at, expires_at, is_active, per_user_limit, max_discount, first_order_only)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetCouponByCode :one
@@ -11,31 +11,69 @@ WHERE code = $1;
 -- L9 Optimization: Single-pass validation logic pushed to DB.
 -- Returns the coupon if valid, or a status reason if not.
 -- Uses covering indexes on (code) and partial indexes on (is_active) where applicable.
+-- FOR UPDATE: inside checkout the row stays locked until commit so usage_limit cannot be overshot.
+-- Scoped coupons (products/categories/collections) report which of @product_ids they discount.
 SELECT 
-    id, 
-    code, 
-    type, 
-    value, 
-    min_spend,
+    c.id, 
+    c.code, 
+    c.type, 
+    c.value, 
+    c.min_spend,
+    c.max_discount,
+    s.is_scoped::boolean as is_scoped,
+    s.eligible_product_ids::uuid[] as eligible_product_ids,
     CASE 
-        WHEN NOT is_active THEN 'inactive'
-        WHEN start_at > NOW() THEN 'not_started'
-        WHEN expires_at < NOW() THEN 'expired'
-        WHEN usage_limit > 0 AND used_count >= usage_limit THEN 'fully_redeemed'
-        WHEN min_spend > @cart_total::decimal THEN 'min_spend_not_met'
+        WHEN NOT c.is_active THEN 'inactive'
+        WHEN c.start_at > NOW() THEN 'not_started'
+        WHEN c.expires_at < NOW() THEN 'expired'
+        WHEN c.usage_limit > 0 AND c.used_count >= c.usage_limit THEN 'fully_redeemed'
+        WHEN c.per_user_limit > 0 AND (
+            SELECT COUNT(*) FROM coupon_redemptions cr
+            WHERE cr.coupon_id = c.id AND cr.user_id = @user_id::uuid
+        ) >= c.per_user_limit THEN 'per_user_limit_reached'
+        WHEN c.first_order_only AND EXISTS (
+            SELECT 1 FROM orders o
+            WHERE o.user_id = @user_id::uuid AND o.status NOT IN ('cancelled', 'fake')
+        ) THEN 'first_order_only'
+        WHEN s.is_scoped AND cardinality(s.eligible_product_ids) = 0 THEN 'not_applicable_to_cart'
+        WHEN c.min_spend > @cart_total::decimal THEN 'min_spend_not_met'
         ELSE 'valid'
     END::text as validation_status
-FROM coupons 
-WHERE code = @code;
+FROM coupons c
+CROSS JOIN LATERAL (
+    SELECT
+        (
+            EXISTS (SELECT 1 FROM coupon_products WHERE coupon_id = c.id)
+            OR EXISTS (SELECT 1 FROM coupon_categories WHERE coupon_id = c.id)
+            OR EXISTS (SELECT 1 FROM coupon_collections WHERE coupon_id = c.id)
+        ) as is_scoped,
+        ARRAY(
+            SELECT p.id FROM unnest(@product_ids::uuid[]) AS p(id)
+            WHERE EXISTS (
+                SELECT 1 FROM coupon_products cp
+                WHERE cp.coupon_id = c.id AND cp.product_id = p.id
+            ) OR EXISTS (
+                SELECT 1 FROM coupon_categories cc
+                JOIN product_categories pc ON pc.category_id = cc.category_id
+                WHERE cc.coupon_id = c.id AND pc.product_id = p.id
+            ) OR EXISTS (
+                SELECT 1 FROM coupon_collections ccl
+                JOIN product_collections pcl ON pcl.collection_id = ccl.collection_id
+                WHERE ccl.coupon_id = c.id AND pcl.product_id = p.id
+            )
+        ) as eligible_product_ids
+) s
+WHERE c.code = @code
+FOR UPDATE OF c;
 
 -- name: ListCoupons :many
 SELECT * FROM coupons 
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
--- name: IncrementCouponUsage :exec
+-- name: IncrementCouponUsage :execrows
 -- L9 Optimization: Atomic increment with optimistic concurrency check if needed.
--- We rely on db-level atomicity here.
+-- We rely on db-level atomicity here. Zero rows affected means the limit was reached.
 UPDATE coupons 
 SET used_count = used_count + 1 
 WHERE id = $1 AND (usage_limit = 0 OR used_count < usage_limit);
@@ -55,8 +93,57 @@ UPDATE coupons SET
     usage_limit = $6,
     start_at = $7,
     expires_at = $8,
-    is_active = $9
+    is_active = $9,
+    per_user_limit = $10,
+    max_discount = $11,
+    first_order_only = $12,
+    updated_at = NOW()
 WHERE id = $1;
 
 -- name: CountCoupons :one
 SELECT COUNT(*) FROM coupons;
+
+-- name: CreateCouponRedemption :one
+INSERT INTO coupon_redemptions (coupon_id, order_id, user_id, code, discount_amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCouponRedemptionByOrderID :one
+SELECT * FROM coupon_redemptions WHERE order_id = $1;
+
+-- Coupon scoping: a coupon with no rows in any of these tables applies to the whole cart.
+
+-- name: AddCouponProducts :exec
+INSERT INTO coupon_products (coupon_id, product_id)
+SELECT @coupon_id::uuid, unnest(@product_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: AddCouponCategories :exec
+INSERT INTO coupon_categories (coupon_id, category_id)
+SELECT @coupon_id::uuid, unnest(@category_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: AddCouponCollections :exec
+INSERT INTO coupon_collections (coupon_id, collection_id)
+SELECT @coupon_id::uuid, unnest(@collection_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: ClearCouponProducts :exec
+DELETE FROM coupon_products WHERE coupon_id = $1;
+
+-- name: ClearCouponCategories :exec
+DELETE FROM coupon_categories WHERE coupon_id = $1;
+
+-- name: ClearCouponCollections :exec
+DELETE FROM coupon_collections WHERE coupon_id = $1;
+
+-- name: ListCouponScopes :many
+-- One round-trip for all scope targets of a page of coupons.
+SELECT coupon_id, 'product'::text as scope_type, product_id as target_id
+FROM coupon_products WHERE coupon_id = ANY(@coupon_ids::uuid[])
+UNION ALL
+SELECT coupon_id, 'category'::text as scope_type, category_id as target_id
+FROM coupon_categories WHERE coupon_id = ANY(@coupon_ids::uuid[])
+UNION ALL
+SELECT coupon_id, 'collection'::text as scope_type, collection_id as target_id
+FROM coupon_collections WHERE coupon_id = ANY(@coupon_ids::uuid[]);
@@ -50,7 +50,8 @@ SELECT
   DATE(created_at) as date,
   COUNT(*)::int as order_count,
   COALESCE(SUM(total_amount), 0)::numeric as total_revenue,
-  COALESCE(AVG(total_amount), 0)::numeric as avg_order_value
+  COALESCE(AVG(total_amount), 0)::numeric as avg_order_value,
+  COALESCE(SUM(discount_amount), 0)::numeric as total_discount
 FROM orders
 WHERE created_at >= sqlc.arg(start_date)::timestamp 
   AND created_at <= sqlc.arg(end_date)::timestamp
@@ -65,7 +66,8 @@ SELECT
   COUNT(*)::bigint as total_orders,
   COALESCE(SUM(total_amount), 0)::numeric as total_revenue,
   COALESCE(AVG(total_amount), 0)::numeric as avg_order_value,
-  COUNT(DISTINCT user_id)::bigint as unique_customers
+  COUNT(DISTINCT user_id)::bigint as unique_customers,
+  COALESCE(SUM(discount_amount), 0)::numeric as total_discount
 FROM orders
 WHERE created_at >= sqlc.arg(start_date)::timestamp
   AND created_at <= sqlc.arg(end_date)::timestamp
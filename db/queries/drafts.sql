@@ -0,0 +1,64 @@
+-- name: CreateDraftOrder :one
+INSERT INTO draft_orders (user_id, shipping_address, payment_method, discount_amount, discount_reason, note, locale, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: UpdateDraftOrder :one
+UPDATE draft_orders
+SET shipping_address = $2,
+    payment_method = $3,
+    discount_amount = $4,
+    discount_reason = $5,
+    note = $6,
+    locale = $7,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDraftOrderByID :one
+SELECT * FROM draft_orders WHERE id = $1;
+
+-- name: GetDraftOrderForUpdate :one
+-- Serializes edits and finalisation of the same draft.
+SELECT * FROM draft_orders WHERE id = $1 FOR UPDATE;
+
+-- name: ListDraftOrders :many
+SELECT * FROM draft_orders
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountDraftOrders :one
+SELECT COUNT(*) FROM draft_orders
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'));
+
+-- name: AddDraftOrderItem :exec
+INSERT INTO draft_order_items (draft_id, product_id, variant_id, quantity, price_override, override_reason)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: DeleteDraftOrderItems :exec
+DELETE FROM draft_order_items WHERE draft_id = $1;
+
+-- name: ListDraftOrderItems :many
+SELECT di.*, p.name AS product_name, v.name AS variant_name
+FROM draft_order_items di
+JOIN products p ON p.id = di.product_id
+JOIN variants v ON v.id = di.variant_id
+WHERE di.draft_id = ANY(sqlc.arg('draft_ids')::uuid[])
+ORDER BY di.draft_id, p.name, v.name;
+
+-- name: MarkDraftOrderSent :exec
+UPDATE draft_orders
+SET status = 'sent', sent_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: CompleteDraftOrder :execrows
+-- Only an open draft can be completed, so a draft never turns into two orders.
+UPDATE draft_orders
+SET status = 'completed', order_id = $2, updated_at = NOW()
+WHERE id = $1 AND status IN ('draft', 'sent');
+
+-- name: CancelDraftOrder :execrows
+UPDATE draft_orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status IN ('draft', 'sent');
@@ -0,0 +1,19 @@
+-- name: CreateOrderExchange :one
+INSERT INTO order_exchanges (order_id, order_item_id, old_variant_id, new_variant_id, new_variant_name, quantity, unit_price, price_difference, note, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetOrderExchangeByID :one
+SELECT * FROM order_exchanges WHERE id = $1;
+
+-- name: GetOrderExchangeForUpdate :one
+-- Serializes receiving the same exchange twice.
+SELECT * FROM order_exchanges WHERE id = $1 FOR UPDATE;
+
+-- name: ListOrderExchangesByOrder :many
+SELECT * FROM order_exchanges WHERE order_id = $1 ORDER BY created_at DESC;
+
+-- name: MarkOrderExchangeReceived :exec
+UPDATE order_exchanges
+SET status = 'received', received_at = NOW(), updated_at = NOW()
+WHERE id = $1;
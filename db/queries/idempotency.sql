@@ -0,0 +1,27 @@
+-- name: ClaimIdempotencyKey :execrows
+-- Claims (user_id, key) for a new request. An expired row is taken over in place.
+-- Zero rows affected means a live record already exists.
+INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+VALUES (@user_id, @key, @request_hash, NOW() + make_interval(secs => @ttl_seconds::float8))
+ON CONFLICT (user_id, key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = NULL,
+    response_body = NULL,
+    created_at = NOW(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < NOW();
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE user_id = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at < NOW();
@@ -0,0 +1,15 @@
+-- name: GetInvoiceByOrderID :one
+SELECT * FROM order_invoices WHERE order_id = $1;
+
+-- name: CreateInvoice :one
+-- Returns no row when the order already has an invoice (a racing issuer won); the
+-- sequence value drawn for the losing insert is skipped. Numbers are zero-padded to
+-- six digits and grow past that.
+INSERT INTO order_invoices (order_id, invoice_number)
+SELECT @order_id, 'INV-' || lpad(n::text, GREATEST(6, length(n::text)), '0')
+FROM (SELECT nextval('invoice_number_seq') AS n) seq
+ON CONFLICT (order_id) DO NOTHING
+RETURNING *;
+
+-- name: SetInvoicePDFURL :exec
+UPDATE order_invoices SET pdf_url = $2 WHERE id = $1;
@@ -0,0 +1,34 @@
+-- name: CreatePayment :one
+INSERT INTO payments (
+    order_id, direction, amount, method, provider, transaction_id, sender_number,
+    status, session_id, refund_id, note, verified_by, verified_at, created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7,
+    $8, $9, $10, $11, $12, $13, $14
+)
+RETURNING *;
+
+-- name: ListPaymentsByOrder :many
+SELECT p.*, u.first_name AS verified_by_name
+FROM payments p
+LEFT JOIN users u ON u.id = p.verified_by
+WHERE p.order_id = $1
+ORDER BY p.created_at, p.id;
+
+-- name: ListPendingPaymentsForUpdate :many
+SELECT * FROM payments
+WHERE order_id = $1 AND direction = 'in' AND status = 'pending'
+ORDER BY created_at, id
+FOR UPDATE;
+
+-- name: VerifyPayment :execrows
+UPDATE payments
+SET status = 'verified', verified_by = $3, verified_at = NOW()
+WHERE id = $1 AND order_id = $2 AND status = 'pending';
+
+-- name: SyncOrderPaymentTotals :exec
+-- Re-derives the order's paid and refunded amounts from its verified ledger entries.
+UPDATE orders
+SET paid_amount = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = orders.id AND p.direction = 'in' AND p.status = 'verified'), 0),
+    refunded_amount = COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.order_id = orders.id AND p.direction = 'out' AND p.status = 'verified'), 0)
+WHERE id = $1;
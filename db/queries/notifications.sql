@@ -0,0 +1,36 @@
+-- name: EnqueueEmail :one
+INSERT INTO email_outbox (order_id, template, locale, to_address, subject, html_body, text_body)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ClaimDueEmails :many
+-- Claims a batch of due emails for delivery. A claim is a lease: if the dispatcher dies
+-- mid-send, the row becomes due again once the lease runs out.
+UPDATE email_outbox
+SET status = 'sending',
+    attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => @lease_seconds::float8),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT e.id FROM email_outbox e
+    WHERE e.status IN ('pending', 'sending') AND e.next_attempt_at <= NOW()
+    ORDER BY e.next_attempt_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET status = 'sent', sent_at = NOW(), last_error = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkEmailRetry :exec
+UPDATE email_outbox
+SET status = 'pending', last_error = @last_error, next_attempt_at = NOW() + make_interval(secs => @retry_seconds::float8), updated_at = NOW()
+WHERE id = @id;
+
+-- name: MarkEmailFailed :exec
+UPDATE email_outbox
+SET status = 'failed', last_error = @last_error, updated_at = NOW()
+WHERE id = @id;
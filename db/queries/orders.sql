@@ -4,6 +4,30 @@ SELECT * FROM carts WHERE user_id = $1;
 -- name: CreateCart :one
 INSERT INTO carts (user_id) VALUES ($1) RETURNING *;
 
+-- name: GetCartByID :one
+SELECT * FROM carts WHERE id = $1;
+
+-- name: AssignCartToUser :execrows
+-- Hands a guest cart over to a user who has no cart yet.
+UPDATE carts SET user_id = @user_id, updated_at = NOW()
+WHERE id = @id AND user_id IS NULL;
+
+-- name: DeleteCart :exec
+DELETE FROM carts WHERE id = $1;
+
+-- name: TouchCart :exec
+UPDATE carts SET updated_at = NOW() WHERE id = $1;
+
+-- name: DeleteIdleGuestCarts :execrows
+-- Deletes a batch of guest carts left untouched for the idle window; items cascade.
+DELETE FROM carts
+WHERE id IN (
+    SELECT c.id FROM carts c
+    WHERE c.user_id IS NULL AND c.updated_at < NOW() - make_interval(secs => @idle_seconds::float8)
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+);
+
 -- name: GetCartItems :many
 SELECT ci.*, p.name, p.slug, p.base_price, p.sale_price, p.media, p.is_preorder, p.preorder_deposit_amount, v.stock, v.sku
 FROM cart_items ci
@@ -39,6 +63,34 @@ LEFT JOIN products p ON ci.product_id = p.id
 LEFT JOIN variants v ON ci.variant_id = v.id
 WHERE c.user_id = $1;
 
+-- name: GetCartWithItemsByCartID :many
+SELECT 
+    c.id as cart_id,
+    c.user_id,
+    ci.id as item_id,
+    ci.product_id,
+    ci.variant_id,
+    ci.quantity,
+    p.name,
+    p.slug,
+    p.base_price,
+    p.sale_price,
+    p.media,
+    p.stock_status,
+    p.is_preorder,
+    p.preorder_deposit_amount,
+    v.stock,
+    v.sku as variant_sku,
+    v.name as variant_name,
+    v.images as variant_images,
+    v.price as variant_price,
+    v.sale_price as variant_sale_price
+FROM carts c
+LEFT JOIN cart_items ci ON c.id = ci.cart_id
+LEFT JOIN products p ON ci.product_id = p.id
+LEFT JOIN variants v ON ci.variant_id = v.id
+WHERE c.id = $1;
+
 
 -- name: GetCartItemByProductID :one
 SELECT * FROM cart_items WHERE cart_id = $1 AND product_id = $2;
@@ -48,13 +100,17 @@ SELECT * FROM cart_items WHERE cart_id = $1 AND product_id = $2;
 WITH 
   user_cart AS (
     SELECT c.id FROM carts c
-    WHERE c.id = sqlc.arg(cart_id) AND c.user_id = sqlc.arg(user_id)
+    -- Guest carts have no owner: a NULL user_id only matches a NULL owner
+    WHERE c.id = sqlc.arg(cart_id) AND c.user_id IS NOT DISTINCT FROM sqlc.narg(user_id)::uuid
   ),
   stock_valid AS (
     SELECT v.id FROM variants v
     JOIN products p ON p.id = v.product_id
     WHERE v.id = sqlc.arg(variant_id)
-      AND v.stock >= sqlc.arg(quantity)
+      AND v.stock - COALESCE((
+            SELECT SUM(sr.quantity) FROM stock_reservations sr
+            WHERE sr.variant_id = v.id AND sr.status = 'active' AND sr.expires_at > NOW()
+          ), 0) >= sqlc.arg(quantity)
       AND p.is_active = TRUE
   ),
   existing_item AS (
@@ -100,12 +156,24 @@ WHERE ci.cart_id = c.id
   AND ci.product_id = $2
   AND ci.variant_id = $3;
 
+-- name: RemoveCartItem :exec
+DELETE FROM cart_items
+WHERE cart_id = $1 AND product_id = $2 AND variant_id = $3;
+
 -- name: ClearCart :exec
 DELETE FROM cart_items WHERE cart_id = $1;
 
+-- name: NextOrderNumber :one
+-- Advances the year's order counter. The counter row stays locked until the
+-- transaction ends, so numbers are handed out in commit order without gaps.
+INSERT INTO order_number_counters (year, last_number)
+VALUES (EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::int, 1)
+ON CONFLICT (year) DO UPDATE SET last_number = order_number_counters.last_number + 1
+RETURNING year, last_number;
+
 -- name: CreateOrder :one
-INSERT INTO orders (user_id, status, total_amount, shipping_fee, shipping_address, payment_method, payment_status, paid_amount, payment_details, is_preorder)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+INSERT INTO orders (user_id, status, total_amount, shipping_fee, shipping_address, payment_method, payment_status, paid_amount, payment_details, is_preorder, discount_amount, coupon_code, locale, customer_phone, ip_address, risk_score, risk_reasons, order_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING *;
 
 -- name: GetOrderByID :one
@@ -114,6 +182,13 @@ FROM orders o
 JOIN users u ON u.id = o.user_id
 WHERE o.id = $1;
 
+-- name: GetOrderIDByNumber :one
+SELECT id FROM orders WHERE order_number = $1;
+
+-- name: LockOrder :one
+-- Serializes claims on the order's items (returns, exchanges) until commit.
+SELECT id FROM orders WHERE id = $1 FOR UPDATE;
+
 -- name: GetOrdersByUserID :many
 SELECT * FROM orders WHERE user_id = $1 ORDER BY created_at DESC;
 
@@ -127,11 +202,14 @@ WHERE
     (sqlc.narg('payment_method')::text IS NULL OR o.payment_method = sqlc.narg('payment_method')) AND
     (sqlc.narg('is_preorder')::boolean IS NULL OR o.is_preorder = sqlc.narg('is_preorder')) AND
     (sqlc.narg('search')::text IS NULL OR 
+        o.order_number ILIKE '%' || sqlc.narg('search') || '%' OR 
         o.id::text ILIKE '%' || sqlc.narg('search') || '%' OR 
         u.email ILIKE '%' || sqlc.narg('search') || '%' OR 
         o.payment_details->>'transaction_id' ILIKE '%' || sqlc.narg('search') || '%' OR
         o.payment_details->>'sender_number' ILIKE '%' || sqlc.narg('search') || '%'
-    )
+    ) AND
+    (sqlc.narg('min_risk_score')::int IS NULL OR o.risk_score >= sqlc.narg('min_risk_score')) AND
+    (sqlc.narg('max_risk_score')::int IS NULL OR o.risk_score <= sqlc.narg('max_risk_score'))
 ORDER BY o.created_at DESC
 LIMIT $1 OFFSET $2;
 
@@ -145,11 +223,34 @@ WHERE
     (sqlc.narg('payment_method')::text IS NULL OR o.payment_method = sqlc.narg('payment_method')) AND
     (sqlc.narg('is_preorder')::boolean IS NULL OR o.is_preorder = sqlc.narg('is_preorder')) AND
     (sqlc.narg('search')::text IS NULL OR 
+        o.order_number ILIKE '%' || sqlc.narg('search') || '%' OR 
         o.id::text ILIKE '%' || sqlc.narg('search') || '%' OR 
         u.email ILIKE '%' || sqlc.narg('search') || '%' OR 
         o.payment_details->>'transaction_id' ILIKE '%' || sqlc.narg('search') || '%' OR
         o.payment_details->>'sender_number' ILIKE '%' || sqlc.narg('search') || '%'
-    );
+    ) AND
+    (sqlc.narg('min_risk_score')::int IS NULL OR o.risk_score >= sqlc.narg('min_risk_score')) AND
+    (sqlc.narg('max_risk_score')::int IS NULL OR o.risk_score <= sqlc.narg('max_risk_score'));
+
+-- name: GetCustomerRiskHistory :one
+-- Signals from the past orders of a phone, client IP and user for scoring a new order.
+-- Counts with a _recent suffix only cover orders placed since @since.
+SELECT
+    COUNT(*) FILTER (WHERE customer_phone = @phone)::int AS phone_orders,
+    COUNT(*) FILTER (WHERE customer_phone = @phone AND status = 'fake')::int AS phone_fake,
+    COUNT(*) FILTER (WHERE customer_phone = @phone AND status = 'returned')::int AS phone_returned,
+    COUNT(*) FILTER (WHERE customer_phone = @phone AND status = 'cancelled')::int AS phone_cancelled,
+    COUNT(*) FILTER (WHERE customer_phone = @phone AND created_at >= @since)::int AS phone_recent,
+    COUNT(DISTINCT user_id) FILTER (WHERE customer_phone = @phone AND user_id IS DISTINCT FROM @user_id)::int AS phone_other_users,
+    COUNT(*) FILTER (WHERE customer_phone = @phone AND shipping_address->>'district' = @district::text)::int AS phone_same_district,
+    COUNT(*) FILTER (WHERE ip_address = @ip_address AND created_at >= @since)::int AS ip_recent,
+    COUNT(*) FILTER (WHERE user_id = @user_id)::int AS user_orders,
+    COUNT(*) FILTER (WHERE user_id = @user_id AND customer_phone = @phone)::int AS user_phone_orders,
+    COUNT(*) FILTER (WHERE user_id = @user_id AND created_at >= @since)::int AS user_recent,
+    COUNT(*) FILTER (WHERE (user_id = @user_id OR customer_phone = @phone) AND status IN ('delivered', 'paid'))::int AS completed_orders,
+    COALESCE(AVG(total_amount) FILTER (WHERE (user_id = @user_id OR customer_phone = @phone) AND status IN ('delivered', 'paid')), 0)::numeric(12, 2) AS completed_avg_value
+FROM orders
+WHERE customer_phone = @phone OR user_id = @user_id OR (ip_address = @ip_address AND created_at >= @since);
 
 -- name: UpdateOrderStatus :exec
 UPDATE orders SET status = $2 WHERE id = $1;
@@ -162,6 +263,12 @@ INSERT INTO order_items (order_id, product_id, variant_id, quantity, price)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
+-- name: UpdateOrderItem :exec
+UPDATE order_items SET quantity = $2, price = $3 WHERE id = $1;
+
+-- name: DeleteOrderItem :exec
+DELETE FROM order_items WHERE id = $1;
+
 -- name: GetOrderItems :many
 SELECT oi.*, p.name, p.slug, p.media, v.name as variant_name, v.sku as variant_sku
 FROM order_items oi
@@ -180,8 +287,8 @@ SELECT EXISTS (
 );
 
 -- name: CreateOrderHistory :one
-INSERT INTO order_history (order_id, previous_status, new_status, reason, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO order_history (order_id, previous_status, new_status, reason, created_by, shipment_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetOrderHistory :many
@@ -191,8 +298,16 @@ LEFT JOIN users u ON u.id = oh.created_by
 WHERE oh.order_id = $1
 ORDER BY oh.created_at DESC;
 
--- name: UpdateOrderPaidAmount :exec
-UPDATE orders SET paid_amount = $2 WHERE id = $1;
+-- name: UpdateOrderTotalAmount :exec
+UPDATE orders SET total_amount = $2 WHERE id = $1;
+
+-- name: UpdateOrderAmounts :exec
+UPDATE orders
+SET total_amount = $2,
+    discount_amount = $3,
+    is_preorder = $4,
+    payment_details = $5
+WHERE id = $1;
 
 -- name: UpdateOrderShippingDetails :exec
 UPDATE orders 
@@ -0,0 +1,28 @@
+-- name: CreateOrderOTP :one
+INSERT INTO order_otps (order_id, phone, code_hash, max_attempts, expires_at)
+VALUES (@order_id, @phone, @code_hash, @max_attempts, NOW() + make_interval(secs => @ttl_seconds::float8))
+RETURNING *;
+
+-- name: GetLatestOrderOTP :one
+SELECT *, (expires_at <= NOW())::bool AS expired
+FROM order_otps
+WHERE order_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetOrderOTPSendStats :one
+-- How many codes an order has been sent, and how long ago the last one went out.
+SELECT COUNT(*)::int AS sent,
+       COALESCE(EXTRACT(EPOCH FROM NOW() - MAX(created_at)), 0)::float8 AS seconds_since_last
+FROM order_otps
+WHERE order_id = $1;
+
+-- name: UseOrderOTPAttempt :one
+-- Spends one guess. No row: the code is verified, expired or out of attempts.
+UPDATE order_otps
+SET attempts = attempts + 1
+WHERE id = $1 AND verified_at IS NULL AND attempts < max_attempts AND expires_at > NOW()
+RETURNING attempts, max_attempts;
+
+-- name: MarkOrderOTPVerified :execrows
+UPDATE order_otps SET verified_at = NOW() WHERE id = $1 AND verified_at IS NULL;
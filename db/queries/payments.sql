@@ -0,0 +1,33 @@
+-- name: CreatePaymentSession :one
+INSERT INTO payment_sessions (order_id, provider, amount, currency)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdatePaymentSessionGateway :exec
+UPDATE payment_sessions
+SET gateway_ref = $2, redirect_url = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetPaymentSessionByID :one
+SELECT * FROM payment_sessions WHERE id = $1;
+
+-- name: GetPaymentSessionForUpdate :one
+-- Serializes concurrent callbacks (IPN + browser redirect) for the same session.
+SELECT * FROM payment_sessions WHERE id = $1 FOR UPDATE;
+
+-- name: GetPaymentSessionByGatewayRef :one
+SELECT * FROM payment_sessions WHERE provider = $1 AND gateway_ref = $2;
+
+-- name: ListPaymentSessionsByOrder :many
+SELECT * FROM payment_sessions WHERE order_id = $1 ORDER BY created_at DESC;
+
+-- name: CompletePaymentSession :exec
+UPDATE payment_sessions
+SET status = $2, transaction_id = $3, completed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'initiated';
+
+-- name: RecordPaymentCallback :execrows
+-- Zero rows affected means this (provider, event_key) was already processed: a replay.
+INSERT INTO payment_callbacks (provider, event_key, session_id, status, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (provider, event_key) DO NOTHING;
@@ -0,0 +1,91 @@
+-- name: LockPreorderProduct :one
+-- Serializes pre-order checkouts and releases of a product until commit.
+SELECT preorder_limit FROM products WHERE id = $1 FOR UPDATE;
+
+-- name: CountOpenPreorderUnits :one
+-- Units pre-ordered on active orders and not yet covered by a release.
+SELECT COALESCE(SUM(oi.quantity), 0)::int AS units
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+WHERE oi.product_id = $1
+  AND oi.preorder_release_id IS NULL
+  AND o.is_preorder
+  AND o.status NOT IN ('cancelled', 'fake', 'returned', 'refunded');
+
+-- name: CreatePreorderRelease :one
+INSERT INTO preorder_releases (product_id, note, released_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ReleasePreorderItems :many
+-- Covers the open pre-ordered items of a product by the release and returns their orders.
+WITH released AS (
+    UPDATE order_items oi
+    SET preorder_release_id = sqlc.arg('release_id')
+    FROM orders o
+    WHERE o.id = oi.order_id
+      AND oi.product_id = sqlc.arg('product_id')
+      AND oi.preorder_release_id IS NULL
+      AND o.is_preorder
+      AND o.status NOT IN ('cancelled', 'fake', 'returned', 'refunded')
+    RETURNING oi.order_id, o.created_at
+)
+SELECT order_id FROM released
+GROUP BY order_id
+ORDER BY MIN(created_at);
+
+-- name: GetPreorderRelease :one
+SELECT r.*, p.name AS product_name,
+       (SELECT COUNT(DISTINCT oi.order_id) FROM order_items oi WHERE oi.preorder_release_id = r.id)::int AS order_count
+FROM preorder_releases r
+JOIN products p ON p.id = r.product_id
+WHERE r.id = $1;
+
+-- name: ListPreorderReleases :many
+SELECT r.*, p.name AS product_name,
+       (SELECT COUNT(DISTINCT oi.order_id) FROM order_items oi WHERE oi.preorder_release_id = r.id)::int AS order_count
+FROM preorder_releases r
+JOIN products p ON p.id = r.product_id
+WHERE r.product_id = $1
+ORDER BY r.created_at DESC;
+
+-- name: CreatePreorderBalanceRequest :one
+INSERT INTO preorder_balance_requests (order_id, release_id, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetPreorderBalanceRequest :one
+SELECT b.*, o.order_number
+FROM preorder_balance_requests b
+JOIN orders o ON o.id = b.order_id
+WHERE b.id = $1;
+
+-- name: GetPreorderBalanceRequestForUpdate :one
+-- Serializes payments recorded against the same request.
+SELECT * FROM preorder_balance_requests WHERE id = $1 FOR UPDATE;
+
+-- name: GetPendingPreorderBalanceRequest :one
+SELECT * FROM preorder_balance_requests WHERE order_id = $1 AND status = 'pending';
+
+-- name: ListPreorderBalanceRequests :many
+SELECT b.*, o.order_number
+FROM preorder_balance_requests b
+JOIN orders o ON o.id = b.order_id
+WHERE b.release_id = $1
+ORDER BY o.order_number;
+
+-- name: RecordPreorderBalancePayment :exec
+-- Adds a collected amount; a settled request is marked paid.
+UPDATE preorder_balance_requests
+SET paid_amount = paid_amount + sqlc.arg('amount'),
+    payment_method = sqlc.arg('payment_method'),
+    reference = sqlc.narg('reference'),
+    status = CASE WHEN sqlc.arg('settled')::boolean THEN 'paid' ELSE status END,
+    paid_at = CASE WHEN sqlc.arg('settled')::boolean THEN NOW() ELSE paid_at END,
+    updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: CancelPreorderBalanceRequest :execrows
+UPDATE preorder_balance_requests
+SET status = 'cancelled', updated_at = NOW()
+WHERE id = $1 AND status = 'pending';
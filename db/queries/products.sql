@@ -22,13 +22,15 @@ INSERT INTO products (
     stock_status, is_featured, is_active, 
     media, attributes, specifications, 
     meta_title, meta_description, meta_keywords, og_image,
-    brand, tags, warranty_info, is_preorder, preorder_deposit_amount
+    brand, tags, warranty_info, is_preorder, preorder_deposit_amount,
+    preorder_release_date, preorder_limit
 ) VALUES (
     $1, $2, $3, $4, $5, 
     $6, $7, $8, 
     $9, $10, $11, 
     $12, $13, $14, $15,
-    $16, $17, $18, $19, $20
+    $16, $17, $18, $19, $20,
+    $21, $22
 ) RETURNING *;
 
 -- name: UpdateProduct :one
@@ -37,7 +39,8 @@ SET name = $2, slug = $3, description = $4, base_price = $5, sale_price = $6,
     stock_status = $7, is_featured = $8, 
     is_active = $9, media = $10, attributes = $11, specifications = $12,
     meta_title = $13, meta_description = $14, meta_keywords = $15, og_image = $16,
-    brand = $17, tags = $18, warranty_info = $19, is_preorder = $20, preorder_deposit_amount = $21
+    brand = $17, tags = $18, warranty_info = $19, is_preorder = $20, preorder_deposit_amount = $21,
+    preorder_release_date = $22, preorder_limit = $23
 WHERE id = $1
 RETURNING *;
 
@@ -122,6 +125,6 @@ SELECT
     COUNT(DISTINCT p.id) FILTER (WHERE p.is_active = false) as inactive_products,
     COUNT(DISTINCT v.id) FILTER (WHERE v.stock = 0) as out_of_stock,
     COUNT(DISTINCT v.id) FILTER (WHERE v.stock > 0 AND v.stock <= v.low_stock_threshold) as low_stock,
-    COALESCE(SUM(COALESCE(v.price, p.base_price) * v.stock), 0)::float8 as total_inventory_value
+    COALESCE(SUM(COALESCE(v.price, p.base_price) * v.stock), 0)::numeric as total_inventory_value
 FROM products p
 LEFT JOIN variants v ON v.product_id = p.id;
@@ -1,18 +1,19 @@
 -- name: CreateRefund :one
-INSERT INTO refunds (order_id, amount, reason, restock_items, created_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO refunds (order_id, amount, reason, restock_items, created_by, method, reference, shipping_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
--- name: UpdateOrderRefundedAmount :exec
-UPDATE orders 
-SET refunded_amount = refunded_amount + sqlc.arg(amount),
-    payment_status = CASE 
-        WHEN (refunded_amount + sqlc.arg(amount)) >= paid_amount THEN 'refunded'
+-- name: AddRefundItem :exec
+INSERT INTO refund_items (refund_id, order_item_id, quantity, amount)
+VALUES ($1, $2, $3, $4);
+
+-- name: UpdateOrderRefundStatus :exec
+-- Run once the refund's ledger entry is synced into refunded_amount. The order status
+-- only moves through the order state machine.
+UPDATE orders
+SET payment_status = CASE
+        WHEN refunded_amount >= paid_amount THEN 'refunded'
         ELSE 'partial_refund'
-    END,
-    status = CASE 
-        WHEN (refunded_amount + sqlc.arg(amount)) >= paid_amount THEN 'refunded'
-        ELSE status
     END
 WHERE id = $1;
 
@@ -22,3 +23,9 @@ FROM refunds r
 LEFT JOIN users u ON u.id = r.created_by
 WHERE r.order_id = $1
 ORDER BY r.created_at DESC;
+
+-- name: ListRefundItemsByOrder :many
+SELECT ri.*
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1;
@@ -0,0 +1,45 @@
+-- name: CreateStockReservation :one
+INSERT INTO stock_reservations (order_id, variant_id, quantity, expires_at)
+VALUES (@order_id, @variant_id, @quantity, NOW() + make_interval(secs => @ttl_seconds::float8))
+RETURNING *;
+
+-- name: GetReservedQuantities :many
+-- Quantities currently held against each variant (active and not yet expired),
+-- optionally ignoring one order's own holds.
+SELECT variant_id, SUM(quantity)::int AS reserved
+FROM stock_reservations
+WHERE variant_id = ANY(@variant_ids::uuid[])
+  AND status = 'active'
+  AND expires_at > NOW()
+  AND order_id IS DISTINCT FROM sqlc.narg(exclude_order_id)::uuid
+GROUP BY variant_id;
+
+-- name: ListStockReservationsByOrder :many
+SELECT * FROM stock_reservations WHERE order_id = $1 ORDER BY created_at;
+
+-- name: RenewOrderReservations :execrows
+-- Re-activates an order's live and timed-out holds for at least another ttl.
+UPDATE stock_reservations
+SET status = 'active',
+    expires_at = GREATEST(expires_at, NOW() + make_interval(secs => @ttl_seconds::float8)),
+    updated_at = NOW()
+WHERE order_id = @order_id AND status IN ('active', 'expired');
+
+-- name: SetOrderReservationsStatus :many
+-- Moves every uncommitted hold of an order to committed/released.
+UPDATE stock_reservations
+SET status = @status, updated_at = NOW()
+WHERE order_id = @order_id AND status IN ('active', 'expired')
+RETURNING *;
+
+-- name: ExpireStockReservations :many
+UPDATE stock_reservations
+SET status = 'expired', updated_at = NOW()
+WHERE id IN (
+    SELECT sr.id FROM stock_reservations sr
+    WHERE sr.status = 'active' AND sr.expires_at <= NOW()
+    ORDER BY sr.expires_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,50 @@
+-- name: CreateOrderReturn :one
+INSERT INTO order_returns (order_id, user_id, reason, note, photos)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AddOrderReturnItem :exec
+INSERT INTO order_return_items (return_id, order_item_id, quantity)
+VALUES ($1, $2, $3);
+
+-- name: GetOrderReturnByID :one
+SELECT * FROM order_returns WHERE id = $1;
+
+-- name: GetOrderReturnForUpdate :one
+-- Serializes admin decisions on the same return.
+SELECT * FROM order_returns WHERE id = $1 FOR UPDATE;
+
+-- name: ListOrderReturnsByOrder :many
+SELECT * FROM order_returns WHERE order_id = $1 ORDER BY created_at DESC;
+
+-- name: ListOrderReturns :many
+SELECT * FROM order_returns
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountOrderReturns :one
+SELECT COUNT(*) FROM order_returns
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'));
+
+-- name: ListOrderReturnItems :many
+SELECT * FROM order_return_items WHERE return_id = ANY(sqlc.arg('return_ids')::uuid[]);
+
+-- name: UpdateOrderReturnStatus :exec
+UPDATE order_returns
+SET status = $2,
+    admin_note = COALESCE(sqlc.narg('admin_note'), admin_note),
+    reviewed_by = COALESCE(sqlc.narg('reviewed_by'), reviewed_by),
+    received_at = CASE WHEN $2 = 'received' THEN NOW() ELSE received_at END,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateOrderReturnItem :exec
+UPDATE order_return_items
+SET approved_quantity = $3, received_quantity = $4
+WHERE return_id = $1 AND order_item_id = $2;
+
+-- name: AddOrderReturnRefund :exec
+UPDATE order_returns
+SET refund_amount = refund_amount + sqlc.arg('amount'), status = 'refunded', updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,49 @@
+-- name: CreateShipment :one
+INSERT INTO shipments (order_id, provider, consignment_id, tracking_code, tracking_url, label_url, cod_amount, courier_status, exchange_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetShipmentByID :one
+SELECT * FROM shipments WHERE id = $1;
+
+-- name: GetShipmentForUpdate :one
+-- Serializes concurrent webhooks for the same consignment.
+SELECT * FROM shipments WHERE id = $1 FOR UPDATE;
+
+-- name: GetShipmentByConsignment :one
+SELECT * FROM shipments WHERE provider = $1 AND consignment_id = $2;
+
+-- name: GetShipmentByTracking :one
+-- Courier statements identify parcels by consignment ID or tracking code.
+SELECT * FROM shipments
+WHERE (consignment_id = sqlc.arg('tracking') OR tracking_code = sqlc.arg('tracking'))
+  AND (sqlc.narg('provider')::text IS NULL OR provider = sqlc.narg('provider'))
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListShipmentsByOrder :many
+SELECT * FROM shipments WHERE order_id = $1 ORDER BY created_at DESC;
+
+-- name: UpdateShipmentStatus :exec
+UPDATE shipments
+SET status = $2, courier_status = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordCourierEvent :execrows
+-- Zero rows affected means this (provider, event_key) was already processed: a replay.
+INSERT INTO courier_events (provider, event_key, shipment_id, status, courier_status, payload)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (provider, event_key) DO NOTHING;
+
+-- name: AddShipmentItem :exec
+INSERT INTO shipment_items (shipment_id, order_item_id, quantity)
+VALUES ($1, $2, $3);
+
+-- name: ListShipmentItemsByOrder :many
+SELECT si.*
+FROM shipment_items si
+JOIN shipments s ON s.id = si.shipment_id
+WHERE s.order_id = $1;
+
+-- name: ListShipmentItems :many
+SELECT * FROM shipment_items WHERE shipment_id = $1;
@@ -1,13 +1,14 @@
 -- name: CreateUser :one
-INSERT INTO users (email, role, first_name, last_name, avatar)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO users (email, role, first_name, last_name, avatar, phone)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetUserByID :one
 SELECT * FROM users WHERE id = $1;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1;
+-- Accounts only: guest checkout records may share an email and are never signed into.
+SELECT * FROM users WHERE email = $1 AND role <> 'guest';
 
 -- name: UpdateUser :one
 UPDATE users
@@ -94,3 +94,12 @@ WHERE
     ($1::uuid IS NULL OR v.product_id = $1)
     AND ($2::boolean = false OR v.stock <= v.low_stock_threshold)
     AND ($3::text = '' OR v.sku ILIKE '%' || $3 || '%' OR v.name ILIKE '%' || $3 || '%' OR p.name ILIKE '%' || $3 || '%');
+
+-- name: GetOrderNetStockChanges :many
+-- Net stock movement per variant logged against an order and its exchanges. Negative means stock is currently deducted.
+SELECT variant_id, SUM(change_amount)::int AS net_change
+FROM inventory_logs
+WHERE (reference_id = @order_id::text
+       OR reference_id IN (SELECT id::text FROM order_exchanges WHERE order_id = @order_id::text::uuid))
+  AND variant_id IS NOT NULL
+GROUP BY variant_id;
@@ -11,6 +11,83 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addCouponCategories = `-- name: AddCouponCategories :exec
+INSERT INTO coupon_categories (coupon_id, category_id)
+SELECT $1::uuid, unnest($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type AddCouponCategoriesParams struct {
+	CouponID    pgtype.UUID   `json:"coupon_id"`
+	CategoryIds []pgtype.UUID `json:"category_ids"`
+}
+
+func (q *Queries) AddCouponCategories(ctx context.Context, arg AddCouponCategoriesParams) error {
+	_, err := q.db.Exec(ctx, addCouponCategories, arg.CouponID, arg.CategoryIds)
+	return err
+}
+
+const addCouponCollections = `-- name: AddCouponCollections :exec
+INSERT INTO coupon_collections (coupon_id, collection_id)
+SELECT $1::uuid, unnest($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type AddCouponCollectionsParams struct {
+	CouponID      pgtype.UUID   `json:"coupon_id"`
+	CollectionIds []pgtype.UUID `json:"collection_ids"`
+}
+
+func (q *Queries) AddCouponCollections(ctx context.Context, arg AddCouponCollectionsParams) error {
+	_, err := q.db.Exec(ctx, addCouponCollections, arg.CouponID, arg.CollectionIds)
+	return err
+}
+
+const addCouponProducts = `-- name: AddCouponProducts :exec
+
+INSERT INTO coupon_products (coupon_id, product_id)
+SELECT $1::uuid, unnest($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type AddCouponProductsParams struct {
+	CouponID   pgtype.UUID   `json:"coupon_id"`
+	ProductIds []pgtype.UUID `json:"product_ids"`
+}
+
+// Coupon scoping: a coupon with no rows in any of these tables applies to the whole cart.
+func (q *Queries) AddCouponProducts(ctx context.Context, arg AddCouponProductsParams) error {
+	_, err := q.db.Exec(ctx, addCouponProducts, arg.CouponID, arg.ProductIds)
+	return err
+}
+
+const clearCouponCategories = `-- name: ClearCouponCategories :exec
+DELETE FROM coupon_categories WHERE coupon_id = $1
+`
+
+func (q *Queries) ClearCouponCategories(ctx context.Context, couponID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearCouponCategories, couponID)
+	return err
+}
+
+const clearCouponCollections = `-- name: ClearCouponCollections :exec
+DELETE FROM coupon_collections WHERE coupon_id = $1
+`
+
+func (q *Queries) ClearCouponCollections(ctx context.Context, couponID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearCouponCollections, couponID)
+	return err
+}
+
+const clearCouponProducts = `-- name: ClearCouponProducts :exec
+DELETE FROM coupon_products WHERE coupon_id = $1
+`
+
+func (q *Queries) ClearCouponProducts(ctx context.Context, couponID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearCouponProducts, couponID)
+	return err
+}
+
 const countCoupons = `-- name: CountCoupons :one
 SELECT COUNT(*) FROM coupons
 `
@@ -23,20 +100,23 @@ func (q *Queries) CountCoupons(ctx context.Context) (int64, error) {
 }
 
 const createCoupon = `-- name: CreateCoupon :one
-INSERT INTO coupons (code, type, value, min_spend, usage_limit, start_at, expires_at, is_active)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at
+INSERT INTO coupons (code, type, value, min_spend, usage_limit, start_at, expires_at, is_active, per_user_limit, max_discount, first_order_only)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at, per_user_limit, max_discount, first_order_only
 `
 
 type CreateCouponParams struct {
-	Code       string           `json:"code"`
-	Type       string           `json:"type"`
-	Value      pgtype.Numeric   `json:"value"`
-	MinSpend   pgtype.Numeric   `json:"min_spend"`
-	UsageLimit *int32           `json:"usage_limit"`
-	StartAt    pgtype.Timestamp `json:"start_at"`
-	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
-	IsActive   *bool            `json:"is_active"`
+	Code           string           `json:"code"`
+	Type           string           `json:"type"`
+	Value          pgtype.Numeric   `json:"value"`
+	MinSpend       pgtype.Numeric   `json:"min_spend"`
+	UsageLimit     *int32           `json:"usage_limit"`
+	StartAt        pgtype.Timestamp `json:"start_at"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	IsActive       *bool            `json:"is_active"`
+	PerUserLimit   int32            `json:"per_user_limit"`
+	MaxDiscount    pgtype.Numeric   `json:"max_discount"`
+	FirstOrderOnly bool             `json:"first_order_only"`
 }
 
 func (q *Queries) CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error) {
@@ -49,6 +129,9 @@ func (q *Queries) CreateCoupon(ctx context.Context, arg CreateCouponParams) (Cou
 		arg.StartAt,
 		arg.ExpiresAt,
 		arg.IsActive,
+		arg.PerUserLimit,
+		arg.MaxDiscount,
+		arg.FirstOrderOnly,
 	)
 	var i Coupon
 	err := row.Scan(
@@ -64,6 +147,44 @@ func (q *Queries) CreateCoupon(ctx context.Context, arg CreateCouponParams) (Cou
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PerUserLimit,
+		&i.MaxDiscount,
+		&i.FirstOrderOnly,
+	)
+	return i, err
+}
+
+const createCouponRedemption = `-- name: CreateCouponRedemption :one
+INSERT INTO coupon_redemptions (coupon_id, order_id, user_id, code, discount_amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, coupon_id, order_id, user_id, code, discount_amount, created_at
+`
+
+type CreateCouponRedemptionParams struct {
+	CouponID       pgtype.UUID    `json:"coupon_id"`
+	OrderID        pgtype.UUID    `json:"order_id"`
+	UserID         pgtype.UUID    `json:"user_id"`
+	Code           string         `json:"code"`
+	DiscountAmount pgtype.Numeric `json:"discount_amount"`
+}
+
+func (q *Queries) CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (CouponRedemption, error) {
+	row := q.db.QueryRow(ctx, createCouponRedemption,
+		arg.CouponID,
+		arg.OrderID,
+		arg.UserID,
+		arg.Code,
+		arg.DiscountAmount,
+	)
+	var i CouponRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.CouponID,
+		&i.OrderID,
+		&i.UserID,
+		&i.Code,
+		&i.DiscountAmount,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -78,7 +199,7 @@ func (q *Queries) DeleteCoupon(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getCouponByCode = `-- name: GetCouponByCode :one
-SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at FROM coupons 
+SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at, per_user_limit, max_discount, first_order_only FROM coupons 
 WHERE code = $1
 `
 
@@ -98,12 +219,15 @@ func (q *Queries) GetCouponByCode(ctx context.Context, code string) (Coupon, err
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PerUserLimit,
+		&i.MaxDiscount,
+		&i.FirstOrderOnly,
 	)
 	return i, err
 }
 
 const getCouponByID = `-- name: GetCouponByID :one
-SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at FROM coupons WHERE id = $1
+SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at, per_user_limit, max_discount, first_order_only FROM coupons WHERE id = $1
 `
 
 func (q *Queries) GetCouponByID(ctx context.Context, id pgtype.UUID) (Coupon, error) {
@@ -122,25 +246,88 @@ func (q *Queries) GetCouponByID(ctx context.Context, id pgtype.UUID) (Coupon, er
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PerUserLimit,
+		&i.MaxDiscount,
+		&i.FirstOrderOnly,
 	)
 	return i, err
 }
 
-const incrementCouponUsage = `-- name: IncrementCouponUsage :exec
+const getCouponRedemptionByOrderID = `-- name: GetCouponRedemptionByOrderID :one
+SELECT id, coupon_id, order_id, user_id, code, discount_amount, created_at FROM coupon_redemptions WHERE order_id = $1
+`
+
+func (q *Queries) GetCouponRedemptionByOrderID(ctx context.Context, orderID pgtype.UUID) (CouponRedemption, error) {
+	row := q.db.QueryRow(ctx, getCouponRedemptionByOrderID, orderID)
+	var i CouponRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.CouponID,
+		&i.OrderID,
+		&i.UserID,
+		&i.Code,
+		&i.DiscountAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementCouponUsage = `-- name: IncrementCouponUsage :execrows
 UPDATE coupons 
 SET used_count = used_count + 1 
 WHERE id = $1 AND (usage_limit = 0 OR used_count < usage_limit)
 `
 
 // L9 Optimization: Atomic increment with optimistic concurrency check if needed.
-// We rely on db-level atomicity here.
-func (q *Queries) IncrementCouponUsage(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, incrementCouponUsage, id)
-	return err
+// We rely on db-level atomicity here. Zero rows affected means the limit was reached.
+func (q *Queries) IncrementCouponUsage(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, incrementCouponUsage, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listCouponScopes = `-- name: ListCouponScopes :many
+SELECT coupon_id, 'product'::text as scope_type, product_id as target_id
+FROM coupon_products WHERE coupon_id = ANY($1::uuid[])
+UNION ALL
+SELECT coupon_id, 'category'::text as scope_type, category_id as target_id
+FROM coupon_categories WHERE coupon_id = ANY($1::uuid[])
+UNION ALL
+SELECT coupon_id, 'collection'::text as scope_type, collection_id as target_id
+FROM coupon_collections WHERE coupon_id = ANY($1::uuid[])
+`
+
+type ListCouponScopesRow struct {
+	CouponID  pgtype.UUID `json:"coupon_id"`
+	ScopeType string      `json:"scope_type"`
+	TargetID  pgtype.UUID `json:"target_id"`
+}
+
+// One round-trip for all scope targets of a page of coupons.
+func (q *Queries) ListCouponScopes(ctx context.Context, couponIds []pgtype.UUID) ([]ListCouponScopesRow, error) {
+	rows, err := q.db.Query(ctx, listCouponScopes, couponIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCouponScopesRow{}
+	for rows.Next() {
+		var i ListCouponScopesRow
+		if err := rows.Scan(&i.CouponID, &i.ScopeType, &i.TargetID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const listCoupons = `-- name: ListCoupons :many
-SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at FROM coupons 
+SELECT id, code, type, value, min_spend, usage_limit, used_count, start_at, expires_at, is_active, created_at, updated_at, per_user_limit, max_discount, first_order_only FROM coupons 
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -172,6 +359,9 @@ func (q *Queries) ListCoupons(ctx context.Context, arg ListCouponsParams) ([]Cou
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PerUserLimit,
+			&i.MaxDiscount,
+			&i.FirstOrderOnly,
 		); err != nil {
 			return nil, err
 		}
@@ -192,20 +382,27 @@ UPDATE coupons SET
     usage_limit = $6,
     start_at = $7,
     expires_at = $8,
-    is_active = $9
+    is_active = $9,
+    per_user_limit = $10,
+    max_discount = $11,
+    first_order_only = $12,
+    updated_at = NOW()
 WHERE id = $1
 `
 
 type UpdateCouponParams struct {
-	ID         pgtype.UUID      `json:"id"`
-	Code       string           `json:"code"`
-	Type       string           `json:"type"`
-	Value      pgtype.Numeric   `json:"value"`
-	MinSpend   pgtype.Numeric   `json:"min_spend"`
-	UsageLimit *int32           `json:"usage_limit"`
-	StartAt    pgtype.Timestamp `json:"start_at"`
-	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
-	IsActive   *bool            `json:"is_active"`
+	ID             pgtype.UUID      `json:"id"`
+	Code           string           `json:"code"`
+	Type           string           `json:"type"`
+	Value          pgtype.Numeric   `json:"value"`
+	MinSpend       pgtype.Numeric   `json:"min_spend"`
+	UsageLimit     *int32           `json:"usage_limit"`
+	StartAt        pgtype.Timestamp `json:"start_at"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	IsActive       *bool            `json:"is_active"`
+	PerUserLimit   int32            `json:"per_user_limit"`
+	MaxDiscount    pgtype.Numeric   `json:"max_discount"`
+	FirstOrderOnly bool             `json:"first_order_only"`
 }
 
 func (q *Queries) UpdateCoupon(ctx context.Context, arg UpdateCouponParams) error {
@@ -219,48 +416,99 @@ func (q *Queries) UpdateCoupon(ctx context.Context, arg UpdateCouponParams) erro
 		arg.StartAt,
 		arg.ExpiresAt,
 		arg.IsActive,
+		arg.PerUserLimit,
+		arg.MaxDiscount,
+		arg.FirstOrderOnly,
 	)
 	return err
 }
 
 const validateCoupon = `-- name: ValidateCoupon :one
 SELECT 
-    id, 
-    code, 
-    type, 
-    value, 
-    min_spend,
+    c.id, 
+    c.code, 
+    c.type, 
+    c.value, 
+    c.min_spend,
+    c.max_discount,
+    s.is_scoped::boolean as is_scoped,
+    s.eligible_product_ids::uuid[] as eligible_product_ids,
     CASE 
-        WHEN NOT is_active THEN 'inactive'
-        WHEN start_at > NOW() THEN 'not_started'
-        WHEN expires_at < NOW() THEN 'expired'
-        WHEN usage_limit > 0 AND used_count >= usage_limit THEN 'fully_redeemed'
-        WHEN min_spend > $1::decimal THEN 'min_spend_not_met'
+        WHEN NOT c.is_active THEN 'inactive'
+        WHEN c.start_at > NOW() THEN 'not_started'
+        WHEN c.expires_at < NOW() THEN 'expired'
+        WHEN c.usage_limit > 0 AND c.used_count >= c.usage_limit THEN 'fully_redeemed'
+        WHEN c.per_user_limit > 0 AND (
+            SELECT COUNT(*) FROM coupon_redemptions cr
+            WHERE cr.coupon_id = c.id AND cr.user_id = $1::uuid
+        ) >= c.per_user_limit THEN 'per_user_limit_reached'
+        WHEN c.first_order_only AND EXISTS (
+            SELECT 1 FROM orders o
+            WHERE o.user_id = $1::uuid AND o.status NOT IN ('cancelled', 'fake')
+        ) THEN 'first_order_only'
+        WHEN s.is_scoped AND cardinality(s.eligible_product_ids) = 0 THEN 'not_applicable_to_cart'
+        WHEN c.min_spend > $2::decimal THEN 'min_spend_not_met'
         ELSE 'valid'
     END::text as validation_status
-FROM coupons 
-WHERE code = $2
+FROM coupons c
+CROSS JOIN LATERAL (
+    SELECT
+        (
+            EXISTS (SELECT 1 FROM coupon_products WHERE coupon_id = c.id)
+            OR EXISTS (SELECT 1 FROM coupon_categories WHERE coupon_id = c.id)
+            OR EXISTS (SELECT 1 FROM coupon_collections WHERE coupon_id = c.id)
+        ) as is_scoped,
+        ARRAY(
+            SELECT p.id FROM unnest($3::uuid[]) AS p(id)
+            WHERE EXISTS (
+                SELECT 1 FROM coupon_products cp
+                WHERE cp.coupon_id = c.id AND cp.product_id = p.id
+            ) OR EXISTS (
+                SELECT 1 FROM coupon_categories cc
+                JOIN product_categories pc ON pc.category_id = cc.category_id
+                WHERE cc.coupon_id = c.id AND pc.product_id = p.id
+            ) OR EXISTS (
+                SELECT 1 FROM coupon_collections ccl
+                JOIN product_collections pcl ON pcl.collection_id = ccl.collection_id
+                WHERE ccl.coupon_id = c.id AND pcl.product_id = p.id
+            )
+        ) as eligible_product_ids
+) s
+WHERE c.code = $4
+FOR UPDATE OF c
 `
 
 type ValidateCouponParams struct {
-	CartTotal pgtype.Numeric `json:"cart_total"`
-	Code      string         `json:"code"`
+	UserID     pgtype.UUID    `json:"user_id"`
+	CartTotal  pgtype.Numeric `json:"cart_total"`
+	ProductIds []pgtype.UUID  `json:"product_ids"`
+	Code       string         `json:"code"`
 }
 
 type ValidateCouponRow struct {
-	ID               pgtype.UUID    `json:"id"`
-	Code             string         `json:"code"`
-	Type             string         `json:"type"`
-	Value            pgtype.Numeric `json:"value"`
-	MinSpend         pgtype.Numeric `json:"min_spend"`
-	ValidationStatus string         `json:"validation_status"`
+	ID                 pgtype.UUID    `json:"id"`
+	Code               string         `json:"code"`
+	Type               string         `json:"type"`
+	Value              pgtype.Numeric `json:"value"`
+	MinSpend           pgtype.Numeric `json:"min_spend"`
+	MaxDiscount        pgtype.Numeric `json:"max_discount"`
+	IsScoped           bool           `json:"is_scoped"`
+	EligibleProductIds []pgtype.UUID  `json:"eligible_product_ids"`
+	ValidationStatus   string         `json:"validation_status"`
 }
 
 // L9 Optimization: Single-pass validation logic pushed to DB.
 // Returns the coupon if valid, or a status reason if not.
 // Uses covering indexes on (code) and partial indexes on (is_active) where applicable.
+// FOR UPDATE: inside checkout the row stays locked until commit so usage_limit cannot be overshot.
+// Scoped coupons (products/categories/collections) report which of @product_ids they discount.
 func (q *Queries) ValidateCoupon(ctx context.Context, arg ValidateCouponParams) (ValidateCouponRow, error) {
-	row := q.db.QueryRow(ctx, validateCoupon, arg.CartTotal, arg.Code)
+	row := q.db.QueryRow(ctx, validateCoupon,
+		arg.UserID,
+		arg.CartTotal,
+		arg.ProductIds,
+		arg.Code,
+	)
 	var i ValidateCouponRow
 	err := row.Scan(
 		&i.ID,
@@ -268,6 +516,9 @@ func (q *Queries) ValidateCoupon(ctx context.Context, arg ValidateCouponParams)
 		&i.Type,
 		&i.Value,
 		&i.MinSpend,
+		&i.MaxDiscount,
+		&i.IsScoped,
+		&i.EligibleProductIds,
 		&i.ValidationStatus,
 	)
 	return i, err
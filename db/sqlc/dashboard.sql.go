@@ -107,7 +107,8 @@ SELECT
   DATE(created_at) as date,
   COUNT(*)::int as order_count,
   COALESCE(SUM(total_amount), 0)::numeric as total_revenue,
-  COALESCE(AVG(total_amount), 0)::numeric as avg_order_value
+  COALESCE(AVG(total_amount), 0)::numeric as avg_order_value,
+  COALESCE(SUM(discount_amount), 0)::numeric as total_discount
 FROM orders
 WHERE created_at >= $1::timestamp 
   AND created_at <= $2::timestamp
@@ -129,6 +130,7 @@ type GetDailySalesRow struct {
 	OrderCount    int32          `json:"order_count"`
 	TotalRevenue  pgtype.Numeric `json:"total_revenue"`
 	AvgOrderValue pgtype.Numeric `json:"avg_order_value"`
+	TotalDiscount pgtype.Numeric `json:"total_discount"`
 }
 
 // Revenue aggregation by day with parameterized date range
@@ -151,6 +153,7 @@ func (q *Queries) GetDailySales(ctx context.Context, arg GetDailySalesParams) ([
 			&i.OrderCount,
 			&i.TotalRevenue,
 			&i.AvgOrderValue,
+			&i.TotalDiscount,
 		); err != nil {
 			return nil, err
 		}
@@ -301,7 +304,8 @@ SELECT
   COUNT(*)::bigint as total_orders,
   COALESCE(SUM(total_amount), 0)::numeric as total_revenue,
   COALESCE(AVG(total_amount), 0)::numeric as avg_order_value,
-  COUNT(DISTINCT user_id)::bigint as unique_customers
+  COUNT(DISTINCT user_id)::bigint as unique_customers,
+  COALESCE(SUM(discount_amount), 0)::numeric as total_discount
 FROM orders
 WHERE created_at >= $1::timestamp
   AND created_at <= $2::timestamp
@@ -318,6 +322,7 @@ type GetRevenueKPIsRow struct {
 	TotalRevenue    pgtype.Numeric `json:"total_revenue"`
 	AvgOrderValue   pgtype.Numeric `json:"avg_order_value"`
 	UniqueCustomers int64          `json:"unique_customers"`
+	TotalDiscount   pgtype.Numeric `json:"total_discount"`
 }
 
 // Key performance indicators for a parameterized date range
@@ -329,6 +334,7 @@ func (q *Queries) GetRevenueKPIs(ctx context.Context, arg GetRevenueKPIsParams)
 		&i.TotalRevenue,
 		&i.AvgOrderValue,
 		&i.UniqueCustomers,
+		&i.TotalDiscount,
 	)
 	return i, err
 }
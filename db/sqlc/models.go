@@ -27,6 +27,19 @@ type Address struct {
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
 }
 
+type BlocklistEntry struct {
+	ID            pgtype.UUID      `json:"id"`
+	Kind          string           `json:"kind"`
+	Value         string           `json:"value"`
+	Action        string           `json:"action"`
+	Reason        string           `json:"reason"`
+	ExpiresAt     pgtype.Timestamp `json:"expires_at"`
+	SourceOrderID pgtype.UUID      `json:"source_order_id"`
+	CreatedBy     pgtype.UUID      `json:"created_by"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
 type Cart struct {
 	ID        pgtype.UUID      `json:"id"`
 	UserID    pgtype.UUID      `json:"user_id"`
@@ -89,18 +102,57 @@ type ContentBlock struct {
 }
 
 type Coupon struct {
-	ID         pgtype.UUID      `json:"id"`
-	Code       string           `json:"code"`
-	Type       string           `json:"type"`
-	Value      pgtype.Numeric   `json:"value"`
-	MinSpend   pgtype.Numeric   `json:"min_spend"`
-	UsageLimit *int32           `json:"usage_limit"`
-	UsedCount  *int32           `json:"used_count"`
-	StartAt    pgtype.Timestamp `json:"start_at"`
-	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
-	IsActive   *bool            `json:"is_active"`
-	CreatedAt  pgtype.Timestamp `json:"created_at"`
-	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+	ID             pgtype.UUID      `json:"id"`
+	Code           string           `json:"code"`
+	Type           string           `json:"type"`
+	Value          pgtype.Numeric   `json:"value"`
+	MinSpend       pgtype.Numeric   `json:"min_spend"`
+	UsageLimit     *int32           `json:"usage_limit"`
+	UsedCount      *int32           `json:"used_count"`
+	StartAt        pgtype.Timestamp `json:"start_at"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	IsActive       *bool            `json:"is_active"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	PerUserLimit   int32            `json:"per_user_limit"`
+	MaxDiscount    pgtype.Numeric   `json:"max_discount"`
+	FirstOrderOnly bool             `json:"first_order_only"`
+}
+
+type CouponCategory struct {
+	CouponID   pgtype.UUID `json:"coupon_id"`
+	CategoryID pgtype.UUID `json:"category_id"`
+}
+
+type CouponCollection struct {
+	CouponID     pgtype.UUID `json:"coupon_id"`
+	CollectionID pgtype.UUID `json:"collection_id"`
+}
+
+type CouponProduct struct {
+	CouponID  pgtype.UUID `json:"coupon_id"`
+	ProductID pgtype.UUID `json:"product_id"`
+}
+
+type CouponRedemption struct {
+	ID             pgtype.UUID      `json:"id"`
+	CouponID       pgtype.UUID      `json:"coupon_id"`
+	OrderID        pgtype.UUID      `json:"order_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Code           string           `json:"code"`
+	DiscountAmount pgtype.Numeric   `json:"discount_amount"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type CourierEvent struct {
+	ID            pgtype.UUID      `json:"id"`
+	Provider      string           `json:"provider"`
+	EventKey      string           `json:"event_key"`
+	ShipmentID    pgtype.UUID      `json:"shipment_id"`
+	Status        *string          `json:"status"`
+	CourierStatus string           `json:"courier_status"`
+	Payload       []byte           `json:"payload"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
 }
 
 type DailySalesStat struct {
@@ -112,6 +164,62 @@ type DailySalesStat struct {
 	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
 }
 
+type DraftOrder struct {
+	ID              pgtype.UUID      `json:"id"`
+	UserID          pgtype.UUID      `json:"user_id"`
+	Status          string           `json:"status"`
+	ShippingAddress []byte           `json:"shipping_address"`
+	PaymentMethod   string           `json:"payment_method"`
+	DiscountAmount  pgtype.Numeric   `json:"discount_amount"`
+	DiscountReason  *string          `json:"discount_reason"`
+	Note            *string          `json:"note"`
+	Locale          string           `json:"locale"`
+	OrderID         pgtype.UUID      `json:"order_id"`
+	CreatedBy       pgtype.UUID      `json:"created_by"`
+	SentAt          pgtype.Timestamp `json:"sent_at"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type DraftOrderItem struct {
+	ID             pgtype.UUID    `json:"id"`
+	DraftID        pgtype.UUID    `json:"draft_id"`
+	ProductID      pgtype.UUID    `json:"product_id"`
+	VariantID      pgtype.UUID    `json:"variant_id"`
+	Quantity       int32          `json:"quantity"`
+	PriceOverride  pgtype.Numeric `json:"price_override"`
+	OverrideReason *string        `json:"override_reason"`
+}
+
+type EmailOutbox struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	Template      string           `json:"template"`
+	Locale        string           `json:"locale"`
+	ToAddress     string           `json:"to_address"`
+	Subject       string           `json:"subject"`
+	HtmlBody      string           `json:"html_body"`
+	TextBody      string           `json:"text_body"`
+	Status        string           `json:"status"`
+	Attempts      int32            `json:"attempts"`
+	LastError     *string          `json:"last_error"`
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	SentAt        pgtype.Timestamp `json:"sent_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	UserID       pgtype.UUID      `json:"user_id"`
+	Key          string           `json:"key"`
+	RequestHash  string           `json:"request_hash"`
+	StatusCode   *int32           `json:"status_code"`
+	ContentType  *string          `json:"content_type"`
+	ResponseBody []byte           `json:"response_body"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+}
+
 type InventoryLog struct {
 	ID           int32            `json:"id"`
 	ProductID    pgtype.UUID      `json:"product_id"`
@@ -137,6 +245,32 @@ type Order struct {
 	IsPreorder      bool             `json:"is_preorder"`
 	RefundedAmount  pgtype.Numeric   `json:"refunded_amount"`
 	ShippingFee     pgtype.Numeric   `json:"shipping_fee"`
+	DiscountAmount  pgtype.Numeric   `json:"discount_amount"`
+	CouponCode      *string          `json:"coupon_code"`
+	Locale          string           `json:"locale"`
+	OrderNumber     string           `json:"order_number"`
+	CustomerPhone   *string          `json:"customer_phone"`
+	IpAddress       *string          `json:"ip_address"`
+	RiskScore       *int32           `json:"risk_score"`
+	RiskReasons     []byte           `json:"risk_reasons"`
+}
+
+type OrderExchange struct {
+	ID              pgtype.UUID      `json:"id"`
+	OrderID         pgtype.UUID      `json:"order_id"`
+	OrderItemID     pgtype.UUID      `json:"order_item_id"`
+	OldVariantID    pgtype.UUID      `json:"old_variant_id"`
+	NewVariantID    pgtype.UUID      `json:"new_variant_id"`
+	NewVariantName  string           `json:"new_variant_name"`
+	Quantity        int32            `json:"quantity"`
+	UnitPrice       pgtype.Numeric   `json:"unit_price"`
+	PriceDifference pgtype.Numeric   `json:"price_difference"`
+	Status          string           `json:"status"`
+	Note            *string          `json:"note"`
+	CreatedBy       pgtype.UUID      `json:"created_by"`
+	ReceivedAt      pgtype.Timestamp `json:"received_at"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
 }
 
 type OrderHistory struct {
@@ -147,15 +281,132 @@ type OrderHistory struct {
 	Reason         *string            `json:"reason"`
 	CreatedBy      pgtype.UUID        `json:"created_by"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ShipmentID     pgtype.UUID        `json:"shipment_id"`
+}
+
+type OrderInvoice struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	InvoiceNumber string           `json:"invoice_number"`
+	PdfUrl        *string          `json:"pdf_url"`
+	IssuedAt      pgtype.Timestamp `json:"issued_at"`
 }
 
 type OrderItem struct {
-	ID        pgtype.UUID    `json:"id"`
-	OrderID   pgtype.UUID    `json:"order_id"`
-	ProductID pgtype.UUID    `json:"product_id"`
-	VariantID pgtype.UUID    `json:"variant_id"`
-	Quantity  int32          `json:"quantity"`
-	Price     pgtype.Numeric `json:"price"`
+	ID                pgtype.UUID    `json:"id"`
+	OrderID           pgtype.UUID    `json:"order_id"`
+	ProductID         pgtype.UUID    `json:"product_id"`
+	VariantID         pgtype.UUID    `json:"variant_id"`
+	Quantity          int32          `json:"quantity"`
+	Price             pgtype.Numeric `json:"price"`
+	PreorderReleaseID pgtype.UUID    `json:"preorder_release_id"`
+}
+
+type OrderNumberCounter struct {
+	Year       int32 `json:"year"`
+	LastNumber int32 `json:"last_number"`
+}
+
+type OrderOtp struct {
+	ID          pgtype.UUID      `json:"id"`
+	OrderID     pgtype.UUID      `json:"order_id"`
+	Phone       string           `json:"phone"`
+	CodeHash    string           `json:"code_hash"`
+	Attempts    int32            `json:"attempts"`
+	MaxAttempts int32            `json:"max_attempts"`
+	ExpiresAt   pgtype.Timestamp `json:"expires_at"`
+	VerifiedAt  pgtype.Timestamp `json:"verified_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type OrderReturn struct {
+	ID           pgtype.UUID      `json:"id"`
+	OrderID      pgtype.UUID      `json:"order_id"`
+	UserID       pgtype.UUID      `json:"user_id"`
+	Status       string           `json:"status"`
+	Reason       string           `json:"reason"`
+	Note         *string          `json:"note"`
+	Photos       []string         `json:"photos"`
+	AdminNote    *string          `json:"admin_note"`
+	RefundAmount pgtype.Numeric   `json:"refund_amount"`
+	ReviewedBy   pgtype.UUID      `json:"reviewed_by"`
+	ReceivedAt   pgtype.Timestamp `json:"received_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type OrderReturnItem struct {
+	ReturnID         pgtype.UUID `json:"return_id"`
+	OrderItemID      pgtype.UUID `json:"order_item_id"`
+	Quantity         int32       `json:"quantity"`
+	ApprovedQuantity int32       `json:"approved_quantity"`
+	ReceivedQuantity int32       `json:"received_quantity"`
+}
+
+type Payment struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	Direction     string           `json:"direction"`
+	Amount        pgtype.Numeric   `json:"amount"`
+	Method        string           `json:"method"`
+	Provider      *string          `json:"provider"`
+	TransactionID *string          `json:"transaction_id"`
+	SenderNumber  *string          `json:"sender_number"`
+	Status        string           `json:"status"`
+	SessionID     pgtype.UUID      `json:"session_id"`
+	RefundID      pgtype.UUID      `json:"refund_id"`
+	Note          *string          `json:"note"`
+	VerifiedBy    pgtype.UUID      `json:"verified_by"`
+	VerifiedAt    pgtype.Timestamp `json:"verified_at"`
+	CreatedBy     pgtype.UUID      `json:"created_by"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type PaymentCallback struct {
+	ID        pgtype.UUID      `json:"id"`
+	Provider  string           `json:"provider"`
+	EventKey  string           `json:"event_key"`
+	SessionID pgtype.UUID      `json:"session_id"`
+	Status    string           `json:"status"`
+	Payload   []byte           `json:"payload"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type PaymentSession struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	Provider      string           `json:"provider"`
+	Amount        pgtype.Numeric   `json:"amount"`
+	Currency      string           `json:"currency"`
+	Status        string           `json:"status"`
+	GatewayRef    *string          `json:"gateway_ref"`
+	RedirectUrl   *string          `json:"redirect_url"`
+	TransactionID *string          `json:"transaction_id"`
+	CompletedAt   pgtype.Timestamp `json:"completed_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type PreorderBalanceRequest struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	ReleaseID     pgtype.UUID      `json:"release_id"`
+	Amount        pgtype.Numeric   `json:"amount"`
+	Status        string           `json:"status"`
+	PaidAmount    pgtype.Numeric   `json:"paid_amount"`
+	PaymentMethod *string          `json:"payment_method"`
+	Reference     *string          `json:"reference"`
+	PaidAt        pgtype.Timestamp `json:"paid_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type PreorderRelease struct {
+	ID         pgtype.UUID      `json:"id"`
+	ProductID  pgtype.UUID      `json:"product_id"`
+	Note       *string          `json:"note"`
+	ReleasedBy pgtype.UUID      `json:"released_by"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
 }
 
 type Product struct {
@@ -183,6 +434,8 @@ type Product struct {
 	WarrantyInfo          []byte           `json:"warranty_info"`
 	IsPreorder            bool             `json:"is_preorder"`
 	PreorderDepositAmount pgtype.Numeric   `json:"preorder_deposit_amount"`
+	PreorderReleaseDate   pgtype.Timestamp `json:"preorder_release_date"`
+	PreorderLimit         *int32           `json:"preorder_limit"`
 }
 
 type ProductCategory struct {
@@ -206,13 +459,23 @@ type RefreshToken struct {
 }
 
 type Refund struct {
-	ID           pgtype.UUID      `json:"id"`
-	OrderID      pgtype.UUID      `json:"order_id"`
-	Amount       pgtype.Numeric   `json:"amount"`
-	Reason       *string          `json:"reason"`
-	RestockItems bool             `json:"restock_items"`
-	CreatedAt    pgtype.Timestamp `json:"created_at"`
-	CreatedBy    pgtype.UUID      `json:"created_by"`
+	ID             pgtype.UUID      `json:"id"`
+	OrderID        pgtype.UUID      `json:"order_id"`
+	Amount         pgtype.Numeric   `json:"amount"`
+	Reason         *string          `json:"reason"`
+	RestockItems   bool             `json:"restock_items"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	CreatedBy      pgtype.UUID      `json:"created_by"`
+	Method         string           `json:"method"`
+	Reference      *string          `json:"reference"`
+	ShippingAmount pgtype.Numeric   `json:"shipping_amount"`
+}
+
+type RefundItem struct {
+	RefundID    pgtype.UUID    `json:"refund_id"`
+	OrderItemID pgtype.UUID    `json:"order_item_id"`
+	Quantity    int32          `json:"quantity"`
+	Amount      pgtype.Numeric `json:"amount"`
 }
 
 type Review struct {
@@ -224,6 +487,28 @@ type Review struct {
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
+type Shipment struct {
+	ID            pgtype.UUID      `json:"id"`
+	OrderID       pgtype.UUID      `json:"order_id"`
+	Provider      string           `json:"provider"`
+	ConsignmentID string           `json:"consignment_id"`
+	TrackingCode  *string          `json:"tracking_code"`
+	TrackingUrl   *string          `json:"tracking_url"`
+	LabelUrl      *string          `json:"label_url"`
+	CodAmount     pgtype.Numeric   `json:"cod_amount"`
+	Status        string           `json:"status"`
+	CourierStatus *string          `json:"courier_status"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+	ExchangeID    pgtype.UUID      `json:"exchange_id"`
+}
+
+type ShipmentItem struct {
+	ShipmentID  pgtype.UUID `json:"shipment_id"`
+	OrderItemID pgtype.UUID `json:"order_item_id"`
+	Quantity    int32       `json:"quantity"`
+}
+
 type ShippingZone struct {
 	ID        int32            `json:"id"`
 	Key       string           `json:"key"`
@@ -234,6 +519,17 @@ type ShippingZone struct {
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
+type StockReservation struct {
+	ID        pgtype.UUID      `json:"id"`
+	OrderID   pgtype.UUID      `json:"order_id"`
+	VariantID pgtype.UUID      `json:"variant_id"`
+	Quantity  int32            `json:"quantity"`
+	Status    string           `json:"status"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
 type User struct {
 	ID        pgtype.UUID      `json:"id"`
 	Email     string           `json:"email"`
@@ -11,6 +11,25 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const assignCartToUser = `-- name: AssignCartToUser :execrows
+UPDATE carts SET user_id = $1, updated_at = NOW()
+WHERE id = $2 AND user_id IS NULL
+`
+
+type AssignCartToUserParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+// Hands a guest cart over to a user who has no cart yet.
+func (q *Queries) AssignCartToUser(ctx context.Context, arg AssignCartToUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, assignCartToUser, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const atomicRemoveCartItem = `-- name: AtomicRemoveCartItem :exec
 DELETE FROM cart_items ci
 USING carts c
@@ -50,11 +69,14 @@ WHERE
     ($3::text IS NULL OR o.payment_method = $3) AND
     ($4::boolean IS NULL OR o.is_preorder = $4) AND
     ($5::text IS NULL OR 
+        o.order_number ILIKE '%' || $5 || '%' OR 
         o.id::text ILIKE '%' || $5 || '%' OR 
         u.email ILIKE '%' || $5 || '%' OR 
         o.payment_details->>'transaction_id' ILIKE '%' || $5 || '%' OR
         o.payment_details->>'sender_number' ILIKE '%' || $5 || '%'
-    )
+    ) AND
+    ($6::int IS NULL OR o.risk_score >= $6) AND
+    ($7::int IS NULL OR o.risk_score <= $7)
 `
 
 type CountOrdersParams struct {
@@ -63,6 +85,8 @@ type CountOrdersParams struct {
 	PaymentMethod *string `json:"payment_method"`
 	IsPreorder    *bool   `json:"is_preorder"`
 	Search        *string `json:"search"`
+	MinRiskScore  *int32  `json:"min_risk_score"`
+	MaxRiskScore  *int32  `json:"max_risk_score"`
 }
 
 func (q *Queries) CountOrders(ctx context.Context, arg CountOrdersParams) (int64, error) {
@@ -72,6 +96,8 @@ func (q *Queries) CountOrders(ctx context.Context, arg CountOrdersParams) (int64
 		arg.PaymentMethod,
 		arg.IsPreorder,
 		arg.Search,
+		arg.MinRiskScore,
+		arg.MaxRiskScore,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -95,9 +121,9 @@ func (q *Queries) CreateCart(ctx context.Context, userID pgtype.UUID) (Cart, err
 }
 
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (user_id, status, total_amount, shipping_fee, shipping_address, payment_method, payment_status, paid_amount, payment_details, is_preorder)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, user_id, status, total_amount, shipping_address, payment_method, payment_status, created_at, updated_at, paid_amount, payment_details, is_preorder, refunded_amount, shipping_fee
+INSERT INTO orders (user_id, status, total_amount, shipping_fee, shipping_address, payment_method, payment_status, paid_amount, payment_details, is_preorder, discount_amount, coupon_code, locale, customer_phone, ip_address, risk_score, risk_reasons, order_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, user_id, status, total_amount, shipping_address, payment_method, payment_status, created_at, updated_at, paid_amount, payment_details, is_preorder, refunded_amount, shipping_fee, discount_amount, coupon_code, locale, order_number, customer_phone, ip_address, risk_score, risk_reasons
 `
 
 type CreateOrderParams struct {
@@ -111,6 +137,14 @@ type CreateOrderParams struct {
 	PaidAmount      pgtype.Numeric `json:"paid_amount"`
 	PaymentDetails  []byte         `json:"payment_details"`
 	IsPreorder      bool           `json:"is_preorder"`
+	DiscountAmount  pgtype.Numeric `json:"discount_amount"`
+	CouponCode      *string        `json:"coupon_code"`
+	Locale          string         `json:"locale"`
+	CustomerPhone   *string        `json:"customer_phone"`
+	IpAddress       *string        `json:"ip_address"`
+	RiskScore       *int32         `json:"risk_score"`
+	RiskReasons     []byte         `json:"risk_reasons"`
+	OrderNumber     string         `json:"order_number"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
@@ -125,6 +159,14 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.PaidAmount,
 		arg.PaymentDetails,
 		arg.IsPreorder,
+		arg.DiscountAmount,
+		arg.CouponCode,
+		arg.Locale,
+		arg.CustomerPhone,
+		arg.IpAddress,
+		arg.RiskScore,
+		arg.RiskReasons,
+		arg.OrderNumber,
 	)
 	var i Order
 	err := row.Scan(
@@ -142,14 +184,22 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.IsPreorder,
 		&i.RefundedAmount,
 		&i.ShippingFee,
+		&i.DiscountAmount,
+		&i.CouponCode,
+		&i.Locale,
+		&i.OrderNumber,
+		&i.CustomerPhone,
+		&i.IpAddress,
+		&i.RiskScore,
+		&i.RiskReasons,
 	)
 	return i, err
 }
 
 const createOrderHistory = `-- name: CreateOrderHistory :one
-INSERT INTO order_history (order_id, previous_status, new_status, reason, created_by)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, order_id, previous_status, new_status, reason, created_by, created_at
+INSERT INTO order_history (order_id, previous_status, new_status, reason, created_by, shipment_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, order_id, previous_status, new_status, reason, created_by, created_at, shipment_id
 `
 
 type CreateOrderHistoryParams struct {
@@ -158,6 +208,7 @@ type CreateOrderHistoryParams struct {
 	NewStatus      string      `json:"new_status"`
 	Reason         *string     `json:"reason"`
 	CreatedBy      pgtype.UUID `json:"created_by"`
+	ShipmentID     pgtype.UUID `json:"shipment_id"`
 }
 
 func (q *Queries) CreateOrderHistory(ctx context.Context, arg CreateOrderHistoryParams) (OrderHistory, error) {
@@ -167,6 +218,7 @@ func (q *Queries) CreateOrderHistory(ctx context.Context, arg CreateOrderHistory
 		arg.NewStatus,
 		arg.Reason,
 		arg.CreatedBy,
+		arg.ShipmentID,
 	)
 	var i OrderHistory
 	err := row.Scan(
@@ -177,6 +229,7 @@ func (q *Queries) CreateOrderHistory(ctx context.Context, arg CreateOrderHistory
 		&i.Reason,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.ShipmentID,
 	)
 	return i, err
 }
@@ -184,7 +237,7 @@ func (q *Queries) CreateOrderHistory(ctx context.Context, arg CreateOrderHistory
 const createOrderItem = `-- name: CreateOrderItem :one
 INSERT INTO order_items (order_id, product_id, variant_id, quantity, price)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, order_id, product_id, variant_id, quantity, price
+RETURNING id, order_id, product_id, variant_id, quantity, price, preorder_release_id
 `
 
 type CreateOrderItemParams struct {
@@ -211,12 +264,55 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		&i.VariantID,
 		&i.Quantity,
 		&i.Price,
+		&i.PreorderReleaseID,
 	)
 	return i, err
 }
 
+const deleteCart = `-- name: DeleteCart :exec
+DELETE FROM carts WHERE id = $1
+`
+
+func (q *Queries) DeleteCart(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteCart, id)
+	return err
+}
+
+const deleteIdleGuestCarts = `-- name: DeleteIdleGuestCarts :execrows
+DELETE FROM carts
+WHERE id IN (
+    SELECT c.id FROM carts c
+    WHERE c.user_id IS NULL AND c.updated_at < NOW() - make_interval(secs => $1::float8)
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+type DeleteIdleGuestCartsParams struct {
+	IdleSeconds float64 `json:"idle_seconds"`
+	BatchSize   int32   `json:"batch_size"`
+}
+
+// Deletes a batch of guest carts left untouched for the idle window; items cascade.
+func (q *Queries) DeleteIdleGuestCarts(ctx context.Context, arg DeleteIdleGuestCartsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteIdleGuestCarts, arg.IdleSeconds, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrderItem = `-- name: DeleteOrderItem :exec
+DELETE FROM order_items WHERE id = $1
+`
+
+func (q *Queries) DeleteOrderItem(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOrderItem, id)
+	return err
+}
+
 const getAllOrders = `-- name: GetAllOrders :many
-SELECT o.id, o.user_id, o.status, o.total_amount, o.shipping_address, o.payment_method, o.payment_status, o.created_at, o.updated_at, o.paid_amount, o.payment_details, o.is_preorder, o.refunded_amount, o.shipping_fee, u.email, u.first_name, u.last_name, u.avatar
+SELECT o.id, o.user_id, o.status, o.total_amount, o.shipping_address, o.payment_method, o.payment_status, o.created_at, o.updated_at, o.paid_amount, o.payment_details, o.is_preorder, o.refunded_amount, o.shipping_fee, o.discount_amount, o.coupon_code, o.locale, o.order_number, o.customer_phone, o.ip_address, o.risk_score, o.risk_reasons, u.email, u.first_name, u.last_name, u.avatar
 FROM orders o
 JOIN users u ON u.id = o.user_id
 WHERE 
@@ -225,11 +321,14 @@ WHERE
     ($5::text IS NULL OR o.payment_method = $5) AND
     ($6::boolean IS NULL OR o.is_preorder = $6) AND
     ($7::text IS NULL OR 
+        o.order_number ILIKE '%' || $7 || '%' OR 
         o.id::text ILIKE '%' || $7 || '%' OR 
         u.email ILIKE '%' || $7 || '%' OR 
         o.payment_details->>'transaction_id' ILIKE '%' || $7 || '%' OR
         o.payment_details->>'sender_number' ILIKE '%' || $7 || '%'
-    )
+    ) AND
+    ($8::int IS NULL OR o.risk_score >= $8) AND
+    ($9::int IS NULL OR o.risk_score <= $9)
 ORDER BY o.created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -242,6 +341,8 @@ type GetAllOrdersParams struct {
 	PaymentMethod *string `json:"payment_method"`
 	IsPreorder    *bool   `json:"is_preorder"`
 	Search        *string `json:"search"`
+	MinRiskScore  *int32  `json:"min_risk_score"`
+	MaxRiskScore  *int32  `json:"max_risk_score"`
 }
 
 type GetAllOrdersRow struct {
@@ -259,6 +360,14 @@ type GetAllOrdersRow struct {
 	IsPreorder      bool             `json:"is_preorder"`
 	RefundedAmount  pgtype.Numeric   `json:"refunded_amount"`
 	ShippingFee     pgtype.Numeric   `json:"shipping_fee"`
+	DiscountAmount  pgtype.Numeric   `json:"discount_amount"`
+	CouponCode      *string          `json:"coupon_code"`
+	Locale          string           `json:"locale"`
+	OrderNumber     string           `json:"order_number"`
+	CustomerPhone   *string          `json:"customer_phone"`
+	IpAddress       *string          `json:"ip_address"`
+	RiskScore       *int32           `json:"risk_score"`
+	RiskReasons     []byte           `json:"risk_reasons"`
 	Email           string           `json:"email"`
 	FirstName       *string          `json:"first_name"`
 	LastName        *string          `json:"last_name"`
@@ -274,6 +383,8 @@ func (q *Queries) GetAllOrders(ctx context.Context, arg GetAllOrdersParams) ([]G
 		arg.PaymentMethod,
 		arg.IsPreorder,
 		arg.Search,
+		arg.MinRiskScore,
+		arg.MaxRiskScore,
 	)
 	if err != nil {
 		return nil, err
@@ -297,6 +408,14 @@ func (q *Queries) GetAllOrders(ctx context.Context, arg GetAllOrdersParams) ([]G
 			&i.IsPreorder,
 			&i.RefundedAmount,
 			&i.ShippingFee,
+			&i.DiscountAmount,
+			&i.CouponCode,
+			&i.Locale,
+			&i.OrderNumber,
+			&i.CustomerPhone,
+			&i.IpAddress,
+			&i.RiskScore,
+			&i.RiskReasons,
 			&i.Email,
 			&i.FirstName,
 			&i.LastName,
@@ -312,6 +431,22 @@ func (q *Queries) GetAllOrders(ctx context.Context, arg GetAllOrdersParams) ([]G
 	return items, nil
 }
 
+const getCartByID = `-- name: GetCartByID :one
+SELECT id, user_id, created_at, updated_at FROM carts WHERE id = $1
+`
+
+func (q *Queries) GetCartByID(ctx context.Context, id pgtype.UUID) (Cart, error) {
+	row := q.db.QueryRow(ctx, getCartByID, id)
+	var i Cart
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getCartByUserID = `-- name: GetCartByUserID :one
 SELECT id, user_id, created_at, updated_at FROM carts WHERE user_id = $1
 `
@@ -503,8 +638,173 @@ func (q *Queries) GetCartWithItems(ctx context.Context, userID pgtype.UUID) ([]G
 	return items, nil
 }
 
+const getCartWithItemsByCartID = `-- name: GetCartWithItemsByCartID :many
+SELECT 
+    c.id as cart_id,
+    c.user_id,
+    ci.id as item_id,
+    ci.product_id,
+    ci.variant_id,
+    ci.quantity,
+    p.name,
+    p.slug,
+    p.base_price,
+    p.sale_price,
+    p.media,
+    p.stock_status,
+    p.is_preorder,
+    p.preorder_deposit_amount,
+    v.stock,
+    v.sku as variant_sku,
+    v.name as variant_name,
+    v.images as variant_images,
+    v.price as variant_price,
+    v.sale_price as variant_sale_price
+FROM carts c
+LEFT JOIN cart_items ci ON c.id = ci.cart_id
+LEFT JOIN products p ON ci.product_id = p.id
+LEFT JOIN variants v ON ci.variant_id = v.id
+WHERE c.id = $1
+`
+
+type GetCartWithItemsByCartIDRow struct {
+	CartID                pgtype.UUID    `json:"cart_id"`
+	UserID                pgtype.UUID    `json:"user_id"`
+	ItemID                pgtype.UUID    `json:"item_id"`
+	ProductID             pgtype.UUID    `json:"product_id"`
+	VariantID             pgtype.UUID    `json:"variant_id"`
+	Quantity              *int32         `json:"quantity"`
+	Name                  *string        `json:"name"`
+	Slug                  *string        `json:"slug"`
+	BasePrice             pgtype.Numeric `json:"base_price"`
+	SalePrice             pgtype.Numeric `json:"sale_price"`
+	Media                 []byte         `json:"media"`
+	StockStatus           *string        `json:"stock_status"`
+	IsPreorder            *bool          `json:"is_preorder"`
+	PreorderDepositAmount pgtype.Numeric `json:"preorder_deposit_amount"`
+	Stock                 *int32         `json:"stock"`
+	VariantSku            *string        `json:"variant_sku"`
+	VariantName           *string        `json:"variant_name"`
+	VariantImages         []string       `json:"variant_images"`
+	VariantPrice          pgtype.Numeric `json:"variant_price"`
+	VariantSalePrice      pgtype.Numeric `json:"variant_sale_price"`
+}
+
+func (q *Queries) GetCartWithItemsByCartID(ctx context.Context, id pgtype.UUID) ([]GetCartWithItemsByCartIDRow, error) {
+	rows, err := q.db.Query(ctx, getCartWithItemsByCartID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCartWithItemsByCartIDRow{}
+	for rows.Next() {
+		var i GetCartWithItemsByCartIDRow
+		if err := rows.Scan(
+			&i.CartID,
+			&i.UserID,
+			&i.ItemID,
+			&i.ProductID,
+			&i.VariantID,
+			&i.Quantity,
+			&i.Name,
+			&i.Slug,
+			&i.BasePrice,
+			&i.SalePrice,
+			&i.Media,
+			&i.StockStatus,
+			&i.IsPreorder,
+			&i.PreorderDepositAmount,
+			&i.Stock,
+			&i.VariantSku,
+			&i.VariantName,
+			&i.VariantImages,
+			&i.VariantPrice,
+			&i.VariantSalePrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCustomerRiskHistory = `-- name: GetCustomerRiskHistory :one
+SELECT
+    COUNT(*) FILTER (WHERE customer_phone = $1)::int AS phone_orders,
+    COUNT(*) FILTER (WHERE customer_phone = $1 AND status = 'fake')::int AS phone_fake,
+    COUNT(*) FILTER (WHERE customer_phone = $1 AND status = 'returned')::int AS phone_returned,
+    COUNT(*) FILTER (WHERE customer_phone = $1 AND status = 'cancelled')::int AS phone_cancelled,
+    COUNT(*) FILTER (WHERE customer_phone = $1 AND created_at >= $2)::int AS phone_recent,
+    COUNT(DISTINCT user_id) FILTER (WHERE customer_phone = $1 AND user_id IS DISTINCT FROM $3)::int AS phone_other_users,
+    COUNT(*) FILTER (WHERE customer_phone = $1 AND shipping_address->>'district' = $4::text)::int AS phone_same_district,
+    COUNT(*) FILTER (WHERE ip_address = $5 AND created_at >= $2)::int AS ip_recent,
+    COUNT(*) FILTER (WHERE user_id = $3)::int AS user_orders,
+    COUNT(*) FILTER (WHERE user_id = $3 AND customer_phone = $1)::int AS user_phone_orders,
+    COUNT(*) FILTER (WHERE user_id = $3 AND created_at >= $2)::int AS user_recent,
+    COUNT(*) FILTER (WHERE (user_id = $3 OR customer_phone = $1) AND status IN ('delivered', 'paid'))::int AS completed_orders,
+    COALESCE(AVG(total_amount) FILTER (WHERE (user_id = $3 OR customer_phone = $1) AND status IN ('delivered', 'paid')), 0)::numeric(12, 2) AS completed_avg_value
+FROM orders
+WHERE customer_phone = $1 OR user_id = $3 OR (ip_address = $5 AND created_at >= $2)
+`
+
+type GetCustomerRiskHistoryParams struct {
+	Phone     *string          `json:"phone"`
+	Since     pgtype.Timestamp `json:"since"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	District  string           `json:"district"`
+	IpAddress *string          `json:"ip_address"`
+}
+
+type GetCustomerRiskHistoryRow struct {
+	PhoneOrders       int32          `json:"phone_orders"`
+	PhoneFake         int32          `json:"phone_fake"`
+	PhoneReturned     int32          `json:"phone_returned"`
+	PhoneCancelled    int32          `json:"phone_cancelled"`
+	PhoneRecent       int32          `json:"phone_recent"`
+	PhoneOtherUsers   int32          `json:"phone_other_users"`
+	PhoneSameDistrict int32          `json:"phone_same_district"`
+	IpRecent          int32          `json:"ip_recent"`
+	UserOrders        int32          `json:"user_orders"`
+	UserPhoneOrders   int32          `json:"user_phone_orders"`
+	UserRecent        int32          `json:"user_recent"`
+	CompletedOrders   int32          `json:"completed_orders"`
+	CompletedAvgValue pgtype.Numeric `json:"completed_avg_value"`
+}
+
+// Signals from the past orders of a phone, client IP and user for scoring a new order.
+// Counts with a _recent suffix only cover orders placed since @since.
+func (q *Queries) GetCustomerRiskHistory(ctx context.Context, arg GetCustomerRiskHistoryParams) (GetCustomerRiskHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getCustomerRiskHistory,
+		arg.Phone,
+		arg.Since,
+		arg.UserID,
+		arg.District,
+		arg.IpAddress,
+	)
+	var i GetCustomerRiskHistoryRow
+	err := row.Scan(
+		&i.PhoneOrders,
+		&i.PhoneFake,
+		&i.PhoneReturned,
+		&i.PhoneCancelled,
+		&i.PhoneRecent,
+		&i.PhoneOtherUsers,
+		&i.PhoneSameDistrict,
+		&i.IpRecent,
+		&i.UserOrders,
+		&i.UserPhoneOrders,
+		&i.UserRecent,
+		&i.CompletedOrders,
+		&i.CompletedAvgValue,
+	)
+	return i, err
+}
+
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT o.id, o.user_id, o.status, o.total_amount, o.shipping_address, o.payment_method, o.payment_status, o.created_at, o.updated_at, o.paid_amount, o.payment_details, o.is_preorder, o.refunded_amount, o.shipping_fee, u.email, u.first_name, u.last_name, u.avatar
+SELECT o.id, o.user_id, o.status, o.total_amount, o.shipping_address, o.payment_method, o.payment_status, o.created_at, o.updated_at, o.paid_amount, o.payment_details, o.is_preorder, o.refunded_amount, o.shipping_fee, o.discount_amount, o.coupon_code, o.locale, o.order_number, o.customer_phone, o.ip_address, o.risk_score, o.risk_reasons, u.email, u.first_name, u.last_name, u.avatar
 FROM orders o
 JOIN users u ON u.id = o.user_id
 WHERE o.id = $1
@@ -525,6 +825,14 @@ type GetOrderByIDRow struct {
 	IsPreorder      bool             `json:"is_preorder"`
 	RefundedAmount  pgtype.Numeric   `json:"refunded_amount"`
 	ShippingFee     pgtype.Numeric   `json:"shipping_fee"`
+	DiscountAmount  pgtype.Numeric   `json:"discount_amount"`
+	CouponCode      *string          `json:"coupon_code"`
+	Locale          string           `json:"locale"`
+	OrderNumber     string           `json:"order_number"`
+	CustomerPhone   *string          `json:"customer_phone"`
+	IpAddress       *string          `json:"ip_address"`
+	RiskScore       *int32           `json:"risk_score"`
+	RiskReasons     []byte           `json:"risk_reasons"`
 	Email           string           `json:"email"`
 	FirstName       *string          `json:"first_name"`
 	LastName        *string          `json:"last_name"`
@@ -549,6 +857,14 @@ func (q *Queries) GetOrderByID(ctx context.Context, id pgtype.UUID) (GetOrderByI
 		&i.IsPreorder,
 		&i.RefundedAmount,
 		&i.ShippingFee,
+		&i.DiscountAmount,
+		&i.CouponCode,
+		&i.Locale,
+		&i.OrderNumber,
+		&i.CustomerPhone,
+		&i.IpAddress,
+		&i.RiskScore,
+		&i.RiskReasons,
 		&i.Email,
 		&i.FirstName,
 		&i.LastName,
@@ -557,8 +873,19 @@ func (q *Queries) GetOrderByID(ctx context.Context, id pgtype.UUID) (GetOrderByI
 	return i, err
 }
 
+const getOrderIDByNumber = `-- name: GetOrderIDByNumber :one
+SELECT id FROM orders WHERE order_number = $1
+`
+
+func (q *Queries) GetOrderIDByNumber(ctx context.Context, orderNumber string) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getOrderIDByNumber, orderNumber)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getOrderHistory = `-- name: GetOrderHistory :many
-SELECT oh.id, oh.order_id, oh.previous_status, oh.new_status, oh.reason, oh.created_by, oh.created_at, u.first_name, u.last_name, u.email
+SELECT oh.id, oh.order_id, oh.previous_status, oh.new_status, oh.reason, oh.created_by, oh.created_at, oh.shipment_id, u.first_name, u.last_name, u.email
 FROM order_history oh
 LEFT JOIN users u ON u.id = oh.created_by
 WHERE oh.order_id = $1
@@ -573,6 +900,7 @@ type GetOrderHistoryRow struct {
 	Reason         *string            `json:"reason"`
 	CreatedBy      pgtype.UUID        `json:"created_by"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ShipmentID     pgtype.UUID        `json:"shipment_id"`
 	FirstName      *string            `json:"first_name"`
 	LastName       *string            `json:"last_name"`
 	Email          *string            `json:"email"`
@@ -595,6 +923,7 @@ func (q *Queries) GetOrderHistory(ctx context.Context, orderID pgtype.UUID) ([]G
 			&i.Reason,
 			&i.CreatedBy,
 			&i.CreatedAt,
+			&i.ShipmentID,
 			&i.FirstName,
 			&i.LastName,
 			&i.Email,
@@ -610,7 +939,7 @@ func (q *Queries) GetOrderHistory(ctx context.Context, orderID pgtype.UUID) ([]G
 }
 
 const getOrderItems = `-- name: GetOrderItems :many
-SELECT oi.id, oi.order_id, oi.product_id, oi.variant_id, oi.quantity, oi.price, p.name, p.slug, p.media, v.name as variant_name, v.sku as variant_sku
+SELECT oi.id, oi.order_id, oi.product_id, oi.variant_id, oi.quantity, oi.price, oi.preorder_release_id, p.name, p.slug, p.media, v.name as variant_name, v.sku as variant_sku
 FROM order_items oi
 JOIN products p ON p.id = oi.product_id
 LEFT JOIN variants v ON v.id = oi.variant_id
@@ -618,17 +947,18 @@ WHERE oi.order_id = $1
 `
 
 type GetOrderItemsRow struct {
-	ID          pgtype.UUID    `json:"id"`
-	OrderID     pgtype.UUID    `json:"order_id"`
-	ProductID   pgtype.UUID    `json:"product_id"`
-	VariantID   pgtype.UUID    `json:"variant_id"`
-	Quantity    int32          `json:"quantity"`
-	Price       pgtype.Numeric `json:"price"`
-	Name        string         `json:"name"`
-	Slug        string         `json:"slug"`
-	Media       []byte         `json:"media"`
-	VariantName *string        `json:"variant_name"`
-	VariantSku  *string        `json:"variant_sku"`
+	ID                pgtype.UUID    `json:"id"`
+	OrderID           pgtype.UUID    `json:"order_id"`
+	ProductID         pgtype.UUID    `json:"product_id"`
+	VariantID         pgtype.UUID    `json:"variant_id"`
+	Quantity          int32          `json:"quantity"`
+	Price             pgtype.Numeric `json:"price"`
+	PreorderReleaseID pgtype.UUID    `json:"preorder_release_id"`
+	Name              string         `json:"name"`
+	Slug              string         `json:"slug"`
+	Media             []byte         `json:"media"`
+	VariantName       *string        `json:"variant_name"`
+	VariantSku        *string        `json:"variant_sku"`
 }
 
 func (q *Queries) GetOrderItems(ctx context.Context, orderID pgtype.UUID) ([]GetOrderItemsRow, error) {
@@ -647,6 +977,7 @@ func (q *Queries) GetOrderItems(ctx context.Context, orderID pgtype.UUID) ([]Get
 			&i.VariantID,
 			&i.Quantity,
 			&i.Price,
+			&i.PreorderReleaseID,
 			&i.Name,
 			&i.Slug,
 			&i.Media,
@@ -664,7 +995,7 @@ func (q *Queries) GetOrderItems(ctx context.Context, orderID pgtype.UUID) ([]Get
 }
 
 const getOrdersByUserID = `-- name: GetOrdersByUserID :many
-SELECT id, user_id, status, total_amount, shipping_address, payment_method, payment_status, created_at, updated_at, paid_amount, payment_details, is_preorder, refunded_amount, shipping_fee FROM orders WHERE user_id = $1 ORDER BY created_at DESC
+SELECT id, user_id, status, total_amount, shipping_address, payment_method, payment_status, created_at, updated_at, paid_amount, payment_details, is_preorder, refunded_amount, shipping_fee, discount_amount, coupon_code, locale, order_number, customer_phone, ip_address, risk_score, risk_reasons FROM orders WHERE user_id = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) GetOrdersByUserID(ctx context.Context, userID pgtype.UUID) ([]Order, error) {
@@ -691,6 +1022,14 @@ func (q *Queries) GetOrdersByUserID(ctx context.Context, userID pgtype.UUID) ([]
 			&i.IsPreorder,
 			&i.RefundedAmount,
 			&i.ShippingFee,
+			&i.DiscountAmount,
+			&i.CouponCode,
+			&i.Locale,
+			&i.OrderNumber,
+			&i.CustomerPhone,
+			&i.IpAddress,
+			&i.RiskScore,
+			&i.RiskReasons,
 		); err != nil {
 			return nil, err
 		}
@@ -725,17 +1064,103 @@ func (q *Queries) HasPurchasedProduct(ctx context.Context, arg HasPurchasedProdu
 	return exists, err
 }
 
-const updateOrderPaidAmount = `-- name: UpdateOrderPaidAmount :exec
-UPDATE orders SET paid_amount = $2 WHERE id = $1
+const lockOrder = `-- name: LockOrder :one
+SELECT id FROM orders WHERE id = $1 FOR UPDATE
+`
+
+// Serializes claims on the order's items (returns, exchanges) until commit.
+func (q *Queries) LockOrder(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, lockOrder, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const nextOrderNumber = `-- name: NextOrderNumber :one
+INSERT INTO order_number_counters (year, last_number)
+VALUES (EXTRACT(YEAR FROM CURRENT_TIMESTAMP)::int, 1)
+ON CONFLICT (year) DO UPDATE SET last_number = order_number_counters.last_number + 1
+RETURNING year, last_number
+`
+
+type NextOrderNumberRow struct {
+	Year       int32 `json:"year"`
+	LastNumber int32 `json:"last_number"`
+}
+
+// Advances the year's order counter. The counter row stays locked until the
+// transaction ends, so numbers are handed out in commit order without gaps.
+func (q *Queries) NextOrderNumber(ctx context.Context) (NextOrderNumberRow, error) {
+	row := q.db.QueryRow(ctx, nextOrderNumber)
+	var i NextOrderNumberRow
+	err := row.Scan(&i.Year, &i.LastNumber)
+	return i, err
+}
+
+const removeCartItem = `-- name: RemoveCartItem :exec
+DELETE FROM cart_items
+WHERE cart_id = $1 AND product_id = $2 AND variant_id = $3
+`
+
+type RemoveCartItemParams struct {
+	CartID    pgtype.UUID `json:"cart_id"`
+	ProductID pgtype.UUID `json:"product_id"`
+	VariantID pgtype.UUID `json:"variant_id"`
+}
+
+func (q *Queries) RemoveCartItem(ctx context.Context, arg RemoveCartItemParams) error {
+	_, err := q.db.Exec(ctx, removeCartItem, arg.CartID, arg.ProductID, arg.VariantID)
+	return err
+}
+
+const touchCart = `-- name: TouchCart :exec
+UPDATE carts SET updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) TouchCart(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, touchCart, id)
+	return err
+}
+
+const updateOrderAmounts = `-- name: UpdateOrderAmounts :exec
+UPDATE orders
+SET total_amount = $2,
+    discount_amount = $3,
+    is_preorder = $4,
+    payment_details = $5
+WHERE id = $1
+`
+
+type UpdateOrderAmountsParams struct {
+	ID             pgtype.UUID    `json:"id"`
+	TotalAmount    pgtype.Numeric `json:"total_amount"`
+	DiscountAmount pgtype.Numeric `json:"discount_amount"`
+	IsPreorder     bool           `json:"is_preorder"`
+	PaymentDetails []byte         `json:"payment_details"`
+}
+
+func (q *Queries) UpdateOrderAmounts(ctx context.Context, arg UpdateOrderAmountsParams) error {
+	_, err := q.db.Exec(ctx, updateOrderAmounts,
+		arg.ID,
+		arg.TotalAmount,
+		arg.DiscountAmount,
+		arg.IsPreorder,
+		arg.PaymentDetails,
+	)
+	return err
+}
+
+const updateOrderItem = `-- name: UpdateOrderItem :exec
+UPDATE order_items SET quantity = $2, price = $3 WHERE id = $1
 `
 
-type UpdateOrderPaidAmountParams struct {
-	ID         pgtype.UUID    `json:"id"`
-	PaidAmount pgtype.Numeric `json:"paid_amount"`
+type UpdateOrderItemParams struct {
+	ID       pgtype.UUID    `json:"id"`
+	Quantity int32          `json:"quantity"`
+	Price    pgtype.Numeric `json:"price"`
 }
 
-func (q *Queries) UpdateOrderPaidAmount(ctx context.Context, arg UpdateOrderPaidAmountParams) error {
-	_, err := q.db.Exec(ctx, updateOrderPaidAmount, arg.ID, arg.PaidAmount)
+func (q *Queries) UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) error {
+	_, err := q.db.Exec(ctx, updateOrderItem, arg.ID, arg.Quantity, arg.Price)
 	return err
 }
 
@@ -792,17 +1217,35 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 	return err
 }
 
+const updateOrderTotalAmount = `-- name: UpdateOrderTotalAmount :exec
+UPDATE orders SET total_amount = $2 WHERE id = $1
+`
+
+type UpdateOrderTotalAmountParams struct {
+	ID          pgtype.UUID    `json:"id"`
+	TotalAmount pgtype.Numeric `json:"total_amount"`
+}
+
+func (q *Queries) UpdateOrderTotalAmount(ctx context.Context, arg UpdateOrderTotalAmountParams) error {
+	_, err := q.db.Exec(ctx, updateOrderTotalAmount, arg.ID, arg.TotalAmount)
+	return err
+}
+
 const upsertCartItemAtomic = `-- name: UpsertCartItemAtomic :many
 WITH 
   user_cart AS (
     SELECT c.id FROM carts c
-    WHERE c.id = $1 AND c.user_id = $2
+    -- Guest carts have no owner: a NULL user_id only matches a NULL owner
+    WHERE c.id = $1 AND c.user_id IS NOT DISTINCT FROM $2::uuid
   ),
   stock_valid AS (
     SELECT v.id FROM variants v
     JOIN products p ON p.id = v.product_id
     WHERE v.id = $3
-      AND v.stock >= $4
+      AND v.stock - COALESCE((
+            SELECT SUM(sr.quantity) FROM stock_reservations sr
+            WHERE sr.variant_id = v.id AND sr.status = 'active' AND sr.expires_at > NOW()
+          ), 0) >= $4
       AND p.is_active = TRUE
   ),
   existing_item AS (
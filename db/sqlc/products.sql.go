@@ -105,37 +105,41 @@ INSERT INTO products (
     stock_status, is_featured, is_active, 
     media, attributes, specifications, 
     meta_title, meta_description, meta_keywords, og_image,
-    brand, tags, warranty_info, is_preorder, preorder_deposit_amount
+    brand, tags, warranty_info, is_preorder, preorder_deposit_amount,
+    preorder_release_date, preorder_limit
 ) VALUES (
     $1, $2, $3, $4, $5, 
     $6, $7, $8, 
     $9, $10, $11, 
     $12, $13, $14, $15,
-    $16, $17, $18, $19, $20
-) RETURNING id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount
+    $16, $17, $18, $19, $20,
+    $21, $22
+) RETURNING id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit
 `
 
 type CreateProductParams struct {
-	Name                  string         `json:"name"`
-	Slug                  string         `json:"slug"`
-	Description           *string        `json:"description"`
-	BasePrice             pgtype.Numeric `json:"base_price"`
-	SalePrice             pgtype.Numeric `json:"sale_price"`
-	StockStatus           *string        `json:"stock_status"`
-	IsFeatured            bool           `json:"is_featured"`
-	IsActive              bool           `json:"is_active"`
-	Media                 []byte         `json:"media"`
-	Attributes            []byte         `json:"attributes"`
-	Specifications        []byte         `json:"specifications"`
-	MetaTitle             *string        `json:"meta_title"`
-	MetaDescription       *string        `json:"meta_description"`
-	MetaKeywords          *string        `json:"meta_keywords"`
-	OgImage               *string        `json:"og_image"`
-	Brand                 *string        `json:"brand"`
-	Tags                  []string       `json:"tags"`
-	WarrantyInfo          []byte         `json:"warranty_info"`
-	IsPreorder            bool           `json:"is_preorder"`
-	PreorderDepositAmount pgtype.Numeric `json:"preorder_deposit_amount"`
+	Name                  string           `json:"name"`
+	Slug                  string           `json:"slug"`
+	Description           *string          `json:"description"`
+	BasePrice             pgtype.Numeric   `json:"base_price"`
+	SalePrice             pgtype.Numeric   `json:"sale_price"`
+	StockStatus           *string          `json:"stock_status"`
+	IsFeatured            bool             `json:"is_featured"`
+	IsActive              bool             `json:"is_active"`
+	Media                 []byte           `json:"media"`
+	Attributes            []byte           `json:"attributes"`
+	Specifications        []byte           `json:"specifications"`
+	MetaTitle             *string          `json:"meta_title"`
+	MetaDescription       *string          `json:"meta_description"`
+	MetaKeywords          *string          `json:"meta_keywords"`
+	OgImage               *string          `json:"og_image"`
+	Brand                 *string          `json:"brand"`
+	Tags                  []string         `json:"tags"`
+	WarrantyInfo          []byte           `json:"warranty_info"`
+	IsPreorder            bool             `json:"is_preorder"`
+	PreorderDepositAmount pgtype.Numeric   `json:"preorder_deposit_amount"`
+	PreorderReleaseDate   pgtype.Timestamp `json:"preorder_release_date"`
+	PreorderLimit         *int32           `json:"preorder_limit"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -160,6 +164,8 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.WarrantyInfo,
 		arg.IsPreorder,
 		arg.PreorderDepositAmount,
+		arg.PreorderReleaseDate,
+		arg.PreorderLimit,
 	)
 	var i Product
 	err := row.Scan(
@@ -187,6 +193,8 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.WarrantyInfo,
 		&i.IsPreorder,
 		&i.PreorderDepositAmount,
+		&i.PreorderReleaseDate,
+		&i.PreorderLimit,
 	)
 	return i, err
 }
@@ -335,7 +343,7 @@ func (q *Queries) GetCollectionsByIDs(ctx context.Context, dollar_1 []pgtype.UUI
 }
 
 const getProductByID = `-- name: GetProductByID :one
-SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount FROM products WHERE id = $1
+SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit FROM products WHERE id = $1
 `
 
 func (q *Queries) GetProductByID(ctx context.Context, id pgtype.UUID) (Product, error) {
@@ -366,12 +374,14 @@ func (q *Queries) GetProductByID(ctx context.Context, id pgtype.UUID) (Product,
 		&i.WarrantyInfo,
 		&i.IsPreorder,
 		&i.PreorderDepositAmount,
+		&i.PreorderReleaseDate,
+		&i.PreorderLimit,
 	)
 	return i, err
 }
 
 const getProductBySlug = `-- name: GetProductBySlug :one
-SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount FROM products WHERE slug = $1
+SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit FROM products WHERE slug = $1
 `
 
 func (q *Queries) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
@@ -402,6 +412,8 @@ func (q *Queries) GetProductBySlug(ctx context.Context, slug string) (Product, e
 		&i.WarrantyInfo,
 		&i.IsPreorder,
 		&i.PreorderDepositAmount,
+		&i.PreorderReleaseDate,
+		&i.PreorderLimit,
 	)
 	return i, err
 }
@@ -413,18 +425,18 @@ SELECT
     COUNT(DISTINCT p.id) FILTER (WHERE p.is_active = false) as inactive_products,
     COUNT(DISTINCT v.id) FILTER (WHERE v.stock = 0) as out_of_stock,
     COUNT(DISTINCT v.id) FILTER (WHERE v.stock > 0 AND v.stock <= v.low_stock_threshold) as low_stock,
-    COALESCE(SUM(COALESCE(v.price, p.base_price) * v.stock), 0)::float8 as total_inventory_value
+    COALESCE(SUM(COALESCE(v.price, p.base_price) * v.stock), 0)::numeric as total_inventory_value
 FROM products p
 LEFT JOIN variants v ON v.product_id = p.id
 `
 
 type GetProductStatsRow struct {
-	TotalProducts       int64   `json:"total_products"`
-	ActiveProducts      int64   `json:"active_products"`
-	InactiveProducts    int64   `json:"inactive_products"`
-	OutOfStock          int64   `json:"out_of_stock"`
-	LowStock            int64   `json:"low_stock"`
-	TotalInventoryValue float64 `json:"total_inventory_value"`
+	TotalProducts       int64          `json:"total_products"`
+	ActiveProducts      int64          `json:"active_products"`
+	InactiveProducts    int64          `json:"inactive_products"`
+	OutOfStock          int64          `json:"out_of_stock"`
+	LowStock            int64          `json:"low_stock"`
+	TotalInventoryValue pgtype.Numeric `json:"total_inventory_value"`
 }
 
 func (q *Queries) GetProductStats(ctx context.Context) (GetProductStatsRow, error) {
@@ -442,7 +454,7 @@ func (q *Queries) GetProductStats(ctx context.Context) (GetProductStatsRow, erro
 }
 
 const getProducts = `-- name: GetProducts :many
-SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount FROM products 
+SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit FROM products 
 WHERE ($3::boolean IS NULL OR is_active = $3)
 AND ($4::boolean IS NULL OR is_featured = $4)
 ORDER BY created_at DESC
@@ -495,6 +507,8 @@ func (q *Queries) GetProducts(ctx context.Context, arg GetProductsParams) ([]Pro
 			&i.WarrantyInfo,
 			&i.IsPreorder,
 			&i.PreorderDepositAmount,
+			&i.PreorderReleaseDate,
+			&i.PreorderLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -507,7 +521,7 @@ func (q *Queries) GetProducts(ctx context.Context, arg GetProductsParams) ([]Pro
 }
 
 const getProductsForCollection = `-- name: GetProductsForCollection :many
-SELECT p.id, p.name, p.slug, p.description, p.base_price, p.sale_price, p.stock_status, p.is_featured, p.is_active, p.media, p.attributes, p.specifications, p.created_at, p.updated_at, p.search_vector, p.meta_title, p.meta_description, p.meta_keywords, p.og_image, p.brand, p.tags, p.warranty_info, p.is_preorder, p.preorder_deposit_amount FROM products p
+SELECT p.id, p.name, p.slug, p.description, p.base_price, p.sale_price, p.stock_status, p.is_featured, p.is_active, p.media, p.attributes, p.specifications, p.created_at, p.updated_at, p.search_vector, p.meta_title, p.meta_description, p.meta_keywords, p.og_image, p.brand, p.tags, p.warranty_info, p.is_preorder, p.preorder_deposit_amount, p.preorder_release_date, p.preorder_limit FROM products p
 JOIN product_collections pc ON pc.product_id = p.id
 WHERE pc.collection_id = $1 AND p.is_active = true
 ORDER BY p.created_at DESC
@@ -547,6 +561,8 @@ func (q *Queries) GetProductsForCollection(ctx context.Context, collectionID pgt
 			&i.WarrantyInfo,
 			&i.IsPreorder,
 			&i.PreorderDepositAmount,
+			&i.PreorderReleaseDate,
+			&i.PreorderLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -559,7 +575,7 @@ func (q *Queries) GetProductsForCollection(ctx context.Context, collectionID pgt
 }
 
 const getProductsWithCategoryFilter = `-- name: GetProductsWithCategoryFilter :many
-SELECT DISTINCT p.id, p.name, p.slug, p.description, p.base_price, p.sale_price, p.stock_status, p.is_featured, p.is_active, p.media, p.attributes, p.specifications, p.created_at, p.updated_at, p.search_vector, p.meta_title, p.meta_description, p.meta_keywords, p.og_image, p.brand, p.tags, p.warranty_info, p.is_preorder, p.preorder_deposit_amount FROM products p
+SELECT DISTINCT p.id, p.name, p.slug, p.description, p.base_price, p.sale_price, p.stock_status, p.is_featured, p.is_active, p.media, p.attributes, p.specifications, p.created_at, p.updated_at, p.search_vector, p.meta_title, p.meta_description, p.meta_keywords, p.og_image, p.brand, p.tags, p.warranty_info, p.is_preorder, p.preorder_deposit_amount, p.preorder_release_date, p.preorder_limit FROM products p
 JOIN product_categories pc ON pc.product_id = p.id
 JOIN categories c ON c.id = pc.category_id
 WHERE c.slug = $1 
@@ -614,6 +630,8 @@ func (q *Queries) GetProductsWithCategoryFilter(ctx context.Context, arg GetProd
 			&i.WarrantyInfo,
 			&i.IsPreorder,
 			&i.PreorderDepositAmount,
+			&i.PreorderReleaseDate,
+			&i.PreorderLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -626,7 +644,7 @@ func (q *Queries) GetProductsWithCategoryFilter(ctx context.Context, arg GetProd
 }
 
 const getProductsWithPriceRange = `-- name: GetProductsWithPriceRange :many
-SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount FROM products
+SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit FROM products
 WHERE base_price >= $1 AND base_price <= $2 AND ($3::boolean IS NULL OR is_active = $3)
 ORDER BY created_at DESC
 LIMIT $4 OFFSET $5
@@ -680,6 +698,8 @@ func (q *Queries) GetProductsWithPriceRange(ctx context.Context, arg GetProducts
 			&i.WarrantyInfo,
 			&i.IsPreorder,
 			&i.PreorderDepositAmount,
+			&i.PreorderReleaseDate,
+			&i.PreorderLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -725,33 +745,36 @@ SET name = $2, slug = $3, description = $4, base_price = $5, sale_price = $6,
     stock_status = $7, is_featured = $8, 
     is_active = $9, media = $10, attributes = $11, specifications = $12,
     meta_title = $13, meta_description = $14, meta_keywords = $15, og_image = $16,
-    brand = $17, tags = $18, warranty_info = $19, is_preorder = $20, preorder_deposit_amount = $21
+    brand = $17, tags = $18, warranty_info = $19, is_preorder = $20, preorder_deposit_amount = $21,
+    preorder_release_date = $22, preorder_limit = $23
 WHERE id = $1
-RETURNING id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount
+RETURNING id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit
 `
 
 type UpdateProductParams struct {
-	ID                    pgtype.UUID    `json:"id"`
-	Name                  string         `json:"name"`
-	Slug                  string         `json:"slug"`
-	Description           *string        `json:"description"`
-	BasePrice             pgtype.Numeric `json:"base_price"`
-	SalePrice             pgtype.Numeric `json:"sale_price"`
-	StockStatus           *string        `json:"stock_status"`
-	IsFeatured            bool           `json:"is_featured"`
-	IsActive              bool           `json:"is_active"`
-	Media                 []byte         `json:"media"`
-	Attributes            []byte         `json:"attributes"`
-	Specifications        []byte         `json:"specifications"`
-	MetaTitle             *string        `json:"meta_title"`
-	MetaDescription       *string        `json:"meta_description"`
-	MetaKeywords          *string        `json:"meta_keywords"`
-	OgImage               *string        `json:"og_image"`
-	Brand                 *string        `json:"brand"`
-	Tags                  []string       `json:"tags"`
-	WarrantyInfo          []byte         `json:"warranty_info"`
-	IsPreorder            bool           `json:"is_preorder"`
-	PreorderDepositAmount pgtype.Numeric `json:"preorder_deposit_amount"`
+	ID                    pgtype.UUID      `json:"id"`
+	Name                  string           `json:"name"`
+	Slug                  string           `json:"slug"`
+	Description           *string          `json:"description"`
+	BasePrice             pgtype.Numeric   `json:"base_price"`
+	SalePrice             pgtype.Numeric   `json:"sale_price"`
+	StockStatus           *string          `json:"stock_status"`
+	IsFeatured            bool             `json:"is_featured"`
+	IsActive              bool             `json:"is_active"`
+	Media                 []byte           `json:"media"`
+	Attributes            []byte           `json:"attributes"`
+	Specifications        []byte           `json:"specifications"`
+	MetaTitle             *string          `json:"meta_title"`
+	MetaDescription       *string          `json:"meta_description"`
+	MetaKeywords          *string          `json:"meta_keywords"`
+	OgImage               *string          `json:"og_image"`
+	Brand                 *string          `json:"brand"`
+	Tags                  []string         `json:"tags"`
+	WarrantyInfo          []byte           `json:"warranty_info"`
+	IsPreorder            bool             `json:"is_preorder"`
+	PreorderDepositAmount pgtype.Numeric   `json:"preorder_deposit_amount"`
+	PreorderReleaseDate   pgtype.Timestamp `json:"preorder_release_date"`
+	PreorderLimit         *int32           `json:"preorder_limit"`
 }
 
 func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
@@ -777,6 +800,8 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		arg.WarrantyInfo,
 		arg.IsPreorder,
 		arg.PreorderDepositAmount,
+		arg.PreorderReleaseDate,
+		arg.PreorderLimit,
 	)
 	var i Product
 	err := row.Scan(
@@ -804,6 +829,8 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		&i.WarrantyInfo,
 		&i.IsPreorder,
 		&i.PreorderDepositAmount,
+		&i.PreorderReleaseDate,
+		&i.PreorderLimit,
 	)
 	return i, err
 }
@@ -11,18 +11,49 @@ import (
 )
 
 type Querier interface {
+	AddCouponCategories(ctx context.Context, arg AddCouponCategoriesParams) error
+	AddCouponCollections(ctx context.Context, arg AddCouponCollectionsParams) error
+	// Coupon scoping: a coupon with no rows in any of these tables applies to the whole cart.
+	AddCouponProducts(ctx context.Context, arg AddCouponProductsParams) error
+	AddDraftOrderItem(ctx context.Context, arg AddDraftOrderItemParams) error
+	AddOrderReturnItem(ctx context.Context, arg AddOrderReturnItemParams) error
+	AddOrderReturnRefund(ctx context.Context, arg AddOrderReturnRefundParams) error
 	AddProductCategory(ctx context.Context, arg AddProductCategoryParams) error
 	AddProductCollection(ctx context.Context, arg AddProductCollectionParams) error
 	AddProductToCollection(ctx context.Context, arg AddProductToCollectionParams) error
+	AddRefundItem(ctx context.Context, arg AddRefundItemParams) error
+	AddShipmentItem(ctx context.Context, arg AddShipmentItemParams) error
 	AddWishlistItem(ctx context.Context, arg AddWishlistItemParams) error
+	// Hands a guest cart over to a user who has no cart yet.
+	AssignCartToUser(ctx context.Context, arg AssignCartToUserParams) (int64, error)
 	AtomicRemoveCartItem(ctx context.Context, arg AtomicRemoveCartItemParams) error
+	CancelDraftOrder(ctx context.Context, id pgtype.UUID) (int64, error)
+	CancelPreorderBalanceRequest(ctx context.Context, id pgtype.UUID) (int64, error)
 	CheckItemInWishlist(ctx context.Context, arg CheckItemInWishlistParams) (bool, error)
+	// Claims a batch of due emails for delivery. A claim is a lease: if the dispatcher dies
+	// mid-send, the row becomes due again once the lease runs out.
+	ClaimDueEmails(ctx context.Context, arg ClaimDueEmailsParams) ([]EmailOutbox, error)
+	// Claims (user_id, key) for a new request. An expired row is taken over in place.
+	// Zero rows affected means a live record already exists.
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int64, error)
 	ClearCart(ctx context.Context, cartID pgtype.UUID) error
+	ClearCouponCategories(ctx context.Context, couponID pgtype.UUID) error
+	ClearCouponCollections(ctx context.Context, couponID pgtype.UUID) error
+	ClearCouponProducts(ctx context.Context, couponID pgtype.UUID) error
 	ClearProductCategories(ctx context.Context, productID pgtype.UUID) error
 	ClearProductCollections(ctx context.Context, productID pgtype.UUID) error
+	// Only an open draft can be completed, so a draft never turns into two orders.
+	CompleteDraftOrder(ctx context.Context, arg CompleteDraftOrderParams) (int64, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	CompletePaymentSession(ctx context.Context, arg CompletePaymentSessionParams) error
 	CountAllVariantsWithProduct(ctx context.Context, arg CountAllVariantsWithProductParams) (int64, error)
+	CountBlocklistEntries(ctx context.Context, arg CountBlocklistEntriesParams) (int64, error)
 	CountCoupons(ctx context.Context) (int64, error)
+	CountDraftOrders(ctx context.Context, status *string) (int64, error)
 	CountInventoryLogs(ctx context.Context, dollar_1 pgtype.UUID) (int64, error)
+	// Units pre-ordered on active orders and not yet covered by a release.
+	CountOpenPreorderUnits(ctx context.Context, productID pgtype.UUID) (int32, error)
+	CountOrderReturns(ctx context.Context, status *string) (int64, error)
 	CountOrders(ctx context.Context, arg CountOrdersParams) (int64, error)
 	CountProducts(ctx context.Context, arg CountProductsParams) (int64, error)
 	CountProductsWithCategoryFilter(ctx context.Context, arg CountProductsWithCategoryFilterParams) (int64, error)
@@ -33,26 +64,50 @@ type Querier interface {
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
 	CreateCollection(ctx context.Context, arg CreateCollectionParams) (Collection, error)
 	CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error)
+	CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (CouponRedemption, error)
+	CreateDraftOrder(ctx context.Context, arg CreateDraftOrderParams) (DraftOrder, error)
 	CreateInventoryLog(ctx context.Context, arg CreateInventoryLogParams) (InventoryLog, error)
+	// Returns no row when the order already has an invoice (a racing issuer won); the
+	// sequence value drawn for the losing insert is skipped. Numbers are zero-padded to
+	// six digits and grow past that.
+	CreateInvoice(ctx context.Context, orderID pgtype.UUID) (OrderInvoice, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreateOrderExchange(ctx context.Context, arg CreateOrderExchangeParams) (OrderExchange, error)
 	CreateOrderHistory(ctx context.Context, arg CreateOrderHistoryParams) (OrderHistory, error)
 	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error)
+	CreateOrderOTP(ctx context.Context, arg CreateOrderOTPParams) (OrderOtp, error)
+	CreateOrderReturn(ctx context.Context, arg CreateOrderReturnParams) (OrderReturn, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreatePaymentSession(ctx context.Context, arg CreatePaymentSessionParams) (PaymentSession, error)
+	CreatePreorderBalanceRequest(ctx context.Context, arg CreatePreorderBalanceRequestParams) (PreorderBalanceRequest, error)
+	CreatePreorderRelease(ctx context.Context, arg CreatePreorderReleaseParams) (PreorderRelease, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
 	CreateReview(ctx context.Context, arg CreateReviewParams) (Review, error)
+	CreateShipment(ctx context.Context, arg CreateShipmentParams) (Shipment, error)
 	CreateShippingZone(ctx context.Context, arg CreateShippingZoneParams) (ShippingZone, error)
+	CreateStockReservation(ctx context.Context, arg CreateStockReservationParams) (StockReservation, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVariant(ctx context.Context, arg CreateVariantParams) (Variant, error)
 	CreateWishlist(ctx context.Context, userID pgtype.UUID) (Wishlist, error)
 	DeleteAddress(ctx context.Context, arg DeleteAddressParams) error
+	DeleteBlocklistEntry(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteCart(ctx context.Context, id pgtype.UUID) error
+	// Deletes a batch of guest carts left untouched for the idle window; items cascade.
+	DeleteIdleGuestCarts(ctx context.Context, arg DeleteIdleGuestCartsParams) (int64, error)
 	DeleteCategory(ctx context.Context, id pgtype.UUID) error
 	DeleteCollection(ctx context.Context, id pgtype.UUID) error
 	DeleteCoupon(ctx context.Context, id pgtype.UUID) error
+	DeleteDraftOrderItems(ctx context.Context, draftID pgtype.UUID) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteOrderItem(ctx context.Context, id pgtype.UUID) error
 	DeleteProduct(ctx context.Context, id pgtype.UUID) error
 	DeleteReview(ctx context.Context, id pgtype.UUID) error
 	DeleteShippingZone(ctx context.Context, id int32) error
 	DeleteVariant(ctx context.Context, id pgtype.UUID) error
 	DeleteVariantsByProductID(ctx context.Context, productID pgtype.UUID) error
+	EnqueueEmail(ctx context.Context, arg EnqueueEmailParams) (EmailOutbox, error)
+	ExpireStockReservations(ctx context.Context, batchSize int32) ([]StockReservation, error)
 	GetActiveChildCategories(ctx context.Context, parentID pgtype.UUID) ([]Category, error)
 	GetActiveCollections(ctx context.Context) ([]Collection, error)
 	GetActiveContentBlock(ctx context.Context, sectionKey string) (ContentBlock, error)
@@ -64,10 +119,13 @@ type Querier interface {
 	GetAllOrders(ctx context.Context, arg GetAllOrdersParams) ([]GetAllOrdersRow, error)
 	GetAllShippingZones(ctx context.Context) ([]ShippingZone, error)
 	GetAllVariantsWithProduct(ctx context.Context, arg GetAllVariantsWithProductParams) ([]GetAllVariantsWithProductRow, error)
+	GetBlocklistEntry(ctx context.Context, id pgtype.UUID) (BlocklistEntry, error)
+	GetCartByID(ctx context.Context, id pgtype.UUID) (Cart, error)
 	GetCartByUserID(ctx context.Context, userID pgtype.UUID) (Cart, error)
 	GetCartItemByProductID(ctx context.Context, arg GetCartItemByProductIDParams) (CartItem, error)
 	GetCartItems(ctx context.Context, cartID pgtype.UUID) ([]GetCartItemsRow, error)
 	GetCartWithItems(ctx context.Context, userID pgtype.UUID) ([]GetCartWithItemsRow, error)
+	GetCartWithItemsByCartID(ctx context.Context, id pgtype.UUID) ([]GetCartWithItemsByCartIDRow, error)
 	GetCategoriesByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]Category, error)
 	GetCategoriesFlat(ctx context.Context, isActive *bool) ([]Category, error)
 	GetCategoryByID(ctx context.Context, id pgtype.UUID) (Category, error)
@@ -83,24 +141,54 @@ type Querier interface {
 	GetContentBlockByKey(ctx context.Context, sectionKey string) (ContentBlock, error)
 	GetCouponByCode(ctx context.Context, code string) (Coupon, error)
 	GetCouponByID(ctx context.Context, id pgtype.UUID) (Coupon, error)
+	GetCouponRedemptionByOrderID(ctx context.Context, orderID pgtype.UUID) (CouponRedemption, error)
 	// Top customers by lifetime value (parameterized date range and limit)
 	GetCustomerLTV(ctx context.Context, arg GetCustomerLTVParams) ([]GetCustomerLTVRow, error)
 	// New vs Returning customers (parameterized date range)
 	GetCustomerRetention(ctx context.Context, arg GetCustomerRetentionParams) (GetCustomerRetentionRow, error)
+	// Signals from the past orders of a phone, client IP and user for scoring a new order.
+	// Counts with a _recent suffix only cover orders placed since @since.
+	GetCustomerRiskHistory(ctx context.Context, arg GetCustomerRiskHistoryParams) (GetCustomerRiskHistoryRow, error)
 	// Revenue aggregation by day with parameterized date range
 	GetDailySales(ctx context.Context, arg GetDailySalesParams) ([]GetDailySalesRow, error)
 	GetDailySalesStats(ctx context.Context, arg GetDailySalesStatsParams) ([]DailySalesStat, error)
 	// Variants with no sales in X days (parameterized)
 	GetDeadStockProducts(ctx context.Context, arg GetDeadStockProductsParams) ([]GetDeadStockProductsRow, error)
+	GetDraftOrderByID(ctx context.Context, id pgtype.UUID) (DraftOrder, error)
+	// Serializes edits and finalisation of the same draft.
+	GetDraftOrderForUpdate(ctx context.Context, id pgtype.UUID) (DraftOrder, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetInventoryLogs(ctx context.Context, arg GetInventoryLogsParams) ([]InventoryLog, error)
+	GetInvoiceByOrderID(ctx context.Context, orderID pgtype.UUID) (OrderInvoice, error)
+	GetLatestOrderOTP(ctx context.Context, orderID pgtype.UUID) (GetLatestOrderOTPRow, error)
 	// L9 Dashboard/Stats Queries: Fully Parameterized (Zero Hardcoded Values)
 	// All date ranges, thresholds, limits controlled by frontend via query params
 	// Variants below threshold (parameterized - no hardcoded limit)
 	GetLowStockProducts(ctx context.Context, arg GetLowStockProductsParams) ([]GetLowStockProductsRow, error)
 	GetOrderByID(ctx context.Context, id pgtype.UUID) (GetOrderByIDRow, error)
+	GetOrderExchangeByID(ctx context.Context, id pgtype.UUID) (OrderExchange, error)
+	// Serializes receiving the same exchange twice.
+	GetOrderExchangeForUpdate(ctx context.Context, id pgtype.UUID) (OrderExchange, error)
 	GetOrderHistory(ctx context.Context, orderID pgtype.UUID) ([]GetOrderHistoryRow, error)
+	GetOrderIDByNumber(ctx context.Context, orderNumber string) (pgtype.UUID, error)
 	GetOrderItems(ctx context.Context, orderID pgtype.UUID) ([]GetOrderItemsRow, error)
+	// Net stock movement per variant logged against an order and its exchanges. Negative means stock is currently deducted.
+	GetOrderNetStockChanges(ctx context.Context, orderID string) ([]GetOrderNetStockChangesRow, error)
+	// How many codes an order has been sent, and how long ago the last one went out.
+	GetOrderOTPSendStats(ctx context.Context, orderID pgtype.UUID) (GetOrderOTPSendStatsRow, error)
+	GetOrderReturnByID(ctx context.Context, id pgtype.UUID) (OrderReturn, error)
+	// Serializes admin decisions on the same return.
+	GetOrderReturnForUpdate(ctx context.Context, id pgtype.UUID) (OrderReturn, error)
 	GetOrdersByUserID(ctx context.Context, userID pgtype.UUID) ([]Order, error)
+	GetPaymentSessionByGatewayRef(ctx context.Context, arg GetPaymentSessionByGatewayRefParams) (PaymentSession, error)
+	GetPaymentSessionByID(ctx context.Context, id pgtype.UUID) (PaymentSession, error)
+	// Serializes concurrent callbacks (IPN + browser redirect) for the same session.
+	GetPaymentSessionForUpdate(ctx context.Context, id pgtype.UUID) (PaymentSession, error)
+	GetPendingPreorderBalanceRequest(ctx context.Context, orderID pgtype.UUID) (PreorderBalanceRequest, error)
+	GetPreorderBalanceRequest(ctx context.Context, id pgtype.UUID) (GetPreorderBalanceRequestRow, error)
+	// Serializes payments recorded against the same request.
+	GetPreorderBalanceRequestForUpdate(ctx context.Context, id pgtype.UUID) (PreorderBalanceRequest, error)
+	GetPreorderRelease(ctx context.Context, id pgtype.UUID) (GetPreorderReleaseRow, error)
 	GetProductByID(ctx context.Context, id pgtype.UUID) (Product, error)
 	GetProductBySlug(ctx context.Context, slug string) (Product, error)
 	GetProductIDsForCollection(ctx context.Context, collectionID pgtype.UUID) ([]pgtype.UUID, error)
@@ -111,16 +199,26 @@ type Querier interface {
 	GetProductsWithPriceRange(ctx context.Context, arg GetProductsWithPriceRangeParams) ([]Product, error)
 	GetRefreshToken(ctx context.Context, token string) (RefreshToken, error)
 	GetRefundsByOrderID(ctx context.Context, orderID pgtype.UUID) ([]GetRefundsByOrderIDRow, error)
+	// Quantities currently held against each variant (active and not yet expired),
+	// optionally ignoring one order's own holds.
+	GetReservedQuantities(ctx context.Context, arg GetReservedQuantitiesParams) ([]GetReservedQuantitiesRow, error)
 	// Key performance indicators for a parameterized date range
 	GetRevenueKPIs(ctx context.Context, arg GetRevenueKPIsParams) (GetRevenueKPIsRow, error)
 	GetReviewByID(ctx context.Context, id pgtype.UUID) (Review, error)
 	GetReviewsByProductID(ctx context.Context, productID pgtype.UUID) ([]GetReviewsByProductIDRow, error)
 	GetRootCategories(ctx context.Context) ([]Category, error)
+	GetShipmentByConsignment(ctx context.Context, arg GetShipmentByConsignmentParams) (Shipment, error)
+	GetShipmentByID(ctx context.Context, id pgtype.UUID) (Shipment, error)
+	// Courier statements identify parcels by consignment ID or tracking code.
+	GetShipmentByTracking(ctx context.Context, arg GetShipmentByTrackingParams) (Shipment, error)
+	// Serializes concurrent webhooks for the same consignment.
+	GetShipmentForUpdate(ctx context.Context, id pgtype.UUID) (Shipment, error)
 	GetShippingZoneByID(ctx context.Context, id int32) (ShippingZone, error)
 	GetShippingZoneByKey(ctx context.Context, key string) (ShippingZone, error)
 	// Best-selling products by quantity (parameterized date range and limit)
 	GetTopSellingProducts(ctx context.Context, arg GetTopSellingProductsParams) ([]GetTopSellingProductsRow, error)
 	GetTotalRevenue(ctx context.Context) (pgtype.Numeric, error)
+	// Accounts only: guest checkout records may share an email and are never signed into.
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 	GetUserReviewForProduct(ctx context.Context, arg GetUserReviewForProductParams) (Review, error)
@@ -132,47 +230,117 @@ type Querier interface {
 	GetWishlistItems(ctx context.Context, wishlistID pgtype.UUID) ([]GetWishlistItemsRow, error)
 	HasPurchasedProduct(ctx context.Context, arg HasPurchasedProductParams) (bool, error)
 	// L9 Optimization: Atomic increment with optimistic concurrency check if needed.
-	// We rely on db-level atomicity here.
-	IncrementCouponUsage(ctx context.Context, id pgtype.UUID) error
+	// We rely on db-level atomicity here. Zero rows affected means the limit was reached.
+	IncrementCouponUsage(ctx context.Context, id pgtype.UUID) (int64, error)
+	ListBlocklistEntries(ctx context.Context, arg ListBlocklistEntriesParams) ([]BlocklistEntry, error)
 	ListCategorySlugs(ctx context.Context) ([]ListCategorySlugsRow, error)
 	ListCollectionSlugs(ctx context.Context) ([]ListCollectionSlugsRow, error)
+	// One round-trip for all scope targets of a page of coupons.
+	ListCouponScopes(ctx context.Context, couponIds []pgtype.UUID) ([]ListCouponScopesRow, error)
 	ListCoupons(ctx context.Context, arg ListCouponsParams) ([]Coupon, error)
+	ListDraftOrderItems(ctx context.Context, draftIds []pgtype.UUID) ([]ListDraftOrderItemsRow, error)
+	ListDraftOrders(ctx context.Context, arg ListDraftOrdersParams) ([]DraftOrder, error)
+	ListOrderExchangesByOrder(ctx context.Context, orderID pgtype.UUID) ([]OrderExchange, error)
+	ListOrderReturnItems(ctx context.Context, returnIds []pgtype.UUID) ([]OrderReturnItem, error)
+	ListOrderReturns(ctx context.Context, arg ListOrderReturnsParams) ([]OrderReturn, error)
+	ListOrderReturnsByOrder(ctx context.Context, orderID pgtype.UUID) ([]OrderReturn, error)
+	ListPaymentsByOrder(ctx context.Context, orderID pgtype.UUID) ([]ListPaymentsByOrderRow, error)
+	ListPaymentSessionsByOrder(ctx context.Context, orderID pgtype.UUID) ([]PaymentSession, error)
+	ListPendingPaymentsForUpdate(ctx context.Context, orderID pgtype.UUID) ([]Payment, error)
+	ListPreorderBalanceRequests(ctx context.Context, releaseID pgtype.UUID) ([]ListPreorderBalanceRequestsRow, error)
+	ListPreorderReleases(ctx context.Context, productID pgtype.UUID) ([]ListPreorderReleasesRow, error)
 	ListProductSlugs(ctx context.Context) ([]ListProductSlugsRow, error)
+	ListRefundItemsByOrder(ctx context.Context, orderID pgtype.UUID) ([]RefundItem, error)
+	ListShipmentItems(ctx context.Context, shipmentID pgtype.UUID) ([]ShipmentItem, error)
+	ListShipmentItemsByOrder(ctx context.Context, orderID pgtype.UUID) ([]ShipmentItem, error)
+	ListShipmentsByOrder(ctx context.Context, orderID pgtype.UUID) ([]Shipment, error)
+	ListStockReservationsByOrder(ctx context.Context, orderID pgtype.UUID) ([]StockReservation, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// Serializes claims on the order's items (returns, exchanges) until commit.
+	LockOrder(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error)
+	// Serializes pre-order checkouts and releases of a product until commit.
+	LockPreorderProduct(ctx context.Context, id pgtype.UUID) (*int32, error)
+	MarkDraftOrderSent(ctx context.Context, id pgtype.UUID) error
+	MarkEmailFailed(ctx context.Context, arg MarkEmailFailedParams) error
+	MarkEmailRetry(ctx context.Context, arg MarkEmailRetryParams) error
+	MarkEmailSent(ctx context.Context, id pgtype.UUID) error
+	MarkOrderExchangeReceived(ctx context.Context, id pgtype.UUID) error
+	MarkOrderOTPVerified(ctx context.Context, id pgtype.UUID) (int64, error)
+	// Unexpired entries matching any of the identifiers, 'block' entries first. IP entries
+	// match when their range contains the address.
+	MatchBlocklistEntries(ctx context.Context, arg MatchBlocklistEntriesParams) ([]BlocklistEntry, error)
+	// Advances the year's order counter. The counter row stays locked until the
+	// transaction ends, so numbers are handed out in commit order without gaps.
+	NextOrderNumber(ctx context.Context) (NextOrderNumberRow, error)
+	// Zero rows affected means this (provider, event_key) was already processed: a replay.
+	RecordCourierEvent(ctx context.Context, arg RecordCourierEventParams) (int64, error)
+	// Zero rows affected means this (provider, event_key) was already processed: a replay.
+	RecordPaymentCallback(ctx context.Context, arg RecordPaymentCallbackParams) (int64, error)
+	// Adds a collected amount; a settled request is marked paid.
+	RecordPreorderBalancePayment(ctx context.Context, arg RecordPreorderBalancePaymentParams) error
+	ReleaseIdempotencyKey(ctx context.Context, arg ReleaseIdempotencyKeyParams) error
+	// Covers the open pre-ordered items of a product by the release and returns their orders.
+	ReleasePreorderItems(ctx context.Context, arg ReleasePreorderItemsParams) ([]pgtype.UUID, error)
+	RemoveCartItem(ctx context.Context, arg RemoveCartItemParams) error
 	RemoveProductCategory(ctx context.Context, arg RemoveProductCategoryParams) error
 	RemoveProductCollection(ctx context.Context, arg RemoveProductCollectionParams) error
 	RemoveProductFromCollection(ctx context.Context, arg RemoveProductFromCollectionParams) error
 	RemoveWishlistItem(ctx context.Context, arg RemoveWishlistItemParams) error
+	// Re-activates an order's live and timed-out holds for at least another ttl.
+	RenewOrderReservations(ctx context.Context, arg RenewOrderReservationsParams) (int64, error)
 	RevokeRefreshToken(ctx context.Context, token string) error
 	SaveRefreshToken(ctx context.Context, arg SaveRefreshTokenParams) (RefreshToken, error)
 	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error)
+	SetInvoicePDFURL(ctx context.Context, arg SetInvoicePDFURLParams) error
+	// Moves every uncommitted hold of an order to committed/released.
+	SetOrderReservationsStatus(ctx context.Context, arg SetOrderReservationsStatusParams) ([]StockReservation, error)
+	// Re-derives the order's paid and refunded amounts from its verified ledger entries.
+	SyncOrderPaymentTotals(ctx context.Context, id pgtype.UUID) error
+	TouchCart(ctx context.Context, id pgtype.UUID) error
 	UpdateAddress(ctx context.Context, arg UpdateAddressParams) (Address, error)
+	UpdateBlocklistEntry(ctx context.Context, arg UpdateBlocklistEntryParams) (BlocklistEntry, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
 	UpdateCategoryOrder(ctx context.Context, arg UpdateCategoryOrderParams) error
 	UpdateCollection(ctx context.Context, arg UpdateCollectionParams) (Collection, error)
 	UpdateContentBlockSchedule(ctx context.Context, arg UpdateContentBlockScheduleParams) error
 	UpdateCoupon(ctx context.Context, arg UpdateCouponParams) error
-	UpdateOrderPaidAmount(ctx context.Context, arg UpdateOrderPaidAmountParams) error
+	UpdateDraftOrder(ctx context.Context, arg UpdateDraftOrderParams) (DraftOrder, error)
+	UpdateOrderAmounts(ctx context.Context, arg UpdateOrderAmountsParams) error
+	UpdateOrderItem(ctx context.Context, arg UpdateOrderItemParams) error
 	UpdateOrderPaymentStatus(ctx context.Context, arg UpdateOrderPaymentStatusParams) error
-	UpdateOrderRefundedAmount(ctx context.Context, arg UpdateOrderRefundedAmountParams) error
+	// Run once the refund's ledger entry is synced into refunded_amount. The order status
+	// only moves through the order state machine.
+	UpdateOrderRefundStatus(ctx context.Context, id pgtype.UUID) error
+	UpdateOrderReturnItem(ctx context.Context, arg UpdateOrderReturnItemParams) error
+	UpdateOrderReturnStatus(ctx context.Context, arg UpdateOrderReturnStatusParams) error
 	UpdateOrderShippingDetails(ctx context.Context, arg UpdateOrderShippingDetailsParams) error
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) error
+	UpdateOrderTotalAmount(ctx context.Context, arg UpdateOrderTotalAmountParams) error
+	UpdatePaymentSessionGateway(ctx context.Context, arg UpdatePaymentSessionGatewayParams) error
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
 	UpdateProductStatus(ctx context.Context, arg UpdateProductStatusParams) error
+	UpdateShipmentStatus(ctx context.Context, arg UpdateShipmentStatusParams) error
 	UpdateShippingZone(ctx context.Context, arg UpdateShippingZoneParams) (ShippingZone, error)
 	UpdateShippingZoneCost(ctx context.Context, arg UpdateShippingZoneCostParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpdateVariant(ctx context.Context, arg UpdateVariantParams) (Variant, error)
 	UpdateVariantStock(ctx context.Context, arg UpdateVariantStockParams) (int64, error)
+	// Adds an entry, or renews the existing one for the same kind and value.
+	UpsertBlocklistEntry(ctx context.Context, arg UpsertBlocklistEntryParams) (BlocklistEntry, error)
 	// L9 FIX: Simplified atomic upsert without expression-based conflict target
 	UpsertCartItemAtomic(ctx context.Context, arg UpsertCartItemAtomicParams) ([]UpsertCartItemAtomicRow, error)
 	UpsertContentBlock(ctx context.Context, arg UpsertContentBlockParams) (ContentBlock, error)
 	UpsertDailySalesStat(ctx context.Context, arg UpsertDailySalesStatParams) error
+	// Spends one guess. No row: the code is verified, expired or out of attempts.
+	UseOrderOTPAttempt(ctx context.Context, id pgtype.UUID) (UseOrderOTPAttemptRow, error)
 	// L9 Optimization: Single-pass validation logic pushed to DB.
 	// Returns the coupon if valid, or a status reason if not.
 	// Uses covering indexes on (code) and partial indexes on (is_active) where applicable.
+	// FOR UPDATE: inside checkout the row stays locked until commit so usage_limit cannot be overshot.
+	// Scoped coupons (products/categories/collections) report which of @product_ids they discount.
 	ValidateCoupon(ctx context.Context, arg ValidateCouponParams) (ValidateCouponRow, error)
+	VerifyPayment(ctx context.Context, arg VerifyPaymentParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
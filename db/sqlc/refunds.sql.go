@@ -11,18 +11,43 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addRefundItem = `-- name: AddRefundItem :exec
+INSERT INTO refund_items (refund_id, order_item_id, quantity, amount)
+VALUES ($1, $2, $3, $4)
+`
+
+type AddRefundItemParams struct {
+	RefundID    pgtype.UUID    `json:"refund_id"`
+	OrderItemID pgtype.UUID    `json:"order_item_id"`
+	Quantity    int32          `json:"quantity"`
+	Amount      pgtype.Numeric `json:"amount"`
+}
+
+func (q *Queries) AddRefundItem(ctx context.Context, arg AddRefundItemParams) error {
+	_, err := q.db.Exec(ctx, addRefundItem,
+		arg.RefundID,
+		arg.OrderItemID,
+		arg.Quantity,
+		arg.Amount,
+	)
+	return err
+}
+
 const createRefund = `-- name: CreateRefund :one
-INSERT INTO refunds (order_id, amount, reason, restock_items, created_by)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, order_id, amount, reason, restock_items, created_at, created_by
+INSERT INTO refunds (order_id, amount, reason, restock_items, created_by, method, reference, shipping_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, order_id, amount, reason, restock_items, created_at, created_by, method, reference, shipping_amount
 `
 
 type CreateRefundParams struct {
-	OrderID      pgtype.UUID    `json:"order_id"`
-	Amount       pgtype.Numeric `json:"amount"`
-	Reason       *string        `json:"reason"`
-	RestockItems bool           `json:"restock_items"`
-	CreatedBy    pgtype.UUID    `json:"created_by"`
+	OrderID        pgtype.UUID    `json:"order_id"`
+	Amount         pgtype.Numeric `json:"amount"`
+	Reason         *string        `json:"reason"`
+	RestockItems   bool           `json:"restock_items"`
+	CreatedBy      pgtype.UUID    `json:"created_by"`
+	Method         string         `json:"method"`
+	Reference      *string        `json:"reference"`
+	ShippingAmount pgtype.Numeric `json:"shipping_amount"`
 }
 
 func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error) {
@@ -32,6 +57,9 @@ func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (Ref
 		arg.Reason,
 		arg.RestockItems,
 		arg.CreatedBy,
+		arg.Method,
+		arg.Reference,
+		arg.ShippingAmount,
 	)
 	var i Refund
 	err := row.Scan(
@@ -42,12 +70,15 @@ func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (Ref
 		&i.RestockItems,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.Method,
+		&i.Reference,
+		&i.ShippingAmount,
 	)
 	return i, err
 }
 
 const getRefundsByOrderID = `-- name: GetRefundsByOrderID :many
-SELECT r.id, r.order_id, r.amount, r.reason, r.restock_items, r.created_at, r.created_by, u.first_name as processed_by_name
+SELECT r.id, r.order_id, r.amount, r.reason, r.restock_items, r.created_at, r.created_by, r.method, r.reference, r.shipping_amount, u.first_name as processed_by_name
 FROM refunds r
 LEFT JOIN users u ON u.id = r.created_by
 WHERE r.order_id = $1
@@ -62,6 +93,9 @@ type GetRefundsByOrderIDRow struct {
 	RestockItems    bool             `json:"restock_items"`
 	CreatedAt       pgtype.Timestamp `json:"created_at"`
 	CreatedBy       pgtype.UUID      `json:"created_by"`
+	Method          string           `json:"method"`
+	Reference       *string          `json:"reference"`
+	ShippingAmount  pgtype.Numeric   `json:"shipping_amount"`
 	ProcessedByName *string          `json:"processed_by_name"`
 }
 
@@ -82,6 +116,9 @@ func (q *Queries) GetRefundsByOrderID(ctx context.Context, orderID pgtype.UUID)
 			&i.RestockItems,
 			&i.CreatedAt,
 			&i.CreatedBy,
+			&i.Method,
+			&i.Reference,
+			&i.ShippingAmount,
 			&i.ProcessedByName,
 		); err != nil {
 			return nil, err
@@ -94,26 +131,50 @@ func (q *Queries) GetRefundsByOrderID(ctx context.Context, orderID pgtype.UUID)
 	return items, nil
 }
 
-const updateOrderRefundedAmount = `-- name: UpdateOrderRefundedAmount :exec
-UPDATE orders 
-SET refunded_amount = refunded_amount + $2,
-    payment_status = CASE 
-        WHEN (refunded_amount + $2) >= paid_amount THEN 'refunded'
+const listRefundItemsByOrder = `-- name: ListRefundItemsByOrder :many
+SELECT ri.refund_id, ri.order_item_id, ri.quantity, ri.amount
+FROM refund_items ri
+JOIN refunds r ON r.id = ri.refund_id
+WHERE r.order_id = $1
+`
+
+func (q *Queries) ListRefundItemsByOrder(ctx context.Context, orderID pgtype.UUID) ([]RefundItem, error) {
+	rows, err := q.db.Query(ctx, listRefundItemsByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RefundItem{}
+	for rows.Next() {
+		var i RefundItem
+		if err := rows.Scan(
+			&i.RefundID,
+			&i.OrderItemID,
+			&i.Quantity,
+			&i.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateOrderRefundStatus = `-- name: UpdateOrderRefundStatus :exec
+UPDATE orders
+SET payment_status = CASE
+        WHEN refunded_amount >= paid_amount THEN 'refunded'
         ELSE 'partial_refund'
-    END,
-    status = CASE 
-        WHEN (refunded_amount + $2) >= paid_amount THEN 'refunded'
-        ELSE status
     END
 WHERE id = $1
 `
 
-type UpdateOrderRefundedAmountParams struct {
-	ID     pgtype.UUID    `json:"id"`
-	Amount pgtype.Numeric `json:"amount"`
-}
-
-func (q *Queries) UpdateOrderRefundedAmount(ctx context.Context, arg UpdateOrderRefundedAmountParams) error {
-	_, err := q.db.Exec(ctx, updateOrderRefundedAmount, arg.ID, arg.Amount)
+// Run once the refund's ledger entry is synced into refunded_amount. The order status
+// only moves through the order state machine.
+func (q *Queries) UpdateOrderRefundStatus(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, updateOrderRefundStatus, id)
 	return err
 }
@@ -37,7 +37,7 @@ func (q *Queries) CountSearchProducts(ctx context.Context, arg CountSearchProduc
 }
 
 const searchProducts = `-- name: SearchProducts :many
-SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount,
+SELECT id, name, slug, description, base_price, sale_price, stock_status, is_featured, is_active, media, attributes, specifications, created_at, updated_at, search_vector, meta_title, meta_description, meta_keywords, og_image, brand, tags, warranty_info, is_preorder, preorder_deposit_amount, preorder_release_date, preorder_limit,
        COALESCE((ts_rank(search_vector, websearch_to_tsquery('english', $3)) +
         similarity(name, $3) * 2.0 +
         similarity(COALESCE(brand, ''), $3))::float8, 0)::float8 as rank
@@ -86,6 +86,8 @@ type SearchProductsRow struct {
 	WarrantyInfo          []byte           `json:"warranty_info"`
 	IsPreorder            bool             `json:"is_preorder"`
 	PreorderDepositAmount pgtype.Numeric   `json:"preorder_deposit_amount"`
+	PreorderReleaseDate   pgtype.Timestamp `json:"preorder_release_date"`
+	PreorderLimit         *int32           `json:"preorder_limit"`
 	Rank                  float64          `json:"rank"`
 }
 
@@ -128,6 +130,8 @@ func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams)
 			&i.WarrantyInfo,
 			&i.IsPreorder,
 			&i.PreorderDepositAmount,
+			&i.PreorderReleaseDate,
+			&i.PreorderLimit,
 			&i.Rank,
 		); err != nil {
 			return nil, err
@@ -74,8 +74,8 @@ func (q *Queries) CreateAddress(ctx context.Context, arg CreateAddressParams) (A
 }
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (email, role, first_name, last_name, avatar)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO users (email, role, first_name, last_name, avatar, phone)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id, email, role, first_name, last_name, avatar, created_at, updated_at, phone
 `
 
@@ -85,6 +85,7 @@ type CreateUserParams struct {
 	FirstName *string `json:"first_name"`
 	LastName  *string `json:"last_name"`
 	Avatar    *string `json:"avatar"`
+	Phone     *string `json:"phone"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -94,6 +95,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.FirstName,
 		arg.LastName,
 		arg.Avatar,
+		arg.Phone,
 	)
 	var i User
 	err := row.Scan(
@@ -185,9 +187,10 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, role, first_name, last_name, avatar, created_at, updated_at, phone FROM users WHERE email = $1
+SELECT id, email, role, first_name, last_name, avatar, created_at, updated_at, phone FROM users WHERE email = $1 AND role <> 'guest'
 `
 
+// Accounts only: guest checkout records may share an email and are never signed into.
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
 	row := q.db.QueryRow(ctx, getUserByEmail, email)
 	var i User
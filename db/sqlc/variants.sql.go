@@ -314,6 +314,41 @@ func (q *Queries) GetInventoryLogs(ctx context.Context, arg GetInventoryLogsPara
 	return items, nil
 }
 
+const getOrderNetStockChanges = `-- name: GetOrderNetStockChanges :many
+SELECT variant_id, SUM(change_amount)::int AS net_change
+FROM inventory_logs
+WHERE (reference_id = $1::text
+       OR reference_id IN (SELECT id::text FROM order_exchanges WHERE order_id = $1::text::uuid))
+  AND variant_id IS NOT NULL
+GROUP BY variant_id
+`
+
+type GetOrderNetStockChangesRow struct {
+	VariantID pgtype.UUID `json:"variant_id"`
+	NetChange int32       `json:"net_change"`
+}
+
+// Net stock movement per variant logged against an order and its exchanges. Negative means stock is currently deducted.
+func (q *Queries) GetOrderNetStockChanges(ctx context.Context, orderID string) ([]GetOrderNetStockChangesRow, error) {
+	rows, err := q.db.Query(ctx, getOrderNetStockChanges, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetOrderNetStockChangesRow{}
+	for rows.Next() {
+		var i GetOrderNetStockChangesRow
+		if err := rows.Scan(&i.VariantID, &i.NetChange); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getVariantByID = `-- name: GetVariantByID :one
 SELECT id, product_id, name, stock, sku, attributes, price, sale_price, images, weight, dimensions, barcode, low_stock_threshold, created_at, updated_at FROM variants WHERE id = $1
 `
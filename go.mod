@@ -17,12 +17,14 @@ require (
 )
 
 require (
+	github.com/boombuler/barcode v1.0.1
 	github.com/chai2010/webp v1.4.0
 	github.com/disintegration/imaging v1.6.2
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 )
 
-require golang.org/x/image v0.0.0-20211028202545-6944b10bf410 // indirect
+require golang.org/x/image v0.12.0 // indirect
 
 require (
 	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.7.4 // indirect
@@ -52,3 +52,24 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// OptionalAuthMiddleware sets the user in context when a valid token is present and
+// otherwise lets the request through anonymously (e.g. guest carts).
+func OptionalAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := utils.ExtractClaims(r)
+		if err != nil || claims.UserID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user := &domain.User{
+			ID:    claims.UserID,
+			Email: claims.Email,
+			Role:  claims.Role,
+		}
+
+		ctx := context.WithValue(r.Context(), domain.UserContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
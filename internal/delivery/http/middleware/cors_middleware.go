@@ -31,7 +31,8 @@ func NewCORSMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 			// If not allowed, we just don't set the header, effectively blocking CORS for browsers.
 
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Cart-Token")
+			w.Header().Set("Access-Control-Expose-Headers", "Idempotent-Replayed, X-Cart-Token")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 			// Handle Preflight requests
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+// IdempotencyHeader is the request header carrying the client-generated key.
+const IdempotencyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLen = 255
+
+// Idempotency replays the first stored response for repeated requests that carry the
+// same Idempotency-Key, so retries and double-clicks cannot repeat a mutation.
+// L9: Keys are scoped per user and persisted in Postgres; expired keys are purged in
+// the background with the same lifecycle pattern as RateLimiter.
+type Idempotency struct {
+	repo          domain.IdempotencyRepository
+	ttl           time.Duration
+	cleanupPeriod time.Duration
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+// NewIdempotency creates the middleware and starts the expired-key cleanup loop.
+// ttl: how long a stored response can be replayed
+func NewIdempotency(ctx context.Context, repo domain.IdempotencyRepository, ttl, cleanupPeriod time.Duration) *Idempotency {
+	m := &Idempotency{
+		repo:          repo,
+		ttl:           ttl,
+		cleanupPeriod: cleanupPeriod,
+	}
+	m.ctx, m.cancel = context.WithCancel(ctx)
+	go m.cleanupLoop()
+	return m
+}
+
+// Wrap guards a handler. It must run after AuthMiddleware (the key is scoped to the user).
+// Requests without the header pass straight through.
+func (m *Idempotency) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	userID := func(r *http.Request) string {
+		if user, ok := r.Context().Value(domain.UserContextKey).(*domain.User); ok && user != nil {
+			return user.ID
+		}
+		return ""
+	}
+	unauthorized := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return m.wrap(next, userID, unauthorized)
+}
+
+// WrapGuest guards a public handler, scoping keys to the UUID that scope returns for the
+// request (e.g. the guest cart). Requests without a scope pass straight through.
+func (m *Idempotency) WrapGuest(scope func(r *http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	return m.wrap(next, scope, next)
+}
+
+// wrap replays stored responses per (scope, key). Keyed requests without a scope are
+// handed to noScope.
+func (m *Idempotency) wrap(next http.HandlerFunc, scope func(r *http.Request) string, noScope http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeIdempotencyError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		scopeID := scope(r)
+		if scopeID == "" {
+			noScope(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Same key on a different endpoint or with a different body is a conflict
+		sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		claimed, err := m.repo.Claim(r.Context(), scopeID, key, hash, m.ttl)
+		if err != nil {
+			slog.Error("Idempotency: claim failed", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if !claimed {
+			m.replay(w, r, scopeID, key, hash)
+			return
+		}
+
+		rec := &idempotencyRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			// Server errors (and panics) are not cached: the client may retry with the same key
+			if p := recover(); p != nil {
+				m.release(scopeID, key)
+				panic(p)
+			}
+			if rec.statusCode >= 500 {
+				m.release(scopeID, key)
+				return
+			}
+			if err := m.repo.Complete(context.Background(), scopeID, key, rec.statusCode, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+				slog.Error("Idempotency: failed to store response", "key", key, "error", err)
+			}
+		}()
+
+		next(rec, r)
+	}
+}
+
+func (m *Idempotency) replay(w http.ResponseWriter, r *http.Request, userID, key, hash string) {
+	stored, err := m.repo.Get(r.Context(), userID, key)
+	if err != nil {
+		slog.Error("Idempotency: lookup failed", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if stored.RequestHash != hash {
+		writeIdempotencyError(w, http.StatusConflict, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if stored.StatusCode == nil {
+		writeIdempotencyError(w, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+		return
+	}
+
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(*stored.StatusCode)
+	w.Write(stored.ResponseBody)
+}
+
+func (m *Idempotency) release(userID, key string) {
+	if err := m.repo.Release(context.Background(), userID, key); err != nil {
+		slog.Error("Idempotency: failed to release key", "key", key, "error", err)
+	}
+}
+
+// cleanupLoop purges expired keys with context cancellation support
+func (m *Idempotency) cleanupLoop() {
+	ticker := time.NewTicker(m.cleanupPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n, err := m.repo.DeleteExpired(m.ctx); err != nil {
+				slog.Error("Idempotency: cleanup failed", "error", err)
+			} else if n > 0 {
+				slog.Info("Idempotency: purged expired keys", "count", n)
+			}
+		case <-m.ctx.Done():
+			return // Graceful shutdown
+		}
+	}
+}
+
+// Shutdown gracefully stops the cleanup goroutine
+func (m *Idempotency) Shutdown() {
+	m.cancel()
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// idempotencyRecorder passes the response through while keeping a copy for storage.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.statusCode = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"valancis-backend/internal/usecase"
 )
 
@@ -19,31 +21,106 @@ func NewAdminCouponHandler(uc *usecase.CouponUsecase) *AdminCouponHandler {
 // ListCoupons returns paginated list of all coupons.
 // GET /api/v1/admin/coupons?page=1&limit=20
 func (h *AdminCouponHandler) ListCoupons(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Coupon system is currently deactivated", http.StatusGone)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	coupons, total, err := h.couponUC.ListCoupons(r.Context(), limit, (page-1)*limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"coupons": coupons,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
 }
 
 // CreateCoupon creates a new coupon.
 // POST /api/v1/admin/coupons
 func (h *AdminCouponHandler) CreateCoupon(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Coupon system is currently deactivated", http.StatusGone)
+	var req usecase.CreateCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.couponUC.CreateCoupon(r.Context(), req)
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(coupon)
 }
 
 // GetCoupon returns a single coupon by ID.
 // GET /api/v1/admin/coupons/{id}
 func (h *AdminCouponHandler) GetCoupon(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Coupon system is currently deactivated", http.StatusGone)
+	coupon, err := h.couponUC.GetCoupon(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupon)
 }
 
 // UpdateCoupon updates an existing coupon.
 // PUT /api/v1/admin/coupons/{id}
 func (h *AdminCouponHandler) UpdateCoupon(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Coupon system is currently deactivated", http.StatusGone)
+	var req usecase.UpdateCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.couponUC.UpdateCoupon(r.Context(), r.PathValue("id"), req); err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Coupon updated"})
 }
 
 // DeleteCoupon deletes a coupon by ID.
 // DELETE /api/v1/admin/coupons/{id}
 func (h *AdminCouponHandler) DeleteCoupon(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "Coupon system is currently deactivated", http.StatusGone)
+	if err := h.couponUC.DeleteCoupon(r.Context(), r.PathValue("id")); err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Coupon deleted"})
+}
+
+// writeCouponError maps usecase errors to HTTP status codes.
+func writeCouponError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case err.Error() == "coupon not found":
+		status = http.StatusNotFound
+	case isValidationError(err):
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 }
 
 // isValidationError checks if an error is a validation error based on message.
@@ -54,6 +131,8 @@ func isValidationError(err error) bool {
 		"is required",
 		"must be",
 		"cannot exceed",
+		"cannot be negative",
+		"cannot be deleted",
 		"already exists",
 		"not found",
 		"invalid",
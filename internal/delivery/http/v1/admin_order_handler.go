@@ -4,16 +4,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"valancis-backend/internal/domain"
 	"valancis-backend/internal/usecase"
 )
 
 type AdminOrderHandler struct {
-	orderUC *usecase.OrderUsecase
+	orderUC     *usecase.OrderUsecase
+	blocklistUC *usecase.BlocklistUsecase
 }
 
-func NewAdminOrderHandler(uc *usecase.OrderUsecase) *AdminOrderHandler {
-	return &AdminOrderHandler{orderUC: uc}
+func NewAdminOrderHandler(uc *usecase.OrderUsecase, blocklistUC *usecase.BlocklistUsecase) *AdminOrderHandler {
+	return &AdminOrderHandler{orderUC: uc, blocklistUC: blocklistUC}
 }
 
 func (h *AdminOrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
@@ -39,6 +41,16 @@ func (h *AdminOrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 			filter.IsPreorder = &b
 		}
 	}
+	if val := r.URL.Query().Get("min_risk_score"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			filter.MinRiskScore = &n
+		}
+	}
+	if val := r.URL.Query().Get("max_risk_score"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			filter.MaxRiskScore = &n
+		}
+	}
 
 	orders, total, err := h.orderUC.GetAllOrders(r.Context(), filter)
 	if err != nil {
@@ -65,6 +77,8 @@ func (h *AdminOrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request)
 	var req struct {
 		Status string `json:"status"`
 		Note   string `json:"note"`
+		// BlockCustomer adds the phone and user of an order marked fake to the blocklist
+		BlockCustomer bool `json:"blockCustomer"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
@@ -86,6 +100,24 @@ func (h *AdminOrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// A fake order offers its customer for the blocklist, or blocks them right away
+	if req.Status == domain.OrderStatusFake {
+		resp := map[string]interface{}{"message": "Order status updated"}
+		if req.BlockCustomer {
+			entries, err := h.blocklistUC.BlockOrderCustomer(r.Context(), id, adminID)
+			if err != nil {
+				resp["blocklistError"] = err.Error()
+			} else {
+				resp["blocklisted"] = entries
+			}
+		} else if entries, err := h.blocklistUC.OrderEntries(r.Context(), id); err == nil {
+			resp["blocklistSuggestions"] = entries
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Order status updated"})
 }
@@ -101,7 +133,18 @@ func (h *AdminOrderHandler) VerifyPayment(w http.ResponseWriter, r *http.Request
 	}
 	adminID := user.ID
 
-	if err := h.orderUC.VerifyOrderPayment(r.Context(), id, adminID); err != nil {
+	// Optional: verify only some ledger entries; default is every pending one.
+	var req struct {
+		PaymentIDs []string `json:"paymentIds"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.orderUC.VerifyOrderPayment(r.Context(), id, req.PaymentIDs, adminID); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -144,11 +187,7 @@ func (h *AdminOrderHandler) RefundOrder(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var req struct {
-		Amount  float64 `json:"amount"`
-		Reason  string  `json:"reason"`
-		Restock bool    `json:"restock"`
-	}
+	var req usecase.RefundReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
@@ -162,15 +201,51 @@ func (h *AdminOrderHandler) RefundOrder(w http.ResponseWriter, r *http.Request)
 	}
 	adminID := user.ID
 
-	if err := h.orderUC.ProcessRefund(r.Context(), id, req.Amount, req.Reason, req.Restock, adminID); err != nil {
+	refund, err := h.orderUC.ProcessRefund(r.Context(), id, req, adminID)
+	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Refund processed"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Refund processed", "refund": refund})
+}
+
+// GetRefunds returns the order's refund ledger, newest first.
+// GET /api/v1/admin/orders/{id}/refunds
+func (h *AdminOrderHandler) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	refunds, err := h.orderUC.GetRefunds(r.Context(), r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refunds)
+}
+
+// GetPayments returns the order's payment ledger, oldest first.
+// GET /api/v1/admin/orders/{id}/payments
+func (h *AdminOrderHandler) GetPayments(w http.ResponseWriter, r *http.Request) {
+	payments, err := h.orderUC.GetPayments(r.Context(), r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payments)
 }
 
 func (h *AdminOrderHandler) UpdatePaymentStatus(w http.ResponseWriter, r *http.Request) {
@@ -249,3 +324,35 @@ func (h *AdminOrderHandler) UpdateShippingZone(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Shipping zone updated"})
 }
+
+// EditOrder changes the items of an order before dispatch and recomputes its totals.
+// PATCH /api/v1/admin/orders/{id}/items
+func (h *AdminOrderHandler) EditOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.EditOrderReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orderUC.EditOrder(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusBadRequest
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "cannot be edited") || strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "out of stock") {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
@@ -32,8 +32,8 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Updated call to AuthenticateGoogle
-	accessToken, refreshToken, user, err := h.authUC.AuthenticateGoogle(r.Context(), req.Code)
+	// Any guest cart built before sign-in is merged into the user's cart
+	accessToken, refreshToken, user, err := h.authUC.AuthenticateGoogle(r.Context(), req.Code, guestCartID(r))
 	if err != nil {
 		slog.Error("Authentication failed", "error", err)
 		http.Error(w, err.Error(), http.StatusUnauthorized)
@@ -50,6 +50,8 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 		SameSite: http.SameSiteNoneMode,
 		MaxAge:   7 * 24 * 60 * 60, // 7 days
 	})
+	// The guest cart has been claimed; drop its token
+	http.SetCookie(w, &http.Cookie{Name: cartTokenCookie, MaxAge: -1, Path: "/"})
 
 	slog.Info("User authenticated successfully", "user_id", user.ID, "email", user.Email)
 
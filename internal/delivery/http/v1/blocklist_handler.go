@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+	"valancis-backend/pkg/utils"
+)
+
+// BlocklistHandler exposes the customer blocklist to admins.
+type BlocklistHandler struct {
+	blocklistUC *usecase.BlocklistUsecase
+}
+
+func NewBlocklistHandler(uc *usecase.BlocklistUsecase) *BlocklistHandler {
+	return &BlocklistHandler{blocklistUC: uc}
+}
+
+// ListEntries returns a page of blocklist entries.
+// GET /api/v1/admin/blocklist?kind=phone&search=017&active=true&page=1&limit=20
+func (h *BlocklistHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	filter := domain.BlocklistFilter{
+		Page:   page,
+		Limit:  limit,
+		Kind:   r.URL.Query().Get("kind"),
+		Search: r.URL.Query().Get("search"),
+	}
+	if val := r.URL.Query().Get("active"); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			filter.Active = &b
+		}
+	}
+
+	entries, total, err := h.blocklistUC.List(r.Context(), filter)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GetEntry returns one blocklist entry.
+// GET /api/v1/admin/blocklist/{id}
+func (h *BlocklistHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.blocklistUC.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, entry)
+}
+
+// CreateEntry adds a phone, email, user or IP range to the blocklist, or renews the
+// existing entry for it.
+// POST /api/v1/admin/blocklist
+func (h *BlocklistHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.BlocklistEntryReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	entry, err := h.blocklistUC.Create(r.Context(), req, user.ID)
+	if err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, entry)
+}
+
+// UpdateEntry changes an entry's action, reason and expiry.
+// PUT /api/v1/admin/blocklist/{id}
+func (h *BlocklistHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	var req usecase.BlocklistEntryReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	entry, err := h.blocklistUC.Update(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, entry)
+}
+
+// DeleteEntry removes an entry from the blocklist.
+// DELETE /api/v1/admin/blocklist/{id}
+func (h *BlocklistHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	if err := h.blocklistUC.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Blocklist entry deleted"})
+}
+
+// GetOrderEntries previews the entries that would block an order's customer.
+// GET /api/v1/admin/orders/{id}/blocklist
+func (h *BlocklistHandler) GetOrderEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.blocklistUC.OrderEntries(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string][]domain.BlocklistEntry{"entries": entries})
+}
+
+// BlockOrderCustomer adds the phone and user of a fake order to the blocklist.
+// POST /api/v1/admin/orders/{id}/blocklist
+func (h *BlocklistHandler) BlockOrderCustomer(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := h.blocklistUC.BlockOrderCustomer(r.Context(), r.PathValue("id"), user.ID)
+	if err != nil {
+		writeBlocklistError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string][]domain.BlocklistEntry{"entries": entries})
+}
+
+// writeBlocklistError maps usecase errors to HTTP status codes.
+func writeBlocklistError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "failed to"):
+		status = http.StatusInternalServerError
+	}
+	utils.WriteError(w, status, err.Error())
+}
@@ -38,8 +38,8 @@ func (h *CatalogHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		page = 1
 	}
 
-	minPrice, _ := strconv.ParseFloat(query.Get("min_price"), 64)
-	maxPrice, _ := strconv.ParseFloat(query.Get("max_price"), 64)
+	minPrice, _ := domain.ParseMoney(query.Get("min_price"))
+	maxPrice, _ := domain.ParseMoney(query.Get("max_price"))
 
 	var isFeatured *bool
 	if val := query.Get("is_featured"); val != "" {
@@ -37,6 +37,8 @@ func (h *ConfigHandler) GetEnums(w http.ResponseWriter, r *http.Request) {
 		"orderStatuses":   domain.OrderStatuses,
 		"paymentStatuses": domain.PaymentStatuses,
 		"paymentMethods":  domain.PaymentMethods,
+		"refundMethods":   domain.RefundMethods,
+		"returnReasons":   domain.ReturnReasons,
 		"shippingZones":   zones,
 	}
 
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// DocumentHandler serves printable order documents as PDFs.
+type DocumentHandler struct {
+	documentUC *usecase.DocumentUsecase
+}
+
+func NewDocumentHandler(uc *usecase.DocumentUsecase) *DocumentHandler {
+	return &DocumentHandler{documentUC: uc}
+}
+
+func writePDF(w http.ResponseWriter, doc *usecase.PrintedDocument) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+doc.FileName+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(doc.PDF)
+}
+
+// writeDocumentError maps document errors to HTTP statuses.
+func writeDocumentError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	errMsg := err.Error()
+	status := http.StatusBadRequest
+	switch {
+	case strings.HasSuffix(errMsg, "not found"):
+		status = http.StatusNotFound
+	case strings.Contains(errMsg, "cannot be printed") || strings.Contains(errMsg, "available once"):
+		status = http.StatusConflict
+	case strings.HasPrefix(errMsg, "failed to"):
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": errMsg})
+}
+
+// GetOrderDocument prints the invoice, packing slip or shipping label of an order.
+// GET /api/v1/admin/orders/{id}/documents/{type}
+func (h *DocumentHandler) GetOrderDocument(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.documentUC.RenderDocuments(r.Context(), r.PathValue("type"), []string{r.PathValue("id")})
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	writePDF(w, doc)
+}
+
+// PrintDocuments merges one document per order into a single printable PDF.
+// POST /api/v1/admin/orders/documents
+func (h *DocumentHandler) PrintDocuments(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Type     string   `json:"type"` // invoice, packing_slip, shipping_label
+		OrderIDs []string `json:"orderIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type == "" || len(req.OrderIDs) == 0 {
+		http.Error(w, "type and orderIds are required", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.documentUC.RenderDocuments(r.Context(), req.Type, req.OrderIDs)
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	writePDF(w, doc)
+}
+
+// GetMyInvoice prints the invoice of one order of the signed-in customer.
+// GET /api/v1/orders/{id}/invoice
+func (h *DocumentHandler) GetMyInvoice(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	doc, err := h.documentUC.RenderMyInvoice(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	writePDF(w, doc)
+}
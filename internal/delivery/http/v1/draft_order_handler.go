@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// DraftOrderHandler exposes admin draft orders (phone / Messenger orders) and the
+// customer's pay/confirm link.
+type DraftOrderHandler struct {
+	draftUC *usecase.DraftOrderUsecase
+}
+
+func NewDraftOrderHandler(uc *usecase.DraftOrderUsecase) *DraftOrderHandler {
+	return &DraftOrderHandler{draftUC: uc}
+}
+
+// writeDraftOrderError maps draft order errors to HTTP statuses.
+func writeDraftOrderError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	errMsg := err.Error()
+	status := http.StatusBadRequest
+	switch {
+	case errMsg == "draft order not found" || errMsg == "customer not found":
+		status = http.StatusNotFound
+	case strings.Contains(errMsg, "cannot be") || strings.Contains(errMsg, "already placed") || strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "out of stock"):
+		status = http.StatusConflict
+	case strings.HasPrefix(errMsg, "failed to"):
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": errMsg})
+}
+
+// ListDrafts returns draft orders, newest first, optionally filtered by status.
+// GET /api/v1/admin/draft-orders
+func (h *DraftOrderHandler) ListDrafts(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	drafts, total, err := h.draftUC.ListDrafts(r.Context(), r.URL.Query().Get("status"), page, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"drafts": drafts,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
+
+// CreateDraft starts a draft order for an existing or new customer.
+// POST /api/v1/admin/draft-orders
+func (h *DraftOrderHandler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.DraftOrderReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	draft, err := h.draftUC.CreateDraft(r.Context(), req, user.ID)
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(draft)
+}
+
+// GetDraft returns a draft order priced at current prices.
+// GET /api/v1/admin/draft-orders/{id}
+func (h *DraftOrderHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	draft, err := h.draftUC.GetDraft(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// UpdateDraft replaces the items and details of an open draft order.
+// PUT /api/v1/admin/draft-orders/{id}
+func (h *DraftOrderHandler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	var req usecase.DraftOrderReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	draft, err := h.draftUC.UpdateDraft(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// SendDraft issues the customer's pay/confirm link and emails it when possible.
+// POST /api/v1/admin/draft-orders/{id}/send
+func (h *DraftOrderHandler) SendDraft(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.draftUC.SendDraft(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// FinalizeDraft places the order of a draft on the customer's behalf.
+// POST /api/v1/admin/draft-orders/{id}/finalize
+func (h *DraftOrderHandler) FinalizeDraft(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.DraftPaymentReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	order, err := h.draftUC.FinalizeDraft(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// CancelDraft cancels an open draft order; its link stops working.
+// POST /api/v1/admin/draft-orders/{id}/cancel
+func (h *DraftOrderHandler) CancelDraft(w http.ResponseWriter, r *http.Request) {
+	draft, err := h.draftUC.CancelDraft(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// GetLinkedDraft returns the draft order behind a customer's pay/confirm link.
+// GET /api/v1/draft-orders/{token}
+func (h *DraftOrderHandler) GetLinkedDraft(w http.ResponseWriter, r *http.Request) {
+	draft, err := h.draftUC.GetDraftByToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// ConfirmLinkedDraft places the order of a draft for the customer following their link.
+// POST /api/v1/draft-orders/{token}/confirm
+func (h *DraftOrderHandler) ConfirmLinkedDraft(w http.ResponseWriter, r *http.Request) {
+	var req usecase.DraftPaymentReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := h.draftUC.ConfirmDraft(r.Context(), r.PathValue("token"), req)
+	if err != nil {
+		writeDraftOrderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// ExchangeHandler exposes size/colour exchanges of delivered order items to admins.
+type ExchangeHandler struct {
+	exchangeUC *usecase.ExchangeUsecase
+}
+
+func NewExchangeHandler(uc *usecase.ExchangeUsecase) *ExchangeHandler {
+	return &ExchangeHandler{exchangeUC: uc}
+}
+
+// writeExchangeError maps exchange workflow errors to HTTP statuses.
+func writeExchangeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	errMsg := err.Error()
+	status := http.StatusBadRequest
+	switch {
+	case errMsg == "order not found" || errMsg == "exchange not found":
+		status = http.StatusNotFound
+	case strings.HasPrefix(errMsg, "exchange created, but"):
+		status = http.StatusInternalServerError
+	case strings.Contains(errMsg, "courier unavailable"):
+		status = http.StatusBadGateway
+	case strings.Contains(errMsg, "cannot be") || strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "already has"):
+		status = http.StatusConflict
+	case strings.HasPrefix(errMsg, "failed to"):
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": errMsg})
+}
+
+// ListExchanges returns the exchanges of an order.
+// GET /api/v1/admin/orders/{id}/exchanges
+func (h *ExchangeHandler) ListExchanges(w http.ResponseWriter, r *http.Request) {
+	exchanges, err := h.exchangeUC.ListExchanges(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exchanges)
+}
+
+// CreateExchange swaps a delivered order item for another variant of the same product.
+// POST /api/v1/admin/orders/{id}/exchanges
+func (h *ExchangeHandler) CreateExchange(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.CreateExchangeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderItemID == "" || req.NewVariantID == "" {
+		http.Error(w, "orderItemId and newVariantId are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.exchangeUC.CreateExchange(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writeExchangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// BookShipment books the replacement shipment of an exchange.
+// POST /api/v1/admin/exchanges/{id}/shipment
+func (h *ExchangeHandler) BookShipment(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.BookShipmentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Provider == "" {
+		http.Error(w, "provider is required", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := h.exchangeUC.BookExchangeShipment(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writeExchangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// ReceiveExchange marks the original item of an exchange as returned and restocks it.
+// POST /api/v1/admin/exchanges/{id}/receive
+func (h *ExchangeHandler) ReceiveExchange(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	exchange, err := h.exchangeUC.ReceiveExchange(r.Context(), r.PathValue("id"), req.Note, user.ID)
+	if err != nil {
+		writeExchangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exchange)
+}
@@ -4,30 +4,97 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"valancis-backend/internal/domain"
 	"valancis-backend/internal/usecase"
+	"valancis-backend/pkg/utils"
 )
 
 type OrderHandler struct {
 	orderUC         *usecase.OrderUsecase
 	maxCartQuantity int
+	trustedProxies  []netip.Prefix // Proxies whose X-Forwarded-For hops are trusted
 }
 
-func NewOrderHandler(uc *usecase.OrderUsecase, maxCartQuantity int) *OrderHandler {
+func NewOrderHandler(uc *usecase.OrderUsecase, maxCartQuantity int, trustedProxies []netip.Prefix) *OrderHandler {
 	return &OrderHandler{
 		orderUC:         uc,
 		maxCartQuantity: maxCartQuantity,
+		trustedProxies:  trustedProxies,
 	}
 }
 
 // --- Cart Handlers ---
+// Cart routes run behind OptionalAuthMiddleware: signed-in users get their own cart,
+// guests get a cart addressed by a signed token (X-Cart-Token header or cartToken cookie).
+
+const (
+	cartTokenCookie = "cartToken"
+	cartTokenHeader = "X-Cart-Token"
+)
+
+// guestCartID returns the cart ID from a valid guest cart token, or "" when there is none.
+func guestCartID(r *http.Request) string {
+	token := r.Header.Get(cartTokenHeader)
+	if token == "" {
+		if cookie, err := r.Cookie(cartTokenCookie); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return ""
+	}
+	cartID, err := utils.ValidateSignedID(utils.SignedIDGuestCart, token)
+	if err != nil {
+		return ""
+	}
+	return cartID
+}
+
+// GuestCartScope returns the guest cart ID of a request; guest checkout scopes its
+// Idempotency-Key to it.
+func GuestCartScope(r *http.Request) string {
+	return guestCartID(r)
+}
+
+// writeGuestCart responds with a guest cart and (re)issues its token as header and cookie.
+// An unsaved (empty) cart gets no token.
+func writeGuestCart(w http.ResponseWriter, cart *domain.Cart) {
+	if cart.ID == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cart)
+		return
+	}
+	token, err := utils.GenerateSignedID(utils.SignedIDGuestCart, cart.ID)
+	if err != nil {
+		slog.Error("Failed to sign guest cart token", "cart_id", cart.ID, "error", err)
+	} else {
+		http.SetCookie(w, &http.Cookie{
+			Name:     cartTokenCookie,
+			Value:    token,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true, // Required for SameSite=None
+			SameSite: http.SameSiteNoneMode,
+			MaxAge:   30 * 24 * 60 * 60, // 30 days
+		})
+		w.Header().Set(cartTokenHeader, token)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
 
 func (h *OrderHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		cart, err := h.orderUC.GetGuestCart(r.Context(), guestCartID(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeGuestCart(w, cart)
 		return
 	}
 	cart, err := h.orderUC.GetMyCart(r.Context(), user.ID)
@@ -46,17 +113,16 @@ type addToCartReq struct {
 }
 
 func (h *OrderHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
-	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
 	var req addToCartReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
-	slog.Info("Handler: AddToCart Request", "user_id", user.ID, "product_id", req.ProductID, "variant_id", *req.VariantID, "quantity", req.Quantity)
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		user = &domain.User{} // Guest
+	}
+	slog.Info("Handler: AddToCart Request", "user_id", user.ID, "product_id", req.ProductID, "variant_id", req.VariantID, "quantity", req.Quantity)
 
 	// L9: Validate quantity bounds
 	if req.Quantity <= 0 {
@@ -68,7 +134,14 @@ func (h *OrderHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cart, err := h.orderUC.AddToCart(r.Context(), user.ID, req.ProductID, req.VariantID, req.Quantity)
+	var cart *domain.Cart
+	var err error
+	ip := utils.ClientIP(r, h.trustedProxies)
+	if ok {
+		cart, err = h.orderUC.AddToCart(r.Context(), user.ID, req.ProductID, req.VariantID, req.Quantity, ip)
+	} else {
+		cart, err = h.orderUC.AddToGuestCart(r.Context(), guestCartID(r), req.ProductID, req.VariantID, req.Quantity, ip)
+	}
 	if err != nil {
 		slog.Error("AddToCart failed", "user_id", user.ID, "product_id", req.ProductID, "error", err)
 
@@ -76,6 +149,8 @@ func (h *OrderHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "out of stock") || strings.Contains(errMsg, "product unavailable") || strings.Contains(errMsg, "not found") {
 			statusCode = http.StatusBadRequest
+		} else if strings.Contains(errMsg, "cannot place orders") {
+			statusCode = http.StatusForbidden
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -85,16 +160,16 @@ func (h *OrderHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if !ok {
+		writeGuestCart(w, cart)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(cart)
 }
 
 func (h *OrderHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
 	productID := r.PathValue("productId")
 	if productID == "" {
 		http.Error(w, "Product ID required", http.StatusBadRequest)
@@ -106,6 +181,17 @@ func (h *OrderHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !ok {
+		cart, err := h.orderUC.RemoveFromGuestCart(r.Context(), guestCartID(r), productID, variantID)
+		if err != nil {
+			slog.Error("RemoveFromCart failed", "cart", "guest", "product_id", productID, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeGuestCart(w, cart)
+		return
+	}
+
 	cart, err := h.orderUC.RemoveFromCart(r.Context(), user.ID, productID, variantID)
 	if err != nil {
 		slog.Error("RemoveFromCart failed", "user_id", user.ID, "product_id", productID, "error", err)
@@ -117,11 +203,6 @@ func (h *OrderHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
-	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
 
 	var req struct {
 		ProductID string  `json:"productId"`
@@ -132,9 +213,19 @@ func (h *OrderHandler) UpdateCart(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
-	slog.Info("Handler: UpdateCart Request", "user_id", user.ID, "product_id", req.ProductID, "variant_id", *req.VariantID, "quantity", req.Quantity)
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		user = &domain.User{} // Guest
+	}
+	slog.Info("Handler: UpdateCart Request", "user_id", user.ID, "product_id", req.ProductID, "variant_id", req.VariantID, "quantity", req.Quantity)
 
-	cart, err := h.orderUC.UpdateCartItemQuantity(r.Context(), user.ID, req.ProductID, req.VariantID, req.Quantity)
+	var cart *domain.Cart
+	var err error
+	if ok {
+		cart, err = h.orderUC.UpdateCartItemQuantity(r.Context(), user.ID, req.ProductID, req.VariantID, req.Quantity)
+	} else {
+		cart, err = h.orderUC.UpdateGuestCartItemQuantity(r.Context(), guestCartID(r), req.ProductID, req.VariantID, req.Quantity)
+	}
 	if err != nil {
 		slog.Error("UpdateCart failed", "user_id", user.ID, "product_id", req.ProductID, "error", err)
 
@@ -169,10 +260,7 @@ func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// L9: Extract Identity Markers for high CAPI IMQ
-	req.IPAddress = r.RemoteAddr
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		req.IPAddress = strings.Split(forwarded, ",")[0]
-	}
+	req.IPAddress = utils.ClientIP(r, h.trustedProxies)
 	req.UserAgent = r.Header.Get("User-Agent")
 
 	// Extract Facebook Cookies
@@ -189,8 +277,10 @@ func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 		errMsg := err.Error()
 		statusCode := http.StatusInternalServerError
 
-		if strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "out of stock") || strings.Contains(errMsg, "cart is empty") || strings.Contains(errMsg, "not found") {
+		if strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "out of stock") || strings.Contains(errMsg, "cart is empty") || strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "coupon") || strings.Contains(errMsg, "in advance") || strings.Contains(errMsg, "advance deposit") {
 			statusCode = http.StatusBadRequest
+		} else if strings.Contains(errMsg, "cannot place orders") {
+			statusCode = http.StatusForbidden
 		}
 
 		w.WriteHeader(statusCode)
@@ -204,6 +294,65 @@ func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// GuestCheckout places an order from the guest cart (cart token) without a login.
+// POST /api/v1/checkout/guest
+func (h *OrderHandler) GuestCheckout(w http.ResponseWriter, r *http.Request) {
+	var req usecase.GuestCheckoutReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	// L9: Extract Identity Markers for high CAPI IMQ
+	req.IPAddress = utils.ClientIP(r, h.trustedProxies)
+	req.UserAgent = r.Header.Get("User-Agent")
+
+	// Extract Facebook Cookies
+	if fbp, err := r.Cookie("_fbp"); err == nil {
+		req.FBP = fbp.Value
+	}
+	if fbc, err := r.Cookie("_fbc"); err == nil {
+		req.FBC = fbc.Value
+	}
+
+	resp, err := h.orderUC.GuestCheckout(r.Context(), guestCartID(r), req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		errMsg := err.Error()
+		statusCode := http.StatusInternalServerError
+
+		if strings.Contains(errMsg, "insufficient stock") || strings.Contains(errMsg, "out of stock") || strings.Contains(errMsg, "cart is empty") || strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "coupon") || strings.Contains(errMsg, "required") || strings.Contains(errMsg, "in advance") || strings.Contains(errMsg, "advance deposit") || strings.Contains(errMsg, "requires sign-in") {
+			statusCode = http.StatusBadRequest
+		} else if strings.Contains(errMsg, "cannot place orders") {
+			statusCode = http.StatusForbidden
+		} else if strings.Contains(errMsg, "please sign in") {
+			statusCode = http.StatusConflict
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": errMsg,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// TrackOrder looks up an order by the tracking token issued at guest checkout.
+// GET /api/v1/track/{token}
+// (Not under /orders/: it would clash with the /orders/{id}/... patterns.)
+func (h *OrderHandler) TrackOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.orderUC.TrackOrder(r.Context(), r.PathValue("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
 func (h *OrderHandler) GetMyOrders(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
 	if !ok {
@@ -219,15 +368,209 @@ func (h *OrderHandler) GetMyOrders(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(orders)
 }
 
+// GetMyOrder returns one order of the signed-in customer.
+// GET /api/v1/orders/{id}
+func (h *OrderHandler) GetMyOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	order, err := h.orderUC.GetMyOrder(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// GetMyOrderTimeline returns the status timeline of one order of the signed-in customer.
+// GET /api/v1/orders/{id}/timeline
+func (h *OrderHandler) GetMyOrderTimeline(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	timeline, err := h.orderUC.GetMyOrderTimeline(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
+// CancelMyOrder cancels an order of the signed-in customer that is not confirmed yet.
+// POST /api/v1/orders/{id}/cancel
+func (h *OrderHandler) CancelMyOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	// Body is optional
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	order, err := h.orderUC.CancelMyOrder(r.Context(), user.ID, r.PathValue("id"), req.Reason)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		errMsg := err.Error()
+		statusCode := http.StatusInternalServerError
+		if errMsg == "order not found" {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(errMsg, "cannot be cancelled") || strings.Contains(errMsg, "can no longer be cancelled") || strings.Contains(errMsg, "forbidden transition") {
+			statusCode = http.StatusConflict
+		}
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": errMsg,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+type ConfirmPhoneReq struct {
+	Code string `json:"code"`
+}
+
+// ConfirmMyOrderPhone confirms a COD order of the signed-in customer with its SMS code.
+// POST /api/v1/orders/{id}/confirm-phone
+func (h *OrderHandler) ConfirmMyOrderPhone(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req ConfirmPhoneReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orderUC.ConfirmMyOrderPhone(r.Context(), user.ID, r.PathValue("id"), req.Code)
+	if err != nil {
+		writePhoneConfirmationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// ResendMyOrderCode sends a new SMS code for a COD order of the signed-in customer.
+// POST /api/v1/orders/{id}/confirm-phone/resend
+func (h *OrderHandler) ResendMyOrderCode(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.orderUC.ResendMyOrderCode(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		writePhoneConfirmationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Confirmation code sent"})
+}
+
+// ConfirmTrackedOrderPhone confirms a guest COD order with its SMS code.
+// POST /api/v1/track/{token}/confirm-phone
+func (h *OrderHandler) ConfirmTrackedOrderPhone(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmPhoneReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orderUC.ConfirmTrackedOrderPhone(r.Context(), r.PathValue("token"), req.Code)
+	if err != nil {
+		writePhoneConfirmationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// ResendTrackedOrderCode sends a new SMS code for a guest COD order.
+// POST /api/v1/track/{token}/confirm-phone/resend
+func (h *OrderHandler) ResendTrackedOrderCode(w http.ResponseWriter, r *http.Request) {
+	if err := h.orderUC.ResendTrackedOrderCode(r.Context(), r.PathValue("token")); err != nil {
+		writePhoneConfirmationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Confirmation code sent"})
+}
+
+func writePhoneConfirmationError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errMsg == "order not found":
+		statusCode = http.StatusNotFound
+	case strings.HasPrefix(errMsg, "failed to"):
+		statusCode = http.StatusInternalServerError
+	case strings.Contains(errMsg, "please wait") || strings.Contains(errMsg, "too many"):
+		statusCode = http.StatusTooManyRequests
+	case strings.Contains(errMsg, "already confirmed") || strings.Contains(errMsg, "does not need"):
+		statusCode = http.StatusConflict
+	case strings.Contains(errMsg, "confirmation code") || strings.Contains(errMsg, "phone number"):
+		statusCode = http.StatusBadRequest
+	case strings.Contains(errMsg, "not enabled"):
+		statusCode = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": errMsg,
+	})
+}
+
 type ApplyCouponReq struct {
 	CouponCode string `json:"couponCode"`
 }
 
+// ApplyCoupon previews a coupon against the current cart.
+// The discount is only committed at checkout, where the coupon is re-validated.
 func (h *OrderHandler) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
-	// Coupon system is deactivated
-	w.WriteHeader(http.StatusGone)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Coupon system is currently deactivated",
-		"valid":   "false",
-	})
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ApplyCouponReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.CouponCode) == "" {
+		http.Error(w, "couponCode is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.orderUC.ApplyCoupon(r.Context(), user.ID, req.CouponCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
 }
@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// PaymentHandler exposes online gateway payments: session start for customers and
+// the public callback/IPN endpoints the gateways call.
+type PaymentHandler struct {
+	paymentUC *usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(uc *usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{paymentUC: uc}
+}
+
+type startPaymentReq struct {
+	Provider string `json:"provider"`
+}
+
+// StartPayment opens a hosted payment session for an order.
+// POST /api/v1/orders/{id}/payments
+func (h *PaymentHandler) StartPayment(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req startPaymentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Provider == "" {
+		http.Error(w, "provider is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.paymentUC.StartPayment(r.Context(), user.ID, r.PathValue("id"), req.Provider)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusBadRequest
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "gateway unavailable") {
+			status = http.StatusBadGateway
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// ListPayments returns the payment attempts for an order.
+// GET /api/v1/orders/{id}/payments
+func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.paymentUC.ListPayments(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// IPN receives server-to-server payment notifications.
+// POST /api/v1/payments/{provider}/ipn
+func (h *PaymentHandler) IPN(w http.ResponseWriter, r *http.Request) {
+	fields, err := callbackFields(r)
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.paymentUC.HandleCallback(r.Context(), r.PathValue("provider"), fields); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "ok"})
+}
+
+// Callback handles the shopper's browser returning from the gateway and
+// redirects to the storefront's payment result page.
+// GET|POST /api/v1/payments/{provider}/callback
+func (h *PaymentHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	fields, err := callbackFields(r)
+	if err != nil {
+		http.Redirect(w, r, h.paymentUC.ResultURL(nil), http.StatusSeeOther)
+		return
+	}
+
+	session, err := h.paymentUC.HandleCallback(r.Context(), r.PathValue("provider"), fields)
+	if err != nil {
+		http.Redirect(w, r, h.paymentUC.ResultURL(nil), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, h.paymentUC.ResultURL(session), http.StatusSeeOther)
+}
+
+// callbackFields flattens query string and form body into a single-valued map.
+func callbackFields(r *http.Request) (map[string]string, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string, len(r.Form))
+	for k, v := range r.Form {
+		if len(v) > 0 {
+			fields[k] = v[0]
+		}
+	}
+	return fields, nil
+}
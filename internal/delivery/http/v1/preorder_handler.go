@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// PreorderHandler exposes admin pre-order releases and balance requests, and the
+// customer's balance pay link.
+type PreorderHandler struct {
+	preorderUC *usecase.PreorderUsecase
+	paymentUC  *usecase.PaymentUsecase
+}
+
+func NewPreorderHandler(preorderUC *usecase.PreorderUsecase, paymentUC *usecase.PaymentUsecase) *PreorderHandler {
+	return &PreorderHandler{preorderUC: preorderUC, paymentUC: paymentUC}
+}
+
+// writePreorderError maps pre-order errors to HTTP statuses.
+func writePreorderError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	errMsg := err.Error()
+	status := http.StatusBadRequest
+	switch {
+	case strings.HasSuffix(errMsg, "not found"):
+		status = http.StatusNotFound
+	case strings.Contains(errMsg, "cannot be") || strings.Contains(errMsg, "no open pre-orders") || strings.Contains(errMsg, "no outstanding balance"):
+		status = http.StatusConflict
+	case strings.Contains(errMsg, "gateway unavailable"):
+		status = http.StatusBadGateway
+	case strings.HasPrefix(errMsg, "failed to"):
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": errMsg})
+}
+
+// ReleasePreorder releases the arrived batch of a pre-order product and asks its
+// customers for their balances.
+// POST /api/v1/admin/products/{id}/preorder-releases
+func (h *PreorderHandler) ReleasePreorder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.ReleasePreorderReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := h.preorderUC.ReleasePreorder(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ListReleases returns the releases of a pre-order product, newest first.
+// GET /api/v1/admin/products/{id}/preorder-releases
+func (h *PreorderHandler) ListReleases(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.preorderUC.ListReleases(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(releases)
+}
+
+// GetRelease returns a release with its balance requests.
+// GET /api/v1/admin/preorder-releases/{id}
+func (h *PreorderHandler) GetRelease(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.preorderUC.GetRelease(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RecordBalancePayment records balance money collected outside the gateways.
+// POST /api/v1/admin/preorder-balances/{id}/payments
+func (h *PreorderHandler) RecordBalancePayment(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.RecordBalancePaymentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	balanceReq, err := h.preorderUC.RecordBalancePayment(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balanceReq)
+}
+
+// CancelBalanceRequest withdraws a pending balance request.
+// POST /api/v1/admin/preorder-balances/{id}/cancel
+func (h *PreorderHandler) CancelBalanceRequest(w http.ResponseWriter, r *http.Request) {
+	balanceReq, err := h.preorderUC.CancelBalanceRequest(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balanceReq)
+}
+
+// GetLinkedBalance returns the balance request behind a customer's pay link.
+// GET /api/v1/preorder-balances/{token}
+func (h *PreorderHandler) GetLinkedBalance(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.preorderUC.GetBalanceByToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PayLinkedBalance opens a gateway session for the balance behind a pay link.
+// POST /api/v1/preorder-balances/{token}/payments
+func (h *PreorderHandler) PayLinkedBalance(w http.ResponseWriter, r *http.Request) {
+	var req startPaymentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Provider == "" {
+		http.Error(w, "provider is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.paymentUC.StartBalancePayment(r.Context(), r.PathValue("token"), req.Provider)
+	if err != nil {
+		writePreorderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
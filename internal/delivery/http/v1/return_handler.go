@@ -0,0 +1,273 @@
+package v1
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+	"valancis-backend/pkg/utils"
+)
+
+// ReturnHandler exposes customer return requests (RMA) and their admin review.
+type ReturnHandler struct {
+	returnUC      *usecase.ReturnUsecase
+	maxUploadSize int64
+}
+
+func NewReturnHandler(uc *usecase.ReturnUsecase, maxUploadSizeMB int64) *ReturnHandler {
+	return &ReturnHandler{
+		returnUC:      uc,
+		maxUploadSize: maxUploadSizeMB << 20, // Convert MB to bytes
+	}
+}
+
+// writeReturnError maps return workflow errors to HTTP statuses.
+func writeReturnError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	errMsg := err.Error()
+	status := http.StatusBadRequest
+	switch {
+	case errMsg == "order not found" || errMsg == "return not found":
+		status = http.StatusNotFound
+	case strings.HasPrefix(errMsg, "items received, but the refund failed"):
+		status = http.StatusInternalServerError
+	case strings.Contains(errMsg, "cannot be"):
+		status = http.StatusConflict
+	case strings.HasPrefix(errMsg, "failed to"):
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": errMsg})
+}
+
+// --- Customer ---
+
+// UploadPhoto uploads a photo to attach to a return request.
+// POST /api/v1/returns/photos
+func (h *ReturnHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "File too large or invalid format")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	if !allowedMimeTypes[header.Header.Get("Content-Type")] || !allowedExtensions[strings.ToLower(filepath.Ext(header.Filename))] {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid file type. Allowed: JPEG, PNG, WebP, GIF")
+		return
+	}
+
+	url, err := h.returnUC.UploadPhoto(r.Context(), file, header.Filename)
+	if err != nil {
+		slog.Error("Returns: photo upload failed", "error", err)
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to upload file")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"url": url})
+}
+
+// RequestReturn opens a return request for items of a delivered order.
+// POST /api/v1/orders/{id}/returns
+func (h *ReturnHandler) RequestReturn(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.RequestReturnReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.returnUC.RequestReturn(r.Context(), user.ID, r.PathValue("id"), req)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ListMyReturns returns the return requests of one of the user's orders.
+// GET /api/v1/orders/{id}/returns
+func (h *ReturnHandler) ListMyReturns(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	returns, err := h.returnUC.ListMyReturns(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+// --- Admin ---
+
+// ListReturns lists return requests, newest first, optionally filtered by status.
+// GET /api/v1/admin/returns
+func (h *ReturnHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	returns, total, err := h.returnUC.ListReturns(r.Context(), r.URL.Query().Get("status"), page, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"returns": returns,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GetReturn returns one return request.
+// GET /api/v1/admin/returns/{id}
+func (h *ReturnHandler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	ret, err := h.returnUC.GetReturn(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ListOrderReturns returns the return requests of an order.
+// GET /api/v1/admin/orders/{id}/returns
+func (h *ReturnHandler) ListOrderReturns(w http.ResponseWriter, r *http.Request) {
+	returns, err := h.returnUC.ListOrderReturns(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+type ReviewReturnReq struct {
+	Items []usecase.ReturnItemReq `json:"items"` // Approved quantities; empty approves everything requested
+	Note  string                  `json:"note"`
+}
+
+// ApproveReturn approves a return request, optionally per item.
+// POST /api/v1/admin/returns/{id}/approve
+func (h *ReturnHandler) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req ReviewReturnReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := h.returnUC.ApproveReturn(r.Context(), r.PathValue("id"), req.Items, req.Note, user.ID)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// RejectReturn rejects a return request.
+// POST /api/v1/admin/returns/{id}/reject
+func (h *ReturnHandler) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req ReviewReturnReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := h.returnUC.RejectReturn(r.Context(), r.PathValue("id"), req.Note, user.ID)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ReceiveReturn marks returned items as received, restocks them and optionally refunds them.
+// POST /api/v1/admin/returns/{id}/receive
+func (h *ReturnHandler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.ReceiveReturnReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := h.returnUC.ReceiveReturn(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// RefundReturn refunds the received items of a return.
+// POST /api/v1/admin/returns/{id}/refund
+func (h *ReturnHandler) RefundReturn(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req usecase.RefundReturnReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ret, err := h.returnUC.RefundReturn(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+	"valancis-backend/pkg/utils"
+)
+
+// SettlementHandler exposes the courier COD settlement import to admins.
+type SettlementHandler struct {
+	settlementUC  *usecase.SettlementUsecase
+	maxUploadSize int64
+}
+
+func NewSettlementHandler(uc *usecase.SettlementUsecase, maxUploadSizeMB int64) *SettlementHandler {
+	return &SettlementHandler{
+		settlementUC:  uc,
+		maxUploadSize: maxUploadSizeMB << 20, // Convert MB to bytes
+	}
+}
+
+// ListMappings returns the registered statement column mappings.
+// GET /api/v1/admin/cod-settlements/mappings
+func (h *SettlementHandler) ListMappings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]domain.SettlementColumnMapping{"mappings": h.settlementUC.Mappings()})
+}
+
+// ImportStatement reconciles an uploaded courier statement (multipart "file", CSV or
+// XLSX) and settles the matching orders. Optional form fields: "mapping" (registered
+// layout name), "customMapping" (JSON column mapping) and "dryRun".
+// POST /api/v1/admin/cod-settlements
+func (h *SettlementHandler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "File too large or invalid format")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Statement file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid file")
+		return
+	}
+
+	req := usecase.ImportSettlementReq{
+		FileName: header.Filename,
+		Data:     data,
+		Mapping:  r.FormValue("mapping"),
+	}
+	if raw := r.FormValue("customMapping"); raw != "" {
+		var custom domain.SettlementColumnMapping
+		if err := json.Unmarshal([]byte(raw), &custom); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid customMapping")
+			return
+		}
+		req.CustomMapping = &custom
+	}
+	if raw := r.FormValue("dryRun"); raw != "" {
+		req.DryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid dryRun")
+			return
+		}
+	}
+
+	report, err := h.settlementUC.ImportStatement(r.Context(), req, user.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, report)
+}
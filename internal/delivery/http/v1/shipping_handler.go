@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/usecase"
+)
+
+// ShippingHandler exposes courier bookings to admins and the public webhook
+// endpoints the couriers call.
+type ShippingHandler struct {
+	shippingUC *usecase.ShippingUsecase
+}
+
+func NewShippingHandler(uc *usecase.ShippingUsecase) *ShippingHandler {
+	return &ShippingHandler{shippingUC: uc}
+}
+
+// ListCouriers returns the configured courier providers.
+// GET /api/v1/admin/couriers
+func (h *ShippingHandler) ListCouriers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"couriers": h.shippingUC.Couriers()})
+}
+
+// BookShipment books a shipment for some or all of an order's remaining items.
+// POST /api/v1/admin/orders/{id}/shipments
+func (h *ShippingHandler) BookShipment(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req usecase.BookShipmentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Provider == "" {
+		http.Error(w, "provider is required", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := h.shippingUC.BookShipment(r.Context(), r.PathValue("id"), req, user.ID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusBadRequest
+		if err.Error() == "order not found" {
+			status = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "already shipped") || strings.Contains(err.Error(), "cannot be shipped") {
+			status = http.StatusConflict
+		} else if strings.Contains(err.Error(), "courier unavailable") {
+			status = http.StatusBadGateway
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// ListShipments returns the consignments booked for an order.
+// GET /api/v1/admin/orders/{id}/shipments
+func (h *ShippingHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	shipments, err := h.shippingUC.ListShipments(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shipments)
+}
+
+type UpdateShipmentStatusReq struct {
+	Status string `json:"status"`
+}
+
+// UpdateShipmentStatus moves a shipment along (manual shipments, missed courier webhooks).
+// PATCH /api/v1/admin/shipments/{id}/status
+func (h *ShippingHandler) UpdateShipmentStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(domain.UserContextKey).(*domain.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateShipmentStatusReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Status == "" {
+		http.Error(w, "status is required", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := h.shippingUC.UpdateShipmentStatus(r.Context(), r.PathValue("id"), req.Status, user.ID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusInternalServerError
+		if err.Error() == "shipment not found" {
+			status = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "cannot move shipment") {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// Webhook receives courier status notifications.
+// POST /api/v1/couriers/{provider}/webhook
+func (h *ShippingHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	hook := domain.CourierWebhook{
+		Headers: make(map[string]string, len(r.Header)),
+		Query:   make(map[string]string),
+		Body:    body,
+	}
+	for k, v := range r.Header {
+		if len(v) > 0 {
+			hook.Headers[k] = v[0]
+		}
+	}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			hook.Query[k] = v[0]
+		}
+	}
+
+	if err := h.shippingUC.HandleWebhook(r.Context(), r.PathValue("provider"), hook); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusInternalServerError
+		if err.Error() == "invalid courier webhook" || strings.Contains(err.Error(), "not available") {
+			status = http.StatusBadRequest
+		} else if err.Error() == "shipment not found" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Pathao checks for 202 Accepted when the webhook is registered
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"message": "ok"})
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Blocklist entry kinds
+const (
+	BlocklistKindPhone = "phone" // Normalized mobile number (01XXXXXXXXX)
+	BlocklistKindEmail = "email" // Lower-cased address
+	BlocklistKindUser  = "user"  // User ID
+	BlocklistKindIP    = "ip"    // CIDR range; a single address is stored as /32 or /128
+)
+
+// What a blocklist match does to a customer
+const (
+	BlocklistActionBlock  = "block"  // No cart additions and no orders
+	BlocklistActionPrepay = "prepay" // Orders only with a full advance payment (no COD)
+)
+
+// BlocklistEntry stops a known fraudster from ordering again, or forces them to pay
+// upfront, until it expires.
+type BlocklistEntry struct {
+	ID            string     `json:"id"`
+	Kind          string     `json:"kind"` // BlocklistKind*
+	Value         string     `json:"value"`
+	Action        string     `json:"action"` // BlocklistAction*
+	Reason        string     `json:"reason"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`     // Nil: never expires
+	SourceOrderID *string    `json:"sourceOrderId,omitempty"` // Fake order the entry was added from
+	CreatedBy     *string    `json:"createdBy,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type BlocklistFilter struct {
+	Page   int
+	Limit  int
+	Kind   string
+	Search string // Matches value or reason
+	Active *bool  // True: unexpired entries only; false: expired only
+}
+
+// BlocklistSubject identifies a customer to check against the blocklist. Values are
+// normalized like entry values; empty fields are not checked.
+type BlocklistSubject struct {
+	UserID string
+	Phone  string
+	Email  string
+	IP     string
+}
+
+type BlocklistRepository interface {
+	// Upsert adds an entry, or renews the one with the same kind and value.
+	Upsert(ctx context.Context, entry *BlocklistEntry) error
+	GetByID(ctx context.Context, id string) (*BlocklistEntry, error)
+	// Update changes an entry's action, reason and expiry.
+	Update(ctx context.Context, entry *BlocklistEntry) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter BlocklistFilter) ([]BlocklistEntry, int64, error)
+	// Match returns the unexpired entries matching the subject, BlocklistActionBlock first.
+	Match(ctx context.Context, subject BlocklistSubject) ([]BlocklistEntry, error)
+}
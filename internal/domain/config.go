@@ -9,7 +9,7 @@ type ShippingZone struct {
 	ID        int32     `json:"id"`
 	Key       string    `json:"key"`
 	Label     string    `json:"label"`
-	Cost      float64   `json:"cost"`
+	Cost      Money     `json:"cost"`
 	IsActive  bool      `json:"isActive"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -22,6 +22,6 @@ type ConfigRepository interface {
 	GetShippingZoneByKey(ctx context.Context, key string) (*ShippingZone, error)
 	CreateShippingZone(ctx context.Context, zone *ShippingZone) (*ShippingZone, error)
 	UpdateShippingZone(ctx context.Context, zone *ShippingZone) (*ShippingZone, error)
-	UpdateShippingZoneCost(ctx context.Context, key string, cost float64) error
+	UpdateShippingZoneCost(ctx context.Context, key string, cost Money) error
 	DeleteShippingZone(ctx context.Context, id int32) error
 }
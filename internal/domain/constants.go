@@ -26,6 +26,10 @@ package domain
 // │    pending → pending_verification → processing → shipped → delivered   │
 // │    → paid                                                              │
 // │                                                                        │
+// │  Split shipment (items leave in several parcels):                      │
+// │    processing → partially_shipped → shipped → delivered                │
+// │    Derived from the order's shipments (OrderFulfillmentStatus)         │
+// │                                                                        │
 // ├─────────────────────────────────────────────────────────────────────────┤
 // │                      EXCEPTIONAL FLOWS                                 │
 // ├─────────────────────────────────────────────────────────────────────────┤
@@ -78,6 +82,11 @@ const (
 	// Next: delivered, returned (failed delivery), cancelled (refused at door).
 	OrderStatusShipped = "shipped"
 
+	// OrderStatusPartiallyShipped — Some items handed to courier, the rest still to ship.
+	// Entry: Derived from the order's shipments when only part of the items left.
+	// Next: shipped (remaining items handed over), cancelled, fake.
+	OrderStatusPartiallyShipped = "partially_shipped"
+
 	// OrderStatusDelivered — Customer received the package.
 	// Entry: Courier confirms delivery.
 	// Next: paid (COD collected / final confirmation), returned, refunded.
@@ -130,14 +139,21 @@ var ValidTransitions = map[string][]string{
 		OrderStatusFake,                // Admin marks as fraud
 	},
 	OrderStatusPendingVerification: {
-		OrderStatusProcessing, // Payment verified → start preparing
-		OrderStatusShipped,    // Pre-order direct ship (skip processing)
-		OrderStatusCancelled,  // Cancel before processing
-		OrderStatusFake,       // Fraud detected
+		OrderStatusProcessing,       // Payment verified → start preparing
+		OrderStatusShipped,          // Pre-order direct ship (skip processing)
+		OrderStatusPartiallyShipped, // Pre-order direct ship, in parts
+		OrderStatusCancelled,        // Cancel before processing
+		OrderStatusFake,             // Fraud detected
 	},
 	OrderStatusProcessing: {
-		OrderStatusShipped,   // Hand to courier
-		OrderStatusCancelled, // Cancel during preparation
+		OrderStatusShipped,          // Hand to courier
+		OrderStatusPartiallyShipped, // Hand part of the items to courier
+		OrderStatusCancelled,        // Cancel during preparation
+		OrderStatusFake,             // Fraud detected late
+	},
+	OrderStatusPartiallyShipped: {
+		OrderStatusShipped,   // Remaining items handed to courier
+		OrderStatusCancelled, // Remaining items cancelled / shipments recalled
 		OrderStatusFake,      // Fraud detected late
 	},
 	OrderStatusShipped: {
@@ -189,7 +205,8 @@ const (
 	// PaymentStatusFailed — Payment attempt failed (gateway error, invalid trx).
 	PaymentStatusFailed = "failed"
 
-	// PaymentStatusPartialPaid — Pre-order deposit collected, remainder outstanding.
+	// PaymentStatusPartialPaid — Pre-order deposit collected, or an exchange added a
+	// balance due; remainder outstanding.
 	PaymentStatusPartialPaid = "partial_paid"
 
 	// PaymentStatusPartialRefund — Part of the payment refunded. Remainder kept.
@@ -219,6 +236,7 @@ var ValidPaymentTransitions = map[string][]string{
 	PaymentStatusPaid: {
 		PaymentStatusPartialRefund, // Partial refund issued
 		PaymentStatusRefunded,      // Full refund issued
+		PaymentStatusPartialPaid,   // An exchange added a balance due
 	},
 	PaymentStatusPartialPaid: {
 		PaymentStatusPaid,          // Remaining balance collected
@@ -243,6 +261,29 @@ const (
 	PaymentMethodSSLCommerz = "sslcommerz" // SSLCommerz payment gateway
 )
 
+// IsGatewayPaymentMethod returns true for methods paid online through a hosted
+// gateway session. Their checkout only reserves stock until payment is confirmed.
+func IsGatewayPaymentMethod(method string) bool {
+	return method == PaymentMethodBKash || method == PaymentMethodSSLCommerz
+}
+
+// Refund methods: how a refund was paid out to the customer
+const (
+	RefundMethodCash        = "cash"
+	RefundMethodBKash       = "bkash"
+	RefundMethodStoreCredit = "store_credit"
+)
+
+// IsValidRefundMethod returns true for a known refund method.
+func IsValidRefundMethod(method string) bool {
+	for _, m := range RefundMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
 // ═══════════════════════════════════════════════════════════════════════════════
 // SECTION 5: SIDE-EFFECTS MAP
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -258,6 +299,8 @@ const (
 	SideEffectDeductStock       SideEffect = "deduct_stock"        // Re-reserve items from inventory
 	SideEffectSyncPaymentPaid   SideEffect = "sync_payment_paid"   // Set PaymentStatus → paid
 	SideEffectSyncPaymentRefund SideEffect = "sync_payment_refund" // Set PaymentStatus → refunded
+	SideEffectCommitStock       SideEffect = "commit_stock"        // Turn active stock reservations into a deduction
+	SideEffectNotifyCustomer    SideEffect = "notify_customer"     // Queue the status email (see OrderStatusEmails)
 )
 
 // StatusSideEffects maps each order status to the automated actions
@@ -266,10 +309,15 @@ var StatusSideEffects = map[string][]SideEffect{
 	OrderStatusCancelled: {SideEffectRestoreStock},
 	OrderStatusFake:      {SideEffectRestoreStock},
 	OrderStatusReturned:  {SideEffectRestoreStock},
-	OrderStatusRefunded:  {SideEffectSyncPaymentRefund},
-	OrderStatusPaid:      {SideEffectSyncPaymentPaid},
-	// Recovery: when admin moves cancelled/fake → processing, stock must be re-deducted
-	OrderStatusProcessing: {SideEffectDeductStock},
+	OrderStatusRefunded:  {SideEffectSyncPaymentRefund, SideEffectNotifyCustomer},
+	OrderStatusPaid:      {SideEffectSyncPaymentPaid, SideEffectCommitStock},
+	OrderStatusShipped:   {SideEffectCommitStock, SideEffectNotifyCustomer},
+	// Stock leaves in parts, but it was committed as a whole when the order was confirmed
+	OrderStatusPartiallyShipped: {SideEffectCommitStock, SideEffectNotifyCustomer},
+	OrderStatusDelivered:        {SideEffectCommitStock, SideEffectNotifyCustomer},
+	// Recovery: when admin moves cancelled/fake → processing, stock must be re-deducted.
+	// Otherwise confirming an order commits any stock still only reserved for it.
+	OrderStatusProcessing: {SideEffectDeductStock, SideEffectNotifyCustomer},
 }
 
 // ═══════════════════════════════════════════════════════════════════════════════
@@ -387,6 +435,22 @@ func IsValidPaymentTransition(current, next string) bool {
 	return false
 }
 
+// IsCustomerCancellable returns true if a customer may cancel their own order from this
+// status: only before it is confirmed (pending / pending_verification), and only where
+// ValidTransitions allows the move to cancelled. Later cancellations are admin-only.
+func IsCustomerCancellable(status string) bool {
+	return (status == OrderStatusPending || status == OrderStatusPendingVerification) &&
+		IsValidTransition(status, OrderStatusCancelled)
+}
+
+// IsEditableOrderStatus returns true if an admin may still change the items of an order
+// in this status: anything before dispatch.
+func IsEditableOrderStatus(status string) bool {
+	return status == OrderStatusPending ||
+		status == OrderStatusPendingVerification ||
+		status == OrderStatusProcessing
+}
+
 // IsTerminalOrderStatus returns true if the status has no forward transitions.
 func IsTerminalOrderStatus(status string) bool {
 	_, exists := ValidTransitions[status]
@@ -411,26 +475,30 @@ func IsRecoveryTransition(from, to string) bool {
 // GetSideEffects returns the list of side-effects for a given transition.
 // Takes both from and to status to handle conditional side-effects (e.g., recovery).
 func GetSideEffects(from, to string) []SideEffect {
-	// Recovery transitions: stock was already restored when cancelled/faked,
-	// so moving back to processing means we need to re-deduct.
-	if IsRecoveryTransition(from, to) {
-		return []SideEffect{SideEffectDeductStock}
+	effects, exists := StatusSideEffects[to]
+	if !exists {
+		return nil
 	}
 
-	// Normal transitions: check the target status
-	if effects, exists := StatusSideEffects[to]; exists {
-		// For processing in non-recovery context, no side-effects
-		if to == OrderStatusProcessing {
-			return nil
+	// Recovery transitions: stock was already restored when cancelled/faked,
+	// so moving back to processing means we need to re-deduct (the map entry).
+	// For processing in non-recovery context, only reserved stock is committed.
+	if to == OrderStatusProcessing && !IsRecoveryTransition(from, to) {
+		resolved := make([]SideEffect, len(effects))
+		for i, effect := range effects {
+			if effect == SideEffectDeductStock {
+				effect = SideEffectCommitStock
+			}
+			resolved[i] = effect
 		}
-		return effects
+		return resolved
 	}
-	return nil
+	return effects
 }
 
 // DetermineInitialStatus returns the correct starting order status and payment status
 // based on the order type and whether payment info was provided.
-func DetermineInitialStatus(paymentMethod string, isPreorder bool, depositRequired float64, hasTrxID bool) (orderStatus, paymentStatus string) {
+func DetermineInitialStatus(paymentMethod string, isPreorder bool, depositRequired Money, hasTrxID bool) (orderStatus, paymentStatus string) {
 	switch {
 	case isPreorder && depositRequired > 0:
 		// Pre-order with deposit: starts at pending_verification
@@ -440,6 +508,10 @@ func DetermineInitialStatus(paymentMethod string, isPreorder bool, depositRequir
 		// Pre-order with no deposit: starts at pending
 		return OrderStatusPending, PaymentStatusPending
 
+	case paymentMethod == PaymentMethodCOD && depositRequired > 0:
+		// High-risk COD with an advance deposit: starts at pending_verification
+		return OrderStatusPendingVerification, PaymentStatusPendingVerif
+
 	case paymentMethod == PaymentMethodCOD:
 		// COD: starts at pending, no payment yet
 		return OrderStatusPending, PaymentStatusPending
@@ -462,6 +534,7 @@ var OrderStatuses = []string{
 	OrderStatusPending,
 	OrderStatusPendingVerification,
 	OrderStatusProcessing,
+	OrderStatusPartiallyShipped,
 	OrderStatusShipped,
 	OrderStatusDelivered,
 	OrderStatusPaid,
@@ -488,3 +561,9 @@ var PaymentMethods = []string{
 	PaymentMethodNagad,
 	PaymentMethodSSLCommerz,
 }
+
+var RefundMethods = []string{
+	RefundMethodCash,
+	RefundMethodBKash,
+	RefundMethodStoreCredit,
+}
@@ -8,36 +8,95 @@ import (
 )
 
 type Coupon struct {
-	ID         uuid.UUID  `json:"id"`
-	Code       string     `json:"code"`
-	Type       string     `json:"type"` // percentage, fixed
-	Value      float64    `json:"value"`
-	MinSpend   float64    `json:"minSpend"`
-	UsageLimit int        `json:"usageLimit"`
-	UsedCount  int        `json:"usedCount"`
-	StartAt    *time.Time `json:"startAt"`
-	ExpiresAt  *time.Time `json:"expiresAt"`
-	IsActive   bool       `json:"isActive"`
-	CreatedAt  time.Time  `json:"createdAt"`
+	ID             uuid.UUID  `json:"id"`
+	Code           string     `json:"code"`
+	Type           string     `json:"type"` // percentage, fixed
+	Value          Money      `json:"value"`
+	MinSpend       Money      `json:"minSpend"`
+	MaxDiscount    *Money     `json:"maxDiscount,omitempty"` // Cap for percentage coupons
+	UsageLimit     int        `json:"usageLimit"`
+	PerUserLimit   int        `json:"perUserLimit"` // 0 = unlimited
+	UsedCount      int        `json:"usedCount"`
+	FirstOrderOnly bool       `json:"firstOrderOnly"`
+	StartAt        *time.Time `json:"startAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	IsActive       bool       `json:"isActive"`
+	CreatedAt      time.Time  `json:"createdAt"`
+
+	// Scoping: when all three are empty the coupon applies to the whole cart
+	ProductIDs    []string `json:"productIds"`
+	CategoryIDs   []string `json:"categoryIds"`
+	CollectionIDs []string `json:"collectionIds"`
 }
 
+// Coupon validation statuses returned by CouponRepository.ValidateCoupon.
+// Anything other than CouponStatusValid is a reason the shopper can act on.
+const (
+	CouponStatusValid               = "valid"
+	CouponStatusInactive            = "inactive"
+	CouponStatusNotStarted          = "not_started"
+	CouponStatusExpired             = "expired"
+	CouponStatusFullyRedeemed       = "fully_redeemed"
+	CouponStatusPerUserLimitReached = "per_user_limit_reached"
+	CouponStatusFirstOrderOnly      = "first_order_only"
+	CouponStatusNotApplicable       = "not_applicable_to_cart"
+	CouponStatusMinSpendNotMet      = "min_spend_not_met"
+)
+
 type CouponValidationResult struct {
 	ID               uuid.UUID `json:"id"`
 	Code             string    `json:"code"`
 	Type             string    `json:"type"`
-	Value            float64   `json:"value"`
-	MinSpend         float64   `json:"minSpend"`
+	Value            Money     `json:"value"`
+	MinSpend         Money     `json:"minSpend"`
+	MaxDiscount      *Money    `json:"maxDiscount,omitempty"`
 	ValidationStatus string    `json:"validationStatus"` // valid, inactive, expired, etc.
+
+	// Scoped coupons only discount EligibleProductIDs; unscoped coupons discount every item
+	IsScoped           bool     `json:"isScoped"`
+	EligibleProductIDs []string `json:"eligibleProductIds,omitempty"`
+}
+
+// AppliesTo reports whether the coupon discounts the given product.
+func (r *CouponValidationResult) AppliesTo(productID string) bool {
+	if !r.IsScoped {
+		return true
+	}
+	for _, id := range r.EligibleProductIDs {
+		if id == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// CouponRedemption records a coupon applied to a placed order.
+// One row per order; the discount is kept so reporting and refunds can work net of it.
+type CouponRedemption struct {
+	ID             string    `json:"id"`
+	CouponID       uuid.UUID `json:"couponId"`
+	OrderID        string    `json:"orderId"`
+	UserID         string    `json:"userId"`
+	Code           string    `json:"code"`
+	DiscountAmount Money     `json:"discountAmount"`
+	CreatedAt      time.Time `json:"createdAt"`
 }
 
 type CouponRepository interface {
 	CreateCoupon(ctx context.Context, coupon *Coupon) error
 	GetCouponByCode(ctx context.Context, code string) (*Coupon, error)
 	GetCouponByID(ctx context.Context, id uuid.UUID) (*Coupon, error)
-	ValidateCoupon(ctx context.Context, code string, cartTotal float64) (*CouponValidationResult, error)
+	// ValidateCoupon checks code for userID against a cart subtotal and the cart's product IDs.
+	ValidateCoupon(ctx context.Context, code, userID string, cartTotal Money, productIDs []string) (*CouponValidationResult, error)
 	ListCoupons(ctx context.Context, limit, offset int) ([]Coupon, error)
 	CountCoupons(ctx context.Context) (int64, error)
 	UpdateCoupon(ctx context.Context, coupon *Coupon) error
 	IncrementCouponUsage(ctx context.Context, id uuid.UUID) error
 	DeleteCoupon(ctx context.Context, id uuid.UUID) error
+	// SetCouponScopes replaces the product/category/collection restrictions of a coupon.
+	SetCouponScopes(ctx context.Context, id uuid.UUID, productIDs, categoryIDs, collectionIDs []string) error
+
+	// Redemptions
+	CreateRedemption(ctx context.Context, redemption *CouponRedemption) error
+	GetRedemptionByOrderID(ctx context.Context, orderID string) (*CouponRedemption, error)
 }
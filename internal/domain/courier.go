@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Couriers with an adapter in internal/infrastructure/courier
+const (
+	CourierPathao    = "pathao"
+	CourierSteadfast = "steadfast"
+	CourierRedX      = "redx"
+)
+
+// Shipment statuses, normalized from each courier's own vocabulary
+const (
+	ShipmentStatusBooked    = "booked"     // Consignment created, waiting for pickup
+	ShipmentStatusPickedUp  = "picked_up"  // Courier has the parcel
+	ShipmentStatusInTransit = "in_transit" // Sorting / out for delivery
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusReturned  = "returned"  // Parcel came back to the merchant
+	ShipmentStatusCancelled = "cancelled" // Consignment cancelled by the courier or merchant
+)
+
+// shipmentStatusRank orders shipment statuses so late or out-of-order webhooks
+// never move a shipment backwards.
+var shipmentStatusRank = map[string]int{
+	ShipmentStatusBooked:    0,
+	ShipmentStatusPickedUp:  1,
+	ShipmentStatusInTransit: 2,
+	ShipmentStatusDelivered: 3,
+	ShipmentStatusReturned:  4,
+	ShipmentStatusCancelled: 4,
+}
+
+// IsShipmentProgress returns true if a shipment may move from `current` to `next`.
+func IsShipmentProgress(current, next string) bool {
+	from, ok := shipmentStatusRank[current]
+	if !ok {
+		return false
+	}
+	to, ok := shipmentStatusRank[next]
+	return ok && to > from
+}
+
+// hasLeftWarehouse returns true once the courier has the parcel.
+func hasLeftWarehouse(status string) bool {
+	return status == ShipmentStatusPickedUp || status == ShipmentStatusInTransit ||
+		status == ShipmentStatusDelivered || status == ShipmentStatusReturned
+}
+
+// UnshippedQuantities returns, per order item ID, the quantity not yet assigned to a
+// shipment. Cancelled shipments release their items.
+func UnshippedQuantities(items []OrderItem, shipments []Shipment) map[string]int {
+	remaining := make(map[string]int, len(items))
+	for _, item := range items {
+		remaining[item.ID] += item.Quantity
+	}
+	for _, s := range shipments {
+		if s.Status == ShipmentStatusCancelled {
+			continue
+		}
+		for _, si := range s.Items {
+			remaining[si.OrderItemID] -= si.Quantity
+		}
+	}
+	return remaining
+}
+
+// OrderFulfillmentStatus derives the order status implied by its shipments:
+// partially_shipped while only some items have left the warehouse, shipped once all
+// have, delivered (or returned) once every shipment that left reached that state.
+// Returns "" while nothing has left the warehouse or the outcome is mixed; courier
+// webhooks apply the result through UpdateOrderStatus, so ValidTransitions still decides.
+// Replacement shipments of exchanges do not count: the order was already delivered.
+func OrderFulfillmentStatus(items []OrderItem, shipments []Shipment) string {
+	left := make(map[string]int, len(items))
+	anyLeft, allDelivered, allReturned := false, true, true
+	for _, s := range shipments {
+		if !hasLeftWarehouse(s.Status) || s.ExchangeID != nil {
+			continue
+		}
+		anyLeft = true
+		allDelivered = allDelivered && s.Status == ShipmentStatusDelivered
+		allReturned = allReturned && s.Status == ShipmentStatusReturned
+		for _, si := range s.Items {
+			left[si.OrderItemID] += si.Quantity
+		}
+	}
+	if !anyLeft {
+		return ""
+	}
+
+	for _, item := range items {
+		if left[item.ID] < item.Quantity {
+			return OrderStatusPartiallyShipped
+		}
+	}
+	switch {
+	case allDelivered:
+		return OrderStatusDelivered
+	case allReturned:
+		return OrderStatusReturned
+	case anyReturned(shipments):
+		return "" // Part delivered, part returned: left for an admin
+	default:
+		return OrderStatusShipped
+	}
+}
+
+func anyReturned(shipments []Shipment) bool {
+	for _, s := range shipments {
+		if s.Status == ShipmentStatusReturned && s.ExchangeID == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ShipmentProviderManual marks a shipment handed over without a courier integration
+// (own delivery, or a courier booked by hand). Its status is updated by admins.
+const ShipmentProviderManual = "manual"
+
+// ShipmentItem is the quantity of one order item carried by a shipment.
+type ShipmentItem struct {
+	OrderItemID string `json:"orderItemId"`
+	Quantity    int    `json:"quantity"`
+}
+
+// Shipment is a courier consignment booked for an order.
+type Shipment struct {
+	ID            string         `json:"id"`
+	OrderID       string         `json:"orderId"`
+	Provider      string         `json:"provider"` // Courier* constant
+	ConsignmentID string         `json:"consignmentId"`
+	TrackingCode  *string        `json:"trackingCode,omitempty"`
+	TrackingURL   *string        `json:"trackingUrl,omitempty"`
+	LabelURL      *string        `json:"labelUrl,omitempty"`
+	CODAmount     Money          `json:"codAmount"` // Cash the courier collects on delivery
+	Status        string         `json:"status"`
+	CourierStatus *string        `json:"courierStatus,omitempty"` // Last raw status reported by the courier
+	Items         []ShipmentItem `json:"items"`
+	ExchangeID    *string        `json:"exchangeId,omitempty"` // Replacement shipment of an exchange (no items)
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+// CourierBookingRequest is what a courier needs to create a consignment.
+type CourierBookingRequest struct {
+	Reference      string // Sent as the merchant invoice / order reference: order number and parcel sequence
+	RecipientName  string
+	RecipientPhone string
+	Address        string
+	Area           string
+	District       string
+	CityID         int // Courier-specific location IDs, when the courier needs them
+	AreaID         int
+	CODAmount      Money
+	ItemCount      int
+	WeightKg       float64
+	Note           string
+}
+
+// CourierBooking is the courier's answer to CourierBookingRequest.
+type CourierBooking struct {
+	ConsignmentID string
+	TrackingCode  string
+	TrackingURL   string
+	LabelURL      string
+	CourierStatus string
+}
+
+// CourierWebhook is an incoming courier notification as received over HTTP.
+type CourierWebhook struct {
+	Headers map[string]string // Canonical header name → first value
+	Query   map[string]string
+	Body    []byte
+}
+
+// CourierEvent is a courier webhook that has passed authentication.
+type CourierEvent struct {
+	ConsignmentID string
+	Status        string // ShipmentStatus*, empty for informational updates
+	CourierStatus string // The courier's own status/event name
+	EventKey      string // Unique per notification; replays carry the same key
+	Payload       JSONB  // Raw fields for audit
+}
+
+// CourierProvider abstracts a delivery company (Pathao, Steadfast, RedX, ...).
+// Adapters live in internal/infrastructure/courier.
+type CourierProvider interface {
+	// Provider returns the Courier* constant this adapter serves.
+	Provider() string
+	Book(ctx context.Context, req CourierBookingRequest) (*CourierBooking, error)
+	// ParseWebhook authenticates and normalizes a webhook. It must reject anything it
+	// cannot authenticate; a nil event acknowledges a notification with nothing to apply.
+	ParseWebhook(ctx context.Context, hook CourierWebhook) (*CourierEvent, error)
+}
+
+type ShipmentRepository interface {
+	// Create stores the shipment together with its items.
+	Create(ctx context.Context, shipment *Shipment) error
+	GetByID(ctx context.Context, id string) (*Shipment, error)
+	// GetForUpdate locks the shipment row (items are not loaded).
+	GetForUpdate(ctx context.Context, id string) (*Shipment, error)
+	GetByConsignment(ctx context.Context, provider, consignmentID string) (*Shipment, error)
+	// GetByTracking finds the latest shipment whose consignment ID or tracking code is
+	// tracking; an empty provider matches any courier.
+	GetByTracking(ctx context.Context, provider, tracking string) (*Shipment, error)
+	ListByOrder(ctx context.Context, orderID string) ([]Shipment, error)
+	UpdateStatus(ctx context.Context, id, status, courierStatus string) error
+	// RecordEvent stores a verified webhook. It returns false if the same
+	// (provider, event key) was already recorded, i.e. the webhook is a replay.
+	RecordEvent(ctx context.Context, provider, shipmentID string, ev *CourierEvent) (bool, error)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Draft order statuses
+//
+//	draft → sent → completed
+//	      → cancelled
+const (
+	DraftStatusDraft     = "draft"     // Being prepared by an admin
+	DraftStatusSent      = "sent"      // Pay/confirm link issued to the customer
+	DraftStatusCompleted = "completed" // Placed as an order (OrderID)
+	DraftStatusCancelled = "cancelled"
+)
+
+// IsOpenDraftStatus returns true while a draft can still be edited and placed.
+func IsOpenDraftStatus(status string) bool {
+	return status == DraftStatusDraft || status == DraftStatusSent
+}
+
+// DraftOrderItem is a variant picked for a draft order.
+type DraftOrderItem struct {
+	ProductID      string  `json:"productId"`
+	ProductName    string  `json:"productName,omitempty"`
+	VariantID      string  `json:"variantId"`
+	VariantName    string  `json:"variantName,omitempty"`
+	Quantity       int     `json:"quantity"`
+	PriceOverride  *Money  `json:"priceOverride,omitempty"` // Replaces the current price
+	OverrideReason *string `json:"overrideReason,omitempty"`
+	UnitPrice      Money   `json:"unitPrice"` // Computed: override or current price
+}
+
+// DraftOrder is an order prepared by an admin for a customer (phone / Messenger orders).
+// It becomes a real order through the checkout path once an admin finalises it or the
+// customer confirms it through their link.
+type DraftOrder struct {
+	ID              string           `json:"id"`
+	UserID          string           `json:"userId"`
+	Status          string           `json:"status"`
+	ShippingAddress JSONB            `json:"shippingAddress"` // deliveryLocation is the shipping zone
+	PaymentMethod   string           `json:"paymentMethod"`
+	DiscountAmount  Money            `json:"discountAmount"` // Manual discount off the subtotal
+	DiscountReason  *string          `json:"discountReason,omitempty"`
+	Note            *string          `json:"note,omitempty"`
+	Locale          string           `json:"locale"`
+	OrderID         *string          `json:"orderId,omitempty"`
+	CreatedBy       *string          `json:"createdBy,omitempty"`
+	Items           []DraftOrderItem `json:"items"`
+	SentAt          *time.Time       `json:"sentAt,omitempty"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
+
+	// Computed at current prices and shipping rates
+	Subtotal    Money `json:"subtotal"`
+	ShippingFee Money `json:"shippingFee"`
+	Total       Money `json:"total"`
+}
+
+type DraftOrderRepository interface {
+	// Create stores the draft together with its items.
+	Create(ctx context.Context, draft *DraftOrder) error
+	// Update replaces the details and items of a draft.
+	Update(ctx context.Context, draft *DraftOrder) error
+	GetByID(ctx context.Context, id string) (*DraftOrder, error)
+	// GetForUpdate locks the draft row; its items are loaded too.
+	GetForUpdate(ctx context.Context, id string) (*DraftOrder, error)
+	List(ctx context.Context, status string, limit, offset int) ([]DraftOrder, int64, error)
+	MarkSent(ctx context.Context, id string) error
+	// Complete links the placed order to an open draft; false if the draft is no longer open.
+	Complete(ctx context.Context, id, orderID string) (bool, error)
+	// Cancel cancels an open draft; false if the draft is no longer open.
+	Cancel(ctx context.Context, id string) (bool, error)
+}
@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+
+// ErrNotFound is wrapped by repositories when a record does not exist, keeping their
+// messages ("order not found"): check it with errors.Is, not the error text.
+var ErrNotFound = errors.New("not found")
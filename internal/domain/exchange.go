@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Exchange statuses
+const (
+	ExchangeStatusAwaitingReturn = "awaiting_return" // Replacement reserved/shipped, original not back yet
+	ExchangeStatusReceived       = "received"        // Original item is back and restocked
+)
+
+// OrderExchange swaps a quantity of a delivered order item for another variant of the
+// same product. The replacement is deducted from stock when the exchange is created and
+// travels in a shipment linked to the exchange; the original is restocked on receipt.
+type OrderExchange struct {
+	ID              string     `json:"id"`
+	OrderID         string     `json:"orderId"`
+	OrderItemID     string     `json:"orderItemId"`
+	OldVariantID    *string    `json:"oldVariantId,omitempty"`
+	NewVariantID    *string    `json:"newVariantId,omitempty"`
+	NewVariantName  string     `json:"newVariantName"`
+	Quantity        int        `json:"quantity"`
+	UnitPrice       Money      `json:"unitPrice"`       // Price of the replacement variant
+	PriceDifference Money      `json:"priceDifference"` // Positive: charged to the customer, negative: refunded
+	Status          string     `json:"status"`
+	Note            *string    `json:"note,omitempty"`
+	CreatedBy       *string    `json:"createdBy,omitempty"`
+	ReceivedAt      *time.Time `json:"receivedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// ExchangedQuantities returns, per order item ID, the quantity swapped by the given
+// exchanges.
+func ExchangedQuantities(exchanges []OrderExchange) map[string]int {
+	exchanged := make(map[string]int)
+	for _, e := range exchanges {
+		exchanged[e.OrderItemID] += e.Quantity
+	}
+	return exchanged
+}
+
+type ExchangeRepository interface {
+	Create(ctx context.Context, exchange *OrderExchange) error
+	GetByID(ctx context.Context, id string) (*OrderExchange, error)
+	GetForUpdate(ctx context.Context, id string) (*OrderExchange, error)
+	ListByOrder(ctx context.Context, orderID string) ([]OrderExchange, error)
+	MarkReceived(ctx context.Context, id string) error
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an Idempotency-Key.
+// StatusCode is nil while the first request is still being processed.
+type IdempotencyRecord struct {
+	UserID       string
+	Key          string
+	RequestHash  string
+	StatusCode   *int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+type IdempotencyRepository interface {
+	// Claim reserves (userID, key) for a new request. It returns false if a
+	// non-expired record already exists for the pair.
+	Claim(ctx context.Context, userID, key, requestHash string, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, userID, key string) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error
+	// Release forgets a claim so the request can be retried (used when it failed server-side).
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Printable order documents
+const (
+	DocumentInvoice       = "invoice"
+	DocumentPackingSlip   = "packing_slip"
+	DocumentShippingLabel = "shipping_label" // 4x6 in thermal label
+)
+
+// IsValidDocumentType returns true for the printable order document types.
+func IsValidDocumentType(docType string) bool {
+	return docType == DocumentInvoice || docType == DocumentPackingSlip || docType == DocumentShippingLabel
+}
+
+// OrderInvoice is the invoice number issued to an order. It is issued the first time
+// the invoice is printed and kept for every reprint.
+type OrderInvoice struct {
+	ID       string    `json:"id"`
+	OrderID  string    `json:"orderId"`
+	Number   string    `json:"number"`           // INV-000123
+	PDFURL   *string   `json:"pdfUrl,omitempty"` // Archived copy, when invoice storage is enabled
+	IssuedAt time.Time `json:"issuedAt"`
+}
+
+type InvoiceRepository interface {
+	// Issue returns the order's invoice, issuing the next invoice number if it has none.
+	Issue(ctx context.Context, orderID string) (*OrderInvoice, error)
+	GetByOrderID(ctx context.Context, orderID string) (*OrderInvoice, error)
+	SetPDFURL(ctx context.Context, id, url string) error
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Payment ledger directions
+const (
+	PaymentDirectionIn  = "in"  // Money received from the customer
+	PaymentDirectionOut = "out" // Money paid back to the customer (refunds)
+)
+
+// Payment ledger entry statuses
+//
+//	pending → verified
+//	        → rejected
+const (
+	PaymentEntryPending  = "pending"  // Claimed by the customer (trx ID submitted), awaiting an admin
+	PaymentEntryVerified = "verified" // Counted in the order's paid or refunded amount
+	PaymentEntryRejected = "rejected"
+)
+
+// Payment is one money movement of an order: a deposit, advance payment, gateway
+// capture, pre-order balance or COD collection coming in, or a refund going out. The
+// ledger is the source of truth for money: Order.PaidAmount and Order.RefundedAmount
+// are the sums of its verified entries.
+type Payment struct {
+	ID             string     `json:"id"`
+	OrderID        string     `json:"orderId"`
+	Direction      string     `json:"direction"` // PaymentDirection*
+	Amount         Money      `json:"amount"`
+	Method         string     `json:"method"`             // PaymentMethod* for money in, RefundMethod* for money out
+	Provider       *string    `json:"provider,omitempty"` // bKash, Nagad, SSLCommerz…
+	TransactionID  *string    `json:"transactionId,omitempty"`
+	SenderNumber   *string    `json:"senderNumber,omitempty"`
+	Status         string     `json:"status"`              // PaymentEntry*
+	SessionID      *string    `json:"sessionId,omitempty"` // Gateway session that captured it
+	RefundID       *string    `json:"refundId,omitempty"`  // Refund it pays out
+	Note           *string    `json:"note,omitempty"`
+	VerifiedBy     *string    `json:"verifiedBy,omitempty"` // Nil for gateway captures
+	VerifiedByName *string    `json:"verifiedByName,omitempty"`
+	VerifiedAt     *time.Time `json:"verifiedAt,omitempty"`
+	CreatedBy      *string    `json:"createdBy,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type PaymentLedgerRepository interface {
+	// Record stores an entry and re-derives the order's paid and refunded amounts.
+	Record(ctx context.Context, payment *Payment) error
+	// ListByOrder returns the order's ledger, oldest first.
+	ListByOrder(ctx context.Context, orderID string) ([]Payment, error)
+	// ListPendingForUpdate locks the order's incoming entries awaiting verification, oldest first.
+	ListPendingForUpdate(ctx context.Context, orderID string) ([]Payment, error)
+	// Verify marks a pending entry verified and re-derives the order's amounts. It
+	// returns false if the entry was no longer pending.
+	Verify(ctx context.Context, id, orderID, verifiedBy string) (bool, error)
+}
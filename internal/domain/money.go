@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in paisa (1/100 taka). Sums and differences are exact; an amount
+// is rounded to the paisa only where a fraction can appear — parsing decimals with more
+// than two places, converting from float64, and taking ratios (percentages, pro-rata
+// shares) — and always half away from zero (0.005 → 0.01, -0.005 → -0.01).
+//
+// Money maps losslessly to Postgres NUMERIC(12, 2) and is encoded in JSON as a number
+// with two decimals (1234.50), as prices always were.
+type Money int64
+
+// ParseMoney parses a decimal amount ("1234.5", "-0.75", "1e3") without going through
+// float64.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount: empty")
+	}
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	// Exponent (JSON numbers may use one)
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > 15 || e < -15 {
+			return 0, fmt.Errorf("invalid amount: %q", s)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+	digits := intPart + fracPart
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid amount: %q", s)
+		}
+	}
+	// The value is digits × 10^scale; bring it to paisa (scale -2)
+	scale := exp - len(fracPart) + 2
+	digits = strings.TrimLeft(digits, "0")
+	if scale > 0 {
+		digits += strings.Repeat("0", scale)
+	}
+	roundUp := false
+	if scale < 0 {
+		cut := len(digits) + scale
+		if cut < 0 {
+			digits, cut = "", 0
+		}
+		if cut < len(digits) {
+			roundUp = digits[cut] >= '5'
+			digits = digits[:cut]
+		}
+	}
+	if len(digits) > 18 {
+		return 0, fmt.Errorf("invalid amount: %q is too large", s)
+	}
+
+	var minor int64
+	if digits != "" {
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount: %q", s)
+		}
+		minor = n
+	}
+	if roundUp {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money(minor), nil
+}
+
+// MoneyFromFloat converts a float64 amount (gateway and courier payloads), rounding
+// to the paisa. The shortest decimal form of f is used, so 0.285 becomes 0.29.
+func MoneyFromFloat(f float64) Money {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	m, err := ParseMoney(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		return Money(math.Round(f * 100))
+	}
+	return m
+}
+
+// Taka returns a whole-taka amount.
+func Taka(n int64) Money {
+	return Money(n * 100)
+}
+
+// MoneyPtr returns a pointer to m.
+func MoneyPtr(m Money) *Money {
+	return &m
+}
+
+// Paisa returns the amount in minor units.
+func (m Money) Paisa() int64 {
+	return int64(m)
+}
+
+// Float64 returns the amount in taka, for APIs that take a float.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String formats the amount with two decimals: 1234.50, -0.75.
+func (m Money) String() string {
+	sign := ""
+	n := int64(m)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// Times multiplies the amount by a quantity.
+func (m Money) Times(n int) Money {
+	return m * Money(n)
+}
+
+// MulDiv returns m × num / den, rounded half away from zero.
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		return 0
+	}
+	p := int64(m) * num
+	q, r := p/den, p%den
+	if r < 0 {
+		r = -r
+	}
+	d := den
+	if d < 0 {
+		d = -d
+	}
+	if 2*r >= d {
+		if (p < 0) != (den < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q)
+}
+
+// Percent returns rate percent of the amount, where rate is itself stored as Money
+// (12.50 means 12.5%), rounded half away from zero.
+func (m Money) Percent(rate Money) Money {
+	return m.MulDiv(int64(rate), 100*100)
+}
+
+// WholeTaka returns the amount rounded to whole taka, half away from zero (couriers
+// collect whole taka).
+func (m Money) WholeTaka() int64 {
+	return int64(m.MulDiv(1, 100))
+}
+
+// Min returns the smaller amount.
+func (m Money) Min(o Money) Money {
+	if o < m {
+		return o
+	}
+	return m
+}
+
+// Max returns the larger amount.
+func (m Money) Max(o Money) Money {
+	if o > m {
+		return o
+	}
+	return m
+}
+
+// Abs returns the absolute amount.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string, parsed exactly.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MoneyFromJSON reads an amount stored in a JSONB map (payment_details): a JSON number
+// decodes to float64, a json.Number or string is parsed exactly.
+func MoneyFromJSON(v interface{}) (Money, bool) {
+	switch n := v.(type) {
+	case float64:
+		return MoneyFromFloat(n), true
+	case int:
+		return Taka(int64(n)), true
+	case int64:
+		return Taka(n), true
+	case Money:
+		return n, true
+	case fmt.Stringer:
+		m, err := ParseMoney(n.String())
+		return m, err == nil
+	case string:
+		m, err := ParseMoney(n)
+		return m, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "1234.5", want: 123450},
+		{in: "1234.50", want: 123450},
+		{in: "-0.75", want: -75},
+		{in: "+3", want: 300},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: " 42 ", want: 4200},
+		{in: "1e3", want: 100000},
+		{in: "1.5e-1", want: 15},
+		{in: "12.345", want: 1235},
+		{in: "0.005", want: 1},
+		{in: "-0.005", want: -1},
+		{in: "0.004", want: 0},
+		{in: "0.0000001", want: 0},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e16", wantErr: true},
+		{in: "12345678901234567890", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: 5, want: "0.05"},
+		{in: 123450, want: "1234.50"},
+		{in: -75, want: "-0.75"},
+		{in: Taka(1000), want: "1000.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+// Ratios round half away from zero.
+func TestMoneyRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{name: "half paisa up", got: Money(1).MulDiv(1, 2), want: 1},
+		{name: "negative half paisa down", got: Money(-1).MulDiv(1, 2), want: -1},
+		{name: "negative denominator", got: Money(1).MulDiv(1, -2), want: -1},
+		{name: "below half", got: Money(10).MulDiv(1, 3), want: 3},
+		{name: "zero denominator", got: Money(100).MulDiv(1, 0), want: 0},
+		{name: "12.5 percent", got: Taka(10).Percent(1250), want: 125},
+		{name: "percent rounds", got: Money(999).Percent(1000), want: 100},
+		{name: "float shortest form", got: MoneyFromFloat(0.285), want: 29},
+		{name: "float", got: MoneyFromFloat(1234.5), want: 123450},
+		{name: "negative float", got: MoneyFromFloat(-0.125), want: -13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %d, want %d", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyWholeTaka(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want int64
+	}{
+		{in: 150, want: 2},
+		{in: 149, want: 1},
+		{in: -150, want: -2},
+		{in: Taka(7), want: 7},
+	}
+	for _, tt := range tests {
+		if got := tt.in.WholeTaka(); got != tt.want {
+			t.Errorf("Money(%d).WholeTaka() = %d, want %d", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+		out  string
+	}{
+		{in: `1234.5`, want: 123450, out: `1234.50`},
+		{in: `"99.99"`, want: 9999, out: `99.99`},
+		{in: `12.345`, want: 1235, out: `12.35`},
+		{in: `-3`, want: -300, out: `-3.00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var m Money
+			if err := json.Unmarshal([]byte(tt.in), &m); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+			}
+			if m != tt.want {
+				t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, m, tt.want)
+			}
+			out, err := json.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(out) != tt.out {
+				t.Errorf("Marshal = %s, want %s", out, tt.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Email outbox statuses
+const (
+	EmailStatusPending = "pending" // Waiting for (re)delivery at NextAttemptAt
+	EmailStatusSending = "sending" // Claimed by the dispatcher until NextAttemptAt (lease)
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed" // Gave up after the maximum number of attempts
+)
+
+// Order email templates, one per customer-facing lifecycle event
+const (
+	EmailOrderPlaced      = "order_placed"
+	EmailOrderConfirmed   = "order_confirmed" // Order/payment verified, being prepared
+	EmailOrderShipped     = "order_shipped"
+	EmailOrderDelivered   = "order_delivered"
+	EmailOrderRefunded    = "order_refunded"
+	EmailDraftOrder       = "draft_order"       // Pay/confirm link of an admin-built draft order
+	EmailPreorderReleased = "preorder_released" // Pre-ordered item arrived; carries the balance pay link
+)
+
+// Notification locales
+const (
+	LocaleEnglish = "en"
+	LocaleBangla  = "bn"
+)
+
+// NormalizeLocale maps a requested locale to a supported one (English by default).
+func NormalizeLocale(locale string) string {
+	if locale == LocaleBangla {
+		return LocaleBangla
+	}
+	return LocaleEnglish
+}
+
+// OrderStatusEmails maps the order statuses that notify the customer
+// (SideEffectNotifyCustomer) to their email template.
+var OrderStatusEmails = map[string]string{
+	OrderStatusProcessing:       EmailOrderConfirmed,
+	OrderStatusShipped:          EmailOrderShipped,
+	OrderStatusPartiallyShipped: EmailOrderShipped,
+	OrderStatusDelivered:        EmailOrderDelivered,
+	OrderStatusRefunded:         EmailOrderRefunded,
+}
+
+// EmailMessage is a rendered email ready to be handed to a Mailer.
+type EmailMessage struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Mailer delivers a single email (SMTP in production, log-only in development).
+type Mailer interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// OutboxEmail is a rendered email queued for delivery.
+type OutboxEmail struct {
+	ID            string     `json:"id"`
+	OrderID       *string    `json:"orderId,omitempty"`
+	Template      string     `json:"template"`
+	Locale        string     `json:"locale"`
+	To            string     `json:"to"`
+	Subject       string     `json:"subject"`
+	HTMLBody      string     `json:"-"`
+	TextBody      string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"lastError,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type EmailOutboxRepository interface {
+	// Enqueue stores an email; pass the caller's transaction context so it commits
+	// (and is only ever sent) together with the change it announces.
+	Enqueue(ctx context.Context, email *OutboxEmail) error
+	// ClaimDue leases up to limit due emails for delivery and increments their attempts.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]OutboxEmail, error)
+	MarkSent(ctx context.Context, id string) error
+	// MarkRetry puts a failed delivery back in the queue, due again after retryIn.
+	MarkRetry(ctx context.Context, id, lastError string, retryIn time.Duration) error
+	MarkFailed(ctx context.Context, id, lastError string) error
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -12,50 +13,66 @@ type OrderFilter struct {
 	PaymentStatus string
 	Search        string
 	IsPreorder    *bool
+	MinRiskScore  *int // Unscored orders (placed before risk scoring) never match a risk bound
+	MaxRiskScore  *int
 }
 
 // --- Cart Entities ---
 
 type Cart struct {
 	ID        string     `json:"id"`
-	UserID    *string    `json:"userId"` // Optional: guest carts could be supported, but for now we link to User if logged in
+	UserID    *string    `json:"userId"` // Nil for guest carts (identified by a signed cart token)
 	Items     []CartItem `json:"items"`
 	CreatedAt time.Time  `json:"createdAt"`
 	UpdatedAt time.Time  `json:"updatedAt"`
 }
 
 type CartItem struct {
-	ID           string   `json:"id"`
-	CartID       string   `json:"cartId"`
-	ProductID    string   `json:"productId"`
-	Product      Product  `json:"product"`
-	VariantID    *string  `json:"variantId"`
-	VariantName  *string  `json:"variantName"`
-	VariantImage *string  `json:"variantImage"`
-	Quantity     int      `json:"quantity"`
-	Price        float64  `json:"price"`     // Effective price
-	SalePrice    *float64 `json:"salePrice"` // Effective sale price (if any)
+	ID           string  `json:"id"`
+	CartID       string  `json:"cartId"`
+	ProductID    string  `json:"productId"`
+	Product      Product `json:"product"`
+	VariantID    *string `json:"variantId"`
+	VariantName  *string `json:"variantName"`
+	VariantImage *string `json:"variantImage"`
+	Quantity     int     `json:"quantity"`
+	Price        Money   `json:"price"`     // Effective price
+	SalePrice    *Money  `json:"salePrice"` // Effective sale price (if any)
 }
 
 // --- Order Entities ---
 
 type Order struct {
-	ID              string      `json:"id"`
-	UserID          string      `json:"userId"`
-	User            User        `json:"user"`
-	Status          string      `json:"status"` // pending, processing, shipped, delivered, cancelled
-	TotalAmount     float64     `json:"totalAmount"`
-	ShippingFee     float64     `json:"shippingFee"`
-	ShippingAddress JSONB       `json:"shippingAddress"`
-	PaymentMethod   string      `json:"paymentMethod"`
-	PaymentStatus   string      `json:"paymentStatus"`
-	PaidAmount      float64     `json:"paidAmount"`
-	RefundedAmount  float64     `json:"refundedAmount"`
-	PaymentDetails  JSONB       `json:"paymentDetails"`
-	IsPreorder      bool        `json:"isPreorder"`
-	Items           []OrderItem `json:"items"`
-	CreatedAt       time.Time   `json:"createdAt"`
-	UpdatedAt       time.Time   `json:"updatedAt"`
+	ID              string       `json:"id"`
+	OrderNumber     string       `json:"orderNumber"` // VAL-26-000123: customer, courier and ad-platform reference
+	UserID          string       `json:"userId"`
+	User            User         `json:"user"`
+	Status          string       `json:"status"`      // pending, processing, shipped, delivered, cancelled
+	TotalAmount     Money        `json:"totalAmount"` // Net of DiscountAmount, includes ShippingFee
+	ShippingFee     Money        `json:"shippingFee"`
+	DiscountAmount  Money        `json:"discountAmount"`
+	CouponCode      *string      `json:"couponCode,omitempty"`
+	ShippingAddress JSONB        `json:"shippingAddress"`
+	PaymentMethod   string       `json:"paymentMethod"`
+	PaymentStatus   string       `json:"paymentStatus"`
+	PaidAmount      Money        `json:"paidAmount"`
+	RefundedAmount  Money        `json:"refundedAmount"`
+	PaymentDetails  JSONB        `json:"paymentDetails"`
+	IsPreorder      bool         `json:"isPreorder"`
+	Locale          string       `json:"locale"`                  // Language of customer notifications (en, bn)
+	CustomerPhone   *string      `json:"customerPhone,omitempty"` // Normalized shipping phone, for fraud history
+	IPAddress       *string      `json:"ipAddress,omitempty"`
+	RiskScore       *int         `json:"riskScore"` // 0-100 from checkout; nil for orders placed before scoring
+	RiskReasons     []RiskReason `json:"riskReasons"`
+	Items           []OrderItem  `json:"items"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+}
+
+// FormatOrderNumber formats the nth order number of a year: VAL-26-000123. The
+// sequence is zero-padded to six digits and grows past them.
+func FormatOrderNumber(year, n int) string {
+	return fmt.Sprintf("VAL-%02d-%06d", year%100, n)
 }
 
 type OrderItem struct {
@@ -67,7 +84,7 @@ type OrderItem struct {
 	VariantName *string `json:"variantName,omitempty"`
 	VariantSKU  *string `json:"variantSku,omitempty"`
 	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"` // Price at time of purchase
+	Price       Money   `json:"price"` // Price at time of purchase
 }
 
 // --- Interfaces ---
@@ -80,18 +97,62 @@ type OrderHistory struct {
 	Reason         *string   `json:"reason"`
 	CreatedBy      *string   `json:"createdBy"`             // UserID
 	CreatedName    *string   `json:"createdName,omitempty"` // Enriched
+	ShipmentID     *string   `json:"shipmentId,omitempty"`  // Set for shipment events
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// RefundItem is the quantity of one order item paid back by a refund.
+type RefundItem struct {
+	OrderItemID string `json:"orderItemId"`
+	Quantity    int    `json:"quantity"`
+	Amount      Money  `json:"amount"` // Price paid for this quantity
+}
+
+// Refund is one entry of an order's refund ledger.
+type Refund struct {
+	ID              string       `json:"id"`
+	OrderID         string       `json:"orderId"`
+	Amount          Money        `json:"amount"`
+	ShippingAmount  Money        `json:"shippingAmount"` // Part of Amount covering the shipping fee
+	Method          string       `json:"method"`         // RefundMethod* constant
+	Reference       *string      `json:"reference,omitempty"`
+	Reason          *string      `json:"reason,omitempty"`
+	Restock         bool         `json:"restock"`
+	Items           []RefundItem `json:"items"` // Empty for plain amount refunds
+	CreatedBy       *string      `json:"createdBy,omitempty"`
+	ProcessedByName *string      `json:"processedByName,omitempty"` // Enriched
+	CreatedAt       time.Time    `json:"createdAt"`
+}
+
+// OrderTimelineEntry is the customer-facing view of an OrderHistory status change,
+// without internal notes or staff identities.
+type OrderTimelineEntry struct {
+	Status         string    `json:"status"`
+	PreviousStatus *string   `json:"previousStatus,omitempty"`
 	CreatedAt      time.Time `json:"createdAt"`
 }
 
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, order *Order) error
 	GetByID(ctx context.Context, id string) (*Order, error)
+	GetByOrderNumber(ctx context.Context, orderNumber string) (*Order, error)
+	// LockForUpdate locks the order row until the transaction ends.
+	LockForUpdate(ctx context.Context, id string) error
+	// GetCustomerRiskHistory summarizes the past orders of the phone, IP and account of q.
+	GetCustomerRiskHistory(ctx context.Context, q RiskQuery) (*CustomerRiskHistory, error)
 	GetByUserID(ctx context.Context, userID string) ([]Order, error)
 	GetAll(ctx context.Context, filter OrderFilter) ([]Order, int64, error)
 	UpdateStatus(ctx context.Context, id, status string) error
 	UpdatePaymentStatus(ctx context.Context, id, status string) error
-	UpdatePaidAmount(ctx context.Context, id string, amount float64) error
-	UpdateOrderShippingDetails(ctx context.Context, id string, address JSONB, shippingFee, totalAmount float64) error
+	UpdateTotalAmount(ctx context.Context, id string, amount Money) error
+	UpdateOrderShippingDetails(ctx context.Context, id string, address JSONB, shippingFee, totalAmount Money) error
+	// UpdateOrderAmounts stores the totals and pre-order deposit recomputed after an edit.
+	UpdateOrderAmounts(ctx context.Context, id string, totalAmount, discountAmount Money, isPreorder bool, paymentDetails JSONB) error
+
+	// Order items (admin edits)
+	AddOrderItem(ctx context.Context, orderID string, item *OrderItem) error
+	UpdateOrderItem(ctx context.Context, itemID string, quantity int, price Money) error
+	DeleteOrderItem(ctx context.Context, itemID string) error
 
 	// Cart
 	GetCartByUserID(ctx context.Context, userID string) (*Cart, error)
@@ -100,9 +161,23 @@ type OrderRepository interface {
 	UpsertCartItemAtomic(ctx context.Context, userID, cartID, productID string, variantID *string, quantity int) ([]CartItem, error)
 	AtomicRemoveCartItem(ctx context.Context, userID, productID, variantID string) error
 	ClearCart(ctx context.Context, cartID string) error
+	// Guest carts (no owner), addressed by cart ID
+	GetCartByID(ctx context.Context, cartID string) (*Cart, error)
+	GetCartItemsByCartID(ctx context.Context, cartID string) ([]CartItem, error)
+	RemoveCartItem(ctx context.Context, cartID, productID, variantID string) error
+	AssignCartToUser(ctx context.Context, cartID, userID string) (bool, error)
+	DeleteCart(ctx context.Context, cartID string) error
+	// TouchCart marks a guest cart as in use, keeping it from the idle sweep.
+	TouchCart(ctx context.Context, cartID string) error
+	// DeleteIdleGuestCarts removes up to limit guest carts untouched for idleFor.
+	DeleteIdleGuestCarts(ctx context.Context, idleFor time.Duration, limit int) (int64, error)
 
 	// Refunds & History
-	CreateRefund(ctx context.Context, orderID string, amount float64, reason string, restock bool, createdBy *string) error
+	// CreateRefund stores the refund with its items and records the money paid out in the
+	// payment ledger, re-deriving the order's refunded amount.
+	CreateRefund(ctx context.Context, refund *Refund) error
+	// GetRefunds returns the order's refund ledger, newest first.
+	GetRefunds(ctx context.Context, orderID string) ([]Refund, error)
 	CreateOrderHistory(ctx context.Context, history *OrderHistory) error
 	GetOrderHistory(ctx context.Context, orderID string) ([]OrderHistory, error)
 
@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestFormatOrderNumber(t *testing.T) {
+	tests := []struct {
+		year, n int
+		want    string
+	}{
+		{year: 2026, n: 1, want: "VAL-26-000001"},
+		{year: 2026, n: 123, want: "VAL-26-000123"},
+		{year: 2026, n: 999999, want: "VAL-26-999999"},
+		{year: 2026, n: 1000000, want: "VAL-26-1000000"},
+		{year: 2100, n: 42, want: "VAL-00-000042"},
+		{year: 2009, n: 7, want: "VAL-09-000007"},
+	}
+	for _, tt := range tests {
+		if got := FormatOrderNumber(tt.year, tt.n); got != tt.want {
+			t.Errorf("FormatOrderNumber(%d, %d) = %q, want %q", tt.year, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Payment session statuses. A session is terminal once it leaves "initiated".
+const (
+	PaymentSessionInitiated = "initiated"
+	PaymentSessionSucceeded = "succeeded"
+	PaymentSessionFailed    = "failed"
+	PaymentSessionCancelled = "cancelled"
+)
+
+// PaymentSession is one hosted-checkout attempt with an online gateway.
+// Its ID is sent to the gateway as the merchant transaction reference.
+type PaymentSession struct {
+	ID            string     `json:"id"`
+	OrderID       string     `json:"orderId"`
+	Provider      string     `json:"provider"` // PaymentMethodSSLCommerz, PaymentMethodBKash
+	Amount        Money      `json:"amount"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"`
+	GatewayRef    *string    `json:"gatewayRef,omitempty"` // SSLCommerz sessionkey / bKash paymentID
+	RedirectURL   *string    `json:"redirectUrl,omitempty"`
+	TransactionID *string    `json:"transactionId,omitempty"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// GatewaySessionRequest is what a gateway needs to open a hosted payment page.
+type GatewaySessionRequest struct {
+	SessionID     string
+	OrderID       string
+	Amount        Money
+	Currency      string
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	Address       string
+	CallbackURL   string // Browser is redirected here after paying
+	IPNURL        string // Server-to-server notification URL (gateways that support IPN)
+}
+
+// GatewaySession is the gateway's answer to GatewaySessionRequest.
+type GatewaySession struct {
+	GatewayRef  string
+	RedirectURL string
+}
+
+// PaymentCallback is a gateway notification that has passed signature/server-side verification.
+type PaymentCallback struct {
+	SessionID     string // Our PaymentSession.ID echoed back by the gateway
+	GatewayRef    string // Gateway's own session/payment reference
+	TransactionID string // Gateway transaction ID (empty unless succeeded)
+	Amount        Money  // Amount the gateway says was captured
+	Status        string // PaymentSessionSucceeded, PaymentSessionFailed, PaymentSessionCancelled
+	EventKey      string // Unique per notification; replays carry the same key
+	Payload       JSONB  // Raw fields for audit
+}
+
+// PaymentGateway abstracts an online payment provider (SSLCommerz, bKash, ...).
+// Adapters live in internal/infrastructure/payment.
+type PaymentGateway interface {
+	// Provider returns the PaymentMethod* constant this gateway serves.
+	Provider() string
+	CreateSession(ctx context.Context, req GatewaySessionRequest) (*GatewaySession, error)
+	// VerifyCallback authenticates a callback/IPN (signature and/or server-side
+	// lookup) and normalizes it. It must reject anything it cannot authenticate.
+	VerifyCallback(ctx context.Context, fields map[string]string) (*PaymentCallback, error)
+}
+
+type PaymentRepository interface {
+	CreateSession(ctx context.Context, session *PaymentSession) error
+	SetSessionGateway(ctx context.Context, id, gatewayRef, redirectURL string) error
+	GetSessionByID(ctx context.Context, id string) (*PaymentSession, error)
+	GetSessionForUpdate(ctx context.Context, id string) (*PaymentSession, error)
+	GetSessionByGatewayRef(ctx context.Context, provider, gatewayRef string) (*PaymentSession, error)
+	ListSessionsByOrder(ctx context.Context, orderID string) ([]PaymentSession, error)
+	CompleteSession(ctx context.Context, id, status string, transactionID *string) error
+	// RecordCallback stores a verified callback. It returns false if the same
+	// (provider, event key) was already recorded, i.e. the callback is a replay.
+	RecordCallback(ctx context.Context, provider string, cb *PaymentCallback) (bool, error)
+}
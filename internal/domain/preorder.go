@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Pre-order balance request statuses
+//
+//	pending → paid
+//	        → cancelled
+const (
+	PreorderBalancePending   = "pending" // Waiting for the customer (link) or an admin to record payment
+	PreorderBalancePaid      = "paid"    // The order is fully paid
+	PreorderBalanceCancelled = "cancelled"
+)
+
+// IsOpenPreorderStatus returns true if a pre-order in this order status still counts
+// against the product's cap and can be asked for its balance: anything but cancelled,
+// fake, returned or refunded (mirrors CountOpenPreorderUnits).
+func IsOpenPreorderStatus(status string) bool {
+	return status != OrderStatusCancelled &&
+		status != OrderStatusFake &&
+		status != OrderStatusReturned &&
+		status != OrderStatusRefunded
+}
+
+// PreorderRelease is a batch of a pre-order product that has arrived. Releasing it covers
+// every open pre-ordered item of the product, freeing the pre-order cap for the next batch,
+// and asks the customers for the balance of their orders.
+type PreorderRelease struct {
+	ID          string    `json:"id"`
+	ProductID   string    `json:"productId"`
+	ProductName string    `json:"productName,omitempty"`
+	Note        *string   `json:"note,omitempty"`
+	ReleasedBy  *string   `json:"releasedBy,omitempty"`
+	OrderCount  int       `json:"orderCount"` // Orders with items covered by the release
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// PreorderBalanceRequest is the outstanding balance of a released pre-order. It is paid
+// through a signed customer link (gateway) or recorded by an admin; payments move the
+// order's payment status partial_paid → paid once the order is fully paid.
+type PreorderBalanceRequest struct {
+	ID            string     `json:"id"`
+	OrderID       string     `json:"orderId"`
+	OrderNumber   string     `json:"orderNumber,omitempty"`
+	ReleaseID     string     `json:"releaseId"`
+	Amount        Money      `json:"amount"` // Balance due when the request was issued
+	Status        string     `json:"status"`
+	PaidAmount    Money      `json:"paidAmount"`
+	PaymentMethod *string    `json:"paymentMethod,omitempty"` // Gateway provider or the admin's method (cash, bkash…)
+	Reference     *string    `json:"reference,omitempty"`     // Gateway or manual transaction ID
+	PaidAt        *time.Time `json:"paidAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type PreorderRepository interface {
+	// LockProductLimit locks the product row until the transaction ends and returns its
+	// pre-order cap (nil = no cap). Checkouts and releases of a product queue behind it.
+	LockProductLimit(ctx context.Context, productID string) (*int, error)
+	// CountOpenUnits counts the units pre-ordered on active orders not yet covered by a release.
+	CountOpenUnits(ctx context.Context, productID string) (int, error)
+
+	// CreateRelease stores the release and covers the product's open pre-ordered items,
+	// returning the IDs of their orders, oldest first.
+	CreateRelease(ctx context.Context, release *PreorderRelease) ([]string, error)
+	GetRelease(ctx context.Context, id string) (*PreorderRelease, error)
+	ListReleases(ctx context.Context, productID string) ([]PreorderRelease, error)
+
+	CreateBalanceRequest(ctx context.Context, req *PreorderBalanceRequest) error
+	GetBalanceRequest(ctx context.Context, id string) (*PreorderBalanceRequest, error)
+	// GetBalanceRequestForUpdate locks the request row.
+	GetBalanceRequestForUpdate(ctx context.Context, id string) (*PreorderBalanceRequest, error)
+	// GetPendingBalanceRequest returns the order's pending request ("not found" if none).
+	GetPendingBalanceRequest(ctx context.Context, orderID string) (*PreorderBalanceRequest, error)
+	ListBalanceRequests(ctx context.Context, releaseID string) ([]PreorderBalanceRequest, error)
+	// RecordBalancePayment adds a collected amount; settled marks the request paid.
+	RecordBalancePayment(ctx context.Context, id string, amount Money, method string, reference *string, settled bool) error
+	// CancelBalanceRequest cancels a pending request; false if it is no longer pending.
+	CancelBalanceRequest(ctx context.Context, id string) (bool, error)
+}
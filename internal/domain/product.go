@@ -41,8 +41,8 @@ type Product struct {
 	Name            string       `json:"name"`
 	Slug            string       `json:"slug"`
 	Description     string       `json:"description"`
-	BasePrice       float64      `json:"basePrice"`
-	SalePrice       *float64     `json:"salePrice"`
+	BasePrice       Money        `json:"basePrice"`
+	SalePrice       *Money       `json:"salePrice"`
 	StockStatus     string       `json:"stockStatus"`
 	Stock           int          `json:"stock"`
 	IsFeatured      bool         `json:"isFeatured"`
@@ -62,11 +62,13 @@ type Product struct {
 	OGImage         string       `json:"ogImage"`
 
 	// L9 Fields
-	Brand                 string   `json:"brand"`
-	Tags                  []string `json:"tags"`
-	WarrantyInfo          JSONB    `json:"warrantyInfo"`
-	IsPreorder            bool     `json:"isPreorder"`
-	PreorderDepositAmount float64  `json:"preorderDepositAmount"`
+	Brand                 string     `json:"brand"`
+	Tags                  []string   `json:"tags"`
+	WarrantyInfo          JSONB      `json:"warrantyInfo"`
+	IsPreorder            bool       `json:"isPreorder"`
+	PreorderDepositAmount Money      `json:"preorderDepositAmount"`
+	PreorderReleaseDate   *time.Time `json:"preorderReleaseDate,omitempty"` // Expected arrival of the next batch
+	PreorderLimit         *int       `json:"preorderLimit,omitempty"`       // Units that may be pre-ordered before the next release; nil = no cap
 }
 
 type Collection struct {
@@ -93,10 +95,14 @@ type Variant struct {
 	Stock     int    `json:"stock"`
 	SKU       string `json:"sku"` // Optional: Variant specific SKU
 
+	// Stock held by active reservations (orders awaiting payment); Available = Stock - Reserved
+	Reserved  int `json:"reserved"`
+	Available int `json:"available"`
+
 	// L9 Fields
 	Attributes        JSONB    `json:"attributes"`
-	Price             *float64 `json:"price"` // Override base price
-	SalePrice         *float64 `json:"salePrice"`
+	Price             *Money   `json:"price"` // Override base price
+	SalePrice         *Money   `json:"salePrice"`
 	Images            []string `json:"images"`
 	Weight            *float64 `json:"weight"`
 	Dimensions        JSONB    `json:"dimensions"`
@@ -107,10 +113,10 @@ type Variant struct {
 // VariantWithProduct is used for SKU-level inventory listing
 type VariantWithProduct struct {
 	Variant
-	ProductName      string  `json:"productName"`
-	ProductSlug      string  `json:"productSlug"`
-	ProductBasePrice float64 `json:"productBasePrice"`
-	ProductImage     string  `json:"productImage"` // First image from product media
+	ProductName      string `json:"productName"`
+	ProductSlug      string `json:"productSlug"`
+	ProductBasePrice Money  `json:"productBasePrice"`
+	ProductImage     string `json:"productImage"` // First image from product media
 }
 
 // VariantListFilter defines filters for variant listing
@@ -124,12 +130,12 @@ type VariantListFilter struct {
 }
 
 type ProductStats struct {
-	TotalProducts       int64   `json:"totalProducts"`
-	ActiveProducts      int64   `json:"activeProducts"`
-	InactiveProducts    int64   `json:"inactiveProducts"`
-	OutOfStock          int64   `json:"outOfStock"`
-	LowStock            int64   `json:"lowStock"`
-	TotalInventoryValue float64 `json:"totalInventoryValue"`
+	TotalProducts       int64 `json:"totalProducts"`
+	ActiveProducts      int64 `json:"activeProducts"`
+	InactiveProducts    int64 `json:"inactiveProducts"`
+	OutOfStock          int64 `json:"outOfStock"`
+	LowStock            int64 `json:"lowStock"`
+	TotalInventoryValue Money `json:"totalInventoryValue"`
 }
 
 type InventoryLog struct {
@@ -169,6 +175,9 @@ type ProductRepository interface {
 	GetProductBySlug(ctx context.Context, slug string) (*Product, error)
 	GetProductByID(ctx context.Context, id string) (*Product, error)
 	UpdateStock(ctx context.Context, variantID string, quantity int, reason, referenceID string) error
+	// GetOrderNetStockChanges sums the inventory log entries of an order and its
+	// exchanges per variant, keyed by variant ID.
+	GetOrderNetStockChanges(ctx context.Context, orderID string) (map[string]int, error)
 	GetInventoryLogs(ctx context.Context, productID string, limit, offset int) ([]InventoryLog, int64, error)
 	GetVariantList(ctx context.Context, filter VariantListFilter) ([]VariantWithProduct, int64, error)
 
@@ -199,8 +208,8 @@ type Review struct {
 type ProductFilter struct {
 	CategorySlug string
 	Query        string
-	MinPrice     float64
-	MaxPrice     float64
+	MinPrice     Money
+	MaxPrice     Money
 	Sort         string // newest, price_asc, price_desc
 	Limit        int
 	Offset       int
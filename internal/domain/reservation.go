@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Stock reservation statuses
+const (
+	ReservationStatusActive    = "active"    // Held against availability until ExpiresAt
+	ReservationStatusExpired   = "expired"   // Timed out; no longer held but the order may still pay
+	ReservationStatusCommitted = "committed" // Converted into a permanent stock deduction
+	ReservationStatusReleased  = "released"  // Hold dropped (order cancelled) without touching stock
+)
+
+// StockReservation holds variant stock for an order while its payment is being collected.
+// Availability = variant stock - active, unexpired reservations.
+type StockReservation struct {
+	ID        string    `json:"id"`
+	OrderID   string    `json:"orderId"`
+	VariantID string    `json:"variantId"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type StockReservationRepository interface {
+	// Reserve locks the variants of the given items and holds their quantities for ttl.
+	// Fails without holding anything if any item exceeds the available stock.
+	Reserve(ctx context.Context, orderID string, items []OrderItem, ttl time.Duration) error
+	// Renew re-holds an order's active/expired reservations for at least ttl, re-checking
+	// availability for holds that timed out. Returns 0 if the order has nothing to renew.
+	Renew(ctx context.Context, orderID string, ttl time.Duration) (int64, error)
+	ListByOrder(ctx context.Context, orderID string) ([]StockReservation, error)
+	// Commit deducts the order's uncommitted holds from stock (with inventory logs) and marks them committed.
+	Commit(ctx context.Context, orderID, reason string) (int, error)
+	// Release drops the order's uncommitted holds without touching stock.
+	Release(ctx context.Context, orderID string) (int, error)
+	// ExpireDue marks up to limit timed-out active holds as expired and returns them.
+	ExpireDue(ctx context.Context, limit int) ([]StockReservation, error)
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Return (RMA) statuses
+//
+//	requested → approved → received → refunded
+//	          → rejected
+const (
+	ReturnStatusRequested = "requested" // Waiting for an admin decision
+	ReturnStatusApproved  = "approved"  // Customer may send the approved quantities back
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received" // Items are back and restocked
+	ReturnStatusRefunded  = "refunded" // A partial refund was issued for the received items
+)
+
+// Return reason codes
+const (
+	ReturnReasonDamaged        = "damaged"
+	ReturnReasonDefective      = "defective"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonSizeFit        = "size_fit"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonChangedMind    = "changed_mind"
+	ReturnReasonOther          = "other"
+)
+
+var ReturnReasons = []string{
+	ReturnReasonDamaged,
+	ReturnReasonDefective,
+	ReturnReasonWrongItem,
+	ReturnReasonSizeFit,
+	ReturnReasonNotAsDescribed,
+	ReturnReasonChangedMind,
+	ReturnReasonOther,
+}
+
+// IsValidReturnReason returns true for a known return reason code.
+func IsValidReturnReason(reason string) bool {
+	for _, r := range ReturnReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReturnable returns true if the customer may request a return for an order in this status.
+func IsReturnable(orderStatus string) bool {
+	return orderStatus == OrderStatusDelivered || orderStatus == OrderStatusPaid
+}
+
+// ReturnItem is the quantity of one order item in a return request.
+type ReturnItem struct {
+	OrderItemID      string `json:"orderItemId"`
+	Quantity         int    `json:"quantity"`         // Requested by the customer
+	ApprovedQuantity int    `json:"approvedQuantity"` // 0 until approved; 0 after approval means rejected
+	ReceivedQuantity int    `json:"receivedQuantity"` // Only these are restocked and refunded
+}
+
+// OrderReturn is a customer return request (RMA) for some items of an order.
+type OrderReturn struct {
+	ID           string       `json:"id"`
+	OrderID      string       `json:"orderId"`
+	UserID       *string      `json:"userId,omitempty"`
+	Status       string       `json:"status"`
+	Reason       string       `json:"reason"` // ReturnReason* code
+	Note         *string      `json:"note,omitempty"`
+	Photos       []string     `json:"photos"`
+	AdminNote    *string      `json:"adminNote,omitempty"`
+	RefundAmount Money        `json:"refundAmount"`
+	ReviewedBy   *string      `json:"reviewedBy,omitempty"`
+	Items        []ReturnItem `json:"items"`
+	ReceivedAt   *time.Time   `json:"receivedAt,omitempty"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	UpdatedAt    time.Time    `json:"updatedAt"`
+}
+
+// ReturnedQuantities returns, per order item ID, the quantity already claimed by the
+// given returns: requested quantities while pending, approved quantities afterwards.
+// Rejected returns claim nothing.
+func ReturnedQuantities(returns []OrderReturn) map[string]int {
+	claimed := make(map[string]int)
+	for _, ret := range returns {
+		if ret.Status == ReturnStatusRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			if ret.Status == ReturnStatusRequested {
+				claimed[item.OrderItemID] += item.Quantity
+			} else {
+				claimed[item.OrderItemID] += item.ApprovedQuantity
+			}
+		}
+	}
+	return claimed
+}
+
+type ReturnRepository interface {
+	// Create stores the return together with its items.
+	Create(ctx context.Context, ret *OrderReturn) error
+	GetByID(ctx context.Context, id string) (*OrderReturn, error)
+	// GetForUpdate locks the return row; its items are loaded too.
+	GetForUpdate(ctx context.Context, id string) (*OrderReturn, error)
+	ListByOrder(ctx context.Context, orderID string) ([]OrderReturn, error)
+	List(ctx context.Context, status string, limit, offset int) ([]OrderReturn, int64, error)
+	// UpdateStatus changes the status; an empty adminNote or reviewedBy keeps the stored value.
+	UpdateStatus(ctx context.Context, id, status, adminNote, reviewedBy string) error
+	UpdateItem(ctx context.Context, returnID string, item ReturnItem) error
+	// AddRefund adds a refunded amount and marks the return refunded.
+	AddRefund(ctx context.Context, id string, amount Money) error
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Risk signals that can raise (or, for a good track record, lower) an order's score
+const (
+	RiskSignalPhoneFake       = "phone_fake"        // Phone has orders marked fake
+	RiskSignalPhoneReturned   = "phone_returned"    // Phone has returned (refused) parcels
+	RiskSignalPhoneCancelled  = "phone_cancelled"   // Phone has cancelled orders
+	RiskSignalPhoneTrusted    = "phone_trusted"     // Phone/customer has completed orders
+	RiskSignalPhoneVelocity   = "phone_velocity"    // Many recent orders from the phone
+	RiskSignalIPVelocity      = "ip_velocity"       // Many recent orders from the client IP
+	RiskSignalUserVelocity    = "user_velocity"     // Many recent orders from the account
+	RiskSignalPhoneShared     = "phone_shared"      // Phone used by other accounts
+	RiskSignalAddressMismatch = "address_mismatch"  // Phone never ordered to this district before
+	RiskSignalNewPhone        = "new_phone"         // Returning customer with a phone they never used
+	RiskSignalCartValue       = "cart_value"        // Cart far above the customer's usual order
+	RiskSignalFirstOrderValue = "first_order_value" // High-value order without any completed one
+)
+
+// RiskReason is one signal behind an order's risk score.
+type RiskReason struct {
+	Signal string `json:"signal"` // RiskSignal*
+	Points int    `json:"points"` // Negative for signals that lower the score
+	Detail string `json:"detail"`
+}
+
+// RiskAssessment is the score (0-100) of a new order and the reasons for it.
+type RiskAssessment struct {
+	Score   int          `json:"score"`
+	Reasons []RiskReason `json:"reasons"`
+}
+
+// RiskPolicy tunes the risk engine.
+type RiskPolicy struct {
+	HighScore      int           // COD orders scoring at least this must pay an advance deposit
+	Deposit        Money         // Advance deposit of a high-risk COD order; 0 = the shipping fee
+	VelocityWindow time.Duration // Window of the velocity signals
+	VelocityLimit  int           // Orders per phone/IP/account inside the window before it counts
+	FirstOrderHigh Money         // Order value that counts as high without any completed order
+}
+
+// IsHighRisk returns true if the score needs an advance deposit under the policy.
+func (p RiskPolicy) IsHighRisk(score int) bool {
+	return p.HighScore > 0 && score >= p.HighScore
+}
+
+// CustomerRiskHistory is what past orders say about the phone, client IP and account
+// placing a new order. Recent counts cover RiskPolicy.VelocityWindow.
+type CustomerRiskHistory struct {
+	PhoneOrders       int
+	PhoneFake         int
+	PhoneReturned     int
+	PhoneCancelled    int
+	PhoneRecent       int
+	PhoneOtherUsers   int // Other accounts that ordered with the phone
+	PhoneSameDistrict int // Phone's past orders to the new order's district
+	IPRecent          int
+	UserOrders        int
+	UserPhoneOrders   int // Account's past orders with the phone
+	UserRecent        int
+	CompletedOrders   int   // Delivered/paid orders of the account or phone
+	CompletedAvgValue Money // Their average total
+}
+
+// RiskQuery identifies the customer of a new order for OrderRepository.GetCustomerRiskHistory.
+type RiskQuery struct {
+	UserID   string
+	Phone    string // Normalized (utils.NormalizePhone); empty if unknown
+	IP       string
+	District string
+	Since    time.Time // Start of the velocity window
+}
+
+// ScoreOrderRisk scores a new order of orderTotal placed by q's customer from their
+// history. The rules are additive and the score is clamped to 0-100.
+func ScoreOrderRisk(q RiskQuery, h CustomerRiskHistory, orderTotal Money, policy RiskPolicy) RiskAssessment {
+	var reasons []RiskReason
+	add := func(signal string, points int, detail string) {
+		reasons = append(reasons, RiskReason{Signal: signal, Points: points, Detail: detail})
+	}
+
+	// Phone history
+	if h.PhoneFake > 0 {
+		add(RiskSignalPhoneFake, min(50*h.PhoneFake, 80), fmt.Sprintf("Phone has %d order(s) marked fake", h.PhoneFake))
+	}
+	if h.PhoneReturned > 0 {
+		add(RiskSignalPhoneReturned, min(15*h.PhoneReturned, 30), fmt.Sprintf("Phone has %d returned order(s)", h.PhoneReturned))
+	}
+	if h.PhoneCancelled > 0 {
+		add(RiskSignalPhoneCancelled, min(10*h.PhoneCancelled, 30), fmt.Sprintf("Phone has %d cancelled order(s)", h.PhoneCancelled))
+	}
+	if h.CompletedOrders > 0 && h.PhoneFake == 0 {
+		add(RiskSignalPhoneTrusted, -min(5*h.CompletedOrders, 20), fmt.Sprintf("Customer has %d completed order(s)", h.CompletedOrders))
+	}
+
+	// Velocity
+	velocity := func(signal string, recent int, who string) {
+		if policy.VelocityLimit > 0 && recent >= policy.VelocityLimit {
+			extra := recent - policy.VelocityLimit
+			add(signal, min(15+5*extra, 30), fmt.Sprintf("%d order(s) from this %s in the last %s", recent, who, policy.VelocityWindow))
+		}
+	}
+	velocity(RiskSignalPhoneVelocity, h.PhoneRecent, "phone")
+	velocity(RiskSignalIPVelocity, h.IPRecent, "IP address")
+	velocity(RiskSignalUserVelocity, h.UserRecent, "account")
+
+	// Address / phone mismatch
+	if h.PhoneOtherUsers > 0 {
+		add(RiskSignalPhoneShared, min(15*h.PhoneOtherUsers, 30), fmt.Sprintf("Phone was used by %d other account(s)", h.PhoneOtherUsers))
+	}
+	if q.District != "" && h.PhoneOrders > 0 && h.PhoneSameDistrict == 0 {
+		add(RiskSignalAddressMismatch, 10, fmt.Sprintf("Phone never ordered to %s before", q.District))
+	}
+	if q.Phone != "" && h.UserOrders > 0 && h.UserPhoneOrders == 0 {
+		add(RiskSignalNewPhone, 10, "Returning customer ordering with a phone they never used")
+	}
+
+	// Cart value against history
+	switch {
+	case h.CompletedOrders > 0 && h.CompletedAvgValue > 0 && orderTotal > h.CompletedAvgValue.Times(3):
+		add(RiskSignalCartValue, 15, fmt.Sprintf("Order of %s is over 3× the customer's average of %s", orderTotal, h.CompletedAvgValue))
+	case h.CompletedOrders == 0 && policy.FirstOrderHigh > 0 && orderTotal >= policy.FirstOrderHigh:
+		add(RiskSignalFirstOrderValue, 10, fmt.Sprintf("Order of %s without any completed order", orderTotal))
+	}
+
+	score := 0
+	for _, r := range reasons {
+		score += r.Points
+	}
+	if reasons == nil {
+		reasons = []RiskReason{}
+	}
+	return RiskAssessment{Score: max(0, min(score, 100)), Reasons: reasons}
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreOrderRisk(t *testing.T) {
+	policy := RiskPolicy{
+		HighScore:      60,
+		VelocityWindow: 24 * time.Hour,
+		VelocityLimit:  3,
+		FirstOrderHigh: Taka(5000),
+	}
+	tests := []struct {
+		name        string
+		q           RiskQuery
+		h           CustomerRiskHistory
+		total       Money
+		wantScore   int
+		wantSignals []string
+	}{
+		{name: "new customer", total: Taka(1000), wantScore: 0},
+		{
+			name:        "fake order on the phone",
+			h:           CustomerRiskHistory{PhoneOrders: 1, PhoneFake: 1},
+			total:       Taka(1000),
+			wantScore:   50,
+			wantSignals: []string{RiskSignalPhoneFake},
+		},
+		{
+			name:        "fake orders are capped",
+			h:           CustomerRiskHistory{PhoneOrders: 3, PhoneFake: 3},
+			total:       Taka(1000),
+			wantScore:   80,
+			wantSignals: []string{RiskSignalPhoneFake},
+		},
+		{
+			name:        "score is clamped to 100",
+			h:           CustomerRiskHistory{PhoneOrders: 4, PhoneFake: 2, PhoneReturned: 2},
+			total:       Taka(1000),
+			wantScore:   100,
+			wantSignals: []string{RiskSignalPhoneFake, RiskSignalPhoneReturned},
+		},
+		{
+			name:        "trusted customer does not go below 0",
+			h:           CustomerRiskHistory{PhoneOrders: 2, CompletedOrders: 2, CompletedAvgValue: Taka(1000)},
+			total:       Taka(1000),
+			wantScore:   0,
+			wantSignals: []string{RiskSignalPhoneTrusted},
+		},
+		{
+			name:        "a fake order voids the track record",
+			h:           CustomerRiskHistory{PhoneOrders: 3, PhoneFake: 1, CompletedOrders: 2, CompletedAvgValue: Taka(1000)},
+			total:       Taka(1000),
+			wantScore:   50,
+			wantSignals: []string{RiskSignalPhoneFake},
+		},
+		{
+			name:      "velocity below the limit",
+			h:         CustomerRiskHistory{PhoneRecent: 2, IPRecent: 2},
+			total:     Taka(1000),
+			wantScore: 0,
+		},
+		{
+			name:        "velocity at the limit",
+			h:           CustomerRiskHistory{PhoneRecent: 3},
+			total:       Taka(1000),
+			wantScore:   15,
+			wantSignals: []string{RiskSignalPhoneVelocity},
+		},
+		{
+			name:        "velocity is capped",
+			h:           CustomerRiskHistory{IPRecent: 10, UserRecent: 4},
+			total:       Taka(1000),
+			wantScore:   50,
+			wantSignals: []string{RiskSignalIPVelocity, RiskSignalUserVelocity},
+		},
+		{
+			name:        "phone shared and new district",
+			q:           RiskQuery{Phone: "01712345678", District: "Sylhet"},
+			h:           CustomerRiskHistory{PhoneOrders: 2, PhoneOtherUsers: 1},
+			total:       Taka(1000),
+			wantScore:   25,
+			wantSignals: []string{RiskSignalPhoneShared, RiskSignalAddressMismatch},
+		},
+		{
+			name:        "returning customer with a new phone",
+			q:           RiskQuery{Phone: "01712345678"},
+			h:           CustomerRiskHistory{UserOrders: 2},
+			total:       Taka(1000),
+			wantScore:   10,
+			wantSignals: []string{RiskSignalNewPhone},
+		},
+		{
+			name:        "cart far above the average",
+			h:           CustomerRiskHistory{PhoneOrders: 1, CompletedOrders: 1, CompletedAvgValue: Taka(1000)},
+			total:       Taka(3500),
+			wantScore:   10,
+			wantSignals: []string{RiskSignalPhoneTrusted, RiskSignalCartValue},
+		},
+		{
+			name:        "high first order",
+			total:       Taka(5000),
+			wantScore:   10,
+			wantSignals: []string{RiskSignalFirstOrderValue},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreOrderRisk(tt.q, tt.h, tt.total, policy)
+			if got.Score != tt.wantScore {
+				t.Errorf("score = %d, want %d (reasons %+v)", got.Score, tt.wantScore, got.Reasons)
+			}
+			if len(got.Reasons) != len(tt.wantSignals) {
+				t.Fatalf("reasons = %+v, want signals %v", got.Reasons, tt.wantSignals)
+			}
+			for i, signal := range tt.wantSignals {
+				if got.Reasons[i].Signal != signal {
+					t.Errorf("reason %d = %s, want %s", i, got.Reasons[i].Signal, signal)
+				}
+			}
+		})
+	}
+}
+
+func TestRiskPolicyIsHighRisk(t *testing.T) {
+	tests := []struct {
+		highScore, score int
+		want             bool
+	}{
+		{highScore: 60, score: 59, want: false},
+		{highScore: 60, score: 60, want: true},
+		{highScore: 0, score: 100, want: false}, // Disabled
+	}
+	for _, tt := range tests {
+		if got := (RiskPolicy{HighScore: tt.highScore}).IsHighRisk(tt.score); got != tt.want {
+			t.Errorf("IsHighRisk(%d) with HighScore %d = %v, want %v", tt.score, tt.highScore, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import "strings"
+
+// COD settlement row outcomes of a courier statement import
+const (
+	SettlementRowSettled      = "settled"       // Order moved delivered → paid
+	SettlementRowRecorded     = "recorded"      // Parcel payout booked; the order settles once its other parcels are paid
+	SettlementRowMatched      = "matched"       // Would be settled or recorded (dry run)
+	SettlementRowAlreadyPaid  = "already_paid"  // Order or parcel was settled before (re-imported statement)
+	SettlementRowUnmatched    = "unmatched"     // No order with that number or tracking ID
+	SettlementRowOverpaid     = "overpaid"      // Courier paid more than the parcel's or order's outstanding COD
+	SettlementRowUnderpaid    = "underpaid"     // Courier paid less than the parcel's or order's outstanding COD
+	SettlementRowNotDelivered = "not_delivered" // Order or parcel is not in delivered state
+	SettlementRowDuplicate    = "duplicate"     // Order or parcel already appears earlier in the statement
+	SettlementRowInvalid      = "invalid"       // Row could not be read
+	SettlementRowFailed       = "failed"        // Settlement was attempted and rejected
+)
+
+// SettlementColumnMapping describes the layout of a courier's COD settlement statement.
+// Each field lists the header names that may hold the value, matched case- and
+// space-insensitively; the first present one wins. Mappings for the integrated couriers
+// live in internal/infrastructure/courier; admins may send their own for anything else.
+type SettlementColumnMapping struct {
+	Name        string   `json:"name"`
+	Courier     string   `json:"courier,omitempty"` // Courier* constant; restricts tracking ID matches to its shipments
+	OrderNumber []string `json:"orderNumber"`       // Merchant invoice / order reference
+	TrackingID  []string `json:"trackingId"`        // Consignment ID or tracking code
+	Amount      []string `json:"amount"`            // Cash collected from the customer
+	Charge      []string `json:"charge,omitempty"`  // Courier fee deducted from the payout (informational)
+}
+
+// Columns resolves the mapping against a statement's header row. It returns the column
+// index of each field (-1 if absent) and false when neither an order reference column
+// nor an amount column is present.
+func (m SettlementColumnMapping) Columns(header []string) (orderNumber, trackingID, amount, charge int, ok bool) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeHeader(h)
+		if _, seen := index[key]; !seen {
+			index[key] = i
+		}
+	}
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, found := index[normalizeHeader(name)]; found {
+				return i
+			}
+		}
+		return -1
+	}
+	orderNumber, trackingID = find(m.OrderNumber), find(m.TrackingID)
+	amount, charge = find(m.Amount), find(m.Charge)
+	ok = (orderNumber >= 0 || trackingID >= 0) && amount >= 0
+	return
+}
+
+func normalizeHeader(h string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimPrefix(h, "\uFEFF"))), " ")
+}
+
+// CODRemittance is a courier's payout of the cash it collected for one order or parcel.
+type CODRemittance struct {
+	Courier   string // Courier* constant or statement name
+	Reference string // Consignment ID of the parcel, else the order number the statement used
+	Amount    Money
+	Source    string // Statement file name
+}
+
+// SettlementRow is one statement row and what the import did with it.
+type SettlementRow struct {
+	Row         int     `json:"row"` // 1-based line in the statement, header included
+	OrderNumber string  `json:"orderNumber,omitempty"`
+	TrackingID  string  `json:"trackingId,omitempty"`
+	OrderID     *string `json:"orderId,omitempty"`
+	ShipmentID  *string `json:"shipmentId,omitempty"` // Parcel the row paid for, when it names one
+	Amount      Money   `json:"amount"`               // Collected according to the courier
+	Charge      Money   `json:"charge"`               // Courier fee
+	Expected    Money   `json:"expected"`             // COD of the parcel, or outstanding COD of the order
+	Difference  Money   `json:"difference"`           // Amount − Expected
+	Status      string  `json:"status"`               // SettlementRow*
+	Message     string  `json:"message,omitempty"`
+}
+
+// SettlementReport reconciles a courier statement against our orders. Exceptions holds
+// the rows that need an admin: unmatched, over/under-paid, undelivered, unreadable or
+// failed ones.
+type SettlementReport struct {
+	Mapping        string          `json:"mapping"`
+	FileName       string          `json:"fileName"`
+	DryRun         bool            `json:"dryRun"`
+	Rows           []SettlementRow `json:"rows"`
+	Exceptions     []SettlementRow `json:"exceptions"`
+	Counts         map[string]int  `json:"counts"` // Rows per status
+	StatementTotal Money           `json:"statementTotal"`
+	ChargeTotal    Money           `json:"chargeTotal"`
+	SettledTotal   Money           `json:"settledTotal"` // Settled (or, in a dry run, settleable) amount
+}
+
+// IsSettlementException reports whether a row outcome needs an admin's attention.
+func IsSettlementException(status string) bool {
+	switch status {
+	case SettlementRowSettled, SettlementRowRecorded, SettlementRowMatched, SettlementRowAlreadyPaid:
+		return false
+	}
+	return true
+}
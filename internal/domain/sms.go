@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SMSProvider delivers a single text message (a local SMS gateway in production,
+// log-only in development).
+type SMSProvider interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// OrderOTP is a one-time code sent by SMS to confirm the phone number of a COD order.
+// Only the hash of the code is stored.
+type OrderOTP struct {
+	ID          string     `json:"id"`
+	OrderID     string     `json:"orderId"`
+	Phone       string     `json:"phone"`
+	CodeHash    string     `json:"-"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"maxAttempts"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	Expired     bool       `json:"expired"`
+	VerifiedAt  *time.Time `json:"verifiedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+type OrderOTPRepository interface {
+	// Create stores a new code for the order, valid for ttl.
+	Create(ctx context.Context, otp *OrderOTP, ttl time.Duration) error
+	// GetLatest returns the most recent code of the order, or nil if none was sent.
+	GetLatest(ctx context.Context, orderID string) (*OrderOTP, error)
+	// SendStats returns how many codes the order was sent and how long ago the last one was.
+	SendStats(ctx context.Context, orderID string) (sent int, sinceLast time.Duration, err error)
+	// UseAttempt spends one guess on the code; ok is false when it is verified,
+	// expired or out of attempts.
+	UseAttempt(ctx context.Context, id string) (attempts int, ok bool, err error)
+	// MarkVerified marks the code used; false if it was already verified.
+	MarkVerified(ctx context.Context, id string) (bool, error)
+}
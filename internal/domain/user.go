@@ -9,6 +9,13 @@ type ContextKey string
 
 const UserContextKey ContextKey = "user"
 
+// User roles
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+	RoleGuest    = "guest" // Created per guest checkout; never signed into
+)
+
 type User struct {
 	ID        string    `json:"id"` // UUID
 	Email     string    `json:"email"`
@@ -0,0 +1,39 @@
+package courier
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// doJSON sends req and decodes a JSON response. Courier APIs explain rejections in
+// the body, so it is included in the error for non-2xx responses.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// secretMatches compares a webhook credential in constant time. An unset secret
+// never matches, so unconfigured webhooks are rejected.
+func secretMatches(got, want string) bool {
+	return want != "" && hmac.Equal([]byte(got), []byte(want))
+}
+
+// payloadOf copies decoded webhook fields into an audit payload.
+func payloadOf(body []byte) map[string]interface{} {
+	payload := map[string]interface{}{}
+	json.Unmarshal(body, &payload)
+	return payload
+}
@@ -0,0 +1,201 @@
+package courier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+// PathaoCourier implements domain.CourierProvider for the Pathao Courier merchant API.
+// Webhooks carry the secret configured for the webhook in the Pathao merchant panel.
+type PathaoCourier struct {
+	baseURL       string
+	clientID      string
+	clientSecret  string
+	username      string
+	password      string
+	storeID       int
+	webhookSecret string
+	httpClient    *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewPathaoCourier creates a Pathao adapter. Returns nil if credentials are missing.
+func NewPathaoCourier(baseURL, clientID, clientSecret, username, password string, storeID int, webhookSecret string) *PathaoCourier {
+	if clientID == "" || clientSecret == "" || username == "" || password == "" || storeID == 0 {
+		return nil
+	}
+	return &PathaoCourier{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		username:      username,
+		password:      password,
+		storeID:       storeID,
+		webhookSecret: webhookSecret,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c *PathaoCourier) Provider() string {
+	return domain.CourierPathao
+}
+
+type pathaoTokenResp struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// issueToken returns a cached access token, refreshing it shortly before expiry.
+func (c *PathaoCourier) issueToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"client_id":     c.clientID,
+		"client_secret": c.clientSecret,
+		"grant_type":    "password",
+		"username":      c.username,
+		"password":      c.password,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/aladdin/api/v1/issue-token", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp pathaoTokenResp
+	if err := doJSON(c.httpClient, req, &resp); err != nil {
+		return "", fmt.Errorf("pathao: token request failed: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", fmt.Errorf("pathao: token request rejected")
+	}
+
+	c.token = resp.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Hour)
+	return c.token, nil
+}
+
+type pathaoCreateResp struct {
+	Message string `json:"message"`
+	Data    struct {
+		ConsignmentID string `json:"consignment_id"`
+		OrderStatus   string `json:"order_status"`
+	} `json:"data"`
+}
+
+func (c *PathaoCourier) Book(ctx context.Context, req domain.CourierBookingRequest) (*domain.CourierBooking, error) {
+	token, err := c.issueToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	weight := req.WeightKg
+	if weight < 0.5 {
+		weight = 0.5 // Pathao's minimum chargeable weight
+	}
+	payload := map[string]interface{}{
+		"store_id":            c.storeID,
+		"merchant_order_id":   req.Reference,
+		"recipient_name":      req.RecipientName,
+		"recipient_phone":     req.RecipientPhone,
+		"recipient_address":   req.Address,
+		"delivery_type":       48, // Normal delivery
+		"item_type":           2,  // Parcel
+		"item_quantity":       req.ItemCount,
+		"item_weight":         weight,
+		"amount_to_collect":   req.CODAmount.WholeTaka(),
+		"special_instruction": req.Note,
+	}
+	// City/zone are resolved from the address when not given
+	if req.CityID > 0 {
+		payload["recipient_city"] = req.CityID
+	}
+	if req.AreaID > 0 {
+		payload["recipient_zone"] = req.AreaID
+	}
+
+	body, _ := json.Marshal(payload)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/aladdin/api/v1/orders", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	var resp pathaoCreateResp
+	if err := doJSON(c.httpClient, httpReq, &resp); err != nil {
+		return nil, fmt.Errorf("pathao: booking request failed: %w", err)
+	}
+	if resp.Data.ConsignmentID == "" {
+		return nil, fmt.Errorf("pathao: booking rejected: %s", resp.Message)
+	}
+
+	q := url.Values{"consignment_id": {resp.Data.ConsignmentID}, "phone": {req.RecipientPhone}}
+	return &domain.CourierBooking{
+		ConsignmentID: resp.Data.ConsignmentID,
+		TrackingCode:  resp.Data.ConsignmentID,
+		TrackingURL:   "https://merchant.pathao.com/tracking?" + q.Encode(),
+		CourierStatus: resp.Data.OrderStatus,
+	}, nil
+}
+
+type pathaoWebhook struct {
+	ConsignmentID string `json:"consignment_id"`
+	Event         string `json:"event"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// pathaoEvents maps Pathao webhook events; pickup requests, failed attempts and holds
+// are informational.
+var pathaoEvents = map[string]string{
+	"order.picked":                    domain.ShipmentStatusPickedUp,
+	"order.at-the-sorting-hub":        domain.ShipmentStatusInTransit,
+	"order.in-transit":                domain.ShipmentStatusInTransit,
+	"order.received-at-last-mile-hub": domain.ShipmentStatusInTransit,
+	"order.assigned-for-delivery":     domain.ShipmentStatusInTransit,
+	"order.delivered":                 domain.ShipmentStatusDelivered,
+	"order.partial-delivery":          domain.ShipmentStatusDelivered,
+	"order.returned":                  domain.ShipmentStatusReturned,
+	"order.paid-return":               domain.ShipmentStatusReturned,
+	"order.pickup-cancelled":          domain.ShipmentStatusCancelled,
+}
+
+func (c *PathaoCourier) ParseWebhook(ctx context.Context, hook domain.CourierWebhook) (*domain.CourierEvent, error) {
+	if !secretMatches(hook.Headers["X-Pathao-Signature"], c.webhookSecret) {
+		return nil, fmt.Errorf("pathao: invalid webhook signature")
+	}
+
+	var payload pathaoWebhook
+	if err := json.Unmarshal(hook.Body, &payload); err != nil {
+		return nil, fmt.Errorf("pathao: malformed webhook")
+	}
+	if payload.ConsignmentID == "" {
+		return nil, nil // Integration check when the webhook is registered
+	}
+
+	return &domain.CourierEvent{
+		ConsignmentID: payload.ConsignmentID,
+		Status:        pathaoEvents[payload.Event],
+		CourierStatus: payload.Event,
+		EventKey:      payload.ConsignmentID + ":" + payload.Event + ":" + payload.UpdatedAt,
+		Payload:       payloadOf(hook.Body),
+	}, nil
+}
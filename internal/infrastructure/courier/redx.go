@@ -0,0 +1,174 @@
+package courier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+// RedXCourier implements domain.CourierProvider for the RedX open API.
+// RedX webhooks are unsigned, so the registered callback URL carries ?token=<webhookToken>.
+type RedXCourier struct {
+	baseURL      string
+	apiToken     string
+	webhookToken string
+	httpClient   *http.Client
+}
+
+// NewRedXCourier creates a RedX adapter. Returns nil if the API token is missing.
+func NewRedXCourier(baseURL, apiToken, webhookToken string) *RedXCourier {
+	if apiToken == "" {
+		return nil
+	}
+	return &RedXCourier{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		apiToken:     apiToken,
+		webhookToken: webhookToken,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c *RedXCourier) Provider() string {
+	return domain.CourierRedX
+}
+
+func (c *RedXCourier) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-ACCESS-TOKEN", "Bearer "+c.apiToken)
+	return req, nil
+}
+
+type redxAreasResp struct {
+	Areas []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"areas"`
+}
+
+// resolveArea finds the RedX delivery area for the address when no area ID was given.
+func (c *RedXCourier) resolveArea(ctx context.Context, req domain.CourierBookingRequest) (int, string, error) {
+	if req.AreaID > 0 {
+		return req.AreaID, fallback(req.Area, req.District), nil
+	}
+	if req.Area == "" || req.District == "" {
+		return 0, "", fmt.Errorf("redx: delivery area is required (area name and district, or an area ID)")
+	}
+
+	httpReq, err := c.newRequest(ctx, http.MethodGet, "/areas?"+url.Values{"district_name": {req.District}}.Encode(), nil)
+	if err != nil {
+		return 0, "", err
+	}
+	var resp redxAreasResp
+	if err := doJSON(c.httpClient, httpReq, &resp); err != nil {
+		return 0, "", fmt.Errorf("redx: area lookup failed: %w", err)
+	}
+	for _, area := range resp.Areas {
+		if strings.EqualFold(area.Name, req.Area) {
+			return area.ID, area.Name, nil
+		}
+	}
+	return 0, "", fmt.Errorf("redx: delivery area %q not found in %s; pass an area ID", req.Area, req.District)
+}
+
+type redxCreateResp struct {
+	TrackingID string `json:"tracking_id"`
+	Message    string `json:"message"`
+}
+
+func (c *RedXCourier) Book(ctx context.Context, req domain.CourierBookingRequest) (*domain.CourierBooking, error) {
+	areaID, areaName, err := c.resolveArea(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	weightGrams := int(math.Round(req.WeightKg * 1000))
+	if weightGrams <= 0 {
+		weightGrams = 500
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"customer_name":          req.RecipientName,
+		"customer_phone":         req.RecipientPhone,
+		"delivery_area":          areaName,
+		"delivery_area_id":       areaID,
+		"customer_address":       req.Address,
+		"merchant_invoice_id":    req.Reference,
+		"cash_collection_amount": strconv.FormatInt(req.CODAmount.WholeTaka(), 10),
+		"parcel_weight":          weightGrams,
+		"instruction":            req.Note,
+		"value":                  req.CODAmount.WholeTaka(),
+	})
+	httpReq, err := c.newRequest(ctx, http.MethodPost, "/parcel", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp redxCreateResp
+	if err := doJSON(c.httpClient, httpReq, &resp); err != nil {
+		return nil, fmt.Errorf("redx: booking request failed: %w", err)
+	}
+	if resp.TrackingID == "" {
+		return nil, fmt.Errorf("redx: booking rejected: %s", resp.Message)
+	}
+
+	return &domain.CourierBooking{
+		ConsignmentID: resp.TrackingID,
+		TrackingCode:  resp.TrackingID,
+		TrackingURL:   "https://redx.com.bd/track-parcel/?trackingId=" + url.QueryEscape(resp.TrackingID),
+		CourierStatus: "pickup-pending",
+	}, nil
+}
+
+type redxWebhook struct {
+	TrackingNumber string `json:"tracking_number"`
+	Status         string `json:"status"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// redxStatuses maps RedX parcel statuses; holds and return-in-progress are informational.
+var redxStatuses = map[string]string{
+	"ready-for-delivery":   domain.ShipmentStatusInTransit,
+	"delivery-in-progress": domain.ShipmentStatusInTransit,
+	"agent-area-change":    domain.ShipmentStatusInTransit,
+	"delivered":            domain.ShipmentStatusDelivered,
+	"returned":             domain.ShipmentStatusReturned,
+}
+
+func (c *RedXCourier) ParseWebhook(ctx context.Context, hook domain.CourierWebhook) (*domain.CourierEvent, error) {
+	if !secretMatches(hook.Query["token"], c.webhookToken) {
+		return nil, fmt.Errorf("redx: invalid webhook token")
+	}
+
+	var payload redxWebhook
+	if err := json.Unmarshal(hook.Body, &payload); err != nil || payload.TrackingNumber == "" {
+		return nil, fmt.Errorf("redx: malformed webhook")
+	}
+
+	return &domain.CourierEvent{
+		ConsignmentID: payload.TrackingNumber,
+		Status:        redxStatuses[payload.Status],
+		CourierStatus: payload.Status,
+		EventKey:      payload.TrackingNumber + ":" + payload.Status + ":" + payload.Timestamp,
+		Payload:       payloadOf(hook.Body),
+	}, nil
+}
+
+func fallback(v, def string) string {
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,42 @@
+package courier
+
+import "valancis-backend/internal/domain"
+
+// SettlementMappings returns the column layouts of the COD payout statements the
+// couriers' merchant panels export, plus a generic layout for hand-made sheets.
+// Header names vary between exports, so each field lists the ones seen in the wild.
+func SettlementMappings() []domain.SettlementColumnMapping {
+	return []domain.SettlementColumnMapping{
+		{
+			Name:        domain.CourierPathao,
+			Courier:     domain.CourierPathao,
+			OrderNumber: []string{"Merchant Order ID", "Merchant Order Id", "Order ID"},
+			TrackingID:  []string{"Consignment ID", "Consignment Id"},
+			Amount:      []string{"Collected Amount", "Amount Collected", "COD Amount"},
+			Charge:      []string{"Delivery Fee", "Total Fee", "COD Fee"},
+		},
+		{
+			Name:        domain.CourierSteadfast,
+			Courier:     domain.CourierSteadfast,
+			OrderNumber: []string{"Invoice", "Invoice ID"},
+			TrackingID:  []string{"Tracking Code", "Consignment ID", "CID"},
+			Amount:      []string{"COD Amount", "Collected Amount", "Cod Amount"},
+			Charge:      []string{"Delivery Charge", "Delivery Fee"},
+		},
+		{
+			Name:        domain.CourierRedX,
+			Courier:     domain.CourierRedX,
+			OrderNumber: []string{"Merchant Invoice ID", "Invoice Number", "Invoice"},
+			TrackingID:  []string{"Tracking ID", "Tracking Id", "Parcel ID"},
+			Amount:      []string{"Cash Collection", "Collected Amount", "Cash Collected"},
+			Charge:      []string{"Delivery Charge", "Charge"},
+		},
+		{
+			Name:        "generic",
+			OrderNumber: []string{"Order Number", "Order No", "Order", "Invoice"},
+			TrackingID:  []string{"Tracking ID", "Tracking Code", "Tracking", "Consignment ID"},
+			Amount:      []string{"Amount", "Collected Amount", "COD Amount", "Collected"},
+			Charge:      []string{"Charge", "Fee", "Delivery Charge"},
+		},
+	}
+}
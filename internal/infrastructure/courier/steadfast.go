@@ -0,0 +1,127 @@
+package courier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+// SteadfastCourier implements domain.CourierProvider for Steadfast (Packzy API v1).
+// Webhooks carry the bearer token configured in the Steadfast merchant panel.
+type SteadfastCourier struct {
+	baseURL      string
+	apiKey       string
+	secretKey    string
+	webhookToken string
+	httpClient   *http.Client
+}
+
+// NewSteadfastCourier creates a Steadfast adapter. Returns nil if credentials are missing.
+func NewSteadfastCourier(baseURL, apiKey, secretKey, webhookToken string) *SteadfastCourier {
+	if apiKey == "" || secretKey == "" {
+		return nil
+	}
+	return &SteadfastCourier{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		apiKey:       apiKey,
+		secretKey:    secretKey,
+		webhookToken: webhookToken,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c *SteadfastCourier) Provider() string {
+	return domain.CourierSteadfast
+}
+
+type steadfastCreateResp struct {
+	Status      int    `json:"status"`
+	Message     string `json:"message"`
+	Consignment struct {
+		ConsignmentID json.Number `json:"consignment_id"`
+		TrackingCode  string      `json:"tracking_code"`
+		Status        string      `json:"status"`
+	} `json:"consignment"`
+}
+
+func (c *SteadfastCourier) Book(ctx context.Context, req domain.CourierBookingRequest) (*domain.CourierBooking, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"invoice":           req.Reference,
+		"recipient_name":    req.RecipientName,
+		"recipient_phone":   req.RecipientPhone,
+		"recipient_address": req.Address,
+		"cod_amount":        req.CODAmount.WholeTaka(),
+		"note":              req.Note,
+	})
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/create_order", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Api-Key", c.apiKey)
+	httpReq.Header.Set("Secret-Key", c.secretKey)
+
+	var resp steadfastCreateResp
+	if err := doJSON(c.httpClient, httpReq, &resp); err != nil {
+		return nil, fmt.Errorf("steadfast: booking request failed: %w", err)
+	}
+	if resp.Status != http.StatusOK || resp.Consignment.ConsignmentID == "" {
+		return nil, fmt.Errorf("steadfast: booking rejected: %s", resp.Message)
+	}
+
+	return &domain.CourierBooking{
+		ConsignmentID: resp.Consignment.ConsignmentID.String(),
+		TrackingCode:  resp.Consignment.TrackingCode,
+		TrackingURL:   "https://steadfast.com.bd/t/" + resp.Consignment.TrackingCode,
+		CourierStatus: resp.Consignment.Status,
+	}, nil
+}
+
+type steadfastWebhook struct {
+	NotificationType string      `json:"notification_type"` // delivery_status, tracking_update
+	ConsignmentID    json.Number `json:"consignment_id"`
+	Status           string      `json:"status"`
+	TrackingMessage  string      `json:"tracking_message"`
+	UpdatedAt        string      `json:"updated_at"`
+}
+
+// steadfastStatuses maps Steadfast delivery statuses; the "*_approval_pending",
+// hold and in_review statuses are informational.
+var steadfastStatuses = map[string]string{
+	"delivered":         domain.ShipmentStatusDelivered,
+	"partial_delivered": domain.ShipmentStatusDelivered,
+	"cancelled":         domain.ShipmentStatusCancelled,
+}
+
+func (c *SteadfastCourier) ParseWebhook(ctx context.Context, hook domain.CourierWebhook) (*domain.CourierEvent, error) {
+	if !secretMatches(strings.TrimPrefix(hook.Headers["Authorization"], "Bearer "), c.webhookToken) {
+		return nil, fmt.Errorf("steadfast: invalid webhook token")
+	}
+
+	var payload steadfastWebhook
+	if err := json.Unmarshal(hook.Body, &payload); err != nil || payload.ConsignmentID == "" {
+		return nil, fmt.Errorf("steadfast: malformed webhook")
+	}
+
+	ev := &domain.CourierEvent{
+		ConsignmentID: payload.ConsignmentID.String(),
+		CourierStatus: payload.Status,
+		Payload:       payloadOf(hook.Body),
+	}
+	if payload.NotificationType == "tracking_update" {
+		// Tracking updates only start once the parcel is with the courier
+		ev.Status = domain.ShipmentStatusInTransit
+		ev.CourierStatus = "tracking_update"
+	} else {
+		ev.Status = steadfastStatuses[payload.Status]
+	}
+	ev.EventKey = strings.Join([]string{ev.ConsignmentID, payload.NotificationType, ev.CourierStatus, payload.UpdatedAt}, ":")
+	return ev, nil
+}
@@ -0,0 +1,20 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"valancis-backend/internal/domain"
+)
+
+// LogMailer only logs emails instead of sending them (development, or SMTP not configured).
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg domain.EmailMessage) error {
+	slog.Info("Mail: email not sent (log-only mailer)", "to", msg.To, "subject", msg.Subject)
+	slog.Debug("Mail: email body", "to", msg.To, "text", msg.TextBody)
+	return nil
+}
@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"valancis-backend/internal/domain"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(htmltemplate.FuncMap{"money": money}).ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(texttemplate.FuncMap{"money": money}).ParseFS(templateFS, "templates/*.txt"))
+)
+
+// OrderEmail is the data of an order lifecycle email.
+type OrderEmail struct {
+	Template     string // domain.EmailOrder*
+	Locale       string // domain.LocaleEnglish / domain.LocaleBangla
+	CustomerName string
+	OrderRef     string
+	Items        []OrderEmailItem
+	ShippingFee  domain.Money
+	Discount     domain.Money
+	Total        domain.Money
+	RefundAmount domain.Money // Refund emails only
+	TrackingURL  string
+	ConfirmURL   string       // Draft order emails only: the pay/confirm link
+	BalanceDue   domain.Money // Pre-order release emails only: the outstanding balance
+	PayURL       string       // Pre-order release emails only: the balance pay link
+}
+
+type OrderEmailItem struct {
+	Name     string
+	Quantity int
+	Price    domain.Money
+}
+
+// eventText is the per-event copy; Subject takes the order reference.
+type eventText struct {
+	Subject string
+	Heading string
+	Body    string
+}
+
+// messages is the copy of one locale.
+type messages struct {
+	Greeting      string
+	OrderLabel    string
+	ItemLabel     string
+	QtyLabel      string
+	PriceLabel    string
+	ShippingLabel string
+	DiscountLabel string
+	TotalLabel    string
+	RefundLabel   string
+	TrackLabel    string
+	ConfirmLabel  string
+	BalanceLabel  string
+	PayLabel      string
+	Footer        string
+	Events        map[string]eventText
+}
+
+var locales = map[string]messages{
+	domain.LocaleEnglish: {
+		Greeting:      "Hi",
+		OrderLabel:    "Order",
+		ItemLabel:     "Item",
+		QtyLabel:      "Qty",
+		PriceLabel:    "Price",
+		ShippingLabel: "Shipping",
+		DiscountLabel: "Discount",
+		TotalLabel:    "Total",
+		RefundLabel:   "Refunded",
+		TrackLabel:    "Track your order",
+		ConfirmLabel:  "Review and confirm",
+		BalanceLabel:  "Balance due",
+		PayLabel:      "Pay the balance",
+		Footer:        "Thank you for shopping with Valancis.",
+		Events: map[string]eventText{
+			domain.EmailOrderPlaced:      {"Order received — %s", "We've received your order", "Thank you for your order. We will confirm it shortly."},
+			domain.EmailOrderConfirmed:   {"Order confirmed — %s", "Your order is confirmed", "Your order has been confirmed and is being prepared."},
+			domain.EmailOrderShipped:     {"Your order is on its way — %s", "Your order has shipped", "Your order has been handed to our delivery partner."},
+			domain.EmailOrderDelivered:   {"Order delivered — %s", "Your order has been delivered", "Your order has been delivered. We hope you love it!"},
+			domain.EmailOrderRefunded:    {"Refund issued — %s", "Your refund is on its way", "We have issued a refund for your order."},
+			domain.EmailDraftOrder:       {"Your order is ready to confirm — %s", "Your order is ready", "We have prepared your order. Please review it and confirm or pay using the link below."},
+			domain.EmailPreorderReleased: {"Your pre-order has arrived — %s", "Your pre-order has arrived", "Good news: the item you pre-ordered is in stock and your order is being prepared. If a balance is due, please pay it using the link below so we can ship your order."},
+		},
+	},
+	domain.LocaleBangla: {
+		Greeting:      "প্রিয়",
+		OrderLabel:    "অর্ডার",
+		ItemLabel:     "পণ্য",
+		QtyLabel:      "পরিমাণ",
+		PriceLabel:    "মূল্য",
+		ShippingLabel: "ডেলিভারি চার্জ",
+		DiscountLabel: "ছাড়",
+		TotalLabel:    "মোট",
+		RefundLabel:   "রিফান্ড",
+		TrackLabel:    "আপনার অর্ডার ট্র্যাক করুন",
+		ConfirmLabel:  "দেখুন ও নিশ্চিত করুন",
+		BalanceLabel:  "বকেয়া",
+		PayLabel:      "বকেয়া পরিশোধ করুন",
+		Footer:        "Valancis-এ কেনাকাটার জন্য ধন্যবাদ।",
+		Events: map[string]eventText{
+			domain.EmailOrderPlaced:      {"অর্ডার গ্রহণ করা হয়েছে — %s", "আমরা আপনার অর্ডার পেয়েছি", "আপনার অর্ডারের জন্য ধন্যবাদ। আমরা শীঘ্রই অর্ডারটি নিশ্চিত করব।"},
+			domain.EmailOrderConfirmed:   {"অর্ডার নিশ্চিত হয়েছে — %s", "আপনার অর্ডার নিশ্চিত হয়েছে", "আপনার অর্ডারটি নিশ্চিত হয়েছে এবং প্রস্তুত করা হচ্ছে।"},
+			domain.EmailOrderShipped:     {"আপনার অর্ডার পাঠানো হয়েছে — %s", "আপনার অর্ডার পাঠানো হয়েছে", "আপনার অর্ডারটি ডেলিভারি পার্টনারের কাছে হস্তান্তর করা হয়েছে।"},
+			domain.EmailOrderDelivered:   {"অর্ডার ডেলিভারি সম্পন্ন — %s", "আপনার অর্ডার ডেলিভারি হয়েছে", "আপনার অর্ডারটি ডেলিভারি করা হয়েছে। আশা করি আপনার পছন্দ হবে!"},
+			domain.EmailOrderRefunded:    {"রিফান্ড প্রদান করা হয়েছে — %s", "আপনার রিফান্ড প্রক্রিয়াধীন", "আপনার অর্ডারের জন্য রিফান্ড প্রদান করা হয়েছে।"},
+			domain.EmailDraftOrder:       {"আপনার অর্ডার নিশ্চিত করুন — %s", "আপনার অর্ডার প্রস্তুত", "আমরা আপনার অর্ডারটি প্রস্তুত করেছি। নিচের লিংক থেকে অর্ডারটি দেখে নিশ্চিত করুন বা পেমেন্ট করুন।"},
+			domain.EmailPreorderReleased: {"আপনার প্রি-অর্ডার পৌঁছেছে — %s", "আপনার প্রি-অর্ডার পৌঁছেছে", "সুখবর: আপনার প্রি-অর্ডার করা পণ্যটি স্টকে এসেছে এবং অর্ডারটি প্রস্তুত করা হচ্ছে। বকেয়া থাকলে অর্ডারটি পাঠানোর জন্য নিচের লিংক থেকে পরিশোধ করুন।"},
+		},
+	},
+}
+
+// RenderOrderEmail renders the subject, HTML and plain-text bodies of an order email.
+// Unknown locales fall back to English.
+func RenderOrderEmail(email OrderEmail) (subject, htmlBody, textBody string, err error) {
+	msgs := locales[domain.NormalizeLocale(email.Locale)]
+	event, ok := msgs.Events[email.Template]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template: %s", email.Template)
+	}
+
+	view := struct {
+		M       messages
+		E       eventText
+		Email   OrderEmail
+		Subject string
+	}{
+		M:       msgs,
+		E:       event,
+		Email:   email,
+		Subject: fmt.Sprintf(event.Subject, email.OrderRef),
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, "order.html", view); err != nil {
+		return "", "", "", fmt.Errorf("failed to render html email: %w", err)
+	}
+	if err := textTemplates.ExecuteTemplate(&text, "order.txt", view); err != nil {
+		return "", "", "", fmt.Errorf("failed to render text email: %w", err)
+	}
+	return view.Subject, html.String(), text.String(), nil
+}
+
+func money(amount domain.Money) string {
+	return "৳" + amount.String()
+}
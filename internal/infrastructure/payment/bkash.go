@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+// BKashGateway implements domain.PaymentGateway for bKash Tokenized Checkout.
+// bKash callbacks are plain browser redirects, so they are authenticated by
+// executing/querying the payment server-side rather than trusting the query string.
+type BKashGateway struct {
+	baseURL    string
+	appKey     string
+	appSecret  string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewBKashGateway creates a bKash adapter. Returns nil if credentials are missing.
+func NewBKashGateway(baseURL, appKey, appSecret, username, password string) *BKashGateway {
+	if appKey == "" || appSecret == "" || username == "" || password == "" {
+		return nil
+	}
+	return &BKashGateway{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		appKey:    appKey,
+		appSecret: appSecret,
+		username:  username,
+		password:  password,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second, // bKash recommends a generous timeout on execute
+		},
+	}
+}
+
+func (g *BKashGateway) Provider() string {
+	return domain.PaymentMethodBKash
+}
+
+type bkashTokenResp struct {
+	IDToken       string `json:"id_token"`
+	ExpiresIn     int    `json:"expires_in"`
+	StatusCode    string `json:"statusCode"`
+	StatusMessage string `json:"statusMessage"`
+}
+
+type bkashPaymentResp struct {
+	StatusCode            string `json:"statusCode"`
+	StatusMessage         string `json:"statusMessage"`
+	PaymentID             string `json:"paymentID"`
+	BKashURL              string `json:"bkashURL"`
+	TrxID                 string `json:"trxID"`
+	TransactionStatus     string `json:"transactionStatus"`
+	Amount                string `json:"amount"`
+	MerchantInvoiceNumber string `json:"merchantInvoiceNumber"`
+}
+
+// grantToken returns a cached id_token, refreshing it shortly before expiry.
+func (g *BKashGateway) grantToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.token != "" && time.Now().Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"app_key":    g.appKey,
+		"app_secret": g.appSecret,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/tokenized/checkout/token/grant", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("username", g.username)
+	req.Header.Set("password", g.password)
+
+	var resp bkashTokenResp
+	if err := doJSON(g.httpClient, req, &resp); err != nil {
+		return "", fmt.Errorf("bkash: token grant failed: %w", err)
+	}
+	if resp.IDToken == "" {
+		return "", fmt.Errorf("bkash: token grant rejected: %s", resp.StatusMessage)
+	}
+
+	g.token = resp.IDToken
+	g.tokenExpiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
+
+func (g *BKashGateway) call(ctx context.Context, path string, payload interface{}) (*bkashPaymentResp, error) {
+	token, err := g.grantToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", token)
+	req.Header.Set("X-APP-Key", g.appKey)
+
+	var resp bkashPaymentResp
+	if err := doJSON(g.httpClient, req, &resp); err != nil {
+		return nil, fmt.Errorf("bkash: %s failed: %w", path, err)
+	}
+	return &resp, nil
+}
+
+func (g *BKashGateway) CreateSession(ctx context.Context, req domain.GatewaySessionRequest) (*domain.GatewaySession, error) {
+	resp, err := g.call(ctx, "/tokenized/checkout/create", map[string]string{
+		"mode":                  "0011",
+		"payerReference":        fallback(req.CustomerPhone, req.OrderID),
+		"callbackURL":           req.CallbackURL,
+		"amount":                req.Amount.String(),
+		"currency":              req.Currency,
+		"intent":                "sale",
+		"merchantInvoiceNumber": req.SessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != "0000" || resp.BKashURL == "" {
+		return nil, fmt.Errorf("bkash: create payment rejected: %s", resp.StatusMessage)
+	}
+
+	return &domain.GatewaySession{
+		GatewayRef:  resp.PaymentID,
+		RedirectURL: resp.BKashURL,
+	}, nil
+}
+
+// VerifyCallback handles the callbackURL redirect (?paymentID=...&status=success|failure|cancel).
+// A "success" redirect is only trusted once Execute (or, for a repeated callback,
+// Query Payment) reports the transaction as Completed.
+func (g *BKashGateway) VerifyCallback(ctx context.Context, fields map[string]string) (*domain.PaymentCallback, error) {
+	paymentID := fields["paymentID"]
+	if paymentID == "" {
+		return nil, fmt.Errorf("bkash: missing paymentID")
+	}
+
+	payload := domain.JSONB{}
+	for k, v := range fields {
+		payload[k] = v
+	}
+
+	cb := &domain.PaymentCallback{
+		GatewayRef: paymentID,
+		Payload:    payload,
+	}
+
+	switch fields["status"] {
+	case "success":
+		resp, err := g.call(ctx, "/tokenized/checkout/execute", map[string]string{"paymentID": paymentID})
+		if err != nil {
+			return nil, err
+		}
+		if resp.TransactionStatus != "Completed" {
+			// Already executed (e.g. browser refresh) — ask bKash for the final state
+			if resp, err = g.call(ctx, "/tokenized/checkout/payment/status", map[string]string{"paymentID": paymentID}); err != nil {
+				return nil, err
+			}
+		}
+		if resp.TransactionStatus != "Completed" {
+			cb.Status = domain.PaymentSessionFailed
+			cb.EventKey = paymentID + ":failed"
+			return cb, nil
+		}
+
+		amount, _ := domain.ParseMoney(resp.Amount)
+		cb.SessionID = resp.MerchantInvoiceNumber
+		cb.Status = domain.PaymentSessionSucceeded
+		cb.Amount = amount
+		cb.TransactionID = resp.TrxID
+		cb.EventKey = paymentID + ":completed"
+	case "cancel":
+		cb.Status = domain.PaymentSessionCancelled
+		cb.EventKey = paymentID + ":cancel"
+	case "failure":
+		cb.Status = domain.PaymentSessionFailed
+		cb.EventKey = paymentID + ":failure"
+	default:
+		return nil, fmt.Errorf("bkash: unknown callback status %q", fields["status"])
+	}
+	return cb, nil
+}
@@ -0,0 +1,117 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"valancis-backend/internal/domain"
+)
+
+// FakeGateway is an in-process stand-in for a real gateway, for offline development
+// and testing. CreateSession returns a redirect straight to the callback URL with a
+// pre-signed "success" notification; SignedCallback builds any other outcome.
+// Callbacks are HMAC-SHA256 signed, so the verification path is exercised too.
+type FakeGateway struct {
+	provider string
+	secret   []byte
+}
+
+// NewFakeGateway creates a fake that reports itself as provider. An empty secret
+// generates a random per-process key.
+func NewFakeGateway(provider, secret string) *FakeGateway {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &FakeGateway{provider: provider, secret: key}
+}
+
+func (g *FakeGateway) Provider() string {
+	return g.provider
+}
+
+func (g *FakeGateway) CreateSession(ctx context.Context, req domain.GatewaySessionRequest) (*domain.GatewaySession, error) {
+	ref := "fake_" + req.SessionID
+	fields := g.SignedCallback(req.SessionID, ref, req.Amount, "success")
+
+	redirect, err := url.Parse(req.CallbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("fake gateway: invalid callback URL: %w", err)
+	}
+	q := redirect.Query()
+	for k, v := range fields {
+		q.Set(k, v)
+	}
+	redirect.RawQuery = q.Encode()
+
+	return &domain.GatewaySession{
+		GatewayRef:  ref,
+		RedirectURL: redirect.String(),
+	}, nil
+}
+
+// SignedCallback builds the callback fields the fake would send for the given outcome
+// ("success", "failed" or "cancelled").
+func (g *FakeGateway) SignedCallback(sessionID, gatewayRef string, amount domain.Money, status string) map[string]string {
+	fields := map[string]string{
+		"session_id":  sessionID,
+		"gateway_ref": gatewayRef,
+		"amount":      amount.String(),
+		"status":      status,
+	}
+	if status == "success" {
+		fields["trx_id"] = "FAKE-" + strings.ToUpper(gatewayRef[len(gatewayRef)-8:])
+	}
+	fields["signature"] = g.sign(fields)
+	return fields
+}
+
+func (g *FakeGateway) VerifyCallback(ctx context.Context, fields map[string]string) (*domain.PaymentCallback, error) {
+	sig := fields["signature"]
+	if sig == "" || !hmac.Equal([]byte(sig), []byte(g.sign(fields))) {
+		return nil, fmt.Errorf("fake gateway: invalid callback signature")
+	}
+
+	amount, _ := domain.ParseMoney(fields["amount"])
+	cb := &domain.PaymentCallback{
+		SessionID:  fields["session_id"],
+		GatewayRef: fields["gateway_ref"],
+		Amount:     amount,
+		EventKey:   fields["gateway_ref"] + ":" + fields["status"],
+		Payload:    domain.JSONB{"status": fields["status"], "trx_id": fields["trx_id"]},
+	}
+	switch fields["status"] {
+	case "success":
+		cb.Status = domain.PaymentSessionSucceeded
+		cb.TransactionID = fields["trx_id"]
+	case "cancelled":
+		cb.Status = domain.PaymentSessionCancelled
+	default:
+		cb.Status = domain.PaymentSessionFailed
+	}
+	return cb, nil
+}
+
+// sign computes the HMAC over every field except the signature, sorted by key.
+func (g *FakeGateway) sign(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != "signature" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha256.New, g.secret)
+	for _, k := range keys {
+		fmt.Fprintf(mac, "%s=%s&", k, fields[k])
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
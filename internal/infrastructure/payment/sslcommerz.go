@@ -0,0 +1,229 @@
+package payment
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+const (
+	sslcommerzSandboxURL = "https://sandbox.sslcommerz.com"
+	sslcommerzLiveURL    = "https://securepay.sslcommerz.com"
+)
+
+// SSLCommerzGateway implements domain.PaymentGateway for SSLCommerz hosted checkout (API v4).
+type SSLCommerzGateway struct {
+	storeID    string
+	storePass  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSSLCommerzGateway creates an SSLCommerz adapter. Returns nil if credentials are missing.
+func NewSSLCommerzGateway(storeID, storePass string, sandbox bool) *SSLCommerzGateway {
+	if storeID == "" || storePass == "" {
+		return nil
+	}
+	baseURL := sslcommerzLiveURL
+	if sandbox {
+		baseURL = sslcommerzSandboxURL
+	}
+	return &SSLCommerzGateway{
+		storeID:   storeID,
+		storePass: storePass,
+		baseURL:   baseURL,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (g *SSLCommerzGateway) Provider() string {
+	return domain.PaymentMethodSSLCommerz
+}
+
+type sslcommerzSessionResp struct {
+	Status         string `json:"status"`
+	FailedReason   string `json:"failedreason"`
+	SessionKey     string `json:"sessionkey"`
+	GatewayPageURL string `json:"GatewayPageURL"`
+}
+
+func (g *SSLCommerzGateway) CreateSession(ctx context.Context, req domain.GatewaySessionRequest) (*domain.GatewaySession, error) {
+	form := url.Values{
+		"store_id":         {g.storeID},
+		"store_passwd":     {g.storePass},
+		"total_amount":     {req.Amount.String()},
+		"currency":         {req.Currency},
+		"tran_id":          {req.SessionID},
+		"success_url":      {req.CallbackURL},
+		"fail_url":         {req.CallbackURL},
+		"cancel_url":       {req.CallbackURL},
+		"ipn_url":          {req.IPNURL},
+		"cus_name":         {fallback(req.CustomerName, "Customer")},
+		"cus_email":        {fallback(req.CustomerEmail, "customer@example.com")},
+		"cus_phone":        {fallback(req.CustomerPhone, "01700000000")},
+		"cus_add1":         {fallback(req.Address, "Dhaka")},
+		"cus_city":         {"Dhaka"},
+		"cus_country":      {"Bangladesh"},
+		"shipping_method":  {"NO"},
+		"product_name":     {"Order " + req.OrderID},
+		"product_category": {"general"},
+		"product_profile":  {"general"},
+		"value_a":          {req.OrderID},
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/gwprocess/v4/api.php", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp sslcommerzSessionResp
+	if err := doJSON(g.httpClient, httpReq, &resp); err != nil {
+		return nil, fmt.Errorf("sslcommerz: session request failed: %w", err)
+	}
+	if resp.Status != "SUCCESS" || resp.GatewayPageURL == "" {
+		return nil, fmt.Errorf("sslcommerz: session rejected: %s", resp.FailedReason)
+	}
+
+	return &domain.GatewaySession{
+		GatewayRef:  resp.SessionKey,
+		RedirectURL: resp.GatewayPageURL,
+	}, nil
+}
+
+type sslcommerzValidationResp struct {
+	Status     string `json:"status"`
+	TranID     string `json:"tran_id"`
+	ValID      string `json:"val_id"`
+	Amount     string `json:"amount"`
+	BankTranID string `json:"bank_tran_id"`
+}
+
+// VerifyCallback checks the verify_sign hash on an IPN or redirect POST and, for
+// successful payments, confirms the val_id against the validation API.
+func (g *SSLCommerzGateway) VerifyCallback(ctx context.Context, fields map[string]string) (*domain.PaymentCallback, error) {
+	if !g.validSignature(fields) {
+		return nil, fmt.Errorf("sslcommerz: invalid callback signature")
+	}
+
+	payload := domain.JSONB{}
+	for k, v := range fields {
+		payload[k] = v
+	}
+
+	cb := &domain.PaymentCallback{
+		SessionID: fields["tran_id"],
+		Payload:   payload,
+	}
+
+	switch strings.ToUpper(fields["status"]) {
+	case "VALID", "VALIDATED":
+		val, err := g.validate(ctx, fields["val_id"])
+		if err != nil {
+			return nil, err
+		}
+		if val.TranID != cb.SessionID {
+			return nil, fmt.Errorf("sslcommerz: validation tran_id mismatch")
+		}
+		amount, _ := domain.ParseMoney(val.Amount)
+		cb.Status = domain.PaymentSessionSucceeded
+		cb.Amount = amount
+		cb.TransactionID = val.BankTranID
+		cb.GatewayRef = val.ValID
+		cb.EventKey = "val:" + val.ValID
+	case "CANCELLED":
+		cb.Status = domain.PaymentSessionCancelled
+		cb.EventKey = cb.SessionID + ":cancelled"
+	default: // FAILED, UNATTEMPTED, EXPIRED
+		cb.Status = domain.PaymentSessionFailed
+		cb.EventKey = cb.SessionID + ":" + strings.ToLower(fields["status"])
+	}
+	return cb, nil
+}
+
+// validSignature implements SSLCommerz's verify_sign scheme: md5 over the fields
+// listed in verify_key plus md5(store_passwd), sorted by key and joined as k=v&...
+func (g *SSLCommerzGateway) validSignature(fields map[string]string) bool {
+	sign, keys := fields["verify_sign"], fields["verify_key"]
+	if sign == "" || keys == "" {
+		return false
+	}
+
+	passHash := md5.Sum([]byte(g.storePass))
+	data := map[string]string{"store_passwd": hex.EncodeToString(passHash[:])}
+	for _, k := range strings.Split(keys, ",") {
+		data[k] = fields[k]
+	}
+
+	names := make([]string, 0, len(data))
+	for k := range data {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, k := range names {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(data[k])
+		sb.WriteByte('&')
+	}
+	sum := md5.Sum([]byte(strings.TrimSuffix(sb.String(), "&")))
+	return hex.EncodeToString(sum[:]) == sign
+}
+
+func (g *SSLCommerzGateway) validate(ctx context.Context, valID string) (*sslcommerzValidationResp, error) {
+	if valID == "" {
+		return nil, fmt.Errorf("sslcommerz: missing val_id")
+	}
+	q := url.Values{
+		"val_id":       {valID},
+		"store_id":     {g.storeID},
+		"store_passwd": {g.storePass},
+		"format":       {"json"},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/validator/api/validationserverAPI.php?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp sslcommerzValidationResp
+	if err := doJSON(g.httpClient, httpReq, &resp); err != nil {
+		return nil, fmt.Errorf("sslcommerz: validation request failed: %w", err)
+	}
+	if resp.Status != "VALID" && resp.Status != "VALIDATED" {
+		return nil, fmt.Errorf("sslcommerz: validation returned %s", resp.Status)
+	}
+	return &resp, nil
+}
+
+// doJSON executes req and decodes a JSON response body into out.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func fallback(v, def string) string {
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,539 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+	"valancis-backend/internal/domain"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+)
+
+// Page sizes in millimetres
+var (
+	pageA4    = fpdf.SizeType{Wd: 210, Ht: 297}
+	pageLabel = fpdf.SizeType{Wd: 101.6, Ht: 152.4} // 4x6 in
+)
+
+// Store is the merchant printed on invoices and packing slips and as the sender of
+// shipping labels.
+type Store struct {
+	Name    string
+	Address string
+	Phone   string
+}
+
+// Renderer prints order documents. The built-in PDF fonts only cover Latin text
+// (Windows-1252); set a UTF-8 TrueType font to print Bangla names and addresses.
+type Renderer struct {
+	store Store
+	font  []byte
+}
+
+// NewRenderer creates a renderer. fontPath is an optional UTF-8 TrueType font file.
+func NewRenderer(store Store, fontPath string) (*Renderer, error) {
+	r := &Renderer{store: store}
+	if fontPath != "" {
+		font, err := os.ReadFile(fontPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF font: %w", err)
+		}
+		r.font = font
+	}
+	return r, nil
+}
+
+// OrderDocument is an order to print. Invoice is required for invoices.
+type OrderDocument struct {
+	Order   *domain.Order
+	Invoice *domain.OrderInvoice
+}
+
+// Render prints one document of the given type per order into a single PDF, in the
+// given order, so a batch can be sent to the printer in one go.
+func (r *Renderer) Render(docType string, docs []OrderDocument) ([]byte, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no orders to print")
+	}
+
+	var d *document
+	switch docType {
+	case domain.DocumentInvoice:
+		d = r.newDocument(pageA4, 15)
+		for _, doc := range docs {
+			if doc.Invoice == nil {
+				return nil, fmt.Errorf("order %s has no invoice number", doc.Order.ID)
+			}
+			d.invoice(doc.Order, doc.Invoice)
+		}
+	case domain.DocumentPackingSlip:
+		d = r.newDocument(pageA4, 15)
+		for _, doc := range docs {
+			d.packingSlip(doc.Order)
+		}
+	case domain.DocumentShippingLabel:
+		d = r.newDocument(pageLabel, 4)
+		d.pdf.SetAutoPageBreak(false, 0) // One label per page: long addresses are cut short instead
+		for _, doc := range docs {
+			d.label(doc.Order)
+		}
+	default:
+		return nil, fmt.Errorf("unknown document type: %s", docType)
+	}
+
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", docType, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// document wraps a PDF being built with the renderer's font and text encoding.
+type document struct {
+	pdf    *fpdf.Fpdf
+	store  Store
+	family string
+	tr     func(string) string
+	margin float64
+}
+
+func (r *Renderer) newDocument(size fpdf.SizeType, margin float64) *document {
+	pdf := fpdf.NewCustom(&fpdf.InitType{UnitStr: "mm", Size: size})
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetCreator(r.store.Name, true)
+
+	d := &document{pdf: pdf, store: r.store, family: "Helvetica", tr: pdf.UnicodeTranslatorFromDescriptor(""), margin: margin}
+	if r.font != nil {
+		pdf.AddUTF8FontFromBytes("body", "", r.font)
+		pdf.AddUTF8FontFromBytes("body", "B", r.font)
+		d.family = "body"
+		d.tr = func(s string) string { return s }
+	}
+	return d
+}
+
+func (d *document) font(style string, size float64) {
+	d.pdf.SetFont(d.family, style, size)
+}
+
+// text writes a single-line cell.
+func (d *document) text(w, h float64, s, align string) {
+	d.pdf.CellFormat(w, h, d.tr(s), "", 0, align, false, 0, "")
+}
+
+// line writes a single-line cell and moves to the next line.
+func (d *document) line(w, h float64, s, align string) {
+	d.pdf.CellFormat(w, h, d.tr(s), "", 1, align, false, 0, "")
+}
+
+// wrap writes s wrapped to width w, at most maxLines lines (0 for no limit).
+func (d *document) wrap(w, h float64, s string, maxLines int) {
+	x := d.pdf.GetX()
+	lines := d.pdf.SplitText(d.tr(s), w)
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	for _, l := range lines {
+		d.pdf.SetX(x)
+		d.pdf.CellFormat(w, h, l, "", 1, "L", false, 0, "")
+	}
+}
+
+func (d *document) contentWidth() float64 {
+	w, _ := d.pdf.GetPageSize()
+	return w - 2*d.margin
+}
+
+func (d *document) rule() {
+	y := d.pdf.GetY()
+	w, _ := d.pdf.GetPageSize()
+	d.pdf.Line(d.margin, y, w-d.margin, y)
+}
+
+// image places a barcode; name must be unique within the document.
+func (d *document) image(name string, code barcode.Barcode, x, y, w, h float64) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code); err != nil {
+		d.pdf.SetError(err)
+		return
+	}
+	opts := fpdf.ImageOptions{ImageType: "PNG"}
+	d.pdf.RegisterImageOptionsReader(name, opts, &buf)
+	d.pdf.ImageOptions(name, x, y, w, h, false, opts, 0, "")
+}
+
+// barcode128 places a Code 128 barcode of an order reference.
+func (d *document) barcode128(ref string, x, y, w, h float64) {
+	encoded, err := code128.Encode(ref)
+	var code barcode.Barcode
+	if err == nil {
+		code, err = barcode.Scale(encoded, encoded.Bounds().Dx()*4, 120)
+	}
+	if err != nil {
+		d.pdf.SetError(fmt.Errorf("failed to encode barcode: %w", err))
+		return
+	}
+	d.image("code128-"+ref, code, x, y, w, h)
+}
+
+// qrCode places a QR code of the order ID.
+func (d *document) qrCode(orderID string, x, y, size float64) {
+	code, err := qr.Encode(orderID, qr.M, qr.Auto)
+	if err == nil {
+		code, err = barcode.Scale(code, 240, 240)
+	}
+	if err != nil {
+		d.pdf.SetError(fmt.Errorf("failed to encode QR code: %w", err))
+		return
+	}
+	d.image("qr-"+orderID, code, x, y, size, size)
+}
+
+// storeHeader prints the store on the left and the document title on the right.
+func (d *document) storeHeader(title string) {
+	width := d.contentWidth()
+	top := d.pdf.GetY()
+	d.font("B", 18)
+	d.line(width/2, 9, d.store.Name, "L")
+	d.font("", 9)
+	if d.store.Address != "" {
+		d.wrap(width/2, 4.5, d.store.Address, 3)
+	}
+	if d.store.Phone != "" {
+		d.line(width/2, 4.5, "Phone: "+d.store.Phone, "L")
+	}
+	bottom := d.pdf.GetY()
+
+	d.pdf.SetXY(d.margin+width/2, top)
+	d.font("B", 18)
+	d.text(width/2, 9, title, "R")
+	d.pdf.SetY(math.Max(bottom, top+9) + 4)
+}
+
+// --- Invoice ---
+
+func (d *document) invoice(order *domain.Order, invoice *domain.OrderInvoice) {
+	d.pdf.AddPage()
+	width := d.contentWidth()
+	d.storeHeader("INVOICE")
+
+	// Invoice details (right) and billing address (left)
+	top := d.pdf.GetY()
+	d.font("B", 10)
+	d.line(width/2, 5, "Bill to", "L")
+	d.font("", 10)
+	d.recipient(order.ShippingAddress, width/2, 5, true)
+	left := d.pdf.GetY()
+
+	d.pdf.SetY(top)
+	for _, row := range [][2]string{
+		{"Invoice no.", invoice.Number},
+		{"Invoice date", invoice.IssuedAt.Format("02 Jan 2006")},
+		{"Order", orderRef(order)},
+		{"Order date", order.CreatedAt.Format("02 Jan 2006")},
+		{"Payment", paymentMethodLabel(order.PaymentMethod) + " (" + order.PaymentStatus + ")"},
+	} {
+		d.pdf.SetX(d.margin + width/2)
+		d.font("B", 10)
+		d.text(width/4, 5, row[0], "L")
+		d.font("", 10)
+		d.line(width/4, 5, row[1], "R")
+	}
+	d.pdf.SetY(math.Max(left, d.pdf.GetY()) + 6)
+
+	// Items
+	cols := []float64{width * 0.46, width * 0.18, width * 0.08, width * 0.14, width * 0.14}
+	d.tableHeader(cols, []string{"Item", "SKU", "Qty", "Unit price", "Amount"}, []string{"L", "L", "R", "R", "R"})
+	d.font("", 10)
+	var subtotal domain.Money
+	for _, item := range order.Items {
+		amount := item.Price.Times(item.Quantity)
+		subtotal += amount
+		d.text(cols[0], 6, itemName(item), "L")
+		d.text(cols[1], 6, deref(item.VariantSKU), "L")
+		d.text(cols[2], 6, fmt.Sprintf("%d", item.Quantity), "R")
+		d.text(cols[3], 6, money(item.Price), "R")
+		d.line(cols[4], 6, money(amount), "R")
+	}
+	d.rule()
+	d.pdf.Ln(2)
+
+	// Totals
+	totals := [][2]string{{"Subtotal", money(subtotal)}}
+	if order.DiscountAmount > 0 {
+		label := "Discount"
+		if order.CouponCode != nil && *order.CouponCode != "" {
+			label += " (" + *order.CouponCode + ")"
+		}
+		totals = append(totals, [2]string{label, "-" + money(order.DiscountAmount)})
+	}
+	totals = append(totals, [2]string{"Shipping", money(order.ShippingFee)}, [2]string{"Total", money(order.TotalAmount)})
+	if order.PaidAmount > 0 {
+		totals = append(totals, [2]string{"Paid", money(order.PaidAmount)})
+	}
+	if order.RefundedAmount > 0 {
+		totals = append(totals, [2]string{"Refunded", money(order.RefundedAmount)})
+	}
+	totals = append(totals, [2]string{"Balance due", money(balanceDue(order))})
+	for _, row := range totals {
+		style := ""
+		if row[0] == "Total" || row[0] == "Balance due" {
+			style = "B"
+		}
+		d.font(style, 10)
+		d.pdf.SetX(d.margin + width*0.55)
+		d.text(width*0.25, 6, row[0], "L")
+		d.line(width*0.2, 6, row[1], "R")
+	}
+
+	d.pdf.Ln(10)
+	d.font("", 9)
+	d.line(width, 5, "Thank you for shopping with "+d.store.Name+".", "C")
+}
+
+// --- Packing slip ---
+
+func (d *document) packingSlip(order *domain.Order) {
+	d.pdf.AddPage()
+	width := d.contentWidth()
+	d.storeHeader("PACKING SLIP")
+
+	top := d.pdf.GetY()
+	d.font("B", 10)
+	d.line(width/2, 5, "Ship to", "L")
+	d.font("", 10)
+	d.recipient(order.ShippingAddress, width/2, 5, false)
+	left := d.pdf.GetY()
+
+	// Order reference and barcode on the right
+	d.pdf.SetXY(d.margin+width/2, top)
+	d.font("B", 12)
+	d.line(width/2, 6, "Order "+orderRef(order), "R")
+	d.pdf.SetX(d.margin + width/2)
+	d.font("", 10)
+	d.line(width/2, 5, order.CreatedAt.Format("02 Jan 2006"), "R")
+	d.barcode128(orderCode(order), d.margin+width-70, d.pdf.GetY()+1, 70, 14)
+	d.pdf.SetY(math.Max(left, d.pdf.GetY()+17) + 6)
+
+	cols := []float64{width * 0.06, width * 0.52, width * 0.2, width * 0.1, width * 0.12}
+	d.tableHeader(cols, []string{"#", "Item", "SKU", "Qty", "Packed"}, []string{"L", "L", "L", "R", "C"})
+	d.font("", 10)
+	units := 0
+	for i, item := range order.Items {
+		units += item.Quantity
+		d.text(cols[0], 7, fmt.Sprintf("%d", i+1), "L")
+		d.text(cols[1], 7, itemName(item), "L")
+		d.text(cols[2], 7, deref(item.VariantSKU), "L")
+		d.text(cols[3], 7, fmt.Sprintf("%d", item.Quantity), "R")
+		x, y := d.pdf.GetX(), d.pdf.GetY()
+		d.pdf.Rect(x+cols[4]/2-2, y+1.5, 4, 4, "D")
+		d.pdf.Ln(7)
+	}
+	d.rule()
+	d.pdf.Ln(2)
+	d.font("B", 10)
+	d.line(width, 6, fmt.Sprintf("Total units: %d", units), "R")
+	if order.IsPreorder {
+		d.font("B", 10)
+		d.line(width, 6, "Pre-order", "L")
+	}
+}
+
+// --- Shipping label ---
+
+func (d *document) label(order *domain.Order) {
+	d.pdf.AddPage()
+	width := d.contentWidth()
+	_, pageHeight := d.pdf.GetPageSize()
+
+	// Sender
+	d.font("B", 10)
+	d.line(width, 5, "From: "+d.store.Name, "L")
+	d.font("", 7)
+	sender := strings.TrimSpace(d.store.Address)
+	if d.store.Phone != "" {
+		sender = strings.TrimSpace(sender + "  " + d.store.Phone)
+	}
+	if sender != "" {
+		d.wrap(width, 3.5, sender, 2)
+	}
+	d.pdf.Ln(1)
+	d.rule()
+	d.pdf.Ln(2)
+
+	// Recipient
+	d.font("", 8)
+	d.line(width, 4, "TO", "L")
+	d.font("B", 14)
+	d.line(width, 7, recipientName(order.ShippingAddress), "L")
+	d.font("B", 13)
+	d.line(width, 7, field(order.ShippingAddress, "phone"), "L")
+	d.font("", 11)
+	d.wrap(width, 5.5, strings.Join(addressLines(order.ShippingAddress), ", "), 5)
+	d.pdf.Ln(1)
+	d.rule()
+	d.pdf.Ln(3)
+
+	// Cash to collect
+	var due domain.Money
+	if !isCancelledOrder(order) {
+		due = balanceDue(order)
+	}
+	y := d.pdf.GetY()
+	d.pdf.SetLineWidth(0.6)
+	d.pdf.Rect(d.margin, y, width, 14, "D")
+	d.pdf.SetLineWidth(0.2)
+	d.pdf.SetXY(d.margin, y)
+	if due > 0 {
+		d.font("B", 20)
+		d.line(width, 14, "COD "+money(due), "C")
+	} else {
+		d.font("B", 14)
+		d.line(width, 14, "PREPAID - collect nothing", "C")
+	}
+	d.pdf.Ln(3)
+
+	// Order details beside the QR code
+	qrSize := 28.0
+	y = d.pdf.GetY()
+	units := 0
+	for _, item := range order.Items {
+		units += item.Quantity
+	}
+	d.font("B", 12)
+	d.line(width-qrSize-2, 6, "Order "+orderRef(order), "L")
+	d.font("", 9)
+	d.line(width-qrSize-2, 4.5, order.CreatedAt.Format("02 Jan 2006"), "L")
+	d.line(width-qrSize-2, 4.5, fmt.Sprintf("%d item(s)", units), "L")
+	d.line(width-qrSize-2, 4.5, paymentMethodLabel(order.PaymentMethod), "L")
+	d.qrCode(order.ID, d.margin+width-qrSize, y, qrSize)
+
+	// Barcode along the bottom
+	barcodeHeight := 16.0
+	barcodeY := pageHeight - d.margin - barcodeHeight - 4
+	d.barcode128(orderCode(order), d.margin, barcodeY, width, barcodeHeight)
+	d.pdf.SetXY(d.margin, barcodeY+barcodeHeight)
+	d.font("", 7)
+	d.line(width, 4, orderCode(order), "C")
+}
+
+// --- Helpers ---
+
+func (d *document) tableHeader(cols []float64, titles, aligns []string) {
+	d.font("B", 10)
+	d.pdf.SetFillColor(235, 235, 235)
+	for i, title := range titles {
+		ln := 0
+		if i == len(titles)-1 {
+			ln = 1
+		}
+		d.pdf.CellFormat(cols[i], 7, d.tr(title), "", ln, aligns[i], true, 0, "")
+	}
+	d.pdf.Ln(1)
+}
+
+// recipient prints the name, contact details and address of a shipping address.
+func (d *document) recipient(address domain.JSONB, w, h float64, withEmail bool) {
+	d.line(w, h, recipientName(address), "L")
+	if phone := field(address, "phone"); phone != "" {
+		d.line(w, h, phone, "L")
+	}
+	if email := field(address, "email"); withEmail && email != "" {
+		d.line(w, h, email, "L")
+	}
+	for _, l := range addressLines(address) {
+		d.wrap(w, h, l, 2)
+	}
+}
+
+func field(address domain.JSONB, key string) string {
+	if v, ok := address[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+func recipientName(address domain.JSONB) string {
+	return strings.TrimSpace(field(address, "firstName") + " " + field(address, "lastName"))
+}
+
+// addressLines returns the street address, area and district/zip of an address.
+func addressLines(address domain.JSONB) []string {
+	var lines []string
+	for _, part := range []string{field(address, "addressLine"), field(address, "area"), strings.TrimSpace(field(address, "district") + " " + field(address, "zip"))} {
+		if part != "" {
+			lines = append(lines, part)
+		}
+	}
+	return lines
+}
+
+func itemName(item domain.OrderItem) string {
+	if item.VariantName != nil && *item.VariantName != "" {
+		return item.Product.Name + " (" + *item.VariantName + ")"
+	}
+	return item.Product.Name
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// balanceDue is what is left to pay on the order.
+func balanceDue(order *domain.Order) domain.Money {
+	return (order.TotalAmount - order.PaidAmount).Max(0)
+}
+
+func isCancelledOrder(order *domain.Order) bool {
+	return order.Status == domain.OrderStatusCancelled
+}
+
+// orderRef is the order reference shown to customers: the order number, or a short
+// form of the ID for orders without one.
+func orderRef(order *domain.Order) string {
+	if order.OrderNumber != "" {
+		return order.OrderNumber
+	}
+	if len(order.ID) < 8 {
+		return strings.ToUpper(order.ID)
+	}
+	return "#" + strings.ToUpper(order.ID[:8])
+}
+
+// orderCode is what barcodes encode: the order number couriers print, or the ID.
+func orderCode(order *domain.Order) string {
+	if order.OrderNumber != "" {
+		return order.OrderNumber
+	}
+	return order.ID
+}
+
+func paymentMethodLabel(method string) string {
+	switch method {
+	case domain.PaymentMethodCOD:
+		return "Cash on delivery"
+	case domain.PaymentMethodAdvance:
+		return "Mobile banking (advance)"
+	case domain.PaymentMethodBKash:
+		return "bKash"
+	case domain.PaymentMethodNagad:
+		return "Nagad"
+	case domain.PaymentMethodSSLCommerz:
+		return "Card / online (SSLCommerz)"
+	}
+	return method
+}
+
+func money(amount domain.Money) string {
+	return "Tk " + amount.String()
+}
@@ -0,0 +1,64 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPProvider sends SMS through a generic HTTP gateway, as offered by most local BD
+// SMS providers: a form POST with api_key, senderid, number (8801XXXXXXXXX) and message.
+// Any 2xx response counts as accepted.
+type HTTPProvider struct {
+	apiURL     string
+	apiKey     string
+	senderID   string
+	httpClient *http.Client
+}
+
+func NewHTTPProvider(apiURL, apiKey, senderID string) *HTTPProvider {
+	return &HTTPProvider{
+		apiURL:   apiURL,
+		apiKey:   apiKey,
+		senderID: senderID,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Send delivers message to a BD mobile number (01XXXXXXXXX, with or without 88).
+func (p *HTTPProvider) Send(ctx context.Context, to, message string) error {
+	number := strings.TrimPrefix(to, "+")
+	if !strings.HasPrefix(number, "88") {
+		number = "88" + number
+	}
+
+	form := url.Values{}
+	form.Set("api_key", p.apiKey)
+	form.Set("senderid", p.senderID)
+	form.Set("number", number)
+	form.Set("message", message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build sms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sms gateway error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
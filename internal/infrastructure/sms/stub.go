@@ -0,0 +1,18 @@
+package sms
+
+import (
+	"context"
+	"log/slog"
+)
+
+// StubProvider only logs messages instead of sending them (development, or no gateway configured).
+type StubProvider struct{}
+
+func NewStubProvider() *StubProvider {
+	return &StubProvider{}
+}
+
+func (p *StubProvider) Send(ctx context.Context, to, message string) error {
+	slog.Info("SMS: message not sent (stub provider)", "to", to, "message", message)
+	return nil
+}
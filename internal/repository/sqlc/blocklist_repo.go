@@ -0,0 +1,157 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type blocklistRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewBlocklistRepository(db *pgxpool.Pool) domain.BlocklistRepository {
+	return &blocklistRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *blocklistRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcBlocklistEntryToDomain(e sqlc.BlocklistEntry) domain.BlocklistEntry {
+	return domain.BlocklistEntry{
+		ID:            uuidToString(e.ID),
+		Kind:          e.Kind,
+		Value:         e.Value,
+		Action:        e.Action,
+		Reason:        e.Reason,
+		ExpiresAt:     toTimePtr(e.ExpiresAt),
+		SourceOrderID: optionalUUID(e.SourceOrderID),
+		CreatedBy:     optionalUUID(e.CreatedBy),
+		CreatedAt:     pgtimeToTime(e.CreatedAt),
+		UpdatedAt:     pgtimeToTime(e.UpdatedAt),
+	}
+}
+
+func (r *blocklistRepository) Upsert(ctx context.Context, entry *domain.BlocklistEntry) error {
+	saved, err := r.getQueries(ctx).UpsertBlocklistEntry(ctx, sqlc.UpsertBlocklistEntryParams{
+		Kind:          entry.Kind,
+		Value:         entry.Value,
+		Action:        entry.Action,
+		Reason:        entry.Reason,
+		ExpiresAt:     timePtrToPgtime(entry.ExpiresAt),
+		SourceOrderID: stringToUUID(ptrString(entry.SourceOrderID)),
+		CreatedBy:     stringToUUID(ptrString(entry.CreatedBy)),
+	})
+	if err != nil {
+		return err
+	}
+	*entry = sqlcBlocklistEntryToDomain(saved)
+	return nil
+}
+
+func (r *blocklistRepository) GetByID(ctx context.Context, id string) (*domain.BlocklistEntry, error) {
+	row, err := r.getQueries(ctx).GetBlocklistEntry(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("blocklist entry %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	entry := sqlcBlocklistEntryToDomain(row)
+	return &entry, nil
+}
+
+func (r *blocklistRepository) Update(ctx context.Context, entry *domain.BlocklistEntry) error {
+	saved, err := r.getQueries(ctx).UpdateBlocklistEntry(ctx, sqlc.UpdateBlocklistEntryParams{
+		ID:        stringToUUID(entry.ID),
+		Action:    entry.Action,
+		Reason:    entry.Reason,
+		ExpiresAt: timePtrToPgtime(entry.ExpiresAt),
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return fmt.Errorf("blocklist entry %w", domain.ErrNotFound)
+		}
+		return err
+	}
+	*entry = sqlcBlocklistEntryToDomain(saved)
+	return nil
+}
+
+func (r *blocklistRepository) Delete(ctx context.Context, id string) error {
+	n, err := r.getQueries(ctx).DeleteBlocklistEntry(ctx, stringToUUID(id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("blocklist entry %w", domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *blocklistRepository) List(ctx context.Context, filter domain.BlocklistFilter) ([]domain.BlocklistEntry, int64, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	offset := (filter.Page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	kind, search := strPtr(filter.Kind), strPtr(filter.Search)
+
+	rows, err := r.getQueries(ctx).ListBlocklistEntries(ctx, sqlc.ListBlocklistEntriesParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+		Kind:   kind,
+		Search: search,
+		Active: filter.Active,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := r.getQueries(ctx).CountBlocklistEntries(ctx, sqlc.CountBlocklistEntriesParams{
+		Kind:   kind,
+		Search: search,
+		Active: filter.Active,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]domain.BlocklistEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = sqlcBlocklistEntryToDomain(row)
+	}
+	return entries, count, nil
+}
+
+func (r *blocklistRepository) Match(ctx context.Context, subject domain.BlocklistSubject) ([]domain.BlocklistEntry, error) {
+	params := sqlc.MatchBlocklistEntriesParams{
+		Phone:  strPtr(subject.Phone),
+		Email:  strPtr(subject.Email),
+		UserID: strPtr(subject.UserID),
+	}
+	if ip, err := netip.ParseAddr(subject.IP); err == nil {
+		ip = ip.Unmap()
+		params.IpAddress = &ip
+	}
+	rows, err := r.getQueries(ctx).MatchBlocklistEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]domain.BlocklistEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = sqlcBlocklistEntryToDomain(row)
+	}
+	return entries, nil
+}
@@ -32,7 +32,7 @@ func (r *configRepository) GetActiveShippingZones(ctx context.Context) ([]domain
 			ID:        z.ID,
 			Key:       z.Key,
 			Label:     z.Label,
-			Cost:      numericToFloat64(z.Cost),
+			Cost:      numericToMoney(z.Cost),
 			IsActive:  z.IsActive,
 			CreatedAt: pgtimeToTime(z.CreatedAt),
 			UpdatedAt: pgtimeToTime(z.UpdatedAt),
@@ -53,7 +53,7 @@ func (r *configRepository) GetAllShippingZones(ctx context.Context) ([]domain.Sh
 			ID:        z.ID,
 			Key:       z.Key,
 			Label:     z.Label,
-			Cost:      numericToFloat64(z.Cost),
+			Cost:      numericToMoney(z.Cost),
 			IsActive:  z.IsActive,
 			CreatedAt: pgtimeToTime(z.CreatedAt),
 			UpdatedAt: pgtimeToTime(z.UpdatedAt),
@@ -72,7 +72,7 @@ func (r *configRepository) GetShippingZoneByID(ctx context.Context, id int32) (*
 		ID:        z.ID,
 		Key:       z.Key,
 		Label:     z.Label,
-		Cost:      numericToFloat64(z.Cost),
+		Cost:      numericToMoney(z.Cost),
 		IsActive:  z.IsActive,
 		CreatedAt: pgtimeToTime(z.CreatedAt),
 		UpdatedAt: pgtimeToTime(z.UpdatedAt),
@@ -89,7 +89,7 @@ func (r *configRepository) GetShippingZoneByKey(ctx context.Context, key string)
 		ID:        z.ID,
 		Key:       z.Key,
 		Label:     z.Label,
-		Cost:      numericToFloat64(z.Cost),
+		Cost:      numericToMoney(z.Cost),
 		IsActive:  z.IsActive,
 		CreatedAt: pgtimeToTime(z.CreatedAt),
 		UpdatedAt: pgtimeToTime(z.UpdatedAt),
@@ -100,7 +100,7 @@ func (r *configRepository) CreateShippingZone(ctx context.Context, zone *domain.
 	z, err := r.queries.CreateShippingZone(ctx, sqlc.CreateShippingZoneParams{
 		Key:      zone.Key,
 		Label:    zone.Label,
-		Cost:     float64ToNumeric(zone.Cost),
+		Cost:     moneyToNumeric(zone.Cost),
 		IsActive: zone.IsActive,
 	})
 	if err != nil {
@@ -111,7 +111,7 @@ func (r *configRepository) CreateShippingZone(ctx context.Context, zone *domain.
 		ID:        z.ID,
 		Key:       z.Key,
 		Label:     z.Label,
-		Cost:      numericToFloat64(z.Cost),
+		Cost:      numericToMoney(z.Cost),
 		IsActive:  z.IsActive,
 		CreatedAt: pgtimeToTime(z.CreatedAt),
 		UpdatedAt: pgtimeToTime(z.UpdatedAt),
@@ -122,7 +122,7 @@ func (r *configRepository) UpdateShippingZone(ctx context.Context, zone *domain.
 	z, err := r.queries.UpdateShippingZone(ctx, sqlc.UpdateShippingZoneParams{
 		ID:       zone.ID,
 		Label:    zone.Label,
-		Cost:     float64ToNumeric(zone.Cost),
+		Cost:     moneyToNumeric(zone.Cost),
 		IsActive: zone.IsActive,
 	})
 	if err != nil {
@@ -133,17 +133,17 @@ func (r *configRepository) UpdateShippingZone(ctx context.Context, zone *domain.
 		ID:        z.ID,
 		Key:       z.Key,
 		Label:     z.Label,
-		Cost:      numericToFloat64(z.Cost),
+		Cost:      numericToMoney(z.Cost),
 		IsActive:  z.IsActive,
 		CreatedAt: pgtimeToTime(z.CreatedAt),
 		UpdatedAt: pgtimeToTime(z.UpdatedAt),
 	}, nil
 }
 
-func (r *configRepository) UpdateShippingZoneCost(ctx context.Context, key string, cost float64) error {
+func (r *configRepository) UpdateShippingZoneCost(ctx context.Context, key string, cost domain.Money) error {
 	return r.queries.UpdateShippingZoneCost(ctx, sqlc.UpdateShippingZoneCostParams{
 		Key:  key,
-		Cost: float64ToNumeric(cost),
+		Cost: moneyToNumeric(cost),
 	})
 }
 
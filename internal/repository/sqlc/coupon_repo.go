@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"valancis-backend/internal/domain"
-	"strconv"
 
 	// Import the GENERATED SQLC package alias
 	"valancis-backend/db/sqlc"
@@ -31,22 +30,19 @@ func (r *couponRepository) CreateCoupon(ctx context.Context, c *domain.Coupon) e
 	isActive := c.IsActive
 
 	params := sqlc.CreateCouponParams{
-		Code:       c.Code,
-		Type:       c.Type,
-		UsageLimit: &usageLimit,
-		IsActive:   &isActive,
+		Code:           c.Code,
+		Type:           c.Type,
+		UsageLimit:     &usageLimit,
+		IsActive:       &isActive,
+		PerUserLimit:   int32(c.PerUserLimit),
+		MaxDiscount:    moneyPtrToNumeric(c.MaxDiscount),
+		FirstOrderOnly: c.FirstOrderOnly,
 	}
 
-	// Convert Value - This is required, don't ignore errors
-	val, err := Float64ToNumeric(c.Value)
-	if err != nil {
-		return fmt.Errorf("invalid coupon value: %w", err)
-	}
-	params.Value = val
+	params.Value = moneyToNumeric(c.Value)
 
-	// Convert MinSpend (optional, default 0)
-	minSpend, _ := Float64ToNumeric(c.MinSpend)
-	params.MinSpend = minSpend
+	// MinSpend is optional, default 0
+	params.MinSpend = moneyToNumeric(c.MinSpend)
 
 	if c.StartAt != nil {
 		params.StartAt = pgtype.Timestamp{Time: *c.StartAt, Valid: true}
@@ -55,7 +51,7 @@ func (r *couponRepository) CreateCoupon(ctx context.Context, c *domain.Coupon) e
 		params.ExpiresAt = pgtype.Timestamp{Time: *c.ExpiresAt, Valid: true}
 	}
 
-	result, err := r.q.CreateCoupon(ctx, params)
+	result, err := GetQueriesFromContext(ctx, r.q).CreateCoupon(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -71,27 +67,40 @@ func (r *couponRepository) GetCouponByCode(ctx context.Context, code string) (*d
 	if err != nil {
 		return nil, err
 	}
-	return toDomainCoupon(c), nil
+	coupon := toDomainCoupon(c)
+	if err := r.enrichScopes(ctx, []*domain.Coupon{coupon}); err != nil {
+		return nil, err
+	}
+	return coupon, nil
 }
 
-func (r *couponRepository) ValidateCoupon(ctx context.Context, code string, cartTotal float64) (*domain.CouponValidationResult, error) {
-	total, _ := Float64ToNumeric(cartTotal)
-
-	res, err := r.q.ValidateCoupon(ctx, sqlc.ValidateCouponParams{
-		Code:      code,
-		CartTotal: total,
+func (r *couponRepository) ValidateCoupon(ctx context.Context, code, userID string, cartTotal domain.Money, productIDs []string) (*domain.CouponValidationResult, error) {
+	// Inside a transaction this also locks the coupon row until commit
+	res, err := GetQueriesFromContext(ctx, r.q).ValidateCoupon(ctx, sqlc.ValidateCouponParams{
+		Code:       code,
+		UserID:     stringToUUID(userID),
+		CartTotal:  moneyToNumeric(cartTotal),
+		ProductIds: stringsToUUIDs(productIDs),
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	eligible := make([]string, len(res.EligibleProductIds))
+	for i, id := range res.EligibleProductIds {
+		eligible[i] = uuidToString(id)
+	}
+
 	return &domain.CouponValidationResult{
-		ID:               uuid.UUID(res.ID.Bytes),
-		Code:             res.Code,
-		Type:             res.Type,
-		Value:            NumericToFloat64(res.Value),
-		MinSpend:         NumericToFloat64(res.MinSpend),
-		ValidationStatus: res.ValidationStatus,
+		ID:                 uuid.UUID(res.ID.Bytes),
+		Code:               res.Code,
+		Type:               res.Type,
+		Value:              numericToMoney(res.Value),
+		MinSpend:           numericToMoney(res.MinSpend),
+		MaxDiscount:        numericToMoneyPtr(res.MaxDiscount),
+		ValidationStatus:   res.ValidationStatus,
+		IsScoped:           res.IsScoped,
+		EligibleProductIDs: eligible,
 	}, nil
 }
 
@@ -104,9 +113,14 @@ func (r *couponRepository) ListCoupons(ctx context.Context, limit, offset int) (
 		return nil, err
 	}
 
-	var result []domain.Coupon
-	for _, c := range coupons {
-		result = append(result, *toDomainCoupon(c))
+	result := make([]domain.Coupon, len(coupons))
+	ptrs := make([]*domain.Coupon, len(coupons))
+	for i, c := range coupons {
+		result[i] = *toDomainCoupon(c)
+		ptrs[i] = &result[i]
+	}
+	if err := r.enrichScopes(ctx, ptrs); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
@@ -114,7 +128,47 @@ func (r *couponRepository) ListCoupons(ctx context.Context, limit, offset int) (
 func (r *couponRepository) IncrementCouponUsage(ctx context.Context, id uuid.UUID) error {
 	// Convert uuid.UUID -> pgtype.UUID
 	pgUUID := pgtype.UUID{Bytes: id, Valid: true}
-	return r.q.IncrementCouponUsage(ctx, pgUUID)
+	rows, err := GetQueriesFromContext(ctx, r.q).IncrementCouponUsage(ctx, pgUUID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("coupon usage limit reached")
+	}
+	return nil
+}
+
+func (r *couponRepository) CreateRedemption(ctx context.Context, red *domain.CouponRedemption) error {
+	created, err := GetQueriesFromContext(ctx, r.q).CreateCouponRedemption(ctx, sqlc.CreateCouponRedemptionParams{
+		CouponID:       pgtype.UUID{Bytes: red.CouponID, Valid: true},
+		OrderID:        stringToUUID(red.OrderID),
+		UserID:         stringToUUID(red.UserID),
+		Code:           red.Code,
+		DiscountAmount: moneyToNumeric(red.DiscountAmount),
+	})
+	if err != nil {
+		return err
+	}
+
+	red.ID = uuidToString(created.ID)
+	red.CreatedAt = created.CreatedAt.Time
+	return nil
+}
+
+func (r *couponRepository) GetRedemptionByOrderID(ctx context.Context, orderID string) (*domain.CouponRedemption, error) {
+	red, err := r.q.GetCouponRedemptionByOrderID(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CouponRedemption{
+		ID:             uuidToString(red.ID),
+		CouponID:       uuid.UUID(red.CouponID.Bytes),
+		OrderID:        uuidToString(red.OrderID),
+		UserID:         uuidToString(red.UserID),
+		Code:           red.Code,
+		DiscountAmount: numericToMoney(red.DiscountAmount),
+		CreatedAt:      red.CreatedAt.Time,
+	}, nil
 }
 
 func (r *couponRepository) DeleteCoupon(ctx context.Context, id uuid.UUID) error {
@@ -128,13 +182,15 @@ func (r *couponRepository) GetCouponByID(ctx context.Context, id uuid.UUID) (*do
 	if err != nil {
 		return nil, err
 	}
-	return toDomainCoupon(c), nil
+	coupon := toDomainCoupon(c)
+	if err := r.enrichScopes(ctx, []*domain.Coupon{coupon}); err != nil {
+		return nil, err
+	}
+	return coupon, nil
 }
 
 func (r *couponRepository) UpdateCoupon(ctx context.Context, c *domain.Coupon) error {
 	usageLimit := int32(c.UsageLimit)
-	val, _ := Float64ToNumeric(c.Value)
-	min, _ := Float64ToNumeric(c.MinSpend)
 
 	var startAt, expiresAt pgtype.Timestamp
 	if c.StartAt != nil {
@@ -144,19 +200,90 @@ func (r *couponRepository) UpdateCoupon(ctx context.Context, c *domain.Coupon) e
 		expiresAt = pgtype.Timestamp{Time: *c.ExpiresAt, Valid: true}
 	}
 
-	return r.q.UpdateCoupon(ctx, sqlc.UpdateCouponParams{
-		ID:         pgtype.UUID{Bytes: c.ID, Valid: true},
-		Code:       c.Code,
-		Type:       c.Type,
-		Value:      val,
-		MinSpend:   min,
-		UsageLimit: &usageLimit,
-		StartAt:    startAt,
-		ExpiresAt:  expiresAt,
-		IsActive:   &c.IsActive,
+	return GetQueriesFromContext(ctx, r.q).UpdateCoupon(ctx, sqlc.UpdateCouponParams{
+		ID:             pgtype.UUID{Bytes: c.ID, Valid: true},
+		Code:           c.Code,
+		Type:           c.Type,
+		Value:          moneyToNumeric(c.Value),
+		MinSpend:       moneyToNumeric(c.MinSpend),
+		UsageLimit:     &usageLimit,
+		StartAt:        startAt,
+		ExpiresAt:      expiresAt,
+		IsActive:       &c.IsActive,
+		PerUserLimit:   int32(c.PerUserLimit),
+		MaxDiscount:    moneyPtrToNumeric(c.MaxDiscount),
+		FirstOrderOnly: c.FirstOrderOnly,
 	})
 }
 
+func (r *couponRepository) SetCouponScopes(ctx context.Context, id uuid.UUID, productIDs, categoryIDs, collectionIDs []string) error {
+	q := GetQueriesFromContext(ctx, r.q)
+	couponID := pgtype.UUID{Bytes: id, Valid: true}
+
+	if err := q.ClearCouponProducts(ctx, couponID); err != nil {
+		return err
+	}
+	if err := q.ClearCouponCategories(ctx, couponID); err != nil {
+		return err
+	}
+	if err := q.ClearCouponCollections(ctx, couponID); err != nil {
+		return err
+	}
+
+	if len(productIDs) > 0 {
+		if err := q.AddCouponProducts(ctx, sqlc.AddCouponProductsParams{CouponID: couponID, ProductIds: stringsToUUIDs(productIDs)}); err != nil {
+			return err
+		}
+	}
+	if len(categoryIDs) > 0 {
+		if err := q.AddCouponCategories(ctx, sqlc.AddCouponCategoriesParams{CouponID: couponID, CategoryIds: stringsToUUIDs(categoryIDs)}); err != nil {
+			return err
+		}
+	}
+	if len(collectionIDs) > 0 {
+		if err := q.AddCouponCollections(ctx, sqlc.AddCouponCollectionsParams{CouponID: couponID, CollectionIds: stringsToUUIDs(collectionIDs)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enrichScopes loads product/category/collection restrictions for a batch of coupons in one query.
+func (r *couponRepository) enrichScopes(ctx context.Context, coupons []*domain.Coupon) error {
+	if len(coupons) == 0 {
+		return nil
+	}
+
+	ids := make([]pgtype.UUID, len(coupons))
+	byID := make(map[uuid.UUID]*domain.Coupon, len(coupons))
+	for i, c := range coupons {
+		ids[i] = pgtype.UUID{Bytes: c.ID, Valid: true}
+		c.ProductIDs, c.CategoryIDs, c.CollectionIDs = []string{}, []string{}, []string{}
+		byID[c.ID] = c
+	}
+
+	rows, err := GetQueriesFromContext(ctx, r.q).ListCouponScopes(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		c, ok := byID[uuid.UUID(row.CouponID.Bytes)]
+		if !ok {
+			continue
+		}
+		target := uuidToString(row.TargetID)
+		switch row.ScopeType {
+		case "product":
+			c.ProductIDs = append(c.ProductIDs, target)
+		case "category":
+			c.CategoryIDs = append(c.CategoryIDs, target)
+		case "collection":
+			c.CollectionIDs = append(c.CollectionIDs, target)
+		}
+	}
+	return nil
+}
+
 func (r *couponRepository) CountCoupons(ctx context.Context) (int64, error) {
 	return r.q.CountCoupons(ctx)
 }
@@ -177,18 +304,30 @@ func toDomainCoupon(c sqlc.Coupon) *domain.Coupon {
 	}
 
 	return &domain.Coupon{
-		ID:         uuid.UUID(c.ID.Bytes),
-		Code:       c.Code,
-		Type:       c.Type,
-		Value:      NumericToFloat64(c.Value),
-		MinSpend:   NumericToFloat64(c.MinSpend),
-		UsageLimit: limit,
-		UsedCount:  used,
-		StartAt:    toTimePtr(c.StartAt),
-		ExpiresAt:  toTimePtr(c.ExpiresAt),
-		IsActive:   active,
-		CreatedAt:  c.CreatedAt.Time,
+		ID:             uuid.UUID(c.ID.Bytes),
+		Code:           c.Code,
+		Type:           c.Type,
+		Value:          numericToMoney(c.Value),
+		MinSpend:       numericToMoney(c.MinSpend),
+		MaxDiscount:    numericToMoneyPtr(c.MaxDiscount),
+		UsageLimit:     limit,
+		PerUserLimit:   int(c.PerUserLimit),
+		UsedCount:      used,
+		FirstOrderOnly: c.FirstOrderOnly,
+		StartAt:        toTimePtr(c.StartAt),
+		ExpiresAt:      toTimePtr(c.ExpiresAt),
+		IsActive:       active,
+		CreatedAt:      c.CreatedAt.Time,
+	}
+}
+
+// stringsToUUIDs converts string IDs to pgtype.UUID for array parameters
+func stringsToUUIDs(ids []string) []pgtype.UUID {
+	out := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		out[i] = stringToUUID(id)
 	}
+	return out
 }
 
 func toTimePtr(t pgtype.Timestamp) *time.Time {
@@ -197,22 +336,3 @@ func toTimePtr(t pgtype.Timestamp) *time.Time {
 	}
 	return nil
 }
-
-// NumericToFloat64 converts pgtype.Numeric to float64
-func NumericToFloat64(n pgtype.Numeric) float64 {
-	f, _ := n.Float64Value()
-	return f.Float64
-}
-
-// Float64ToNumeric converts float64 to pgtype.Numeric
-// L9: Use strconv.FormatFloat for zero-allocation conversion
-func Float64ToNumeric(f float64) (pgtype.Numeric, error) {
-	var n pgtype.Numeric
-	// Convert float64 to string with 2 decimal precision
-	str := strconv.FormatFloat(f, 'f', 2, 64)
-	err := n.Scan(str)
-	if err != nil {
-		return pgtype.Numeric{}, err
-	}
-	return n, nil
-}
@@ -0,0 +1,251 @@
+package sqlcrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type draftOrderRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewDraftOrderRepository(db *pgxpool.Pool) domain.DraftOrderRepository {
+	return &draftOrderRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *draftOrderRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcDraftOrderToDomain(d sqlc.DraftOrder) domain.DraftOrder {
+	draft := domain.DraftOrder{
+		ID:              uuidToString(d.ID),
+		UserID:          uuidToString(d.UserID),
+		Status:          d.Status,
+		ShippingAddress: domain.JSONB{},
+		PaymentMethod:   d.PaymentMethod,
+		DiscountAmount:  numericToMoney(d.DiscountAmount),
+		DiscountReason:  d.DiscountReason,
+		Note:            d.Note,
+		Locale:          d.Locale,
+		OrderID:         optionalUUID(d.OrderID),
+		CreatedBy:       optionalUUID(d.CreatedBy),
+		Items:           []domain.DraftOrderItem{},
+		SentAt:          toTimePtr(d.SentAt),
+		CreatedAt:       pgtimeToTime(d.CreatedAt),
+		UpdatedAt:       pgtimeToTime(d.UpdatedAt),
+	}
+	if len(d.ShippingAddress) > 0 {
+		json.Unmarshal(d.ShippingAddress, &draft.ShippingAddress)
+	}
+	return draft
+}
+
+// withItems converts the drafts and attaches their items.
+func (r *draftOrderRepository) withItems(ctx context.Context, rows []sqlc.DraftOrder) ([]domain.DraftOrder, error) {
+	drafts := make([]domain.DraftOrder, len(rows))
+	if len(rows) == 0 {
+		return drafts, nil
+	}
+	ids := make([]pgtype.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	itemRows, err := r.getQueries(ctx).ListDraftOrderItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string][]domain.DraftOrderItem)
+	for _, row := range itemRows {
+		draftID := uuidToString(row.DraftID)
+		items[draftID] = append(items[draftID], domain.DraftOrderItem{
+			ProductID:      uuidToString(row.ProductID),
+			ProductName:    row.ProductName,
+			VariantID:      uuidToString(row.VariantID),
+			VariantName:    row.VariantName,
+			Quantity:       int(row.Quantity),
+			PriceOverride:  numericToMoneyPtr(row.PriceOverride),
+			OverrideReason: row.OverrideReason,
+		})
+	}
+
+	for i, row := range rows {
+		drafts[i] = sqlcDraftOrderToDomain(row)
+		if draftItems, ok := items[drafts[i].ID]; ok {
+			drafts[i].Items = draftItems
+		}
+	}
+	return drafts, nil
+}
+
+func (r *draftOrderRepository) one(ctx context.Context, row sqlc.DraftOrder, err error) (*domain.DraftOrder, error) {
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("draft order %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	drafts, err := r.withItems(ctx, []sqlc.DraftOrder{row})
+	if err != nil {
+		return nil, err
+	}
+	return &drafts[0], nil
+}
+
+// addDraftItems stores the items of a draft.
+func addDraftItems(ctx context.Context, q *sqlc.Queries, draftID pgtype.UUID, items []domain.DraftOrderItem) error {
+	for _, item := range items {
+		if err := q.AddDraftOrderItem(ctx, sqlc.AddDraftOrderItemParams{
+			DraftID:        draftID,
+			ProductID:      stringToUUID(item.ProductID),
+			VariantID:      stringToUUID(item.VariantID),
+			Quantity:       int32(item.Quantity),
+			PriceOverride:  moneyPtrToNumeric(item.PriceOverride),
+			OverrideReason: item.OverrideReason,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *draftOrderRepository) Create(ctx context.Context, draft *domain.DraftOrder) error {
+	addressBytes, err := json.Marshal(draft.ShippingAddress)
+	if err != nil {
+		return err
+	}
+	createdBy := ""
+	if draft.CreatedBy != nil {
+		createdBy = *draft.CreatedBy
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	created, err := q.CreateDraftOrder(ctx, sqlc.CreateDraftOrderParams{
+		UserID:          stringToUUID(draft.UserID),
+		ShippingAddress: addressBytes,
+		PaymentMethod:   draft.PaymentMethod,
+		DiscountAmount:  moneyToNumeric(draft.DiscountAmount),
+		DiscountReason:  draft.DiscountReason,
+		Note:            draft.Note,
+		Locale:          draft.Locale,
+		CreatedBy:       stringToUUID(createdBy),
+	})
+	if err != nil {
+		return err
+	}
+	if err := addDraftItems(ctx, q, created.ID, draft.Items); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	items := draft.Items
+	*draft = sqlcDraftOrderToDomain(created)
+	draft.Items = items
+	return nil
+}
+
+func (r *draftOrderRepository) Update(ctx context.Context, draft *domain.DraftOrder) error {
+	addressBytes, err := json.Marshal(draft.ShippingAddress)
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	updated, err := q.UpdateDraftOrder(ctx, sqlc.UpdateDraftOrderParams{
+		ID:              stringToUUID(draft.ID),
+		ShippingAddress: addressBytes,
+		PaymentMethod:   draft.PaymentMethod,
+		DiscountAmount:  moneyToNumeric(draft.DiscountAmount),
+		DiscountReason:  draft.DiscountReason,
+		Note:            draft.Note,
+		Locale:          draft.Locale,
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.DeleteDraftOrderItems(ctx, updated.ID); err != nil {
+		return err
+	}
+	if err := addDraftItems(ctx, q, updated.ID, draft.Items); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	items := draft.Items
+	*draft = sqlcDraftOrderToDomain(updated)
+	draft.Items = items
+	return nil
+}
+
+func (r *draftOrderRepository) GetByID(ctx context.Context, id string) (*domain.DraftOrder, error) {
+	row, err := r.getQueries(ctx).GetDraftOrderByID(ctx, stringToUUID(id))
+	return r.one(ctx, row, err)
+}
+
+func (r *draftOrderRepository) GetForUpdate(ctx context.Context, id string) (*domain.DraftOrder, error) {
+	row, err := r.getQueries(ctx).GetDraftOrderForUpdate(ctx, stringToUUID(id))
+	return r.one(ctx, row, err)
+}
+
+func (r *draftOrderRepository) List(ctx context.Context, status string, limit, offset int) ([]domain.DraftOrder, int64, error) {
+	rows, err := r.getQueries(ctx).ListDraftOrders(ctx, sqlc.ListDraftOrdersParams{
+		Status: strPtr(status),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.getQueries(ctx).CountDraftOrders(ctx, strPtr(status))
+	if err != nil {
+		return nil, 0, err
+	}
+	drafts, err := r.withItems(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return drafts, total, nil
+}
+
+func (r *draftOrderRepository) MarkSent(ctx context.Context, id string) error {
+	return r.getQueries(ctx).MarkDraftOrderSent(ctx, stringToUUID(id))
+}
+
+func (r *draftOrderRepository) Complete(ctx context.Context, id, orderID string) (bool, error) {
+	n, err := r.getQueries(ctx).CompleteDraftOrder(ctx, sqlc.CompleteDraftOrderParams{
+		ID:      stringToUUID(id),
+		OrderID: stringToUUID(orderID),
+	})
+	return n > 0, err
+}
+
+func (r *draftOrderRepository) Cancel(ctx context.Context, id string) (bool, error) {
+	n, err := r.getQueries(ctx).CancelDraftOrder(ctx, stringToUUID(id))
+	return n > 0, err
+}
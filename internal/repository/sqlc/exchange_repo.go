@@ -0,0 +1,117 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type exchangeRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewExchangeRepository(db *pgxpool.Pool) domain.ExchangeRepository {
+	return &exchangeRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *exchangeRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func optionalUUID(u pgtype.UUID) *string {
+	if !u.Valid {
+		return nil
+	}
+	s := uuidToString(u)
+	return &s
+}
+
+func sqlcOrderExchangeToDomain(e sqlc.OrderExchange) *domain.OrderExchange {
+	return &domain.OrderExchange{
+		ID:              uuidToString(e.ID),
+		OrderID:         uuidToString(e.OrderID),
+		OrderItemID:     uuidToString(e.OrderItemID),
+		OldVariantID:    optionalUUID(e.OldVariantID),
+		NewVariantID:    optionalUUID(e.NewVariantID),
+		NewVariantName:  e.NewVariantName,
+		Quantity:        int(e.Quantity),
+		UnitPrice:       numericToMoney(e.UnitPrice),
+		PriceDifference: numericToMoney(e.PriceDifference),
+		Status:          e.Status,
+		Note:            e.Note,
+		CreatedBy:       optionalUUID(e.CreatedBy),
+		ReceivedAt:      toTimePtr(e.ReceivedAt),
+		CreatedAt:       pgtimeToTime(e.CreatedAt),
+		UpdatedAt:       pgtimeToTime(e.UpdatedAt),
+	}
+}
+
+func (r *exchangeRepository) Create(ctx context.Context, exchange *domain.OrderExchange) error {
+	valueOf := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	created, err := r.getQueries(ctx).CreateOrderExchange(ctx, sqlc.CreateOrderExchangeParams{
+		OrderID:         stringToUUID(exchange.OrderID),
+		OrderItemID:     stringToUUID(exchange.OrderItemID),
+		OldVariantID:    stringToUUID(valueOf(exchange.OldVariantID)),
+		NewVariantID:    stringToUUID(valueOf(exchange.NewVariantID)),
+		NewVariantName:  exchange.NewVariantName,
+		Quantity:        int32(exchange.Quantity),
+		UnitPrice:       moneyToNumeric(exchange.UnitPrice),
+		PriceDifference: moneyToNumeric(exchange.PriceDifference),
+		Note:            exchange.Note,
+		CreatedBy:       stringToUUID(valueOf(exchange.CreatedBy)),
+	})
+	if err != nil {
+		return err
+	}
+	*exchange = *sqlcOrderExchangeToDomain(created)
+	return nil
+}
+
+func (r *exchangeRepository) GetByID(ctx context.Context, id string) (*domain.OrderExchange, error) {
+	e, err := r.getQueries(ctx).GetOrderExchangeByID(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("exchange %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return sqlcOrderExchangeToDomain(e), nil
+}
+
+func (r *exchangeRepository) GetForUpdate(ctx context.Context, id string) (*domain.OrderExchange, error) {
+	e, err := r.getQueries(ctx).GetOrderExchangeForUpdate(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("exchange %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return sqlcOrderExchangeToDomain(e), nil
+}
+
+func (r *exchangeRepository) ListByOrder(ctx context.Context, orderID string) ([]domain.OrderExchange, error) {
+	rows, err := r.getQueries(ctx).ListOrderExchangesByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	exchanges := make([]domain.OrderExchange, len(rows))
+	for i, row := range rows {
+		exchanges[i] = *sqlcOrderExchangeToDomain(row)
+	}
+	return exchanges, nil
+}
+
+func (r *exchangeRepository) MarkReceived(ctx context.Context, id string) error {
+	return r.getQueries(ctx).MarkOrderExchangeReceived(ctx, stringToUUID(id))
+}
@@ -0,0 +1,80 @@
+package sqlcrepo
+
+import (
+	"context"
+	"time"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type idempotencyRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) domain.IdempotencyRepository {
+	return &idempotencyRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *idempotencyRepository) Claim(ctx context.Context, userID, key, requestHash string, ttl time.Duration) (bool, error) {
+	rows, err := r.queries.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+		UserID:      stringToUUID(userID),
+		Key:         key,
+		RequestHash: requestHash,
+		TtlSeconds:  ttl.Seconds(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	row, err := r.queries.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{
+		UserID: stringToUUID(userID),
+		Key:    key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &domain.IdempotencyRecord{
+		UserID:       uuidToString(row.UserID),
+		Key:          row.Key,
+		RequestHash:  row.RequestHash,
+		ContentType:  ptrString(row.ContentType),
+		ResponseBody: row.ResponseBody,
+		CreatedAt:    pgtimeToTime(row.CreatedAt),
+		ExpiresAt:    pgtimeToTime(row.ExpiresAt),
+	}
+	if row.StatusCode != nil {
+		code := int(*row.StatusCode)
+		rec.StatusCode = &code
+	}
+	return rec, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	code := int32(statusCode)
+	return r.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		UserID:       stringToUUID(userID),
+		Key:          key,
+		StatusCode:   &code,
+		ContentType:  &contentType,
+		ResponseBody: body,
+	})
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID, key string) error {
+	return r.queries.ReleaseIdempotencyKey(ctx, sqlc.ReleaseIdempotencyKeyParams{
+		UserID: stringToUUID(userID),
+		Key:    key,
+	})
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return r.queries.DeleteExpiredIdempotencyKeys(ctx)
+}
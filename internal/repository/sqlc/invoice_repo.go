@@ -0,0 +1,67 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type invoiceRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewInvoiceRepository(db *pgxpool.Pool) domain.InvoiceRepository {
+	return &invoiceRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *invoiceRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcOrderInvoiceToDomain(i sqlc.OrderInvoice) *domain.OrderInvoice {
+	return &domain.OrderInvoice{
+		ID:       uuidToString(i.ID),
+		OrderID:  uuidToString(i.OrderID),
+		Number:   i.InvoiceNumber,
+		PDFURL:   i.PdfUrl,
+		IssuedAt: pgtimeToTime(i.IssuedAt),
+	}
+}
+
+func (r *invoiceRepository) Issue(ctx context.Context, orderID string) (*domain.OrderInvoice, error) {
+	invoice, err := r.GetByOrderID(ctx, orderID)
+	if err == nil || err.Error() != "invoice not found" {
+		return invoice, err
+	}
+	created, err := r.getQueries(ctx).CreateInvoice(ctx, stringToUUID(orderID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return r.GetByOrderID(ctx, orderID) // Issued concurrently
+		}
+		return nil, err
+	}
+	return sqlcOrderInvoiceToDomain(created), nil
+}
+
+func (r *invoiceRepository) GetByOrderID(ctx context.Context, orderID string) (*domain.OrderInvoice, error) {
+	invoice, err := r.getQueries(ctx).GetInvoiceByOrderID(ctx, stringToUUID(orderID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("invoice %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return sqlcOrderInvoiceToDomain(invoice), nil
+}
+
+func (r *invoiceRepository) SetPDFURL(ctx context.Context, id, url string) error {
+	return r.getQueries(ctx).SetInvoicePDFURL(ctx, sqlc.SetInvoicePDFURLParams{
+		ID:     stringToUUID(id),
+		PdfUrl: &url,
+	})
+}
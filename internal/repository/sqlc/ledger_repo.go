@@ -0,0 +1,155 @@
+package sqlcrepo
+
+import (
+	"context"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type paymentLedgerRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPaymentLedgerRepository(db *pgxpool.Pool) domain.PaymentLedgerRepository {
+	return &paymentLedgerRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *paymentLedgerRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcPaymentToDomain(p sqlc.Payment) domain.Payment {
+	return domain.Payment{
+		ID:            uuidToString(p.ID),
+		OrderID:       uuidToString(p.OrderID),
+		Direction:     p.Direction,
+		Amount:        numericToMoney(p.Amount),
+		Method:        p.Method,
+		Provider:      p.Provider,
+		TransactionID: p.TransactionID,
+		SenderNumber:  p.SenderNumber,
+		Status:        p.Status,
+		SessionID:     optionalUUID(p.SessionID),
+		RefundID:      optionalUUID(p.RefundID),
+		Note:          p.Note,
+		VerifiedBy:    optionalUUID(p.VerifiedBy),
+		VerifiedAt:    toTimePtr(p.VerifiedAt),
+		CreatedBy:     optionalUUID(p.CreatedBy),
+		CreatedAt:     pgtimeToTime(p.CreatedAt),
+	}
+}
+
+// recordPayment inserts a ledger entry and re-derives the order's amounts with q, so
+// callers that already hold a transaction (refunds) keep both in it.
+func recordPayment(ctx context.Context, q *sqlc.Queries, payment *domain.Payment) error {
+	created, err := q.CreatePayment(ctx, sqlc.CreatePaymentParams{
+		OrderID:       stringToUUID(payment.OrderID),
+		Direction:     payment.Direction,
+		Amount:        moneyToNumeric(payment.Amount),
+		Method:        payment.Method,
+		Provider:      payment.Provider,
+		TransactionID: payment.TransactionID,
+		SenderNumber:  payment.SenderNumber,
+		Status:        payment.Status,
+		SessionID:     stringToUUID(ptrString(payment.SessionID)),
+		RefundID:      stringToUUID(ptrString(payment.RefundID)),
+		Note:          payment.Note,
+		VerifiedBy:    stringToUUID(ptrString(payment.VerifiedBy)),
+		VerifiedAt:    timePtrToPgtime(payment.VerifiedAt),
+		CreatedBy:     stringToUUID(ptrString(payment.CreatedBy)),
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.SyncOrderPaymentTotals(ctx, created.OrderID); err != nil {
+		return err
+	}
+	*payment = sqlcPaymentToDomain(created)
+	return nil
+}
+
+func (r *paymentLedgerRepository) Record(ctx context.Context, payment *domain.Payment) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := recordPayment(ctx, sqlc.New(tx), payment); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *paymentLedgerRepository) ListByOrder(ctx context.Context, orderID string) ([]domain.Payment, error) {
+	rows, err := r.getQueries(ctx).ListPaymentsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]domain.Payment, len(rows))
+	for i, row := range rows {
+		payments[i] = sqlcPaymentToDomain(sqlc.Payment{
+			ID:            row.ID,
+			OrderID:       row.OrderID,
+			Direction:     row.Direction,
+			Amount:        row.Amount,
+			Method:        row.Method,
+			Provider:      row.Provider,
+			TransactionID: row.TransactionID,
+			SenderNumber:  row.SenderNumber,
+			Status:        row.Status,
+			SessionID:     row.SessionID,
+			RefundID:      row.RefundID,
+			Note:          row.Note,
+			VerifiedBy:    row.VerifiedBy,
+			VerifiedAt:    row.VerifiedAt,
+			CreatedBy:     row.CreatedBy,
+			CreatedAt:     row.CreatedAt,
+		})
+		payments[i].VerifiedByName = row.VerifiedByName
+	}
+	return payments, nil
+}
+
+func (r *paymentLedgerRepository) ListPendingForUpdate(ctx context.Context, orderID string) ([]domain.Payment, error) {
+	rows, err := r.getQueries(ctx).ListPendingPaymentsForUpdate(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]domain.Payment, len(rows))
+	for i, row := range rows {
+		payments[i] = sqlcPaymentToDomain(row)
+	}
+	return payments, nil
+}
+
+func (r *paymentLedgerRepository) Verify(ctx context.Context, id, orderID, verifiedBy string) (bool, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	rows, err := q.VerifyPayment(ctx, sqlc.VerifyPaymentParams{
+		ID:         stringToUUID(id),
+		OrderID:    stringToUUID(orderID),
+		VerifiedBy: stringToUUID(verifiedBy),
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	if err := q.SyncOrderPaymentTotals(ctx, stringToUUID(orderID)); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
@@ -0,0 +1,107 @@
+package sqlcrepo
+
+import (
+	"context"
+	"time"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type emailOutboxRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewEmailOutboxRepository(db *pgxpool.Pool) domain.EmailOutboxRepository {
+	return &emailOutboxRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *emailOutboxRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcOutboxEmailToDomain(e sqlc.EmailOutbox) domain.OutboxEmail {
+	email := domain.OutboxEmail{
+		ID:            uuidToString(e.ID),
+		Template:      e.Template,
+		Locale:        e.Locale,
+		To:            e.ToAddress,
+		Subject:       e.Subject,
+		HTMLBody:      e.HtmlBody,
+		TextBody:      e.TextBody,
+		Status:        e.Status,
+		Attempts:      int(e.Attempts),
+		LastError:     e.LastError,
+		NextAttemptAt: pgtimeToTime(e.NextAttemptAt),
+		SentAt:        toTimePtr(e.SentAt),
+		CreatedAt:     pgtimeToTime(e.CreatedAt),
+		UpdatedAt:     pgtimeToTime(e.UpdatedAt),
+	}
+	if e.OrderID.Valid {
+		orderID := uuidToString(e.OrderID)
+		email.OrderID = &orderID
+	}
+	return email
+}
+
+func (r *emailOutboxRepository) Enqueue(ctx context.Context, email *domain.OutboxEmail) error {
+	var orderID pgtype.UUID
+	if email.OrderID != nil {
+		orderID = stringToUUID(*email.OrderID)
+	}
+
+	created, err := r.getQueries(ctx).EnqueueEmail(ctx, sqlc.EnqueueEmailParams{
+		OrderID:   orderID,
+		Template:  email.Template,
+		Locale:    email.Locale,
+		ToAddress: email.To,
+		Subject:   email.Subject,
+		HtmlBody:  email.HTMLBody,
+		TextBody:  email.TextBody,
+	})
+	if err != nil {
+		return err
+	}
+	*email = sqlcOutboxEmailToDomain(created)
+	return nil
+}
+
+func (r *emailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEmail, error) {
+	rows, err := r.getQueries(ctx).ClaimDueEmails(ctx, sqlc.ClaimDueEmailsParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.OutboxEmail, len(rows))
+	for i, row := range rows {
+		result[i] = sqlcOutboxEmailToDomain(row)
+	}
+	return result, nil
+}
+
+func (r *emailOutboxRepository) MarkSent(ctx context.Context, id string) error {
+	return r.getQueries(ctx).MarkEmailSent(ctx, stringToUUID(id))
+}
+
+func (r *emailOutboxRepository) MarkRetry(ctx context.Context, id, lastError string, retryIn time.Duration) error {
+	return r.getQueries(ctx).MarkEmailRetry(ctx, sqlc.MarkEmailRetryParams{
+		ID:           stringToUUID(id),
+		LastError:    &lastError,
+		RetrySeconds: retryIn.Seconds(),
+	})
+}
+
+func (r *emailOutboxRepository) MarkFailed(ctx context.Context, id, lastError string) error {
+	return r.getQueries(ctx).MarkEmailFailed(ctx, sqlc.MarkEmailFailedParams{
+		ID:        stringToUUID(id),
+		LastError: &lastError,
+	})
+}
@@ -3,6 +3,8 @@ package sqlcrepo
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 	"valancis-backend/db/sqlc"
 	"valancis-backend/internal/domain"
 
@@ -22,6 +24,11 @@ func NewOrderRepository(db *pgxpool.Pool) domain.OrderRepository {
 	}
 }
 
+// getQueries returns queries bound to the transaction in ctx, if any.
+func (r *orderRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
 // --- Mappers ---
 
 func sqlcCartToDomain(c sqlc.Cart, items []sqlc.GetCartItemsRow) *domain.Cart {
@@ -46,10 +53,10 @@ func sqlcCartToDomain(c sqlc.Cart, items []sqlc.GetCartItemsRow) *domain.Cart {
 				ID:                    uuidToString(item.ProductID),
 				Name:                  item.Name,
 				Slug:                  item.Slug,
-				BasePrice:             numericToFloat64(item.BasePrice),
-				SalePrice:             numericToFloat64Ptr(item.SalePrice),
+				BasePrice:             numericToMoney(item.BasePrice),
+				SalePrice:             numericToMoneyPtr(item.SalePrice),
 				IsPreorder:            item.IsPreorder,
-				PreorderDepositAmount: numericToFloat64(item.PreorderDepositAmount),
+				PreorderDepositAmount: numericToMoney(item.PreorderDepositAmount),
 			},
 		}
 		if item.VariantID.Valid {
@@ -65,18 +72,35 @@ func sqlcCartToDomain(c sqlc.Cart, items []sqlc.GetCartItemsRow) *domain.Cart {
 	return cart
 }
 
+// riskReasonsFromJSON decodes orders.risk_reasons (always an array, possibly empty).
+func riskReasonsFromJSON(b []byte) []domain.RiskReason {
+	reasons := []domain.RiskReason{}
+	if len(b) > 0 {
+		json.Unmarshal(b, &reasons)
+	}
+	return reasons
+}
+
 func sqlcOrderToDomain(o sqlc.Order, items []sqlc.GetOrderItemsRow) *domain.Order {
 	order := &domain.Order{
 		ID:             uuidToString(o.ID),
+		OrderNumber:    o.OrderNumber,
 		UserID:         uuidToString(o.UserID),
 		Status:         o.Status,
-		TotalAmount:    numericToFloat64(o.TotalAmount),
+		TotalAmount:    numericToMoney(o.TotalAmount),
 		PaymentMethod:  ptrString(o.PaymentMethod),
 		PaymentStatus:  ptrString(o.PaymentStatus),
-		PaidAmount:     numericToFloat64(o.PaidAmount),
-		RefundedAmount: numericToFloat64(o.RefundedAmount),
-		ShippingFee:    numericToFloat64(o.ShippingFee),
+		PaidAmount:     numericToMoney(o.PaidAmount),
+		RefundedAmount: numericToMoney(o.RefundedAmount),
+		ShippingFee:    numericToMoney(o.ShippingFee),
+		DiscountAmount: numericToMoney(o.DiscountAmount),
+		CouponCode:     o.CouponCode,
 		IsPreorder:     o.IsPreorder,
+		Locale:         o.Locale,
+		CustomerPhone:  o.CustomerPhone,
+		IPAddress:      o.IpAddress,
+		RiskScore:      int32PtrToIntPtr(o.RiskScore),
+		RiskReasons:    riskReasonsFromJSON(o.RiskReasons),
 		CreatedAt:      pgtimeToTime(o.CreatedAt),
 		UpdatedAt:      pgtimeToTime(o.UpdatedAt),
 	}
@@ -108,7 +132,7 @@ func sqlcOrderToDomain(o sqlc.Order, items []sqlc.GetOrderItemsRow) *domain.Orde
 			OrderID:     uuidToString(item.OrderID),
 			ProductID:   uuidToString(item.ProductID),
 			Quantity:    int(item.Quantity),
-			Price:       numericToFloat64(item.Price),
+			Price:       numericToMoney(item.Price),
 			VariantName: item.VariantName,
 			VariantSKU:  item.VariantSku,
 			Product: domain.Product{
@@ -133,7 +157,7 @@ func sqlcOrderToDomain(o sqlc.Order, items []sqlc.GetOrderItemsRow) *domain.Orde
 // --- Cart Methods ---
 
 func (r *orderRepository) GetCartByUserID(ctx context.Context, userID string) (*domain.Cart, error) {
-	cart, err := r.queries.GetCartByUserID(ctx, stringToUUID(userID))
+	cart, err := r.getQueries(ctx).GetCartByUserID(ctx, stringToUUID(userID))
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			return nil, nil
@@ -141,7 +165,7 @@ func (r *orderRepository) GetCartByUserID(ctx context.Context, userID string) (*
 		return nil, err
 	}
 
-	items, err := r.queries.GetCartItems(ctx, cart.ID)
+	items, err := r.getQueries(ctx).GetCartItems(ctx, cart.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +179,7 @@ func (r *orderRepository) CreateCart(ctx context.Context, cart *domain.Cart) err
 		userID = stringToUUID(*cart.UserID)
 	}
 
-	created, err := r.queries.CreateCart(ctx, userID)
+	created, err := r.getQueries(ctx).CreateCart(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -166,11 +190,26 @@ func (r *orderRepository) CreateCart(ctx context.Context, cart *domain.Cart) err
 }
 
 func (r *orderRepository) GetCartWithItems(ctx context.Context, userID string) ([]domain.CartItem, error) {
-	rows, err := r.queries.GetCartWithItems(ctx, stringToUUID(userID))
+	rows, err := r.getQueries(ctx).GetCartWithItems(ctx, stringToUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	return sqlcCartRowsToItems(rows), nil
+}
+
+func (r *orderRepository) GetCartItemsByCartID(ctx context.Context, cartID string) ([]domain.CartItem, error) {
+	rows, err := r.getQueries(ctx).GetCartWithItemsByCartID(ctx, stringToUUID(cartID))
 	if err != nil {
 		return nil, err
 	}
+	converted := make([]sqlc.GetCartWithItemsRow, len(rows))
+	for i, row := range rows {
+		converted[i] = sqlc.GetCartWithItemsRow(row)
+	}
+	return sqlcCartRowsToItems(converted), nil
+}
 
+func sqlcCartRowsToItems(rows []sqlc.GetCartWithItemsRow) []domain.CartItem {
 	items := make([]domain.CartItem, 0, len(rows))
 	for _, row := range rows {
 		// Skip rows where item_id is null (empty cart)
@@ -187,10 +226,10 @@ func (r *orderRepository) GetCartWithItems(ctx context.Context, userID string) (
 				ID:                    uuidToString(row.ProductID),
 				Name:                  *row.Name,
 				Slug:                  *row.Slug,
-				BasePrice:             numericToFloat64(row.BasePrice),
-				SalePrice:             numericToFloat64Ptr(row.SalePrice),
+				BasePrice:             numericToMoney(row.BasePrice),
+				SalePrice:             numericToMoneyPtr(row.SalePrice),
 				IsPreorder:            row.IsPreorder != nil && *row.IsPreorder,
-				PreorderDepositAmount: numericToFloat64(row.PreorderDepositAmount),
+				PreorderDepositAmount: numericToMoney(row.PreorderDepositAmount),
 			},
 		}
 
@@ -211,17 +250,17 @@ func (r *orderRepository) GetCartWithItems(ctx context.Context, userID string) (
 		}
 
 		// RESOLVE Effective Price (Variant Price > Product Base)
-		item.Price = numericToFloat64(row.BasePrice)
+		item.Price = numericToMoney(row.BasePrice)
 		if row.VariantPrice.Valid {
-			item.Price = numericToFloat64(row.VariantPrice)
+			item.Price = numericToMoney(row.VariantPrice)
 		}
 
 		// RESOLVE Effective Sale Price (Variant Sale > Product Sale)
 		if row.VariantSalePrice.Valid {
-			s := numericToFloat64(row.VariantSalePrice)
+			s := numericToMoney(row.VariantSalePrice)
 			item.SalePrice = &s
 		} else if row.SalePrice.Valid {
-			s := numericToFloat64(row.SalePrice)
+			s := numericToMoney(row.SalePrice)
 			item.SalePrice = &s
 		}
 
@@ -233,7 +272,7 @@ func (r *orderRepository) GetCartWithItems(ctx context.Context, userID string) (
 		items = append(items, item)
 	}
 
-	return items, nil
+	return items
 }
 
 func (r *orderRepository) UpsertCartItemAtomic(ctx context.Context, userID, cartID, productID string, variantID *string, quantity int) ([]domain.CartItem, error) {
@@ -242,7 +281,7 @@ func (r *orderRepository) UpsertCartItemAtomic(ctx context.Context, userID, cart
 		variantUUID = stringToUUID(*variantID)
 	}
 
-	rows, err := r.queries.UpsertCartItemAtomic(ctx, sqlc.UpsertCartItemAtomicParams{
+	rows, err := r.getQueries(ctx).UpsertCartItemAtomic(ctx, sqlc.UpsertCartItemAtomicParams{
 		CartID:    stringToUUID(cartID),
 		UserID:    stringToUUID(userID),
 		ProductID: stringToUUID(productID),
@@ -265,10 +304,10 @@ func (r *orderRepository) UpsertCartItemAtomic(ctx context.Context, userID, cart
 				ID:                    uuidToString(row.ProductID),
 				Name:                  row.Name,
 				Slug:                  row.Slug,
-				BasePrice:             numericToFloat64(row.BasePrice),
-				SalePrice:             numericToFloat64Ptr(row.SalePrice),
+				BasePrice:             numericToMoney(row.BasePrice),
+				SalePrice:             numericToMoneyPtr(row.SalePrice),
 				IsPreorder:            row.IsPreorder,
-				PreorderDepositAmount: numericToFloat64(row.PreorderDepositAmount),
+				PreorderDepositAmount: numericToMoney(row.PreorderDepositAmount),
 			},
 		}
 		if row.VariantID.Valid {
@@ -281,17 +320,17 @@ func (r *orderRepository) UpsertCartItemAtomic(ctx context.Context, userID, cart
 		}
 
 		// RESOLVE Effective Price (Variant Price > Product Base)
-		items[i].Price = numericToFloat64(row.BasePrice)
+		items[i].Price = numericToMoney(row.BasePrice)
 		if row.VariantPrice.Valid {
-			items[i].Price = numericToFloat64(row.VariantPrice)
+			items[i].Price = numericToMoney(row.VariantPrice)
 		}
 
 		// RESOLVE Effective Sale Price (Variant Sale > Product Sale)
 		if row.VariantSalePrice.Valid {
-			s := numericToFloat64(row.VariantSalePrice)
+			s := numericToMoney(row.VariantSalePrice)
 			items[i].SalePrice = &s
 		} else if row.SalePrice.Valid {
-			s := numericToFloat64(row.SalePrice)
+			s := numericToMoney(row.SalePrice)
 			items[i].SalePrice = &s
 		}
 
@@ -305,7 +344,7 @@ func (r *orderRepository) UpsertCartItemAtomic(ctx context.Context, userID, cart
 }
 
 func (r *orderRepository) AtomicRemoveCartItem(ctx context.Context, userID, productID, variantID string) error {
-	return r.queries.AtomicRemoveCartItem(ctx, sqlc.AtomicRemoveCartItemParams{
+	return r.getQueries(ctx).AtomicRemoveCartItem(ctx, sqlc.AtomicRemoveCartItemParams{
 		UserID:    stringToUUID(userID),
 		ProductID: stringToUUID(productID),
 		VariantID: stringToUUID(variantID),
@@ -313,7 +352,52 @@ func (r *orderRepository) AtomicRemoveCartItem(ctx context.Context, userID, prod
 }
 
 func (r *orderRepository) ClearCart(ctx context.Context, cartID string) error {
-	return r.queries.ClearCart(ctx, stringToUUID(cartID))
+	return r.getQueries(ctx).ClearCart(ctx, stringToUUID(cartID))
+}
+
+func (r *orderRepository) GetCartByID(ctx context.Context, cartID string) (*domain.Cart, error) {
+	cart, err := r.getQueries(ctx).GetCartByID(ctx, stringToUUID(cartID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sqlcCartToDomain(cart, nil), nil
+}
+
+func (r *orderRepository) RemoveCartItem(ctx context.Context, cartID, productID, variantID string) error {
+	return r.getQueries(ctx).RemoveCartItem(ctx, sqlc.RemoveCartItemParams{
+		CartID:    stringToUUID(cartID),
+		ProductID: stringToUUID(productID),
+		VariantID: stringToUUID(variantID),
+	})
+}
+
+func (r *orderRepository) AssignCartToUser(ctx context.Context, cartID, userID string) (bool, error) {
+	rows, err := r.getQueries(ctx).AssignCartToUser(ctx, sqlc.AssignCartToUserParams{
+		ID:     stringToUUID(cartID),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *orderRepository) DeleteCart(ctx context.Context, cartID string) error {
+	return r.getQueries(ctx).DeleteCart(ctx, stringToUUID(cartID))
+}
+
+func (r *orderRepository) TouchCart(ctx context.Context, cartID string) error {
+	return r.getQueries(ctx).TouchCart(ctx, stringToUUID(cartID))
+}
+
+func (r *orderRepository) DeleteIdleGuestCarts(ctx context.Context, idleFor time.Duration, limit int) (int64, error) {
+	return r.getQueries(ctx).DeleteIdleGuestCarts(ctx, sqlc.DeleteIdleGuestCartsParams{
+		IdleSeconds: idleFor.Seconds(),
+		BatchSize:   int32(limit),
+	})
 }
 
 // --- Order Methods ---
@@ -321,24 +405,42 @@ func (r *orderRepository) ClearCart(ctx context.Context, cartID string) error {
 func (r *orderRepository) CreateOrder(ctx context.Context, order *domain.Order) error {
 	shippingAddrBytes, _ := json.Marshal(order.ShippingAddress)
 	paymentDetailsBytes, _ := json.Marshal(order.PaymentDetails)
+	if order.RiskReasons == nil {
+		order.RiskReasons = []domain.RiskReason{}
+	}
+	riskReasonsBytes, _ := json.Marshal(order.RiskReasons)
 
-	created, err := r.queries.CreateOrder(ctx, sqlc.CreateOrderParams{
+	q := r.getQueries(ctx)
+	next, err := q.NextOrderNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to draw order number: %w", err)
+	}
+	created, err := q.CreateOrder(ctx, sqlc.CreateOrderParams{
 		UserID:          stringToUUID(order.UserID),
 		Status:          order.Status,
-		TotalAmount:     float64ToNumeric(order.TotalAmount),
-		ShippingFee:     float64ToNumeric(order.ShippingFee),
+		TotalAmount:     moneyToNumeric(order.TotalAmount),
+		ShippingFee:     moneyToNumeric(order.ShippingFee),
 		ShippingAddress: shippingAddrBytes,
 		PaymentMethod:   strPtr(order.PaymentMethod),
 		PaymentStatus:   strPtr(order.PaymentStatus),
-		PaidAmount:      float64ToNumeric(order.PaidAmount),
+		PaidAmount:      moneyToNumeric(order.PaidAmount),
 		PaymentDetails:  paymentDetailsBytes,
 		IsPreorder:      order.IsPreorder,
+		DiscountAmount:  moneyToNumeric(order.DiscountAmount),
+		CouponCode:      order.CouponCode,
+		Locale:          order.Locale,
+		CustomerPhone:   order.CustomerPhone,
+		IpAddress:       order.IPAddress,
+		RiskScore:       intPtrToInt32Ptr(order.RiskScore),
+		RiskReasons:     riskReasonsBytes,
+		OrderNumber:     domain.FormatOrderNumber(int(next.Year), int(next.LastNumber)),
 	})
 	if err != nil {
 		return err
 	}
 
 	order.ID = uuidToString(created.ID)
+	order.OrderNumber = created.OrderNumber
 	order.CreatedAt = pgtimeToTime(created.CreatedAt)
 	order.UpdatedAt = pgtimeToTime(created.UpdatedAt)
 
@@ -350,12 +452,12 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *domain.Order)
 			variantID = stringToUUID(*item.VariantID)
 		}
 
-		createdItem, err := r.queries.CreateOrderItem(ctx, sqlc.CreateOrderItemParams{
+		createdItem, err := r.getQueries(ctx).CreateOrderItem(ctx, sqlc.CreateOrderItemParams{
 			OrderID:   created.ID,
 			ProductID: stringToUUID(item.ProductID),
 			VariantID: variantID,
 			Quantity:  int32(item.Quantity),
-			Price:     float64ToNumeric(item.Price),
+			Price:     moneyToNumeric(item.Price),
 		})
 		if err != nil {
 			return err
@@ -369,13 +471,24 @@ func (r *orderRepository) CreateOrder(ctx context.Context, order *domain.Order)
 
 // ...
 
+func (r *orderRepository) GetByOrderNumber(ctx context.Context, orderNumber string) (*domain.Order, error) {
+	id, err := r.getQueries(ctx).GetOrderIDByNumber(ctx, orderNumber)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("order %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return r.GetByID(ctx, uuidToString(id))
+}
+
 func (r *orderRepository) GetByID(ctx context.Context, id string) (*domain.Order, error) {
-	row, err := r.queries.GetOrderByID(ctx, stringToUUID(id))
+	row, err := r.getQueries(ctx).GetOrderByID(ctx, stringToUUID(id))
 	if err != nil {
 		return nil, err
 	}
 
-	items, err := r.queries.GetOrderItems(ctx, row.ID)
+	items, err := r.getQueries(ctx).GetOrderItems(ctx, row.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -383,6 +496,7 @@ func (r *orderRepository) GetByID(ctx context.Context, id string) (*domain.Order
 	// Map row to sqlc.Order for the shared mapper
 	o := sqlc.Order{
 		ID:              row.ID,
+		OrderNumber:     row.OrderNumber,
 		UserID:          row.UserID,
 		Status:          row.Status,
 		TotalAmount:     row.TotalAmount,
@@ -396,6 +510,13 @@ func (r *orderRepository) GetByID(ctx context.Context, id string) (*domain.Order
 		IsPreorder:      row.IsPreorder,
 		RefundedAmount:  row.RefundedAmount,
 		ShippingFee:     row.ShippingFee,
+		DiscountAmount:  row.DiscountAmount,
+		CouponCode:      row.CouponCode,
+		Locale:          row.Locale,
+		CustomerPhone:   row.CustomerPhone,
+		IpAddress:       row.IpAddress,
+		RiskScore:       row.RiskScore,
+		RiskReasons:     row.RiskReasons,
 	}
 
 	order := sqlcOrderToDomain(o, items)
@@ -411,15 +532,43 @@ func (r *orderRepository) GetByID(ctx context.Context, id string) (*domain.Order
 	return order, nil
 }
 
+func (r *orderRepository) GetCustomerRiskHistory(ctx context.Context, q domain.RiskQuery) (*domain.CustomerRiskHistory, error) {
+	row, err := r.getQueries(ctx).GetCustomerRiskHistory(ctx, sqlc.GetCustomerRiskHistoryParams{
+		Phone:     strPtr(q.Phone),
+		Since:     pgtype.Timestamp{Time: q.Since, Valid: true},
+		UserID:    stringToUUID(q.UserID),
+		District:  q.District,
+		IpAddress: strPtr(q.IP),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CustomerRiskHistory{
+		PhoneOrders:       int(row.PhoneOrders),
+		PhoneFake:         int(row.PhoneFake),
+		PhoneReturned:     int(row.PhoneReturned),
+		PhoneCancelled:    int(row.PhoneCancelled),
+		PhoneRecent:       int(row.PhoneRecent),
+		PhoneOtherUsers:   int(row.PhoneOtherUsers),
+		PhoneSameDistrict: int(row.PhoneSameDistrict),
+		IPRecent:          int(row.IpRecent),
+		UserOrders:        int(row.UserOrders),
+		UserPhoneOrders:   int(row.UserPhoneOrders),
+		UserRecent:        int(row.UserRecent),
+		CompletedOrders:   int(row.CompletedOrders),
+		CompletedAvgValue: numericToMoney(row.CompletedAvgValue),
+	}, nil
+}
+
 func (r *orderRepository) GetByUserID(ctx context.Context, userID string) ([]domain.Order, error) {
-	orders, err := r.queries.GetOrdersByUserID(ctx, stringToUUID(userID))
+	orders, err := r.getQueries(ctx).GetOrdersByUserID(ctx, stringToUUID(userID))
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]domain.Order, len(orders))
 	for i, o := range orders {
-		items, _ := r.queries.GetOrderItems(ctx, o.ID)
+		items, _ := r.getQueries(ctx).GetOrderItems(ctx, o.ID)
 		order := sqlcOrderToDomain(o, items)
 		result[i] = *order
 	}
@@ -453,11 +602,16 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 		search = &filter.Search
 	}
 
-	orders, err := r.queries.GetAllOrders(ctx, sqlc.GetAllOrdersParams{
+	minRisk := intPtrToInt32Ptr(filter.MinRiskScore)
+	maxRisk := intPtrToInt32Ptr(filter.MaxRiskScore)
+
+	orders, err := r.getQueries(ctx).GetAllOrders(ctx, sqlc.GetAllOrdersParams{
 		Status:        status,
 		PaymentStatus: paymentStatus,
 		IsPreorder:    filter.IsPreorder,
 		Search:        search,
+		MinRiskScore:  minRisk,
+		MaxRiskScore:  maxRisk,
 		Limit:         int32(limit),
 		Offset:        int32(offset),
 	})
@@ -465,11 +619,13 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 		return nil, 0, err
 	}
 
-	count, err := r.queries.CountOrders(ctx, sqlc.CountOrdersParams{
+	count, err := r.getQueries(ctx).CountOrders(ctx, sqlc.CountOrdersParams{
 		Status:        status,
 		PaymentStatus: paymentStatus,
 		IsPreorder:    filter.IsPreorder,
 		Search:        search,
+		MinRiskScore:  minRisk,
+		MaxRiskScore:  maxRisk,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -479,15 +635,23 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 	for i, o := range orders {
 		result[i] = domain.Order{
 			ID:             uuidToString(o.ID),
+			OrderNumber:    o.OrderNumber,
 			UserID:         uuidToString(o.UserID),
 			Status:         o.Status,
-			TotalAmount:    numericToFloat64(o.TotalAmount),
+			TotalAmount:    numericToMoney(o.TotalAmount),
 			PaymentMethod:  ptrString(o.PaymentMethod),
 			PaymentStatus:  ptrString(o.PaymentStatus),
-			PaidAmount:     numericToFloat64(o.PaidAmount),
-			RefundedAmount: numericToFloat64(o.RefundedAmount),
-			ShippingFee:    numericToFloat64(o.ShippingFee),
+			PaidAmount:     numericToMoney(o.PaidAmount),
+			RefundedAmount: numericToMoney(o.RefundedAmount),
+			ShippingFee:    numericToMoney(o.ShippingFee),
+			DiscountAmount: numericToMoney(o.DiscountAmount),
+			CouponCode:     o.CouponCode,
 			IsPreorder:     o.IsPreorder,
+			Locale:         o.Locale,
+			CustomerPhone:  o.CustomerPhone,
+			IPAddress:      o.IpAddress,
+			RiskScore:      int32PtrToIntPtr(o.RiskScore),
+			RiskReasons:    riskReasonsFromJSON(o.RiskReasons),
 			CreatedAt:      pgtimeToTime(o.CreatedAt),
 			UpdatedAt:      pgtimeToTime(o.UpdatedAt),
 			User: domain.User{
@@ -509,7 +673,7 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 		}
 
 		// Fetch items for this order
-		items, _ := r.queries.GetOrderItems(ctx, o.ID)
+		items, _ := r.getQueries(ctx).GetOrderItems(ctx, o.ID)
 		domainItems := make([]domain.OrderItem, len(items))
 		for j, item := range items {
 			domainItems[j] = domain.OrderItem{
@@ -517,7 +681,7 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 				OrderID:     uuidToString(item.OrderID),
 				ProductID:   uuidToString(item.ProductID),
 				Quantity:    int(item.Quantity),
-				Price:       numericToFloat64(item.Price),
+				Price:       numericToMoney(item.Price),
 				VariantName: item.VariantName,
 				VariantSKU:  item.VariantSku,
 				Product: domain.Product{
@@ -541,56 +705,207 @@ func (r *orderRepository) GetAll(ctx context.Context, filter domain.OrderFilter)
 }
 
 func (r *orderRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	return r.queries.UpdateOrderStatus(ctx, sqlc.UpdateOrderStatusParams{
+	return r.getQueries(ctx).UpdateOrderStatus(ctx, sqlc.UpdateOrderStatusParams{
 		ID:     stringToUUID(id),
 		Status: status,
 	})
 }
 
 func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, id, status string) error {
-	return r.queries.UpdateOrderPaymentStatus(ctx, sqlc.UpdateOrderPaymentStatusParams{
+	return r.getQueries(ctx).UpdateOrderPaymentStatus(ctx, sqlc.UpdateOrderPaymentStatusParams{
 		ID:            stringToUUID(id),
 		PaymentStatus: strPtr(status),
 	})
 }
 
-func (r *orderRepository) UpdatePaidAmount(ctx context.Context, id string, amount float64) error {
-	return r.queries.UpdateOrderPaidAmount(ctx, sqlc.UpdateOrderPaidAmountParams{
-		ID:         stringToUUID(id),
-		PaidAmount: float64ToNumeric(amount),
+func (r *orderRepository) LockForUpdate(ctx context.Context, id string) error {
+	if _, err := r.getQueries(ctx).LockOrder(ctx, stringToUUID(id)); err != nil {
+		if err.Error() == "no rows in result set" {
+			return fmt.Errorf("order %w", domain.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *orderRepository) UpdateTotalAmount(ctx context.Context, id string, amount domain.Money) error {
+	return r.getQueries(ctx).UpdateOrderTotalAmount(ctx, sqlc.UpdateOrderTotalAmountParams{
+		ID:          stringToUUID(id),
+		TotalAmount: moneyToNumeric(amount),
+	})
+}
+
+func (r *orderRepository) UpdateOrderAmounts(ctx context.Context, id string, totalAmount, discountAmount domain.Money, isPreorder bool, paymentDetails domain.JSONB) error {
+	detailsBytes, err := json.Marshal(paymentDetails)
+	if err != nil {
+		return err
+	}
+	return r.getQueries(ctx).UpdateOrderAmounts(ctx, sqlc.UpdateOrderAmountsParams{
+		ID:             stringToUUID(id),
+		TotalAmount:    moneyToNumeric(totalAmount),
+		DiscountAmount: moneyToNumeric(discountAmount),
+		IsPreorder:     isPreorder,
+		PaymentDetails: detailsBytes,
+	})
+}
+
+func (r *orderRepository) AddOrderItem(ctx context.Context, orderID string, item *domain.OrderItem) error {
+	var variantID pgtype.UUID
+	if item.VariantID != nil {
+		variantID = stringToUUID(*item.VariantID)
+	}
+	created, err := r.getQueries(ctx).CreateOrderItem(ctx, sqlc.CreateOrderItemParams{
+		OrderID:   stringToUUID(orderID),
+		ProductID: stringToUUID(item.ProductID),
+		VariantID: variantID,
+		Quantity:  int32(item.Quantity),
+		Price:     moneyToNumeric(item.Price),
+	})
+	if err != nil {
+		return err
+	}
+	item.ID = uuidToString(created.ID)
+	item.OrderID = orderID
+	return nil
+}
+
+func (r *orderRepository) UpdateOrderItem(ctx context.Context, itemID string, quantity int, price domain.Money) error {
+	return r.getQueries(ctx).UpdateOrderItem(ctx, sqlc.UpdateOrderItemParams{
+		ID:       stringToUUID(itemID),
+		Quantity: int32(quantity),
+		Price:    moneyToNumeric(price),
 	})
 }
 
+func (r *orderRepository) DeleteOrderItem(ctx context.Context, itemID string) error {
+	return r.getQueries(ctx).DeleteOrderItem(ctx, stringToUUID(itemID))
+}
+
 func (r *orderRepository) HasPurchasedProduct(ctx context.Context, userID, productID string) (bool, error) {
-	return r.queries.HasPurchasedProduct(ctx, sqlc.HasPurchasedProductParams{
+	return r.getQueries(ctx).HasPurchasedProduct(ctx, sqlc.HasPurchasedProductParams{
 		UserID:    stringToUUID(userID),
 		ProductID: stringToUUID(productID),
 	})
 }
 
-func (r *orderRepository) CreateRefund(ctx context.Context, orderID string, amount float64, reason string, restock bool, createdBy *string) error {
+func (r *orderRepository) CreateRefund(ctx context.Context, refund *domain.Refund) error {
 	var createdByUUID pgtype.UUID
-	if createdBy != nil {
-		createdByUUID = stringToUUID(*createdBy)
+	if refund.CreatedBy != nil {
+		createdByUUID = stringToUUID(*refund.CreatedBy)
+	}
+	reason := ""
+	if refund.Reason != nil {
+		reason = *refund.Reason
 	}
 
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
 	// 1. Create Refund Record
-	_, err := r.queries.CreateRefund(ctx, sqlc.CreateRefundParams{
-		OrderID:      stringToUUID(orderID),
-		Amount:       float64ToNumeric(amount),
-		Reason:       strPtr(reason),
-		RestockItems: restock,
-		CreatedBy:    createdByUUID,
+	created, err := q.CreateRefund(ctx, sqlc.CreateRefundParams{
+		OrderID:        stringToUUID(refund.OrderID),
+		Amount:         moneyToNumeric(refund.Amount),
+		Reason:         strPtr(reason),
+		RestockItems:   refund.Restock,
+		CreatedBy:      createdByUUID,
+		Method:         refund.Method,
+		Reference:      refund.Reference,
+		ShippingAmount: moneyToNumeric(refund.ShippingAmount),
 	})
 	if err != nil {
 		return err
 	}
+	for _, item := range refund.Items {
+		if err := q.AddRefundItem(ctx, sqlc.AddRefundItemParams{
+			RefundID:    created.ID,
+			OrderItemID: stringToUUID(item.OrderItemID),
+			Quantity:    int32(item.Quantity),
+			Amount:      moneyToNumeric(item.Amount),
+		}); err != nil {
+			return err
+		}
+	}
 
-	// 2. Update Order Totals
-	return r.queries.UpdateOrderRefundedAmount(ctx, sqlc.UpdateOrderRefundedAmountParams{
-		Amount: float64ToNumeric(amount),
-		ID:     stringToUUID(orderID),
-	})
+	// 2. Money out in the payment ledger, which re-derives the order's refunded amount
+	if refund.Amount > 0 {
+		refundID := uuidToString(created.ID)
+		paidOutAt := pgtimeToTime(created.CreatedAt)
+		if err := recordPayment(ctx, q, &domain.Payment{
+			OrderID:       refund.OrderID,
+			Direction:     domain.PaymentDirectionOut,
+			Amount:        refund.Amount,
+			Method:        refund.Method,
+			TransactionID: refund.Reference,
+			Status:        domain.PaymentEntryVerified,
+			RefundID:      &refundID,
+			VerifiedBy:    refund.CreatedBy,
+			VerifiedAt:    &paidOutAt,
+			CreatedBy:     refund.CreatedBy,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 3. Update Order Status
+	if err := q.UpdateOrderRefundStatus(ctx, stringToUUID(refund.OrderID)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	refund.ID = uuidToString(created.ID)
+	refund.CreatedAt = pgtimeToTime(created.CreatedAt)
+	return nil
+}
+
+func (r *orderRepository) GetRefunds(ctx context.Context, orderID string) ([]domain.Refund, error) {
+	rows, err := r.getQueries(ctx).GetRefundsByOrderID(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	itemRows, err := r.getQueries(ctx).ListRefundItemsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string][]domain.RefundItem)
+	for _, row := range itemRows {
+		refundID := uuidToString(row.RefundID)
+		items[refundID] = append(items[refundID], domain.RefundItem{
+			OrderItemID: uuidToString(row.OrderItemID),
+			Quantity:    int(row.Quantity),
+			Amount:      numericToMoney(row.Amount),
+		})
+	}
+
+	refunds := make([]domain.Refund, len(rows))
+	for i, row := range rows {
+		refunds[i] = domain.Refund{
+			ID:              uuidToString(row.ID),
+			OrderID:         uuidToString(row.OrderID),
+			Amount:          numericToMoney(row.Amount),
+			ShippingAmount:  numericToMoney(row.ShippingAmount),
+			Method:          row.Method,
+			Reference:       row.Reference,
+			Reason:          row.Reason,
+			Restock:         row.RestockItems,
+			Items:           items[uuidToString(row.ID)],
+			ProcessedByName: row.ProcessedByName,
+			CreatedAt:       pgtimeToTime(row.CreatedAt),
+		}
+		if refunds[i].Items == nil {
+			refunds[i].Items = []domain.RefundItem{}
+		}
+		if row.CreatedBy.Valid {
+			uid := uuidToString(row.CreatedBy)
+			refunds[i].CreatedBy = &uid
+		}
+	}
+	return refunds, nil
 }
 
 func (r *orderRepository) CreateOrderHistory(ctx context.Context, history *domain.OrderHistory) error {
@@ -598,19 +913,24 @@ func (r *orderRepository) CreateOrderHistory(ctx context.Context, history *domai
 	if history.CreatedBy != nil {
 		createdBy = stringToUUID(*history.CreatedBy)
 	}
+	var shipmentID pgtype.UUID
+	if history.ShipmentID != nil {
+		shipmentID = stringToUUID(*history.ShipmentID)
+	}
 
-	_, err := r.queries.CreateOrderHistory(ctx, sqlc.CreateOrderHistoryParams{
+	_, err := r.getQueries(ctx).CreateOrderHistory(ctx, sqlc.CreateOrderHistoryParams{
 		OrderID:        stringToUUID(history.OrderID),
 		PreviousStatus: history.PreviousStatus,
 		NewStatus:      history.NewStatus,
 		Reason:         history.Reason,
 		CreatedBy:      createdBy,
+		ShipmentID:     shipmentID,
 	})
 	return err
 }
 
 func (r *orderRepository) GetOrderHistory(ctx context.Context, orderID string) ([]domain.OrderHistory, error) {
-	rows, err := r.queries.GetOrderHistory(ctx, stringToUUID(orderID))
+	rows, err := r.getQueries(ctx).GetOrderHistory(ctx, stringToUUID(orderID))
 	if err != nil {
 		return nil, err
 	}
@@ -633,6 +953,10 @@ func (r *orderRepository) GetOrderHistory(ctx context.Context, orderID string) (
 			uid := uuidToString(row.CreatedBy)
 			h.CreatedBy = &uid
 		}
+		if row.ShipmentID.Valid {
+			sid := uuidToString(row.ShipmentID)
+			h.ShipmentID = &sid
+		}
 
 		if row.FirstName != nil || row.LastName != nil {
 			fname := ""
@@ -653,15 +977,15 @@ func (r *orderRepository) GetOrderHistory(ctx context.Context, orderID string) (
 	return history, nil
 }
 
-func (r *orderRepository) UpdateOrderShippingDetails(ctx context.Context, id string, address domain.JSONB, shippingFee, totalAmount float64) error {
+func (r *orderRepository) UpdateOrderShippingDetails(ctx context.Context, id string, address domain.JSONB, shippingFee, totalAmount domain.Money) error {
 	addrBytes, err := json.Marshal(address)
 	if err != nil {
 		return err
 	}
-	return r.queries.UpdateOrderShippingDetails(ctx, sqlc.UpdateOrderShippingDetailsParams{
+	return r.getQueries(ctx).UpdateOrderShippingDetails(ctx, sqlc.UpdateOrderShippingDetailsParams{
 		ID:              stringToUUID(id),
 		ShippingAddress: addrBytes,
-		ShippingFee:     float64ToNumeric(shippingFee),
-		TotalAmount:     float64ToNumeric(totalAmount),
+		ShippingFee:     moneyToNumeric(shippingFee),
+		TotalAmount:     moneyToNumeric(totalAmount),
 	})
 }
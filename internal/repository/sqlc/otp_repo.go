@@ -0,0 +1,93 @@
+package sqlcrepo
+
+import (
+	"context"
+	"time"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type orderOTPRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewOrderOTPRepository(db *pgxpool.Pool) domain.OrderOTPRepository {
+	return &orderOTPRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *orderOTPRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func (r *orderOTPRepository) Create(ctx context.Context, otp *domain.OrderOTP, ttl time.Duration) error {
+	created, err := r.getQueries(ctx).CreateOrderOTP(ctx, sqlc.CreateOrderOTPParams{
+		OrderID:     stringToUUID(otp.OrderID),
+		Phone:       otp.Phone,
+		CodeHash:    otp.CodeHash,
+		MaxAttempts: int32(otp.MaxAttempts),
+		TtlSeconds:  ttl.Seconds(),
+	})
+	if err != nil {
+		return err
+	}
+	otp.ID = uuidToString(created.ID)
+	otp.Attempts = int(created.Attempts)
+	otp.ExpiresAt = pgtimeToTime(created.ExpiresAt)
+	otp.CreatedAt = pgtimeToTime(created.CreatedAt)
+	return nil
+}
+
+func (r *orderOTPRepository) GetLatest(ctx context.Context, orderID string) (*domain.OrderOTP, error) {
+	o, err := r.getQueries(ctx).GetLatestOrderOTP(ctx, stringToUUID(orderID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &domain.OrderOTP{
+		ID:          uuidToString(o.ID),
+		OrderID:     uuidToString(o.OrderID),
+		Phone:       o.Phone,
+		CodeHash:    o.CodeHash,
+		Attempts:    int(o.Attempts),
+		MaxAttempts: int(o.MaxAttempts),
+		ExpiresAt:   pgtimeToTime(o.ExpiresAt),
+		Expired:     o.Expired,
+		VerifiedAt:  toTimePtr(o.VerifiedAt),
+		CreatedAt:   pgtimeToTime(o.CreatedAt),
+	}, nil
+}
+
+func (r *orderOTPRepository) SendStats(ctx context.Context, orderID string) (int, time.Duration, error) {
+	stats, err := r.getQueries(ctx).GetOrderOTPSendStats(ctx, stringToUUID(orderID))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(stats.Sent), time.Duration(stats.SecondsSinceLast * float64(time.Second)), nil
+}
+
+func (r *orderOTPRepository) UseAttempt(ctx context.Context, id string) (int, bool, error) {
+	row, err := r.getQueries(ctx).UseOrderOTPAttempt(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return int(row.Attempts), true, nil
+}
+
+func (r *orderOTPRepository) MarkVerified(ctx context.Context, id string) (bool, error) {
+	rows, err := r.getQueries(ctx).MarkOrderOTPVerified(ctx, stringToUUID(id))
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
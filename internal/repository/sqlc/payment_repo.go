@@ -0,0 +1,140 @@
+package sqlcrepo
+
+import (
+	"context"
+	"encoding/json"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type paymentRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) domain.PaymentRepository {
+	return &paymentRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *paymentRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcPaymentSessionToDomain(s sqlc.PaymentSession) *domain.PaymentSession {
+	session := &domain.PaymentSession{
+		ID:            uuidToString(s.ID),
+		OrderID:       uuidToString(s.OrderID),
+		Provider:      s.Provider,
+		Amount:        numericToMoney(s.Amount),
+		Currency:      s.Currency,
+		Status:        s.Status,
+		GatewayRef:    s.GatewayRef,
+		RedirectURL:   s.RedirectUrl,
+		TransactionID: s.TransactionID,
+		CreatedAt:     pgtimeToTime(s.CreatedAt),
+		UpdatedAt:     pgtimeToTime(s.UpdatedAt),
+	}
+	if s.CompletedAt.Valid {
+		t := s.CompletedAt.Time
+		session.CompletedAt = &t
+	}
+	return session
+}
+
+func (r *paymentRepository) CreateSession(ctx context.Context, session *domain.PaymentSession) error {
+	currency := session.Currency
+	if currency == "" {
+		currency = "BDT"
+	}
+	created, err := r.getQueries(ctx).CreatePaymentSession(ctx, sqlc.CreatePaymentSessionParams{
+		OrderID:  stringToUUID(session.OrderID),
+		Provider: session.Provider,
+		Amount:   moneyToNumeric(session.Amount),
+		Currency: currency,
+	})
+	if err != nil {
+		return err
+	}
+	*session = *sqlcPaymentSessionToDomain(created)
+	return nil
+}
+
+func (r *paymentRepository) SetSessionGateway(ctx context.Context, id, gatewayRef, redirectURL string) error {
+	return r.getQueries(ctx).UpdatePaymentSessionGateway(ctx, sqlc.UpdatePaymentSessionGatewayParams{
+		ID:          stringToUUID(id),
+		GatewayRef:  &gatewayRef,
+		RedirectUrl: &redirectURL,
+	})
+}
+
+func (r *paymentRepository) GetSessionByID(ctx context.Context, id string) (*domain.PaymentSession, error) {
+	s, err := r.getQueries(ctx).GetPaymentSessionByID(ctx, stringToUUID(id))
+	if err != nil {
+		return nil, err
+	}
+	return sqlcPaymentSessionToDomain(s), nil
+}
+
+func (r *paymentRepository) GetSessionForUpdate(ctx context.Context, id string) (*domain.PaymentSession, error) {
+	s, err := r.getQueries(ctx).GetPaymentSessionForUpdate(ctx, stringToUUID(id))
+	if err != nil {
+		return nil, err
+	}
+	return sqlcPaymentSessionToDomain(s), nil
+}
+
+func (r *paymentRepository) GetSessionByGatewayRef(ctx context.Context, provider, gatewayRef string) (*domain.PaymentSession, error) {
+	s, err := r.getQueries(ctx).GetPaymentSessionByGatewayRef(ctx, sqlc.GetPaymentSessionByGatewayRefParams{
+		Provider:   provider,
+		GatewayRef: &gatewayRef,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sqlcPaymentSessionToDomain(s), nil
+}
+
+func (r *paymentRepository) ListSessionsByOrder(ctx context.Context, orderID string) ([]domain.PaymentSession, error) {
+	rows, err := r.getQueries(ctx).ListPaymentSessionsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]domain.PaymentSession, len(rows))
+	for i, s := range rows {
+		sessions[i] = *sqlcPaymentSessionToDomain(s)
+	}
+	return sessions, nil
+}
+
+func (r *paymentRepository) CompleteSession(ctx context.Context, id, status string, transactionID *string) error {
+	return r.getQueries(ctx).CompletePaymentSession(ctx, sqlc.CompletePaymentSessionParams{
+		ID:            stringToUUID(id),
+		Status:        status,
+		TransactionID: transactionID,
+	})
+}
+
+func (r *paymentRepository) RecordCallback(ctx context.Context, provider string, cb *domain.PaymentCallback) (bool, error) {
+	payload, err := json.Marshal(cb.Payload)
+	if err != nil {
+		return false, err
+	}
+	if cb.Payload == nil {
+		payload = []byte("{}")
+	}
+
+	rows, err := r.getQueries(ctx).RecordPaymentCallback(ctx, sqlc.RecordPaymentCallbackParams{
+		Provider:  provider,
+		EventKey:  cb.EventKey,
+		SessionID: stringToUUID(cb.SessionID),
+		Status:    cb.Status,
+		Payload:   payload,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
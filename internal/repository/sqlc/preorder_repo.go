@@ -0,0 +1,250 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type preorderRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewPreorderRepository(db *pgxpool.Pool) domain.PreorderRepository {
+	return &preorderRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *preorderRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcPreorderBalanceRequestToDomain(b sqlc.PreorderBalanceRequest) domain.PreorderBalanceRequest {
+	return domain.PreorderBalanceRequest{
+		ID:            uuidToString(b.ID),
+		OrderID:       uuidToString(b.OrderID),
+		ReleaseID:     uuidToString(b.ReleaseID),
+		Amount:        numericToMoney(b.Amount),
+		Status:        b.Status,
+		PaidAmount:    numericToMoney(b.PaidAmount),
+		PaymentMethod: b.PaymentMethod,
+		Reference:     b.Reference,
+		PaidAt:        toTimePtr(b.PaidAt),
+		CreatedAt:     pgtimeToTime(b.CreatedAt),
+		UpdatedAt:     pgtimeToTime(b.UpdatedAt),
+	}
+}
+
+func sqlcPreorderReleaseToDomain(rel sqlc.PreorderRelease, productName string, orderCount int32) domain.PreorderRelease {
+	return domain.PreorderRelease{
+		ID:          uuidToString(rel.ID),
+		ProductID:   uuidToString(rel.ProductID),
+		ProductName: productName,
+		Note:        rel.Note,
+		ReleasedBy:  optionalUUID(rel.ReleasedBy),
+		OrderCount:  int(orderCount),
+		CreatedAt:   pgtimeToTime(rel.CreatedAt),
+	}
+}
+
+func (r *preorderRepository) LockProductLimit(ctx context.Context, productID string) (*int, error) {
+	limit, err := r.getQueries(ctx).LockPreorderProduct(ctx, stringToUUID(productID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("product %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return int32PtrToIntPtr(limit), nil
+}
+
+func (r *preorderRepository) CountOpenUnits(ctx context.Context, productID string) (int, error) {
+	units, err := r.getQueries(ctx).CountOpenPreorderUnits(ctx, stringToUUID(productID))
+	return int(units), err
+}
+
+func (r *preorderRepository) CreateRelease(ctx context.Context, release *domain.PreorderRelease) ([]string, error) {
+	releasedBy := ""
+	if release.ReleasedBy != nil {
+		releasedBy = *release.ReleasedBy
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	created, err := q.CreatePreorderRelease(ctx, sqlc.CreatePreorderReleaseParams{
+		ProductID:  stringToUUID(release.ProductID),
+		Note:       release.Note,
+		ReleasedBy: stringToUUID(releasedBy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	orderIDs, err := q.ReleasePreorderItems(ctx, sqlc.ReleasePreorderItemsParams{
+		ReleaseID: created.ID,
+		ProductID: created.ProductID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(orderIDs))
+	for i, id := range orderIDs {
+		ids[i] = uuidToString(id)
+	}
+	*release = sqlcPreorderReleaseToDomain(created, release.ProductName, int32(len(ids)))
+	return ids, nil
+}
+
+func (r *preorderRepository) GetRelease(ctx context.Context, id string) (*domain.PreorderRelease, error) {
+	row, err := r.getQueries(ctx).GetPreorderRelease(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("pre-order release %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	release := sqlcPreorderReleaseToDomain(sqlc.PreorderRelease{
+		ID:         row.ID,
+		ProductID:  row.ProductID,
+		Note:       row.Note,
+		ReleasedBy: row.ReleasedBy,
+		CreatedAt:  row.CreatedAt,
+	}, row.ProductName, row.OrderCount)
+	return &release, nil
+}
+
+func (r *preorderRepository) ListReleases(ctx context.Context, productID string) ([]domain.PreorderRelease, error) {
+	rows, err := r.getQueries(ctx).ListPreorderReleases(ctx, stringToUUID(productID))
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]domain.PreorderRelease, len(rows))
+	for i, row := range rows {
+		releases[i] = sqlcPreorderReleaseToDomain(sqlc.PreorderRelease{
+			ID:         row.ID,
+			ProductID:  row.ProductID,
+			Note:       row.Note,
+			ReleasedBy: row.ReleasedBy,
+			CreatedAt:  row.CreatedAt,
+		}, row.ProductName, row.OrderCount)
+	}
+	return releases, nil
+}
+
+func (r *preorderRepository) CreateBalanceRequest(ctx context.Context, req *domain.PreorderBalanceRequest) error {
+	created, err := r.getQueries(ctx).CreatePreorderBalanceRequest(ctx, sqlc.CreatePreorderBalanceRequestParams{
+		OrderID:   stringToUUID(req.OrderID),
+		ReleaseID: stringToUUID(req.ReleaseID),
+		Amount:    moneyToNumeric(req.Amount),
+	})
+	if err != nil {
+		return err
+	}
+	orderNumber := req.OrderNumber
+	*req = sqlcPreorderBalanceRequestToDomain(created)
+	req.OrderNumber = orderNumber
+	return nil
+}
+
+func (r *preorderRepository) GetBalanceRequest(ctx context.Context, id string) (*domain.PreorderBalanceRequest, error) {
+	row, err := r.getQueries(ctx).GetPreorderBalanceRequest(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("balance request %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	req := sqlcPreorderBalanceRequestToDomain(sqlc.PreorderBalanceRequest{
+		ID:            row.ID,
+		OrderID:       row.OrderID,
+		ReleaseID:     row.ReleaseID,
+		Amount:        row.Amount,
+		Status:        row.Status,
+		PaidAmount:    row.PaidAmount,
+		PaymentMethod: row.PaymentMethod,
+		Reference:     row.Reference,
+		PaidAt:        row.PaidAt,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	})
+	req.OrderNumber = row.OrderNumber
+	return &req, nil
+}
+
+func (r *preorderRepository) GetBalanceRequestForUpdate(ctx context.Context, id string) (*domain.PreorderBalanceRequest, error) {
+	row, err := r.getQueries(ctx).GetPreorderBalanceRequestForUpdate(ctx, stringToUUID(id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("balance request %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	req := sqlcPreorderBalanceRequestToDomain(row)
+	return &req, nil
+}
+
+func (r *preorderRepository) GetPendingBalanceRequest(ctx context.Context, orderID string) (*domain.PreorderBalanceRequest, error) {
+	row, err := r.getQueries(ctx).GetPendingPreorderBalanceRequest(ctx, stringToUUID(orderID))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("balance request %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	req := sqlcPreorderBalanceRequestToDomain(row)
+	return &req, nil
+}
+
+func (r *preorderRepository) ListBalanceRequests(ctx context.Context, releaseID string) ([]domain.PreorderBalanceRequest, error) {
+	rows, err := r.getQueries(ctx).ListPreorderBalanceRequests(ctx, stringToUUID(releaseID))
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]domain.PreorderBalanceRequest, len(rows))
+	for i, row := range rows {
+		requests[i] = sqlcPreorderBalanceRequestToDomain(sqlc.PreorderBalanceRequest{
+			ID:            row.ID,
+			OrderID:       row.OrderID,
+			ReleaseID:     row.ReleaseID,
+			Amount:        row.Amount,
+			Status:        row.Status,
+			PaidAmount:    row.PaidAmount,
+			PaymentMethod: row.PaymentMethod,
+			Reference:     row.Reference,
+			PaidAt:        row.PaidAt,
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+		})
+		requests[i].OrderNumber = row.OrderNumber
+	}
+	return requests, nil
+}
+
+func (r *preorderRepository) RecordBalancePayment(ctx context.Context, id string, amount domain.Money, method string, reference *string, settled bool) error {
+	return r.getQueries(ctx).RecordPreorderBalancePayment(ctx, sqlc.RecordPreorderBalancePaymentParams{
+		ID:            stringToUUID(id),
+		Amount:        moneyToNumeric(amount),
+		PaymentMethod: &method,
+		Reference:     reference,
+		Settled:       settled,
+	})
+}
+
+func (r *preorderRepository) CancelBalanceRequest(ctx context.Context, id string) (bool, error) {
+	rows, err := r.getQueries(ctx).CancelPreorderBalanceRequest(ctx, stringToUUID(id))
+	return rows > 0, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 	"sync"
 	"time"
@@ -59,6 +60,49 @@ func numericToFloat64Ptr(n pgtype.Numeric) *float64 {
 	return &val
 }
 
+// numericToMoney converts a NUMERIC amount to paisa exactly (amounts are stored with
+// two decimals; any further places are rounded half away from zero). NULL is zero.
+func numericToMoney(n pgtype.Numeric) domain.Money {
+	if !n.Valid || n.NaN || n.Int == nil {
+		return 0
+	}
+	paisa := new(big.Int).Set(n.Int)
+	shift := int64(n.Exp) + 2
+	if shift >= 0 {
+		paisa.Mul(paisa, new(big.Int).Exp(big.NewInt(10), big.NewInt(shift), nil))
+		return domain.Money(paisa.Int64())
+	}
+	div := new(big.Int).Exp(big.NewInt(10), big.NewInt(-shift), nil)
+	q, r := new(big.Int).QuoRem(paisa, div, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(div) >= 0 {
+		if paisa.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return domain.Money(q.Int64())
+}
+
+func numericToMoneyPtr(n pgtype.Numeric) *domain.Money {
+	if !n.Valid {
+		return nil
+	}
+	m := numericToMoney(n)
+	return &m
+}
+
+func moneyToNumeric(m domain.Money) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(m.Paisa()), Exp: -2, Valid: true}
+}
+
+func moneyPtrToNumeric(m *domain.Money) pgtype.Numeric {
+	if m == nil {
+		return pgtype.Numeric{}
+	}
+	return moneyToNumeric(*m)
+}
+
 // --- Mappers ---
 
 func ptrStrToStr(s *string) string {
@@ -68,14 +112,37 @@ func ptrStrToStr(s *string) string {
 	return *s
 }
 
+func timePtrToPgtime(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: *t, Valid: true}
+}
+
+func int32PtrToIntPtr(n *int32) *int {
+	if n == nil {
+		return nil
+	}
+	v := int(*n)
+	return &v
+}
+
+func intPtrToInt32Ptr(n *int) *int32 {
+	if n == nil {
+		return nil
+	}
+	v := int32(*n)
+	return &v
+}
+
 func sqlcProductToDomain(p sqlc.Product) domain.Product {
 	prod := domain.Product{
 		ID:                    uuidToString(p.ID),
 		Name:                  p.Name,
 		Slug:                  p.Slug,
 		Description:           ptrString(p.Description),
-		BasePrice:             numericToFloat64(p.BasePrice),
-		SalePrice:             numericToFloat64Ptr(p.SalePrice),
+		BasePrice:             numericToMoney(p.BasePrice),
+		SalePrice:             numericToMoneyPtr(p.SalePrice),
 		StockStatus:           ptrString(p.StockStatus),
 		IsFeatured:            p.IsFeatured,
 		IsActive:              p.IsActive,
@@ -88,7 +155,9 @@ func sqlcProductToDomain(p sqlc.Product) domain.Product {
 		Brand:                 ptrString(p.Brand),
 		Tags:                  p.Tags,
 		IsPreorder:            p.IsPreorder,
-		PreorderDepositAmount: numericToFloat64(p.PreorderDepositAmount),
+		PreorderDepositAmount: numericToMoney(p.PreorderDepositAmount),
+		PreorderReleaseDate:   toTimePtr(p.PreorderReleaseDate),
+		PreorderLimit:         int32PtrToIntPtr(p.PreorderLimit),
 	}
 
 	// Handle Media (JSONB)
@@ -198,9 +267,10 @@ func sqlcVariantToDomain(v sqlc.Variant) domain.Variant {
 		ProductID:         uuidToString(v.ProductID),
 		Name:              v.Name,
 		Stock:             int(v.Stock),
+		Available:         int(v.Stock),
 		SKU:               ptrString(v.Sku),
-		Price:             numericToFloat64Ptr(v.Price),
-		SalePrice:         numericToFloat64Ptr(v.SalePrice),
+		Price:             numericToMoneyPtr(v.Price),
+		SalePrice:         numericToMoneyPtr(v.SalePrice),
 		Images:            v.Images,
 		Weight:            numericToFloat64Ptr(v.Weight),
 		Barcode:           ptrString(v.Barcode),
@@ -664,14 +734,18 @@ func (r *productRepository) enrichVariants(ctx context.Context, products []domai
 		prodVarMap := make(map[string][]domain.Variant)
 		prodStockMap := make(map[string]int)
 
+		all := make([]domain.Variant, 0, len(variantRows))
 		for _, v := range variantRows {
 			if v.ProductID.Valid {
-				pid := uuidToString(v.ProductID)
-				variant := sqlcVariantToDomain(v)
-				prodVarMap[pid] = append(prodVarMap[pid], variant)
-				prodStockMap[pid] += variant.Stock
+				all = append(all, sqlcVariantToDomain(v))
 			}
 		}
+		r.applyReservations(ctx, r.queries, all)
+
+		for _, variant := range all {
+			prodVarMap[variant.ProductID] = append(prodVarMap[variant.ProductID], variant)
+			prodStockMap[variant.ProductID] += variant.Available
+		}
 
 		for i := range products {
 			pid := products[i].ID
@@ -702,6 +776,30 @@ func (r *productRepository) enrichVariants(ctx context.Context, products []domai
 	}
 }
 
+// applyReservations sets Reserved/Available from active stock reservations.
+// Best-effort like the other hydrators: on error, Available stays equal to Stock.
+func (r *productRepository) applyReservations(ctx context.Context, q *sqlc.Queries, variants []domain.Variant) {
+	if len(variants) == 0 {
+		return
+	}
+	ids := make([]pgtype.UUID, len(variants))
+	for i, v := range variants {
+		ids[i] = stringToUUID(v.ID)
+	}
+	rows, err := q.GetReservedQuantities(ctx, sqlc.GetReservedQuantitiesParams{VariantIds: ids})
+	if err != nil {
+		return
+	}
+	reserved := make(map[string]int, len(rows))
+	for _, row := range rows {
+		reserved[uuidToString(row.VariantID)] = int(row.Reserved)
+	}
+	for i := range variants {
+		variants[i].Reserved = reserved[variants[i].ID]
+		variants[i].Available = max(variants[i].Stock-variants[i].Reserved, 0)
+	}
+}
+
 func (r *productRepository) enrichCategories(ctx context.Context, products []domain.Product, productIDs []pgtype.UUID) {
 	catRows, err := r.queries.GetCategoryIDsForProducts(ctx, productIDs)
 	if err == nil && len(catRows) > 0 {
@@ -803,11 +901,13 @@ func (r *productRepository) GetProductBySlug(ctx context.Context, slug string) (
 	// Load variants
 	variants, _ := r.queries.GetVariantsByProductID(ctx, p.ID)
 	prod.Variants = make([]domain.Variant, len(variants))
-	totalStock := 0
 	for i, v := range variants {
-		variant := sqlcVariantToDomain(v)
-		prod.Variants[i] = variant
-		totalStock += variant.Stock
+		prod.Variants[i] = sqlcVariantToDomain(v)
+	}
+	r.applyReservations(ctx, r.queries, prod.Variants)
+	totalStock := 0
+	for _, variant := range prod.Variants {
+		totalStock += variant.Available
 	}
 	prod.Stock = totalStock
 
@@ -865,11 +965,13 @@ func (r *productRepository) GetProductByID(ctx context.Context, id string) (*dom
 	// Load variants
 	variants, _ := r.queries.GetVariantsByProductID(ctx, p.ID)
 	prod.Variants = make([]domain.Variant, len(variants))
-	totalStock := 0
 	for i, v := range variants {
-		variant := sqlcVariantToDomain(v)
-		prod.Variants[i] = variant
-		totalStock += variant.Stock
+		prod.Variants[i] = sqlcVariantToDomain(v)
+	}
+	r.applyReservations(ctx, r.queries, prod.Variants)
+	totalStock := 0
+	for _, variant := range prod.Variants {
+		totalStock += variant.Available
 	}
 	prod.Stock = totalStock
 
@@ -918,7 +1020,7 @@ func (r *productRepository) GetProductByID(ctx context.Context, id string) (*dom
 }
 
 func (r *productRepository) UpdateStock(ctx context.Context, variantID string, quantity int, reason, referenceID string) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -960,6 +1062,18 @@ func (r *productRepository) UpdateStock(ctx context.Context, variantID string, q
 	return tx.Commit(ctx)
 }
 
+func (r *productRepository) GetOrderNetStockChanges(ctx context.Context, orderID string) (map[string]int, error) {
+	rows, err := GetQueriesFromContext(ctx, r.queries).GetOrderNetStockChanges(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]int, len(rows))
+	for _, row := range rows {
+		changes[uuidToString(row.VariantID)] = int(row.NetChange)
+	}
+	return changes, nil
+}
+
 func (r *productRepository) GetInventoryLogs(ctx context.Context, productID string, limit, offset int) ([]domain.InventoryLog, int64, error) {
 	var prodUUID pgtype.UUID
 	if productID != "" {
@@ -1018,8 +1132,8 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 		Name:                  product.Name,
 		Slug:                  product.Slug,
 		Description:           strPtr(product.Description),
-		BasePrice:             float64ToNumeric(product.BasePrice),
-		SalePrice:             float64PtrToNumeric(product.SalePrice),
+		BasePrice:             moneyToNumeric(product.BasePrice),
+		SalePrice:             moneyPtrToNumeric(product.SalePrice),
 		StockStatus:           strPtr(product.StockStatus),
 		IsFeatured:            product.IsFeatured,
 		IsActive:              product.IsActive,
@@ -1034,7 +1148,9 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 		Tags:                  product.Tags,
 		WarrantyInfo:          warrantyBytes,
 		IsPreorder:            product.IsPreorder,
-		PreorderDepositAmount: float64ToNumeric(product.PreorderDepositAmount),
+		PreorderDepositAmount: moneyToNumeric(product.PreorderDepositAmount),
+		PreorderReleaseDate:   timePtrToPgtime(product.PreorderReleaseDate),
+		PreorderLimit:         intPtrToInt32Ptr(product.PreorderLimit),
 	})
 	if err != nil {
 		return err
@@ -1065,8 +1181,8 @@ func (r *productRepository) CreateProduct(ctx context.Context, product *domain.P
 				Name:              v.Name,
 				Stock:             int32(v.Stock),
 				Sku:               strPtr(v.SKU),
-				Price:             float64PtrToNumeric(v.Price),
-				SalePrice:         float64PtrToNumeric(v.SalePrice),
+				Price:             moneyPtrToNumeric(v.Price),
+				SalePrice:         moneyPtrToNumeric(v.SalePrice),
 				Images:            v.Images,
 				Weight:            float64PtrToNumeric(v.Weight),
 				Barcode:           strPtr(v.Barcode),
@@ -1118,8 +1234,8 @@ func (r *productRepository) UpdateProduct(ctx context.Context, product *domain.P
 		Name:                  product.Name,
 		Slug:                  product.Slug,
 		Description:           strPtr(product.Description),
-		BasePrice:             float64ToNumeric(product.BasePrice),
-		SalePrice:             float64PtrToNumeric(product.SalePrice),
+		BasePrice:             moneyToNumeric(product.BasePrice),
+		SalePrice:             moneyPtrToNumeric(product.SalePrice),
 		StockStatus:           strPtr(product.StockStatus),
 		IsFeatured:            product.IsFeatured,
 		IsActive:              product.IsActive,
@@ -1134,7 +1250,9 @@ func (r *productRepository) UpdateProduct(ctx context.Context, product *domain.P
 		Tags:                  product.Tags,
 		WarrantyInfo:          warrantyBytes,
 		IsPreorder:            product.IsPreorder,
-		PreorderDepositAmount: float64ToNumeric(product.PreorderDepositAmount),
+		PreorderDepositAmount: moneyToNumeric(product.PreorderDepositAmount),
+		PreorderReleaseDate:   timePtrToPgtime(product.PreorderReleaseDate),
+		PreorderLimit:         intPtrToInt32Ptr(product.PreorderLimit),
 	})
 	if err != nil {
 		return err
@@ -1185,8 +1303,8 @@ func (r *productRepository) UpdateProduct(ctx context.Context, product *domain.P
 					Stock:             int32(v.Stock),
 					Sku:               strPtr(v.SKU),
 					Attributes:        vAttributes,
-					Price:             float64PtrToNumeric(v.Price),
-					SalePrice:         float64PtrToNumeric(v.SalePrice),
+					Price:             moneyPtrToNumeric(v.Price),
+					SalePrice:         moneyPtrToNumeric(v.SalePrice),
 					Images:            v.Images,
 					Weight:            float64PtrToNumeric(v.Weight),
 					Dimensions:        vDimensions,
@@ -1208,8 +1326,8 @@ func (r *productRepository) UpdateProduct(ctx context.Context, product *domain.P
 			Stock:             int32(v.Stock),
 			Sku:               strPtr(v.SKU),
 			Attributes:        vAttributes,
-			Price:             float64PtrToNumeric(v.Price),
-			SalePrice:         float64PtrToNumeric(v.SalePrice),
+			Price:             moneyPtrToNumeric(v.Price),
+			SalePrice:         moneyPtrToNumeric(v.SalePrice),
 			Images:            v.Images,
 			Weight:            float64PtrToNumeric(v.Weight),
 			Dimensions:        vDimensions,
@@ -1282,9 +1400,10 @@ func (r *productRepository) GetVariantList(ctx context.Context, filter domain.Va
 			ProductID:         uuidToString(row.ProductID),
 			Name:              row.Name,
 			Stock:             int(row.Stock),
+			Available:         int(row.Stock),
 			SKU:               ptrStrToStr(row.Sku),
-			Price:             numericToFloat64Ptr(row.Price),
-			SalePrice:         numericToFloat64Ptr(row.SalePrice),
+			Price:             numericToMoneyPtr(row.Price),
+			SalePrice:         numericToMoneyPtr(row.SalePrice),
 			Weight:            numericToFloat64Ptr(row.Weight),
 			Barcode:           ptrStrToStr(row.Barcode),
 			LowStockThreshold: int(row.LowStockThreshold),
@@ -1311,7 +1430,7 @@ func (r *productRepository) GetVariantList(ctx context.Context, filter domain.Va
 			Variant:          v,
 			ProductName:      row.ProductName,
 			ProductSlug:      row.ProductSlug,
-			ProductBasePrice: numericToFloat64(row.ProductBasePrice),
+			ProductBasePrice: numericToMoney(row.ProductBasePrice),
 		}
 
 		// Extract first product image if available
@@ -1327,6 +1446,15 @@ func (r *productRepository) GetVariantList(ctx context.Context, filter domain.Va
 		variants[i] = vp
 	}
 
+	plain := make([]domain.Variant, len(variants))
+	for i := range variants {
+		plain[i] = variants[i].Variant
+	}
+	r.applyReservations(ctx, r.queries, plain)
+	for i := range variants {
+		variants[i].Variant = plain[i]
+	}
+
 	return variants, count, nil
 }
 
@@ -1342,7 +1470,7 @@ func (r *productRepository) GetProductStats(ctx context.Context) (*domain.Produc
 		InactiveProducts:    row.InactiveProducts,
 		OutOfStock:          row.OutOfStock,
 		LowStock:            row.LowStock,
-		TotalInventoryValue: row.TotalInventoryValue,
+		TotalInventoryValue: numericToMoney(row.TotalInventoryValue),
 	}, nil
 }
 
@@ -1394,15 +1522,19 @@ func (r *productRepository) GetVariantByID(ctx context.Context, id string) (*dom
 	if err != nil {
 		return nil, err
 	}
-	variant := sqlcVariantToDomain(v)
-	return &variant, nil
+	variants := []domain.Variant{sqlcVariantToDomain(v)}
+	r.applyReservations(ctx, r.queries, variants)
+	return &variants[0], nil
 }
 
 func (r *productRepository) GetVariantByIDForUpdate(ctx context.Context, id string) (*domain.Variant, error) {
-	v, err := r.queries.GetVariantByIDForUpdate(ctx, stringToUUID(id))
+	// The row lock only holds when ctx carries the caller's transaction
+	q := GetQueriesFromContext(ctx, r.queries)
+	v, err := q.GetVariantByIDForUpdate(ctx, stringToUUID(id))
 	if err != nil {
 		return nil, err
 	}
-	variant := sqlcVariantToDomain(v)
-	return &variant, nil
+	variants := []domain.Variant{sqlcVariantToDomain(v)}
+	r.applyReservations(ctx, q, variants)
+	return &variants[0], nil
 }
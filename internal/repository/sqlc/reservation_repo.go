@@ -0,0 +1,237 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reservationRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewStockReservationRepository(db *pgxpool.Pool) domain.StockReservationRepository {
+	return &reservationRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *reservationRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcReservationToDomain(s sqlc.StockReservation) domain.StockReservation {
+	return domain.StockReservation{
+		ID:        uuidToString(s.ID),
+		OrderID:   uuidToString(s.OrderID),
+		VariantID: uuidToString(s.VariantID),
+		Quantity:  int(s.Quantity),
+		Status:    s.Status,
+		ExpiresAt: pgtimeToTime(s.ExpiresAt),
+		CreatedAt: pgtimeToTime(s.CreatedAt),
+		UpdatedAt: pgtimeToTime(s.UpdatedAt),
+	}
+}
+
+func (r *reservationRepository) Reserve(ctx context.Context, orderID string, items []domain.OrderItem, ttl time.Duration) error {
+	wanted := make(map[string]int)
+	for _, item := range items {
+		if item.VariantID == nil {
+			return fmt.Errorf("item %s has no variant ID", item.ProductID)
+		}
+		wanted[*item.VariantID] += item.Quantity
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := lockAvailable(ctx, qtx, wanted, pgtype.UUID{}); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := qtx.CreateStockReservation(ctx, sqlc.CreateStockReservationParams{
+			OrderID:    stringToUUID(orderID),
+			VariantID:  stringToUUID(*item.VariantID),
+			Quantity:   int32(item.Quantity),
+			TtlSeconds: ttl.Seconds(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *reservationRepository) Renew(ctx context.Context, orderID string, ttl time.Duration) (int64, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	holds, err := qtx.ListStockReservationsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return 0, err
+	}
+
+	// Every uncommitted hold must fit into what other orders leave available
+	wanted := make(map[string]int)
+	for _, h := range holds {
+		if h.Status == domain.ReservationStatusActive || h.Status == domain.ReservationStatusExpired {
+			wanted[uuidToString(h.VariantID)] += int(h.Quantity)
+		}
+	}
+	if len(wanted) == 0 {
+		return 0, nil
+	}
+	if err := lockAvailable(ctx, qtx, wanted, stringToUUID(orderID)); err != nil {
+		return 0, err
+	}
+
+	rows, err := qtx.RenewOrderReservations(ctx, sqlc.RenewOrderReservationsParams{
+		OrderID:    stringToUUID(orderID),
+		TtlSeconds: ttl.Seconds(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+// lockAvailable locks each variant (in a stable order, so concurrent checkouts cannot
+// deadlock) and checks that stock minus live reservations of other orders covers the
+// wanted quantity.
+func lockAvailable(ctx context.Context, qtx *sqlc.Queries, wanted map[string]int, excludeOrderID pgtype.UUID) error {
+	variantIDs := make([]string, 0, len(wanted))
+	for id := range wanted {
+		variantIDs = append(variantIDs, id)
+	}
+	sort.Strings(variantIDs)
+
+	for _, id := range variantIDs {
+		variantUUID := stringToUUID(id)
+		v, err := qtx.GetVariantByIDForUpdate(ctx, variantUUID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock for variant %s: %v", id, err)
+		}
+		rows, err := qtx.GetReservedQuantities(ctx, sqlc.GetReservedQuantitiesParams{
+			VariantIds:     []pgtype.UUID{variantUUID},
+			ExcludeOrderID: excludeOrderID,
+		})
+		if err != nil {
+			return err
+		}
+		reserved := 0
+		for _, row := range rows {
+			reserved += int(row.Reserved)
+		}
+		if available := int(v.Stock) - reserved; available < wanted[id] {
+			return fmt.Errorf("insufficient stock for item %s (requested: %d, available: %d)", v.Name, wanted[id], max(available, 0))
+		}
+	}
+	return nil
+}
+
+func (r *reservationRepository) ListByOrder(ctx context.Context, orderID string) ([]domain.StockReservation, error) {
+	rows, err := r.getQueries(ctx).ListStockReservationsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.StockReservation, len(rows))
+	for i, row := range rows {
+		result[i] = sqlcReservationToDomain(row)
+	}
+	return result, nil
+}
+
+func (r *reservationRepository) Commit(ctx context.Context, orderID, reason string) (int, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	held, err := qtx.SetOrderReservationsStatus(ctx, sqlc.SetOrderReservationsStatusParams{
+		Status:  domain.ReservationStatusCommitted,
+		OrderID: stringToUUID(orderID),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Same deduction + log as productRepository.UpdateStock, inside one transaction
+	for _, res := range held {
+		v, err := qtx.GetVariantByID(ctx, res.VariantID)
+		if err != nil {
+			return 0, fmt.Errorf("variant not found: %s", uuidToString(res.VariantID))
+		}
+		rows, err := qtx.UpdateVariantStock(ctx, sqlc.UpdateVariantStockParams{
+			ID:    res.VariantID,
+			Stock: -res.Quantity,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if rows == 0 {
+			return 0, fmt.Errorf("insufficient stock for variant: %s", uuidToString(res.VariantID))
+		}
+		if _, err := qtx.CreateInventoryLog(ctx, sqlc.CreateInventoryLogParams{
+			ProductID:    v.ProductID,
+			VariantID:    res.VariantID,
+			ChangeAmount: -res.Quantity,
+			Reason:       reason,
+			ReferenceID:  orderID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(held), nil
+}
+
+func (r *reservationRepository) Release(ctx context.Context, orderID string) (int, error) {
+	rows, err := r.getQueries(ctx).SetOrderReservationsStatus(ctx, sqlc.SetOrderReservationsStatusParams{
+		Status:  domain.ReservationStatusReleased,
+		OrderID: stringToUUID(orderID),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (r *reservationRepository) ExpireDue(ctx context.Context, limit int) ([]domain.StockReservation, error) {
+	rows, err := r.getQueries(ctx).ExpireStockReservations(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.StockReservation, len(rows))
+	for i, row := range rows {
+		result[i] = sqlcReservationToDomain(row)
+	}
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package sqlcrepo
+
+import (
+	"context"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type returnRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewReturnRepository(db *pgxpool.Pool) domain.ReturnRepository {
+	return &returnRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *returnRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcOrderReturnToDomain(ret sqlc.OrderReturn) domain.OrderReturn {
+	result := domain.OrderReturn{
+		ID:           uuidToString(ret.ID),
+		OrderID:      uuidToString(ret.OrderID),
+		Status:       ret.Status,
+		Reason:       ret.Reason,
+		Note:         ret.Note,
+		Photos:       ret.Photos,
+		AdminNote:    ret.AdminNote,
+		RefundAmount: numericToMoney(ret.RefundAmount),
+		ReceivedAt:   toTimePtr(ret.ReceivedAt),
+		CreatedAt:    pgtimeToTime(ret.CreatedAt),
+		UpdatedAt:    pgtimeToTime(ret.UpdatedAt),
+	}
+	if ret.UserID.Valid {
+		userID := uuidToString(ret.UserID)
+		result.UserID = &userID
+	}
+	if ret.ReviewedBy.Valid {
+		reviewedBy := uuidToString(ret.ReviewedBy)
+		result.ReviewedBy = &reviewedBy
+	}
+	if result.Photos == nil {
+		result.Photos = []string{}
+	}
+	return result
+}
+
+// withItems converts the returns and attaches their items.
+func (r *returnRepository) withItems(ctx context.Context, rows []sqlc.OrderReturn) ([]domain.OrderReturn, error) {
+	returns := make([]domain.OrderReturn, len(rows))
+	if len(rows) == 0 {
+		return returns, nil
+	}
+	ids := make([]pgtype.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	itemRows, err := r.getQueries(ctx).ListOrderReturnItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string][]domain.ReturnItem)
+	for _, row := range itemRows {
+		returnID := uuidToString(row.ReturnID)
+		items[returnID] = append(items[returnID], domain.ReturnItem{
+			OrderItemID:      uuidToString(row.OrderItemID),
+			Quantity:         int(row.Quantity),
+			ApprovedQuantity: int(row.ApprovedQuantity),
+			ReceivedQuantity: int(row.ReceivedQuantity),
+		})
+	}
+
+	for i, row := range rows {
+		returns[i] = sqlcOrderReturnToDomain(row)
+		returns[i].Items = items[returns[i].ID]
+	}
+	return returns, nil
+}
+
+func (r *returnRepository) one(ctx context.Context, row sqlc.OrderReturn, err error) (*domain.OrderReturn, error) {
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("return %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	returns, err := r.withItems(ctx, []sqlc.OrderReturn{row})
+	if err != nil {
+		return nil, err
+	}
+	return &returns[0], nil
+}
+
+func (r *returnRepository) Create(ctx context.Context, ret *domain.OrderReturn) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	userID := ""
+	if ret.UserID != nil {
+		userID = *ret.UserID
+	}
+	photos := ret.Photos
+	if photos == nil {
+		photos = []string{}
+	}
+	created, err := q.CreateOrderReturn(ctx, sqlc.CreateOrderReturnParams{
+		OrderID: stringToUUID(ret.OrderID),
+		UserID:  stringToUUID(userID),
+		Reason:  ret.Reason,
+		Note:    ret.Note,
+		Photos:  photos,
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range ret.Items {
+		if err := q.AddOrderReturnItem(ctx, sqlc.AddOrderReturnItemParams{
+			ReturnID:    created.ID,
+			OrderItemID: stringToUUID(item.OrderItemID),
+			Quantity:    int32(item.Quantity),
+		}); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	items := ret.Items
+	*ret = sqlcOrderReturnToDomain(created)
+	ret.Items = items
+	return nil
+}
+
+func (r *returnRepository) GetByID(ctx context.Context, id string) (*domain.OrderReturn, error) {
+	row, err := r.getQueries(ctx).GetOrderReturnByID(ctx, stringToUUID(id))
+	return r.one(ctx, row, err)
+}
+
+func (r *returnRepository) GetForUpdate(ctx context.Context, id string) (*domain.OrderReturn, error) {
+	row, err := r.getQueries(ctx).GetOrderReturnForUpdate(ctx, stringToUUID(id))
+	return r.one(ctx, row, err)
+}
+
+func (r *returnRepository) ListByOrder(ctx context.Context, orderID string) ([]domain.OrderReturn, error) {
+	rows, err := r.getQueries(ctx).ListOrderReturnsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	return r.withItems(ctx, rows)
+}
+
+func (r *returnRepository) List(ctx context.Context, status string, limit, offset int) ([]domain.OrderReturn, int64, error) {
+	rows, err := r.getQueries(ctx).ListOrderReturns(ctx, sqlc.ListOrderReturnsParams{
+		Status: strPtr(status),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.getQueries(ctx).CountOrderReturns(ctx, strPtr(status))
+	if err != nil {
+		return nil, 0, err
+	}
+	returns, err := r.withItems(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return returns, total, nil
+}
+
+func (r *returnRepository) UpdateStatus(ctx context.Context, id, status, adminNote, reviewedBy string) error {
+	return r.getQueries(ctx).UpdateOrderReturnStatus(ctx, sqlc.UpdateOrderReturnStatusParams{
+		ID:         stringToUUID(id),
+		Status:     status,
+		AdminNote:  strPtr(adminNote),
+		ReviewedBy: stringToUUID(reviewedBy),
+	})
+}
+
+func (r *returnRepository) UpdateItem(ctx context.Context, returnID string, item domain.ReturnItem) error {
+	return r.getQueries(ctx).UpdateOrderReturnItem(ctx, sqlc.UpdateOrderReturnItemParams{
+		ReturnID:         stringToUUID(returnID),
+		OrderItemID:      stringToUUID(item.OrderItemID),
+		ApprovedQuantity: int32(item.ApprovedQuantity),
+		ReceivedQuantity: int32(item.ReceivedQuantity),
+	})
+}
+
+func (r *returnRepository) AddRefund(ctx context.Context, id string, amount domain.Money) error {
+	return r.getQueries(ctx).AddOrderReturnRefund(ctx, sqlc.AddOrderReturnRefundParams{
+		ID:     stringToUUID(id),
+		Amount: moneyToNumeric(amount),
+	})
+}
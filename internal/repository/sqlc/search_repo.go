@@ -65,8 +65,8 @@ func mapSearchRowToProduct(row sqlc.SearchProductsRow) domain.Product {
 		ID:        uuidToString(row.ID),
 		Name:      row.Name,
 		Slug:      row.Slug,
-		BasePrice: numericToFloat64(row.BasePrice),
-		SalePrice: numericToFloat64Ptr(row.SalePrice),
+		BasePrice: numericToMoney(row.BasePrice),
+		SalePrice: numericToMoneyPtr(row.SalePrice),
 		// Stock & SKU moved to variants
 		IsFeatured: row.IsFeatured,
 		IsActive:   row.IsActive,
@@ -0,0 +1,207 @@
+package sqlcrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"valancis-backend/db/sqlc"
+	"valancis-backend/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type shipmentRepository struct {
+	db      *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+func NewShipmentRepository(db *pgxpool.Pool) domain.ShipmentRepository {
+	return &shipmentRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *shipmentRepository) getQueries(ctx context.Context) *sqlc.Queries {
+	return GetQueriesFromContext(ctx, r.queries)
+}
+
+func sqlcShipmentToDomain(s sqlc.Shipment) *domain.Shipment {
+	shipment := &domain.Shipment{
+		ID:            uuidToString(s.ID),
+		OrderID:       uuidToString(s.OrderID),
+		Provider:      s.Provider,
+		ConsignmentID: s.ConsignmentID,
+		TrackingCode:  s.TrackingCode,
+		TrackingURL:   s.TrackingUrl,
+		LabelURL:      s.LabelUrl,
+		CODAmount:     numericToMoney(s.CodAmount),
+		Status:        s.Status,
+		CourierStatus: s.CourierStatus,
+		CreatedAt:     pgtimeToTime(s.CreatedAt),
+		UpdatedAt:     pgtimeToTime(s.UpdatedAt),
+	}
+	if s.ExchangeID.Valid {
+		exchangeID := uuidToString(s.ExchangeID)
+		shipment.ExchangeID = &exchangeID
+	}
+	return shipment
+}
+
+func sqlcShipmentItemToDomain(si sqlc.ShipmentItem) domain.ShipmentItem {
+	return domain.ShipmentItem{
+		OrderItemID: uuidToString(si.OrderItemID),
+		Quantity:    int(si.Quantity),
+	}
+}
+
+func (r *shipmentRepository) Create(ctx context.Context, shipment *domain.Shipment) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	exchangeID := ""
+	if shipment.ExchangeID != nil {
+		exchangeID = *shipment.ExchangeID
+	}
+	created, err := q.CreateShipment(ctx, sqlc.CreateShipmentParams{
+		OrderID:       stringToUUID(shipment.OrderID),
+		Provider:      shipment.Provider,
+		ConsignmentID: shipment.ConsignmentID,
+		TrackingCode:  shipment.TrackingCode,
+		TrackingUrl:   shipment.TrackingURL,
+		LabelUrl:      shipment.LabelURL,
+		CodAmount:     moneyToNumeric(shipment.CODAmount),
+		CourierStatus: shipment.CourierStatus,
+		ExchangeID:    stringToUUID(exchangeID),
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range shipment.Items {
+		if err := q.AddShipmentItem(ctx, sqlc.AddShipmentItemParams{
+			ShipmentID:  created.ID,
+			OrderItemID: stringToUUID(item.OrderItemID),
+			Quantity:    int32(item.Quantity),
+		}); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	items := shipment.Items
+	*shipment = *sqlcShipmentToDomain(created)
+	shipment.Items = items
+	return nil
+}
+
+func (r *shipmentRepository) GetByID(ctx context.Context, id string) (*domain.Shipment, error) {
+	s, err := r.getQueries(ctx).GetShipmentByID(ctx, stringToUUID(id))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.getQueries(ctx).ListShipmentItems(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	shipment := sqlcShipmentToDomain(s)
+	for _, row := range rows {
+		shipment.Items = append(shipment.Items, sqlcShipmentItemToDomain(row))
+	}
+	return shipment, nil
+}
+
+func (r *shipmentRepository) GetForUpdate(ctx context.Context, id string) (*domain.Shipment, error) {
+	s, err := r.getQueries(ctx).GetShipmentForUpdate(ctx, stringToUUID(id))
+	if err != nil {
+		return nil, err
+	}
+	return sqlcShipmentToDomain(s), nil
+}
+
+func (r *shipmentRepository) GetByConsignment(ctx context.Context, provider, consignmentID string) (*domain.Shipment, error) {
+	s, err := r.getQueries(ctx).GetShipmentByConsignment(ctx, sqlc.GetShipmentByConsignmentParams{
+		Provider:      provider,
+		ConsignmentID: consignmentID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("shipment %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return sqlcShipmentToDomain(s), nil
+}
+
+func (r *shipmentRepository) GetByTracking(ctx context.Context, provider, tracking string) (*domain.Shipment, error) {
+	s, err := r.getQueries(ctx).GetShipmentByTracking(ctx, sqlc.GetShipmentByTrackingParams{
+		Tracking: tracking,
+		Provider: strPtr(provider),
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, fmt.Errorf("shipment %w", domain.ErrNotFound)
+		}
+		return nil, err
+	}
+	return sqlcShipmentToDomain(s), nil
+}
+
+func (r *shipmentRepository) ListByOrder(ctx context.Context, orderID string) ([]domain.Shipment, error) {
+	rows, err := r.getQueries(ctx).ListShipmentsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	itemRows, err := r.getQueries(ctx).ListShipmentItemsByOrder(ctx, stringToUUID(orderID))
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string][]domain.ShipmentItem)
+	for _, row := range itemRows {
+		shipmentID := uuidToString(row.ShipmentID)
+		items[shipmentID] = append(items[shipmentID], sqlcShipmentItemToDomain(row))
+	}
+
+	shipments := make([]domain.Shipment, len(rows))
+	for i, s := range rows {
+		shipments[i] = *sqlcShipmentToDomain(s)
+		shipments[i].Items = items[shipments[i].ID]
+	}
+	return shipments, nil
+}
+
+func (r *shipmentRepository) UpdateStatus(ctx context.Context, id, status, courierStatus string) error {
+	return r.getQueries(ctx).UpdateShipmentStatus(ctx, sqlc.UpdateShipmentStatusParams{
+		ID:            stringToUUID(id),
+		Status:        status,
+		CourierStatus: strPtr(courierStatus),
+	})
+}
+
+func (r *shipmentRepository) RecordEvent(ctx context.Context, provider, shipmentID string, ev *domain.CourierEvent) (bool, error) {
+	payload, err := json.Marshal(ev.Payload)
+	if err != nil {
+		return false, err
+	}
+	if ev.Payload == nil {
+		payload = []byte("{}")
+	}
+
+	rows, err := r.getQueries(ctx).RecordCourierEvent(ctx, sqlc.RecordCourierEventParams{
+		Provider:      provider,
+		EventKey:      ev.EventKey,
+		ShipmentID:    stringToUUID(shipmentID),
+		Status:        strPtr(ev.Status),
+		CourierStatus: ev.CourierStatus,
+		Payload:       payload,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -5,6 +5,7 @@ import (
 	"valancis-backend/db/sqlc"
 	"valancis-backend/internal/domain"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -43,3 +44,13 @@ func GetQueriesFromContext(ctx context.Context, defaultQueries *sqlc.Queries) *s
 	}
 	return defaultQueries
 }
+
+// beginTx starts a transaction, or a savepoint when an outer transaction is
+// already carried by the context, so repositories that manage their own
+// transactions still commit or roll back together with the caller.
+func beginTx(ctx context.Context, db *pgxpool.Pool) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return db.Begin(ctx)
+}
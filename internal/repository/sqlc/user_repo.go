@@ -125,13 +125,15 @@ func sqlcRefreshTokenToDomain(rt sqlc.RefreshToken) *domain.RefreshToken {
 
 // --- Repository Implementation ---
 
+// Create joins the caller's transaction, if any (guest users are saved with their order).
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	created, err := r.queries.CreateUser(ctx, sqlc.CreateUserParams{
+	created, err := GetQueriesFromContext(ctx, r.queries).CreateUser(ctx, sqlc.CreateUserParams{
 		Email:     user.Email,
 		Role:      user.Role,
 		FirstName: strPtr(user.FirstName),
 		LastName:  strPtr(user.LastName),
 		Avatar:    strPtr(user.Avatar),
+		Phone:     strPtr(user.Phone),
 	})
 	if err != nil {
 		return err
@@ -67,8 +67,8 @@ func (r *wishlistRepository) GetWishlistItems(ctx context.Context, wishlistID st
 				ID:        uuidToString(row.ProductID),
 				Name:      row.Name,
 				Slug:      row.Slug,
-				BasePrice: numericToFloat64(row.BasePrice),
-				SalePrice: numericToFloat64Ptr(row.SalePrice),
+				BasePrice: numericToMoney(row.BasePrice),
+				SalePrice: numericToMoneyPtr(row.SalePrice),
 				Stock:     int(row.TotalStock),
 			},
 		}
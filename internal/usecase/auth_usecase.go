@@ -13,8 +13,14 @@ import (
 	"time"
 )
 
+// CartMerger folds a guest cart into a user's cart (implemented by OrderUsecase).
+type CartMerger interface {
+	MergeGuestCart(ctx context.Context, userID, guestCartID string) error
+}
+
 type AuthUsecase struct {
 	userRepo           domain.UserRepository
+	cartMerger         CartMerger
 	clientID           string
 	clientSecret       string
 	tokenInfoURL       string
@@ -22,9 +28,10 @@ type AuthUsecase struct {
 	refreshTokenExpiry time.Duration
 }
 
-func NewAuthUsecase(userRepo domain.UserRepository, clientID, clientSecret, tokenInfoURL string, atExpiry, rtExpiry time.Duration) *AuthUsecase {
+func NewAuthUsecase(userRepo domain.UserRepository, cartMerger CartMerger, clientID, clientSecret, tokenInfoURL string, atExpiry, rtExpiry time.Duration) *AuthUsecase {
 	return &AuthUsecase{
 		userRepo:           userRepo,
+		cartMerger:         cartMerger,
 		clientID:           clientID,
 		clientSecret:       clientSecret,
 		tokenInfoURL:       tokenInfoURL,
@@ -44,7 +51,9 @@ type GoogleUser struct {
 	Aud           string      `json:"aud"`
 }
 
-func (u *AuthUsecase) AuthenticateGoogle(ctx context.Context, code string) (string, string, *domain.User, error) {
+// AuthenticateGoogle signs the user in. A non-empty guestCartID (from the guest cart
+// token) is merged into the user's cart.
+func (u *AuthUsecase) AuthenticateGoogle(ctx context.Context, code, guestCartID string) (string, string, *domain.User, error) {
 	slog.Info("Authenticating with Google via Code Flow", "code_length", len(code))
 
 	// 1. Exchange Code for Tokens
@@ -99,6 +108,14 @@ func (u *AuthUsecase) AuthenticateGoogle(ctx context.Context, code string) (stri
 		}
 	}
 
+	// Carry over what the shopper added before signing in
+	if guestCartID != "" && u.cartMerger != nil {
+		if err := u.cartMerger.MergeGuestCart(ctx, user.ID, guestCartID); err != nil {
+			slog.Error("Failed to merge guest cart", "user_id", user.ID, "cart_id", guestCartID, "error", err)
+			// Non-critical error, continue login
+		}
+	}
+
 	// 3. Generate Access Token (JWT)
 	accessToken, err := utils.GenerateJWT(user.ID, user.Email, user.Role, u.accessTokenExpiry)
 	if err != nil {
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/netip"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// BlocklistUsecase manages the customer blocklist and checks customers against it.
+// Entries match on phone, email, user ID or IP range; a match either blocks the
+// customer or only lets them order with a full advance payment.
+type BlocklistUsecase struct {
+	blocklistRepo domain.BlocklistRepository
+	orderRepo     domain.OrderRepository
+	txManager     domain.TransactionManager
+	// Entries added from fake orders expire after fakeOrderTTL (0: never)
+	fakeOrderTTL time.Duration
+}
+
+func NewBlocklistUsecase(blocklistRepo domain.BlocklistRepository, orderRepo domain.OrderRepository, txManager domain.TransactionManager, fakeOrderTTL time.Duration) *BlocklistUsecase {
+	return &BlocklistUsecase{
+		blocklistRepo: blocklistRepo,
+		orderRepo:     orderRepo,
+		txManager:     txManager,
+		fakeOrderTTL:  fakeOrderTTL,
+	}
+}
+
+type BlocklistEntryReq struct {
+	Kind      string     `json:"kind"`   // phone, email, user or ip
+	Value     string     `json:"value"`  // IPs may be a single address or a CIDR range
+	Action    string     `json:"action"` // block (default) or prepay
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Omit for a permanent entry
+}
+
+// Create adds an entry. An existing entry for the same value is renewed with the new
+// action, reason and expiry.
+func (u *BlocklistUsecase) Create(ctx context.Context, req BlocklistEntryReq, adminID string) (*domain.BlocklistEntry, error) {
+	value, err := normalizeBlocklistValue(req.Kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	action, reason, err := validateBlocklistEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	entry := &domain.BlocklistEntry{
+		Kind:      req.Kind,
+		Value:     value,
+		Action:    action,
+		Reason:    reason,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: &adminID,
+	}
+	if err := u.blocklistRepo.Upsert(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save blocklist entry: %w", err)
+	}
+	slog.Info("Blocklist entry saved", "entry_id", entry.ID, "kind", entry.Kind, "action", entry.Action, "admin_id", adminID)
+	return entry, nil
+}
+
+// Update changes an entry's action, reason and expiry; its kind and value are fixed.
+func (u *BlocklistUsecase) Update(ctx context.Context, id string, req BlocklistEntryReq) (*domain.BlocklistEntry, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid blocklist entry ID")
+	}
+	entry, err := u.blocklistRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	entry.Action, entry.Reason, err = validateBlocklistEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	entry.ExpiresAt = req.ExpiresAt
+	if err := u.blocklistRepo.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (u *BlocklistUsecase) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return fmt.Errorf("invalid blocklist entry ID")
+	}
+	return u.blocklistRepo.Delete(ctx, id)
+}
+
+func (u *BlocklistUsecase) Get(ctx context.Context, id string) (*domain.BlocklistEntry, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid blocklist entry ID")
+	}
+	return u.blocklistRepo.GetByID(ctx, id)
+}
+
+func (u *BlocklistUsecase) List(ctx context.Context, filter domain.BlocklistFilter) ([]domain.BlocklistEntry, int64, error) {
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	return u.blocklistRepo.List(ctx, filter)
+}
+
+// Check returns the strongest unexpired entry matching the customer, or nil. Always
+// nil on a nil usecase (blocklist disabled).
+func (u *BlocklistUsecase) Check(ctx context.Context, subject domain.BlocklistSubject) (*domain.BlocklistEntry, error) {
+	if u == nil || (subject.UserID == "" && subject.Phone == "" && subject.Email == "" && subject.IP == "") {
+		return nil, nil
+	}
+	entries, err := u.blocklistRepo.Match(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check blocklist: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+// OrderEntries returns the entries that would block the customer of an order: its
+// phone and its user. Nothing is saved; admins are offered these when marking an
+// order fake.
+func (u *BlocklistUsecase) OrderEntries(ctx context.Context, orderID string) ([]domain.BlocklistEntry, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return u.orderEntries(order), nil
+}
+
+func (u *BlocklistUsecase) orderEntries(order *domain.Order) []domain.BlocklistEntry {
+	reason := fmt.Sprintf("Order %s marked fake", order.OrderNumber)
+	var expiresAt *time.Time
+	if u.fakeOrderTTL > 0 {
+		t := time.Now().Add(u.fakeOrderTTL)
+		expiresAt = &t
+	}
+	entry := func(kind, value string) domain.BlocklistEntry {
+		return domain.BlocklistEntry{
+			Kind:          kind,
+			Value:         value,
+			Action:        domain.BlocklistActionBlock,
+			Reason:        reason,
+			ExpiresAt:     expiresAt,
+			SourceOrderID: &order.ID,
+		}
+	}
+
+	entries := []domain.BlocklistEntry{}
+	phone := ""
+	if order.CustomerPhone != nil {
+		phone = *order.CustomerPhone
+	} else if normalized, ok := utils.NormalizePhone(addressField(order.ShippingAddress, "phone")); ok {
+		phone = normalized
+	}
+	if phone != "" {
+		entries = append(entries, entry(domain.BlocklistKindPhone, phone))
+	}
+	if order.UserID != "" {
+		entries = append(entries, entry(domain.BlocklistKindUser, order.UserID))
+	}
+	return entries
+}
+
+// BlockOrderCustomer adds the phone and user of a fake order to the blocklist.
+func (u *BlocklistUsecase) BlockOrderCustomer(ctx context.Context, orderID, adminID string) ([]domain.BlocklistEntry, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusFake {
+		return nil, fmt.Errorf("only orders marked fake can add their customer to the blocklist")
+	}
+
+	entries := u.orderEntries(order)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("order has no phone or user to block")
+	}
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		for i := range entries {
+			entries[i].CreatedBy = &adminID
+			if err := u.blocklistRepo.Upsert(txCtx, &entries[i]); err != nil {
+				return fmt.Errorf("failed to save blocklist entry: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Fake order customer blocklisted", "order_id", orderID, "entries", len(entries), "admin_id", adminID)
+	return entries, nil
+}
+
+func validateBlocklistEntry(req BlocklistEntryReq) (action, reason string, err error) {
+	action = req.Action
+	if action == "" {
+		action = domain.BlocklistActionBlock
+	}
+	if action != domain.BlocklistActionBlock && action != domain.BlocklistActionPrepay {
+		return "", "", fmt.Errorf("action must be 'block' or 'prepay'")
+	}
+	reason = strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return "", "", fmt.Errorf("reason is required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return "", "", fmt.Errorf("expiry must be in the future")
+	}
+	return action, reason, nil
+}
+
+// normalizeBlocklistValue puts a value in the form entries are stored and matched in.
+func normalizeBlocklistValue(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("value is required")
+	}
+	switch kind {
+	case domain.BlocklistKindPhone:
+		phone, ok := utils.NormalizePhone(value)
+		if !ok {
+			return "", fmt.Errorf("invalid phone number")
+		}
+		return phone, nil
+	case domain.BlocklistKindEmail:
+		email := strings.ToLower(value)
+		if _, err := mail.ParseAddress(email); err != nil {
+			return "", fmt.Errorf("invalid email")
+		}
+		return email, nil
+	case domain.BlocklistKindUser:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid user ID")
+		}
+		return id.String(), nil
+	case domain.BlocklistKindIP:
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			return prefix.Masked().String(), nil
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid IP address or range")
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()).String(), nil
+	}
+	return "", fmt.Errorf("kind must be one of 'phone', 'email', 'user' or 'ip'")
+}
@@ -14,25 +14,34 @@ import (
 // L9: Follows single responsibility principle - focuses only on coupon CRUD.
 type CouponUsecase struct {
 	couponRepo domain.CouponRepository
+	txManager  domain.TransactionManager
 }
 
 // NewCouponUsecase creates a new CouponUsecase instance.
-func NewCouponUsecase(couponRepo domain.CouponRepository) *CouponUsecase {
+func NewCouponUsecase(couponRepo domain.CouponRepository, txManager domain.TransactionManager) *CouponUsecase {
 	return &CouponUsecase{
 		couponRepo: couponRepo,
+		txManager:  txManager,
 	}
 }
 
 // CreateCouponRequest represents the input for creating a coupon.
 type CreateCouponRequest struct {
-	Code       string  `json:"code"`
-	Type       string  `json:"type"` // "percentage" or "fixed"
-	Value      float64 `json:"value"`
-	MinSpend   float64 `json:"minSpend"`
-	UsageLimit int     `json:"usageLimit"`
-	StartAt    string  `json:"startAt"`   // ISO8601 format
-	ExpiresAt  string  `json:"expiresAt"` // ISO8601 format
-	IsActive   bool    `json:"isActive"`
+	Code           string        `json:"code"`
+	Type           string        `json:"type"` // "percentage" or "fixed"
+	Value          domain.Money  `json:"value"`
+	MinSpend       domain.Money  `json:"minSpend"`
+	MaxDiscount    *domain.Money `json:"maxDiscount"` // Optional cap for percentage coupons
+	UsageLimit     int           `json:"usageLimit"`
+	PerUserLimit   int           `json:"perUserLimit"` // 0 = unlimited
+	FirstOrderOnly bool          `json:"firstOrderOnly"`
+	StartAt        string        `json:"startAt"`   // ISO8601 format
+	ExpiresAt      string        `json:"expiresAt"` // ISO8601 format
+	IsActive       bool          `json:"isActive"`
+	// Scoping: leave all empty to apply to the whole cart
+	ProductIDs    []string `json:"productIds"`
+	CategoryIDs   []string `json:"categoryIds"`
+	CollectionIDs []string `json:"collectionIds"`
 }
 
 // CreateCoupon creates a new coupon with validation.
@@ -55,10 +64,17 @@ func (uc *CouponUsecase) CreateCoupon(ctx context.Context, req CreateCouponReque
 	}
 
 	// Validation: Percentage cannot exceed 100
-	if req.Type == "percentage" && req.Value > 100 {
+	if req.Type == "percentage" && req.Value > domain.Taka(100) {
 		return nil, fmt.Errorf("percentage discount cannot exceed 100%%")
 	}
 
+	if err := validateCouponLimits(req.MinSpend, req.MaxDiscount, req.UsageLimit, req.PerUserLimit); err != nil {
+		return nil, err
+	}
+	if err := validateScopeIDs(req.ProductIDs, req.CategoryIDs, req.CollectionIDs); err != nil {
+		return nil, err
+	}
+
 	// Check for duplicate code
 	existing, _ := uc.couponRepo.GetCouponByCode(ctx, code)
 	if existing != nil {
@@ -66,12 +82,18 @@ func (uc *CouponUsecase) CreateCoupon(ctx context.Context, req CreateCouponReque
 	}
 
 	coupon := &domain.Coupon{
-		Code:       code,
-		Type:       req.Type,
-		Value:      req.Value,
-		MinSpend:   req.MinSpend,
-		UsageLimit: req.UsageLimit,
-		IsActive:   req.IsActive,
+		Code:           code,
+		Type:           req.Type,
+		Value:          req.Value,
+		MinSpend:       req.MinSpend,
+		MaxDiscount:    normalizeMaxDiscount(req.Type, req.MaxDiscount),
+		UsageLimit:     req.UsageLimit,
+		PerUserLimit:   req.PerUserLimit,
+		FirstOrderOnly: req.FirstOrderOnly,
+		IsActive:       req.IsActive,
+		ProductIDs:     nonNilStrings(req.ProductIDs),
+		CategoryIDs:    nonNilStrings(req.CategoryIDs),
+		CollectionIDs:  nonNilStrings(req.CollectionIDs),
 	}
 
 	// Parse dates if provided
@@ -86,7 +108,13 @@ func (uc *CouponUsecase) CreateCoupon(ctx context.Context, req CreateCouponReque
 		}
 	}
 
-	if err := uc.couponRepo.CreateCoupon(ctx, coupon); err != nil {
+	err := uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.couponRepo.CreateCoupon(txCtx, coupon); err != nil {
+			return err
+		}
+		return uc.couponRepo.SetCouponScopes(txCtx, coupon.ID, coupon.ProductIDs, coupon.CategoryIDs, coupon.CollectionIDs)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to create coupon: %w", err)
 	}
 
@@ -132,14 +160,20 @@ func (uc *CouponUsecase) GetCoupon(ctx context.Context, id string) (*domain.Coup
 
 // UpdateCouponRequest represents the input for updating a coupon.
 type UpdateCouponRequest struct {
-	Code       string  `json:"code"`
-	Type       string  `json:"type"`
-	Value      float64 `json:"value"`
-	MinSpend   float64 `json:"minSpend"`
-	UsageLimit int     `json:"usageLimit"`
-	StartAt    string  `json:"startAt"`
-	ExpiresAt  string  `json:"expiresAt"`
-	IsActive   bool    `json:"isActive"`
+	Code           string        `json:"code"`
+	Type           string        `json:"type"`
+	Value          domain.Money  `json:"value"`
+	MinSpend       domain.Money  `json:"minSpend"`
+	MaxDiscount    *domain.Money `json:"maxDiscount"`
+	UsageLimit     int           `json:"usageLimit"`
+	PerUserLimit   int           `json:"perUserLimit"`
+	FirstOrderOnly bool          `json:"firstOrderOnly"`
+	StartAt        string        `json:"startAt"`
+	ExpiresAt      string        `json:"expiresAt"`
+	IsActive       bool          `json:"isActive"`
+	ProductIDs     []string      `json:"productIds"`
+	CategoryIDs    []string      `json:"categoryIds"`
+	CollectionIDs  []string      `json:"collectionIds"`
 }
 
 // UpdateCoupon updates an existing coupon.
@@ -169,10 +203,17 @@ func (uc *CouponUsecase) UpdateCoupon(ctx context.Context, id string, req Update
 		return fmt.Errorf("coupon value must be greater than 0")
 	}
 
-	if req.Type == "percentage" && req.Value > 100 {
+	if req.Type == "percentage" && req.Value > domain.Taka(100) {
 		return fmt.Errorf("percentage discount cannot exceed 100%%")
 	}
 
+	if err := validateCouponLimits(req.MinSpend, req.MaxDiscount, req.UsageLimit, req.PerUserLimit); err != nil {
+		return err
+	}
+	if err := validateScopeIDs(req.ProductIDs, req.CategoryIDs, req.CollectionIDs); err != nil {
+		return err
+	}
+
 	// Check for duplicate code (if changed)
 	if code != existing.Code {
 		dup, _ := uc.couponRepo.GetCouponByCode(ctx, code)
@@ -182,13 +223,16 @@ func (uc *CouponUsecase) UpdateCoupon(ctx context.Context, id string, req Update
 	}
 
 	coupon := &domain.Coupon{
-		ID:         uid,
-		Code:       code,
-		Type:       req.Type,
-		Value:      req.Value,
-		MinSpend:   req.MinSpend,
-		UsageLimit: req.UsageLimit,
-		IsActive:   req.IsActive,
+		ID:             uid,
+		Code:           code,
+		Type:           req.Type,
+		Value:          req.Value,
+		MinSpend:       req.MinSpend,
+		MaxDiscount:    normalizeMaxDiscount(req.Type, req.MaxDiscount),
+		UsageLimit:     req.UsageLimit,
+		PerUserLimit:   req.PerUserLimit,
+		FirstOrderOnly: req.FirstOrderOnly,
+		IsActive:       req.IsActive,
 	}
 
 	if req.StartAt != "" {
@@ -202,7 +246,12 @@ func (uc *CouponUsecase) UpdateCoupon(ctx context.Context, id string, req Update
 		}
 	}
 
-	return uc.couponRepo.UpdateCoupon(ctx, coupon)
+	return uc.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := uc.couponRepo.UpdateCoupon(txCtx, coupon); err != nil {
+			return err
+		}
+		return uc.couponRepo.SetCouponScopes(txCtx, uid, req.ProductIDs, req.CategoryIDs, req.CollectionIDs)
+	})
 }
 
 // DeleteCoupon deletes a coupon by ID.
@@ -213,13 +262,63 @@ func (uc *CouponUsecase) DeleteCoupon(ctx context.Context, id string) error {
 	}
 
 	// Verify exists
-	if _, err := uc.couponRepo.GetCouponByID(ctx, uid); err != nil {
+	coupon, err := uc.couponRepo.GetCouponByID(ctx, uid)
+	if err != nil {
 		return fmt.Errorf("coupon not found")
 	}
 
+	// Redemptions reference the coupon for order history and refunds
+	if coupon.UsedCount > 0 {
+		return fmt.Errorf("coupon has been redeemed and cannot be deleted, deactivate it instead")
+	}
+
 	return uc.couponRepo.DeleteCoupon(ctx, uid)
 }
 
+// validateCouponLimits checks the numeric limits shared by create and update.
+func validateCouponLimits(minSpend domain.Money, maxDiscount *domain.Money, usageLimit, perUserLimit int) error {
+	if minSpend < 0 {
+		return fmt.Errorf("minimum spend cannot be negative")
+	}
+	if maxDiscount != nil && *maxDiscount < 0 {
+		return fmt.Errorf("max discount cannot be negative")
+	}
+	if usageLimit < 0 || perUserLimit < 0 {
+		return fmt.Errorf("usage limits must be 0 (unlimited) or positive")
+	}
+	if usageLimit > 0 && perUserLimit > usageLimit {
+		return fmt.Errorf("per-user limit cannot exceed usage limit")
+	}
+	return nil
+}
+
+// validateScopeIDs ensures every scope target is a well-formed UUID.
+func validateScopeIDs(groups ...[]string) error {
+	for _, ids := range groups {
+		for _, id := range ids {
+			if _, err := uuid.Parse(id); err != nil {
+				return fmt.Errorf("invalid scope ID: %s", id)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeMaxDiscount drops the cap for fixed coupons and treats 0 as "no cap".
+func normalizeMaxDiscount(couponType string, maxDiscount *domain.Money) *domain.Money {
+	if couponType != "percentage" || maxDiscount == nil || *maxDiscount == 0 {
+		return nil
+	}
+	return maxDiscount
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
 // parseISO8601 parses an ISO8601 date string.
 func parseISO8601(s string) (time.Time, error) {
 	// Try multiple formats
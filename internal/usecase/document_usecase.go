@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/infrastructure/pdf"
+	"valancis-backend/pkg/storage"
+)
+
+// maxDocumentBatch caps the orders merged into one printable PDF.
+const maxDocumentBatch = 200
+
+// DocumentUsecase prints invoices, packing slips and shipping labels of orders.
+type DocumentUsecase struct {
+	orderRepo   domain.OrderRepository
+	invoiceRepo domain.InvoiceRepository
+	renderer    *pdf.Renderer
+	storage     *storage.R2Storage // Archives issued invoices; nil to disable
+}
+
+func NewDocumentUsecase(orderRepo domain.OrderRepository, invoiceRepo domain.InvoiceRepository, renderer *pdf.Renderer, storage *storage.R2Storage) *DocumentUsecase {
+	return &DocumentUsecase{
+		orderRepo:   orderRepo,
+		invoiceRepo: invoiceRepo,
+		renderer:    renderer,
+		storage:     storage,
+	}
+}
+
+// PrintedDocument is a rendered PDF and its download file name.
+type PrintedDocument struct {
+	PDF      []byte
+	FileName string
+}
+
+// RenderDocuments prints one document of the given type per order, merged into a single
+// PDF in the given order. Invoices are numbered the first time they are printed.
+func (u *DocumentUsecase) RenderDocuments(ctx context.Context, docType string, orderIDs []string) (*PrintedDocument, error) {
+	if !domain.IsValidDocumentType(docType) {
+		return nil, fmt.Errorf("invalid document type: %s", docType)
+	}
+	ids := make([]string, 0, len(orderIDs))
+	seen := make(map[string]bool, len(orderIDs))
+	for _, id := range orderIDs {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no orders selected")
+	}
+	if len(ids) > maxDocumentBatch {
+		return nil, fmt.Errorf("at most %d orders can be printed at once", maxDocumentBatch)
+	}
+
+	docs := make([]pdf.OrderDocument, 0, len(ids))
+	for _, id := range ids {
+		order, err := u.orderRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("order %s not found", id)
+		}
+		if order.Status == domain.OrderStatusCancelled {
+			return nil, fmt.Errorf("order %s is cancelled and cannot be printed", orderRef(order))
+		}
+		doc := pdf.OrderDocument{Order: order}
+		if docType == domain.DocumentInvoice {
+			if doc.Invoice, err = u.issueInvoice(ctx, order); err != nil {
+				return nil, err
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	data, err := u.renderer.Render(docType, docs)
+	if err != nil {
+		return nil, err
+	}
+	return &PrintedDocument{PDF: data, FileName: documentFileName(docType, docs)}, nil
+}
+
+// RenderMyInvoice prints the invoice of one of the customer's orders. Invoices are
+// available once the order is paid or delivered.
+func (u *DocumentUsecase) RenderMyInvoice(ctx context.Context, userID, orderID string) (*PrintedDocument, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+	if order.PaymentStatus != domain.PaymentStatusPaid && order.Status != domain.OrderStatusDelivered {
+		return nil, fmt.Errorf("invoice is available once the order is paid or delivered")
+	}
+	return u.RenderDocuments(ctx, domain.DocumentInvoice, []string{order.ID})
+}
+
+// issueInvoice returns the order's invoice, numbering it on first print. With storage
+// enabled the first print is archived; archiving problems are logged, never returned.
+func (u *DocumentUsecase) issueInvoice(ctx context.Context, order *domain.Order) (*domain.OrderInvoice, error) {
+	invoice, err := u.invoiceRepo.Issue(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue invoice number: %w", err)
+	}
+	if u.storage == nil || invoice.PDFURL != nil {
+		return invoice, nil
+	}
+
+	data, err := u.renderer.Render(domain.DocumentInvoice, []pdf.OrderDocument{{Order: order, Invoice: invoice}})
+	if err != nil {
+		slog.Error("Documents: failed to render invoice for archiving", "order_id", order.ID, "invoice", invoice.Number, "error", err)
+		return invoice, nil
+	}
+	url, err := u.storage.UploadBuffer(ctx, data, "application/pdf")
+	if err != nil {
+		slog.Error("Documents: failed to archive invoice", "order_id", order.ID, "invoice", invoice.Number, "error", err)
+		return invoice, nil
+	}
+	if err := u.invoiceRepo.SetPDFURL(ctx, invoice.ID, url); err != nil {
+		slog.Error("Documents: failed to save archived invoice URL", "order_id", order.ID, "invoice", invoice.Number, "error", err)
+		return invoice, nil
+	}
+	invoice.PDFURL = &url
+	return invoice, nil
+}
+
+// documentFileName names the download: the invoice number or order reference for a
+// single document, the type and date for a batch.
+func documentFileName(docType string, docs []pdf.OrderDocument) string {
+	prefix := strings.ReplaceAll(docType, "_", "-")
+	if len(docs) > 1 {
+		return fmt.Sprintf("%ss-%s.pdf", prefix, time.Now().Format("20060102-150405"))
+	}
+	if docs[0].Invoice != nil {
+		return docs[0].Invoice.Number + ".pdf"
+	}
+	return prefix + "-" + strings.TrimPrefix(orderRef(docs[0].Order), "#") + ".pdf"
+}
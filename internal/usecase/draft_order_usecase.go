@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+// DraftOrderUsecase lets admins build orders for customers who order by phone or
+// Messenger. A draft picks variants, a shipping zone, optional price overrides and a
+// manual discount; it becomes a real order through the checkout path (placeOrder, with
+// its stock locking) when an admin finalises it or the customer confirms it through a
+// signed pay/confirm link.
+type DraftOrderUsecase struct {
+	draftRepo   domain.DraftOrderRepository
+	orderUC     *OrderUsecase
+	productRepo domain.ProductRepository
+	configRepo  domain.ConfigRepository
+	userRepo    domain.UserRepository
+	txManager   domain.TransactionManager
+	notifier    *OrderNotifier
+	frontendURL string
+}
+
+func NewDraftOrderUsecase(draftRepo domain.DraftOrderRepository, orderUC *OrderUsecase, productRepo domain.ProductRepository, configRepo domain.ConfigRepository, userRepo domain.UserRepository, txManager domain.TransactionManager, notifier *OrderNotifier, frontendURL string) *DraftOrderUsecase {
+	return &DraftOrderUsecase{
+		draftRepo:   draftRepo,
+		orderUC:     orderUC,
+		productRepo: productRepo,
+		configRepo:  configRepo,
+		userRepo:    userRepo,
+		txManager:   txManager,
+		notifier:    notifier,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// DraftCustomerReq picks the customer of a new draft: an existing user by ID, or contact
+// details, recorded like a guest checkout customer.
+type DraftCustomerReq struct {
+	UserID    string `json:"userId,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+}
+
+type DraftOrderItemReq struct {
+	VariantID   string        `json:"variantId"`
+	Quantity    int           `json:"quantity"`
+	Price       *domain.Money `json:"price,omitempty"`       // Overrides the current unit price
+	PriceReason string        `json:"priceReason,omitempty"` // Required with Price
+}
+
+type DraftOrderReq struct {
+	Customer       DraftCustomerReq    `json:"customer"` // Only used when creating a draft
+	Items          []DraftOrderItemReq `json:"items"`
+	Address        domain.JSONB        `json:"address"`
+	ShippingZone   string              `json:"shippingZone"`  // Defaults to the address deliveryLocation, then inside_dhaka
+	PaymentMethod  string              `json:"paymentMethod"` // cod (default) or mobile_banking
+	Discount       domain.Money        `json:"discount"`
+	DiscountReason string              `json:"discountReason"` // Required with Discount
+	Note           string              `json:"note"`
+	Locale         string              `json:"locale"`
+}
+
+// DraftPaymentReq carries the payment details an order needs when it is placed:
+// the advance/pre-order transaction, and the customer's choice of payment method.
+type DraftPaymentReq struct {
+	PaymentMethod   string `json:"paymentMethod,omitempty"` // Customer links only; defaults to the draft's method
+	PaymentTrxID    string `json:"paymentTrxId,omitempty"`
+	PaymentProvider string `json:"paymentProvider,omitempty"`
+	PaymentPhone    string `json:"paymentPhone,omitempty"`
+}
+
+// DraftLinkResp is the pay/confirm link of a draft sent to the customer.
+type DraftLinkResp struct {
+	Draft   *domain.DraftOrder `json:"draft"`
+	Token   string             `json:"token"`
+	URL     string             `json:"url"`
+	Emailed bool               `json:"emailed"` // False when the customer has no email: share the URL by hand
+}
+
+// --- Admin ---
+
+// CreateDraft starts a draft order for an existing or new customer.
+func (u *DraftOrderUsecase) CreateDraft(ctx context.Context, req DraftOrderReq, adminID string) (*domain.DraftOrder, error) {
+	user, err := u.draftCustomer(ctx, req.Customer)
+	if err != nil {
+		return nil, err
+	}
+	draft := &domain.DraftOrder{UserID: user.ID, CreatedBy: &adminID}
+	if err := u.applyDraftReq(ctx, draft, user, req); err != nil {
+		return nil, err
+	}
+	if err := u.draftRepo.Create(ctx, draft); err != nil {
+		return nil, err
+	}
+	slog.Info("Drafts: draft order created", "draft_id", draft.ID, "user_id", user.ID, "admin_id", adminID)
+	return u.priced(ctx, draft)
+}
+
+// UpdateDraft replaces the items and details of an open draft. The customer is kept.
+func (u *DraftOrderUsecase) UpdateDraft(ctx context.Context, id string, req DraftOrderReq) (*domain.DraftOrder, error) {
+	var draft *domain.DraftOrder
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		if draft, err = u.draftRepo.GetForUpdate(txCtx, id); err != nil {
+			return err
+		}
+		if !domain.IsOpenDraftStatus(draft.Status) {
+			return fmt.Errorf("draft order is %s and cannot be changed", draft.Status)
+		}
+		user, err := u.userRepo.GetByID(txCtx, draft.UserID)
+		if err != nil || user == nil {
+			return fmt.Errorf("customer not found")
+		}
+		if err := u.applyDraftReq(txCtx, draft, user, req); err != nil {
+			return err
+		}
+		return u.draftRepo.Update(txCtx, draft)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.priced(ctx, draft)
+}
+
+func (u *DraftOrderUsecase) GetDraft(ctx context.Context, id string) (*domain.DraftOrder, error) {
+	draft, err := u.draftRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.priced(ctx, draft)
+}
+
+func (u *DraftOrderUsecase) ListDrafts(ctx context.Context, status string, page, limit int) ([]domain.DraftOrder, int64, error) {
+	drafts, total, err := u.draftRepo.List(ctx, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range drafts {
+		if _, err := u.priced(ctx, &drafts[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return drafts, total, nil
+}
+
+// SendDraft issues the customer's pay/confirm link and emails it when the customer has
+// an email address. Sending again re-issues the same link.
+func (u *DraftOrderUsecase) SendDraft(ctx context.Context, id string) (*DraftLinkResp, error) {
+	draft, err := u.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsOpenDraftStatus(draft.Status) {
+		return nil, fmt.Errorf("draft order is %s and cannot be sent", draft.Status)
+	}
+	token, err := utils.GenerateSignedID(utils.SignedIDDraftOrder, draft.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue draft order link: %w", err)
+	}
+	resp := &DraftLinkResp{Draft: draft, Token: token, URL: u.frontendURL + "/draft-orders/" + token}
+
+	user, err := u.userRepo.GetByID(ctx, draft.UserID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("customer not found")
+	}
+	to := addressField(draft.ShippingAddress, "email")
+	if to == "" {
+		to = user.Email
+	}
+	name := strings.TrimSpace(addressField(draft.ShippingAddress, "firstName") + " " + addressField(draft.ShippingAddress, "lastName"))
+	if name == "" {
+		name = user.FirstName
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := u.draftRepo.MarkSent(txCtx, draft.ID); err != nil {
+			return err
+		}
+		var err error
+		resp.Emailed, err = u.notifier.NotifyDraftOrder(txCtx, draft, to, name, resp.URL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	draft.Status = domain.DraftStatusSent
+	return resp, nil
+}
+
+// FinalizeDraft places the order of a draft on the admin's behalf.
+func (u *DraftOrderUsecase) FinalizeDraft(ctx context.Context, id string, req DraftPaymentReq, adminID string) (*domain.Order, error) {
+	draft, err := u.draftRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req.PaymentMethod = ""
+	return u.place(ctx, draft, req, adminID, fmt.Sprintf("Order placed by admin from draft %s", shortID(draft.ID)))
+}
+
+func (u *DraftOrderUsecase) CancelDraft(ctx context.Context, id string) (*domain.DraftOrder, error) {
+	draft, err := u.draftRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := u.draftRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("draft order is %s and cannot be cancelled", draft.Status)
+	}
+	return u.GetDraft(ctx, id)
+}
+
+// --- Customer (pay/confirm link) ---
+
+// GetDraftByToken returns the draft behind a pay/confirm link, without admin notes.
+func (u *DraftOrderUsecase) GetDraftByToken(ctx context.Context, token string) (*domain.DraftOrder, error) {
+	draft, err := u.draftByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.priced(ctx, draft); err != nil {
+		return nil, err
+	}
+	draft.Note = nil
+	draft.CreatedBy = nil
+	for i := range draft.Items {
+		draft.Items[i].OverrideReason = nil
+	}
+	return draft, nil
+}
+
+// ConfirmDraft places the order of a draft for the customer following their link. The
+// customer may switch between cash on delivery and advance payment; online gateway
+// payments need a signed-in session, as for guest checkout.
+func (u *DraftOrderUsecase) ConfirmDraft(ctx context.Context, token string, req DraftPaymentReq) (*GuestCheckoutResp, error) {
+	draft, err := u.draftByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if req.PaymentMethod != "" && !isDraftPaymentMethod(req.PaymentMethod) {
+		return nil, fmt.Errorf("online payment requires sign-in; choose cash on delivery or advance payment")
+	}
+
+	order, err := u.place(ctx, draft, req, draft.UserID, fmt.Sprintf("Order confirmed by customer from draft %s", shortID(draft.ID)))
+	if err != nil {
+		return nil, err
+	}
+	trackingToken, err := utils.GenerateSignedID(utils.SignedIDOrderTracking, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue tracking token: %w", err)
+	}
+	return &GuestCheckoutResp{Order: order, TrackingToken: trackingToken}, nil
+}
+
+func (u *DraftOrderUsecase) draftByToken(ctx context.Context, token string) (*domain.DraftOrder, error) {
+	id, err := utils.ValidateSignedID(utils.SignedIDDraftOrder, token)
+	if err != nil {
+		return nil, fmt.Errorf("draft order not found")
+	}
+	draft, err := u.draftRepo.GetByID(ctx, id)
+	if err != nil || draft.Status == domain.DraftStatusCancelled {
+		return nil, fmt.Errorf("draft order not found")
+	}
+	return draft, nil
+}
+
+// --- Helpers ---
+
+// place turns a draft into an order through the checkout path. The draft is marked
+// completed in the order's transaction, so it can only ever be placed once.
+func (u *DraftOrderUsecase) place(ctx context.Context, draft *domain.DraftOrder, payment DraftPaymentReq, actorID, reason string) (*domain.Order, error) {
+	if !domain.IsOpenDraftStatus(draft.Status) {
+		return nil, fmt.Errorf("draft order is %s and cannot be placed", draft.Status)
+	}
+	if len(draft.Items) == 0 {
+		return nil, fmt.Errorf("draft order has no items")
+	}
+
+	cart := &domain.Cart{}
+	overrides := &orderOverrides{
+		prices:   make(map[string]domain.Money),
+		discount: draft.DiscountAmount,
+		actorID:  actorID,
+		inTx: func(txCtx context.Context, order *domain.Order) error {
+			completed, err := u.draftRepo.Complete(txCtx, draft.ID, order.ID)
+			if err != nil {
+				return err
+			}
+			if !completed {
+				return fmt.Errorf("draft order was already placed or cancelled")
+			}
+			return nil
+		},
+	}
+	for _, item := range draft.Items {
+		cart.Items = append(cart.Items, domain.CartItem{
+			ProductID: item.ProductID,
+			VariantID: &item.VariantID,
+			Quantity:  item.Quantity,
+		})
+		if item.PriceOverride != nil {
+			overrides.prices[item.VariantID] = *item.PriceOverride
+			reason += fmt.Sprintf("; %s (%s) at %s", item.ProductName, item.VariantName, *item.PriceOverride)
+			if item.OverrideReason != nil {
+				reason += " — " + *item.OverrideReason
+			}
+		}
+	}
+	if draft.DiscountAmount > 0 {
+		reason += fmt.Sprintf("; manual discount %s", draft.DiscountAmount)
+		if draft.DiscountReason != nil {
+			reason += " — " + *draft.DiscountReason
+		}
+	}
+	overrides.reason = reason
+
+	method := draft.PaymentMethod
+	if payment.PaymentMethod != "" {
+		method = payment.PaymentMethod
+	}
+	order, err := u.orderUC.placeOrder(ctx, draft.UserID, cart, CheckoutReq{
+		Address:         draft.ShippingAddress,
+		Payment:         method,
+		PaymentTrxID:    strings.TrimSpace(payment.PaymentTrxID),
+		PaymentProvider: payment.PaymentProvider,
+		PaymentPhone:    payment.PaymentPhone,
+		Locale:          draft.Locale,
+	}, overrides, nil)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Drafts: draft order placed", "draft_id", draft.ID, "order_id", order.ID, "actor_id", actorID)
+	return order, nil
+}
+
+// draftCustomer resolves the customer of a new draft.
+func (u *DraftOrderUsecase) draftCustomer(ctx context.Context, req DraftCustomerReq) (*domain.User, error) {
+	if req.UserID != "" {
+		user, err := u.userRepo.GetByID(ctx, req.UserID)
+		if err != nil || user == nil {
+			return nil, fmt.Errorf("customer not found")
+		}
+		return user, nil
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return nil, fmt.Errorf("a valid customer email is required")
+	}
+	phone, ok := utils.NormalizePhone(req.Phone)
+	if !ok {
+		return nil, fmt.Errorf("a valid customer phone number is required")
+	}
+	return u.orderUC.guestUser(ctx, email, phone, strings.TrimSpace(req.FirstName), strings.TrimSpace(req.LastName))
+}
+
+// applyDraftReq validates a draft request and applies it to draft.
+func (u *DraftOrderUsecase) applyDraftReq(ctx context.Context, draft *domain.DraftOrder, user *domain.User, req DraftOrderReq) error {
+	if len(req.Items) == 0 {
+		return fmt.Errorf("add at least one item")
+	}
+	items := make([]domain.DraftOrderItem, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for _, reqItem := range req.Items {
+		if seen[reqItem.VariantID] {
+			return fmt.Errorf("variant %s is listed twice", reqItem.VariantID)
+		}
+		seen[reqItem.VariantID] = true
+		if reqItem.Quantity <= 0 {
+			return fmt.Errorf("variant %s: quantity must be positive", reqItem.VariantID)
+		}
+		variant, err := u.productRepo.GetVariantByID(ctx, reqItem.VariantID)
+		if err != nil || variant == nil {
+			return fmt.Errorf("variant %s not found", reqItem.VariantID)
+		}
+		item := domain.DraftOrderItem{
+			ProductID:   variant.ProductID,
+			VariantID:   variant.ID,
+			VariantName: variant.Name,
+			Quantity:    reqItem.Quantity,
+		}
+		if reqItem.Price != nil {
+			if *reqItem.Price <= 0 {
+				return fmt.Errorf("variant %s: price must be positive", reqItem.VariantID)
+			}
+			reason := strings.TrimSpace(reqItem.PriceReason)
+			if reason == "" {
+				return fmt.Errorf("variant %s: a reason is required to override the price", reqItem.VariantID)
+			}
+			item.PriceOverride = reqItem.Price
+			item.OverrideReason = &reason
+		}
+		items = append(items, item)
+	}
+
+	if req.Discount < 0 {
+		return fmt.Errorf("discount cannot be negative")
+	}
+	discountReason := strings.TrimSpace(req.DiscountReason)
+	if req.Discount > 0 && discountReason == "" {
+		return fmt.Errorf("a reason is required for a manual discount")
+	}
+
+	method := req.PaymentMethod
+	if method == "" {
+		method = domain.PaymentMethodCOD
+	}
+	if !isDraftPaymentMethod(method) {
+		return fmt.Errorf("payment method %s is not available for draft orders", method)
+	}
+
+	address := domain.JSONB{}
+	for k, v := range req.Address {
+		address[k] = v
+	}
+	zoneKey := req.ShippingZone
+	if zoneKey == "" {
+		zoneKey = addressField(address, "deliveryLocation")
+	}
+	if zoneKey == "" {
+		zoneKey = "inside_dhaka"
+	}
+	if _, err := u.configRepo.GetShippingZoneByKey(ctx, zoneKey); err != nil {
+		return fmt.Errorf("shipping zone %s not found", zoneKey)
+	}
+	address["deliveryLocation"] = zoneKey
+	// Contact details travel with the order (admin views, notifications, CAPI)
+	if addressField(address, "email") == "" {
+		address["email"] = user.Email
+	}
+	if addressField(address, "phone") == "" && user.Phone != "" {
+		address["phone"] = user.Phone
+	}
+
+	draft.Items = items
+	draft.ShippingAddress = address
+	draft.PaymentMethod = method
+	draft.DiscountAmount = req.Discount
+	draft.DiscountReason = optionalString(discountReason)
+	draft.Note = optionalString(strings.TrimSpace(req.Note))
+	draft.Locale = domain.NormalizeLocale(req.Locale)
+	return nil
+}
+
+// priced fills in the draft's unit prices and totals at current prices and shipping rates.
+func (u *DraftOrderUsecase) priced(ctx context.Context, draft *domain.DraftOrder) (*domain.DraftOrder, error) {
+	products := make(map[string]*domain.Product)
+	draft.Subtotal = 0
+	for i := range draft.Items {
+		item := &draft.Items[i]
+		product, ok := products[item.ProductID]
+		if !ok {
+			var err error
+			if product, err = u.productRepo.GetProductByID(ctx, item.ProductID); err != nil {
+				return nil, fmt.Errorf("product %s not found", item.ProductID)
+			}
+			products[item.ProductID] = product
+		}
+		item.ProductName = product.Name
+		if _, price, found := productVariantPrice(product, item.VariantID); found {
+			item.UnitPrice = price
+		}
+		if item.PriceOverride != nil {
+			item.UnitPrice = *item.PriceOverride
+		}
+		draft.Subtotal += item.UnitPrice.Times(item.Quantity)
+	}
+
+	draft.ShippingFee = 0
+	if zone, err := u.configRepo.GetShippingZoneByKey(ctx, addressField(draft.ShippingAddress, "deliveryLocation")); err == nil {
+		draft.ShippingFee = zone.Cost
+	}
+	draft.Total = draft.Subtotal - draft.DiscountAmount.Min(draft.Subtotal) + draft.ShippingFee
+	return draft, nil
+}
+
+// isDraftPaymentMethod returns true for the payment methods a draft order can be placed with.
+func isDraftPaymentMethod(method string) bool {
+	return method == domain.PaymentMethodCOD || method == domain.PaymentMethodAdvance
+}
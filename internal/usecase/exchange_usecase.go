@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"valancis-backend/internal/domain"
+)
+
+// ExchangeUsecase swaps delivered order items for another variant of the same product
+// (size/colour exchanges) instead of refunding them. The replacement is deducted from
+// stock up front and shipped in a shipment linked to the exchange; the original item is
+// restocked when it comes back. Price differences are charged on the replacement
+// shipment (COD) or refunded through ProcessRefund.
+type ExchangeUsecase struct {
+	exchangeRepo domain.ExchangeRepository
+	returnRepo   domain.ReturnRepository
+	orderRepo    domain.OrderRepository
+	productRepo  domain.ProductRepository
+	orderUC      *OrderUsecase
+	shippingUC   *ShippingUsecase
+	txManager    domain.TransactionManager
+}
+
+func NewExchangeUsecase(exchangeRepo domain.ExchangeRepository, returnRepo domain.ReturnRepository, orderRepo domain.OrderRepository, productRepo domain.ProductRepository, orderUC *OrderUsecase, shippingUC *ShippingUsecase, txManager domain.TransactionManager) *ExchangeUsecase {
+	return &ExchangeUsecase{
+		exchangeRepo: exchangeRepo,
+		returnRepo:   returnRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		orderUC:      orderUC,
+		shippingUC:   shippingUC,
+		txManager:    txManager,
+	}
+}
+
+type CreateExchangeReq struct {
+	OrderItemID     string           `json:"orderItemId"`
+	NewVariantID    string           `json:"newVariantId"`
+	Quantity        int              `json:"quantity"` // Defaults to 1
+	Note            string           `json:"note"`
+	RefundMethod    string           `json:"refundMethod,omitempty"` // For a cheaper replacement; defaults to cash
+	RefundReference string           `json:"refundReference,omitempty"`
+	Shipment        *BookShipmentReq `json:"shipment,omitempty"` // Books the replacement shipment right away
+}
+
+type ExchangeResp struct {
+	Exchange *domain.OrderExchange `json:"exchange"`
+	Shipment *domain.Shipment      `json:"shipment,omitempty"`
+}
+
+// CreateExchange swaps a quantity of a delivered order item for another variant.
+func (u *ExchangeUsecase) CreateExchange(ctx context.Context, orderID string, req CreateExchangeReq, adminID string) (*ExchangeResp, error) {
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if req.RefundMethod != "" && !domain.IsValidRefundMethod(req.RefundMethod) {
+		return nil, fmt.Errorf("invalid refund method: %s", req.RefundMethod)
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	if !domain.IsReturnable(order.Status) {
+		return nil, fmt.Errorf("order is %s and cannot be exchanged", order.Status)
+	}
+	var item *domain.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == req.OrderItemID {
+			item = &order.Items[i]
+		}
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item %s is not part of this order", req.OrderItemID)
+	}
+	if item.VariantID != nil && *item.VariantID == req.NewVariantID {
+		return nil, fmt.Errorf("the replacement must be a different variant")
+	}
+
+	product, err := u.productRepo.GetProductByID(ctx, item.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("product %s not found", item.ProductID)
+	}
+	variant, unitPrice, ok := productVariantPrice(product, req.NewVariantID)
+	if !ok {
+		return nil, fmt.Errorf("variant %s is not a variant of %s", req.NewVariantID, product.Name)
+	}
+	difference := (unitPrice - item.Price).Times(req.Quantity)
+	if difference < 0 {
+		if refundable := order.PaidAmount - order.RefundedAmount; -difference > refundable {
+			return nil, fmt.Errorf("cannot refund the price difference of %s (max refundable: %s)", -difference, refundable)
+		}
+	}
+
+	exchange := &domain.OrderExchange{
+		OrderID:         order.ID,
+		OrderItemID:     item.ID,
+		OldVariantID:    item.VariantID,
+		NewVariantID:    &variant.ID,
+		NewVariantName:  variant.Name,
+		Quantity:        req.Quantity,
+		UnitPrice:       unitPrice,
+		PriceDifference: difference,
+		Note:            optionalString(strings.TrimSpace(req.Note)),
+		CreatedBy:       &adminID,
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		// Units already returned or exchanged cannot be exchanged again. Checked under the
+		// order lock, which return requests take too, so no unit is claimed twice.
+		if err := u.orderRepo.LockForUpdate(txCtx, order.ID); err != nil {
+			return err
+		}
+		left, err := u.exchangeableQuantity(txCtx, item)
+		if err != nil {
+			return err
+		}
+		if req.Quantity > left {
+			return fmt.Errorf("only %d of this item can be exchanged", left)
+		}
+		current, err := u.orderRepo.GetByID(txCtx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		// Lock the replacement variant so concurrent checkouts cannot oversell it
+		locked, err := u.productRepo.GetVariantByIDForUpdate(txCtx, variant.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock stock for variant %s: %v", variant.ID, err)
+		}
+		if locked.Available < req.Quantity {
+			return fmt.Errorf("insufficient stock for %s (requested: %d, available: %d)", locked.Name, req.Quantity, locked.Available)
+		}
+		if err := u.exchangeRepo.Create(txCtx, exchange); err != nil {
+			return err
+		}
+		// Logged against the exchange; restoring the order's stock counts it per variant
+		if err := u.productRepo.UpdateStock(txCtx, variant.ID, -req.Quantity, "exchange_out", exchange.ID); err != nil {
+			return err
+		}
+		// The customer owes the difference, collected with the replacement: a paid order
+		// has a balance due again
+		if difference > 0 {
+			if err := u.orderRepo.UpdateTotalAmount(txCtx, order.ID, current.TotalAmount+difference); err != nil {
+				return err
+			}
+			if current.PaymentStatus == domain.PaymentStatusPaid {
+				if err := u.orderRepo.UpdatePaymentStatus(txCtx, order.ID, domain.PaymentStatusPartialPaid); err != nil {
+					return err
+				}
+			}
+		}
+
+		reason := fmt.Sprintf("Exchange: %s → %s x%d [exchange %s]", itemLabel(item), variant.Name, req.Quantity, shortID(exchange.ID))
+		switch {
+		case difference > 0:
+			reason += fmt.Sprintf(", %s BDT balance due from the customer", difference)
+		case difference < 0:
+			reason += fmt.Sprintf(", %s BDT difference refunded", -difference)
+		}
+		if exchange.Note != nil {
+			reason += " — " + *exchange.Note
+		}
+		return u.addHistory(txCtx, order, reason, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Exchanges: exchange created", "order_id", order.ID, "exchange_id", exchange.ID, "difference", difference)
+
+	// Both run their own transactions; on failure they can be retried separately
+	resp := &ExchangeResp{Exchange: exchange}
+	if difference < 0 {
+		_, err := u.orderUC.ProcessRefund(ctx, order.ID, RefundReq{
+			Amount:    -difference,
+			Reason:    fmt.Sprintf("Exchange %s price difference", shortID(exchange.ID)),
+			Method:    req.RefundMethod,
+			Reference: req.RefundReference,
+		}, adminID)
+		if err != nil {
+			return nil, fmt.Errorf("exchange created, but the price difference refund failed: %w", err)
+		}
+	}
+	if req.Shipment != nil {
+		order, err = u.orderRepo.GetByID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Shipment, err = u.shippingUC.BookReplacementShipment(ctx, order, exchange, *req.Shipment, adminID); err != nil {
+			return nil, fmt.Errorf("exchange created, but the replacement shipment could not be booked: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// BookExchangeShipment books the replacement shipment of an exchange.
+func (u *ExchangeUsecase) BookExchangeShipment(ctx context.Context, exchangeID string, req BookShipmentReq, adminID string) (*domain.Shipment, error) {
+	exchange, err := u.exchangeRepo.GetByID(ctx, exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := u.orderRepo.GetByID(ctx, exchange.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	return u.shippingUC.BookReplacementShipment(ctx, order, exchange, req, adminID)
+}
+
+// ReceiveExchange records that the original item came back and restocks it
+// (inventory log reason "exchange_return").
+func (u *ExchangeUsecase) ReceiveExchange(ctx context.Context, exchangeID, note, adminID string) (*domain.OrderExchange, error) {
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		exchange, err := u.exchangeRepo.GetForUpdate(txCtx, exchangeID)
+		if err != nil {
+			return err
+		}
+		if exchange.Status != domain.ExchangeStatusAwaitingReturn {
+			return fmt.Errorf("exchange is %s and cannot be received", exchange.Status)
+		}
+		order, err := u.orderRepo.GetByID(txCtx, exchange.OrderID)
+		if err != nil {
+			return err
+		}
+
+		label := exchange.OrderItemID
+		for _, item := range order.Items {
+			if item.ID != exchange.OrderItemID {
+				continue
+			}
+			label = itemLabel(&item)
+			targetID := item.ProductID
+			if item.VariantID != nil {
+				targetID = *item.VariantID
+			}
+			if err := u.productRepo.UpdateStock(txCtx, targetID, exchange.Quantity, "exchange_return", exchange.ID); err != nil {
+				return fmt.Errorf("failed to restock item %s: %w", item.ID, err)
+			}
+		}
+		if err := u.exchangeRepo.MarkReceived(txCtx, exchange.ID); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("Exchange: Received and restocked %s x%d [exchange %s]", label, exchange.Quantity, shortID(exchange.ID))
+		if note = strings.TrimSpace(note); note != "" {
+			reason += " — " + note
+		}
+		return u.addHistory(txCtx, order, reason, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.exchangeRepo.GetByID(ctx, exchangeID)
+}
+
+func (u *ExchangeUsecase) ListExchanges(ctx context.Context, orderID string) ([]domain.OrderExchange, error) {
+	return u.exchangeRepo.ListByOrder(ctx, orderID)
+}
+
+// exchangeableQuantity is the quantity of an order item not yet returned or exchanged.
+func (u *ExchangeUsecase) exchangeableQuantity(ctx context.Context, item *domain.OrderItem) (int, error) {
+	returns, err := u.returnRepo.ListByOrder(ctx, item.OrderID)
+	if err != nil {
+		return 0, err
+	}
+	exchanges, err := u.exchangeRepo.ListByOrder(ctx, item.OrderID)
+	if err != nil {
+		return 0, err
+	}
+	return item.Quantity - domain.ReturnedQuantities(returns)[item.ID] - domain.ExchangedQuantities(exchanges)[item.ID], nil
+}
+
+// addHistory records a note-only order history entry.
+func (u *ExchangeUsecase) addHistory(ctx context.Context, order *domain.Order, reason, actorID string) error {
+	return u.orderRepo.CreateOrderHistory(ctx, &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      order.Status,
+		Reason:         &reason,
+		CreatedBy:      &actorID,
+	})
+}
+
+// productVariantPrice finds a variant of the product and its current selling price,
+// priced like at checkout: variant sale price, variant price, product sale price, base price.
+func productVariantPrice(product *domain.Product, variantID string) (*domain.Variant, domain.Money, bool) {
+	for i := range product.Variants {
+		v := &product.Variants[i]
+		if v.ID != variantID {
+			continue
+		}
+		price := product.BasePrice
+		if product.SalePrice != nil {
+			price = *product.SalePrice
+		}
+		if v.Price != nil {
+			price = *v.Price
+		}
+		if v.SalePrice != nil {
+			price = *v.SalePrice
+		}
+		return v, price, true
+	}
+	return nil, 0, false
+}
+
+// itemLabel names an order item in history notes.
+func itemLabel(item *domain.OrderItem) string {
+	name := item.Product.Name
+	if name == "" {
+		name = "item " + shortID(item.ID)
+	}
+	if item.VariantName != nil && *item.VariantName != "" {
+		name += " (" + *item.VariantName + ")"
+	}
+	return name
+}
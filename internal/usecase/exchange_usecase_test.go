@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"valancis-backend/internal/domain"
+)
+
+// After an exchange, moving the order to returned restores what the customer holds:
+// the replacement, and the original only as far as it has not come back already.
+func TestExchangeThenReturnedRestocksHeldVariants(t *testing.T) {
+	tests := []struct {
+		name              string
+		receive           bool // The original came back before the status change
+		replacementPrice  domain.Money
+		wantTotal         domain.Money
+		wantPaymentStatus string
+	}{
+		{name: "original not back yet", replacementPrice: domain.Taka(500), wantTotal: domain.Taka(1000), wantPaymentStatus: domain.PaymentStatusPaid},
+		{name: "original received", receive: true, replacementPrice: domain.Taka(500), wantTotal: domain.Taka(1000), wantPaymentStatus: domain.PaymentStatusPaid},
+		{name: "dearer replacement leaves a balance due", replacementPrice: domain.Taka(600), wantTotal: domain.Taka(1100), wantPaymentStatus: domain.PaymentStatusPartialPaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			variantA, variantB := "variant-a", "variant-b"
+			order := &domain.Order{
+				ID:            "order-1",
+				Status:        domain.OrderStatusDelivered,
+				PaymentStatus: domain.PaymentStatusPaid,
+				TotalAmount:   domain.Taka(1000),
+				PaidAmount:    domain.Taka(1000),
+				Items: []domain.OrderItem{
+					{ID: "item-1", OrderID: "order-1", ProductID: "product-1", VariantID: &variantA, Quantity: 2, Price: domain.Taka(500)},
+				},
+			}
+			products := &fakeProductRepo{
+				products: map[string]*domain.Product{"product-1": {
+					ID:        "product-1",
+					BasePrice: domain.Taka(500),
+					Variants:  []domain.Variant{{ID: variantA}, {ID: variantB, Price: &tt.replacementPrice}},
+				}},
+				stock: map[string]int{variantA: 10, variantB: 10},
+			}
+			products.UpdateStock(ctx, variantA, -2, "order_placed", order.ID)
+
+			orders := &fakeOrderRepo{order: order}
+			orderUC := &OrderUsecase{
+				orderRepo:       orders,
+				productRepo:     products,
+				txManager:       fakeTxManager{},
+				reservationRepo: fakeReservationRepo{},
+			}
+			exchangeUC := &ExchangeUsecase{
+				exchangeRepo: &fakeExchangeRepo{products: products},
+				returnRepo:   &fakeReturnRepo{},
+				orderRepo:    orders,
+				productRepo:  products,
+				orderUC:      orderUC,
+				txManager:    fakeTxManager{},
+			}
+
+			resp, err := exchangeUC.CreateExchange(ctx, order.ID, CreateExchangeReq{OrderItemID: "item-1", NewVariantID: variantB}, "admin-1")
+			if err != nil {
+				t.Fatalf("CreateExchange: %v", err)
+			}
+			if orders.order.TotalAmount != tt.wantTotal {
+				t.Errorf("total = %s, want %s", orders.order.TotalAmount, tt.wantTotal)
+			}
+			if orders.order.PaymentStatus != tt.wantPaymentStatus {
+				t.Errorf("payment status = %s, want %s", orders.order.PaymentStatus, tt.wantPaymentStatus)
+			}
+			if tt.receive {
+				if _, err := exchangeUC.ReceiveExchange(ctx, resp.Exchange.ID, "", "admin-1"); err != nil {
+					t.Fatalf("ReceiveExchange: %v", err)
+				}
+			}
+			if err := orderUC.UpdateOrderStatus(ctx, order.ID, domain.OrderStatusReturned, "", "admin-1"); err != nil {
+				t.Fatalf("UpdateOrderStatus: %v", err)
+			}
+
+			for variantID, want := range map[string]int{variantA: 10, variantB: 10} {
+				if got := products.stock[variantID]; got != want {
+					t.Errorf("stock of %s = %d, want %d", variantID, got, want)
+				}
+			}
+		})
+	}
+}
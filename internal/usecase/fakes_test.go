@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"valancis-backend/internal/domain"
+)
+
+// The fakes embed the repository interfaces, so a test only implements the methods
+// the code under test calls; any other call panics on the nil interface.
+
+type fakeTxManager struct{}
+
+func (fakeTxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type stockMove struct {
+	variantID string
+	change    int
+	reason    string
+	reference string
+}
+
+// fakeProductRepo keeps variant stock and the inventory log in memory.
+type fakeProductRepo struct {
+	domain.ProductRepository
+	products  map[string]*domain.Product
+	stock     map[string]int
+	log       []stockMove
+	exchanges map[string]string // Exchange ID → order ID
+}
+
+func (r *fakeProductRepo) GetProductByID(ctx context.Context, id string) (*domain.Product, error) {
+	return r.products[id], nil
+}
+
+func (r *fakeProductRepo) GetVariantByIDForUpdate(ctx context.Context, id string) (*domain.Variant, error) {
+	return &domain.Variant{ID: id, Name: id, Stock: r.stock[id], Available: r.stock[id]}, nil
+}
+
+func (r *fakeProductRepo) UpdateStock(ctx context.Context, variantID string, quantity int, reason, referenceID string) error {
+	r.stock[variantID] += quantity
+	r.log = append(r.log, stockMove{variantID: variantID, change: quantity, reason: reason, reference: referenceID})
+	return nil
+}
+
+// GetOrderNetStockChanges counts the moves logged against the order and, as the fake
+// has no exchanges table, against any reference mapped to it in exchanges.
+func (r *fakeProductRepo) GetOrderNetStockChanges(ctx context.Context, orderID string) (map[string]int, error) {
+	changes := map[string]int{}
+	for _, move := range r.log {
+		if move.reference == orderID || r.exchanges[move.reference] == orderID {
+			changes[move.variantID] += move.change
+		}
+	}
+	return changes, nil
+}
+
+type fakeReservationRepo struct {
+	domain.StockReservationRepository
+	commitErr error
+}
+
+func (fakeReservationRepo) Release(ctx context.Context, orderID string) (int, error) {
+	return 0, nil
+}
+
+func (r fakeReservationRepo) Commit(ctx context.Context, orderID, reason string) (int, error) {
+	if r.commitErr != nil {
+		return 0, r.commitErr
+	}
+	return 1, nil
+}
+
+// fakeOrderRepo holds a single order.
+type fakeOrderRepo struct {
+	domain.OrderRepository
+	order   *domain.Order
+	history []domain.OrderHistory
+	refunds []domain.Refund
+}
+
+func (r *fakeOrderRepo) GetByID(ctx context.Context, id string) (*domain.Order, error) {
+	order := *r.order
+	order.Items = append([]domain.OrderItem(nil), r.order.Items...)
+	return &order, nil
+}
+
+func (r *fakeOrderRepo) LockForUpdate(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdateTotalAmount(ctx context.Context, id string, amount domain.Money) error {
+	r.order.TotalAmount = amount
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(ctx context.Context, id, status string) error {
+	r.order.Status = status
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdatePaymentStatus(ctx context.Context, id, status string) error {
+	r.order.PaymentStatus = status
+	return nil
+}
+
+// CreateRefund books the refund like the ledger does: it re-derives the refunded
+// amount and the payment status.
+func (r *fakeOrderRepo) CreateRefund(ctx context.Context, refund *domain.Refund) error {
+	r.refunds = append(r.refunds, *refund)
+	r.order.RefundedAmount += refund.Amount
+	r.order.PaymentStatus = domain.PaymentStatusPartialRefund
+	if r.order.RefundedAmount >= r.order.PaidAmount {
+		r.order.PaymentStatus = domain.PaymentStatusRefunded
+	}
+	return nil
+}
+
+func (r *fakeOrderRepo) GetRefunds(ctx context.Context, orderID string) ([]domain.Refund, error) {
+	return r.refunds, nil
+}
+
+func (r *fakeOrderRepo) CreateOrderHistory(ctx context.Context, history *domain.OrderHistory) error {
+	r.history = append(r.history, *history)
+	return nil
+}
+
+// fakeReturnRepo holds a single return.
+type fakeReturnRepo struct {
+	domain.ReturnRepository
+	ret *domain.OrderReturn
+}
+
+func (r *fakeReturnRepo) GetByID(ctx context.Context, id string) (*domain.OrderReturn, error) {
+	ret := *r.ret
+	ret.Items = append([]domain.ReturnItem(nil), r.ret.Items...)
+	return &ret, nil
+}
+
+func (r *fakeReturnRepo) ListByOrder(ctx context.Context, orderID string) ([]domain.OrderReturn, error) {
+	if r.ret == nil {
+		return nil, nil
+	}
+	return []domain.OrderReturn{*r.ret}, nil
+}
+
+func (r *fakeReturnRepo) GetForUpdate(ctx context.Context, id string) (*domain.OrderReturn, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeReturnRepo) UpdateItem(ctx context.Context, returnID string, item domain.ReturnItem) error {
+	for i := range r.ret.Items {
+		if r.ret.Items[i].OrderItemID == item.OrderItemID {
+			r.ret.Items[i] = item
+		}
+	}
+	return nil
+}
+
+func (r *fakeReturnRepo) UpdateStatus(ctx context.Context, id, status, adminNote, reviewedBy string) error {
+	r.ret.Status = status
+	return nil
+}
+
+// fakeExchangeRepo keeps exchanges in memory and maps them to their order in the
+// product fake, like the inventory log query joins them.
+type fakeExchangeRepo struct {
+	domain.ExchangeRepository
+	exchanges []domain.OrderExchange
+	products  *fakeProductRepo
+}
+
+func (r *fakeExchangeRepo) Create(ctx context.Context, exchange *domain.OrderExchange) error {
+	exchange.ID = fmt.Sprintf("exchange-%d", len(r.exchanges)+1)
+	exchange.Status = domain.ExchangeStatusAwaitingReturn
+	r.exchanges = append(r.exchanges, *exchange)
+	if r.products.exchanges == nil {
+		r.products.exchanges = map[string]string{}
+	}
+	r.products.exchanges[exchange.ID] = exchange.OrderID
+	return nil
+}
+
+func (r *fakeExchangeRepo) GetByID(ctx context.Context, id string) (*domain.OrderExchange, error) {
+	for i := range r.exchanges {
+		if r.exchanges[i].ID == id {
+			exchange := r.exchanges[i]
+			return &exchange, nil
+		}
+	}
+	return nil, fmt.Errorf("exchange %w", domain.ErrNotFound)
+}
+
+func (r *fakeExchangeRepo) GetForUpdate(ctx context.Context, id string) (*domain.OrderExchange, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeExchangeRepo) ListByOrder(ctx context.Context, orderID string) ([]domain.OrderExchange, error) {
+	return r.exchanges, nil
+}
+
+func (r *fakeExchangeRepo) MarkReceived(ctx context.Context, id string) error {
+	for i := range r.exchanges {
+		if r.exchanges[i].ID == id {
+			r.exchanges[i].Status = domain.ExchangeStatusReceived
+		}
+	}
+	return nil
+}
+
+type fakePaymentRepo struct {
+	domain.PaymentRepository
+}
+
+func (fakePaymentRepo) CompleteSession(ctx context.Context, id, status string, transactionID *string) error {
+	return nil
+}
+
+// fakeLedgerRepo re-derives the order's paid amount like the ledger does.
+type fakeLedgerRepo struct {
+	domain.PaymentLedgerRepository
+	orders *fakeOrderRepo
+}
+
+func (r *fakeLedgerRepo) Record(ctx context.Context, payment *domain.Payment) error {
+	if payment.Direction == domain.PaymentDirectionIn && payment.Status == domain.PaymentEntryVerified {
+		r.orders.order.PaidAmount += payment.Amount
+	}
+	return nil
+}
+
+// fakePreorderRepo has no balance requests.
+type fakePreorderRepo struct {
+	domain.PreorderRepository
+}
+
+func (fakePreorderRepo) GetPendingBalanceRequest(ctx context.Context, orderID string) (*domain.PreorderBalanceRequest, error) {
+	return nil, fmt.Errorf("balance request %w", domain.ErrNotFound)
+}
+
+// fakeBlocklistRepo matches entries by exact value; IP entries are single addresses.
+type fakeBlocklistRepo struct {
+	domain.BlocklistRepository
+	entries []domain.BlocklistEntry
+}
+
+func (r *fakeBlocklistRepo) Match(ctx context.Context, subject domain.BlocklistSubject) ([]domain.BlocklistEntry, error) {
+	values := map[string]string{
+		domain.BlocklistKindUser:  subject.UserID,
+		domain.BlocklistKindPhone: subject.Phone,
+		domain.BlocklistKindEmail: subject.Email,
+		domain.BlocklistKindIP:    subject.IP,
+	}
+	var matched []domain.BlocklistEntry
+	for _, entry := range r.entries {
+		if value := values[entry.Kind]; value != "" && value == entry.Value {
+			matched = append(matched, entry)
+		}
+	}
+	return matched, nil
+}
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("user %w", domain.ErrNotFound)
+}
+
+// GetByEmail finds accounts only, like the query.
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Email == email && user.Role != domain.RoleGuest {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
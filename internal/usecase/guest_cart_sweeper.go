@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+const guestCartSweepBatch = 500
+
+// GuestCartSweeper deletes guest carts nobody has changed for the TTL. Their tokens
+// expire with them, so an abandoned cart is never seen again.
+// Same lifecycle as StockReservationSweeper: started on construction, stopped via
+// Shutdown on graceful exit.
+type GuestCartSweeper struct {
+	orderRepo domain.OrderRepository
+	ttl       time.Duration
+	interval  time.Duration
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewGuestCartSweeper(ctx context.Context, orderRepo domain.OrderRepository, ttl, interval time.Duration) *GuestCartSweeper {
+	s := &GuestCartSweeper{
+		orderRepo: orderRepo,
+		ttl:       ttl,
+		interval:  interval,
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	go s.loop()
+	return s
+}
+
+func (s *GuestCartSweeper) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Sweep(s.ctx); err != nil {
+				slog.Error("Guest carts: sweep failed", "error", err)
+			}
+		case <-s.ctx.Done():
+			return // Graceful shutdown
+		}
+	}
+}
+
+// Sweep deletes idle guest carts in batches and returns how many were removed.
+func (s *GuestCartSweeper) Sweep(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		n, err := s.orderRepo.DeleteIdleGuestCarts(ctx, s.ttl, guestCartSweepBatch)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < guestCartSweepBatch {
+			break
+		}
+	}
+	if total > 0 {
+		slog.Info("Guest carts: deleted idle carts", "count", total)
+	}
+	return total, nil
+}
+
+// Shutdown gracefully stops the sweeper goroutine
+func (s *GuestCartSweeper) Shutdown() {
+	s.cancel()
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/internal/infrastructure/mail"
+	"valancis-backend/pkg/utils"
+)
+
+// OrderNotifier renders order lifecycle emails and queues them in the outbox.
+// It must be called with the transaction context of the change being announced:
+// the email is only delivered if that transaction commits.
+type OrderNotifier struct {
+	outboxRepo  domain.EmailOutboxRepository
+	orderRepo   domain.OrderRepository
+	frontendURL string
+}
+
+func NewOrderNotifier(outboxRepo domain.EmailOutboxRepository, orderRepo domain.OrderRepository, frontendURL string) *OrderNotifier {
+	return &OrderNotifier{
+		outboxRepo:  outboxRepo,
+		orderRepo:   orderRepo,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// NotifyOrder queues the given order email. refundAmount is only used by refund emails.
+// Orders without a contact email and rendering problems are logged and skipped so a
+// notification can never block an order change; outbox write errors are returned.
+// Safe to call on a nil notifier (notifications disabled).
+func (n *OrderNotifier) NotifyOrder(ctx context.Context, orderID, template string, refundAmount domain.Money) error {
+	if n == nil {
+		return nil
+	}
+	return n.notify(ctx, orderID, template, func(email *mail.OrderEmail) {
+		email.RefundAmount = refundAmount
+	})
+}
+
+// NotifyPreorderRelease queues the pre-order release email of an order, with the balance
+// due and its pay link when there is one. Same delivery rules as NotifyOrder.
+func (n *OrderNotifier) NotifyPreorderRelease(ctx context.Context, orderID string, balanceDue domain.Money, payURL string) error {
+	if n == nil {
+		return nil
+	}
+	return n.notify(ctx, orderID, domain.EmailPreorderReleased, func(email *mail.OrderEmail) {
+		email.BalanceDue = balanceDue
+		email.PayURL = payURL
+	})
+}
+
+// notify renders an order email (adjusted by the template-specific fill) and queues it.
+func (n *OrderNotifier) notify(ctx context.Context, orderID, template string, fill func(email *mail.OrderEmail)) error {
+	order, err := n.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to load order for notification: %w", err)
+	}
+
+	to := addressField(order.ShippingAddress, "email")
+	if to == "" {
+		to = order.User.Email
+	}
+	if to == "" {
+		slog.Warn("Notify: order has no contact email, skipping", "order_id", order.ID, "template", template)
+		return nil
+	}
+
+	email := mail.OrderEmail{
+		Template:     template,
+		Locale:       domain.NormalizeLocale(order.Locale),
+		CustomerName: strings.TrimSpace(addressField(order.ShippingAddress, "firstName") + " " + addressField(order.ShippingAddress, "lastName")),
+		OrderRef:     orderRef(order),
+		ShippingFee:  order.ShippingFee,
+		Discount:     order.DiscountAmount,
+		Total:        order.TotalAmount,
+	}
+	if email.CustomerName == "" {
+		email.CustomerName = order.User.FirstName
+	}
+	for _, item := range order.Items {
+		name := item.Product.Name
+		if item.VariantName != nil && *item.VariantName != "" {
+			name += " (" + *item.VariantName + ")"
+		}
+		email.Items = append(email.Items, mail.OrderEmailItem{Name: name, Quantity: item.Quantity, Price: item.Price})
+	}
+	if token, err := utils.GenerateSignedID(utils.SignedIDOrderTracking, order.ID); err == nil {
+		email.TrackingURL = n.frontendURL + "/track/" + token
+	}
+	fill(&email)
+
+	subject, htmlBody, textBody, err := mail.RenderOrderEmail(email)
+	if err != nil {
+		slog.Error("Notify: failed to render email, skipping", "order_id", order.ID, "template", template, "error", err)
+		return nil
+	}
+
+	return n.outboxRepo.Enqueue(ctx, &domain.OutboxEmail{
+		OrderID:  &order.ID,
+		Template: template,
+		Locale:   email.Locale,
+		To:       to,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+}
+
+// NotifyDraftOrder queues the pay/confirm link of a priced draft order to the customer.
+// Returns false without queueing anything if there is no contact email.
+// Safe to call on a nil notifier (notifications disabled).
+func (n *OrderNotifier) NotifyDraftOrder(ctx context.Context, draft *domain.DraftOrder, to, customerName, confirmURL string) (bool, error) {
+	if n == nil || to == "" {
+		return false, nil
+	}
+
+	email := mail.OrderEmail{
+		Template:     domain.EmailDraftOrder,
+		Locale:       domain.NormalizeLocale(draft.Locale),
+		CustomerName: customerName,
+		OrderRef:     "#" + shortID(draft.ID),
+		ShippingFee:  draft.ShippingFee,
+		Discount:     draft.DiscountAmount.Min(draft.Subtotal),
+		Total:        draft.Total,
+		ConfirmURL:   confirmURL,
+	}
+	for _, item := range draft.Items {
+		email.Items = append(email.Items, mail.OrderEmailItem{Name: item.ProductName + " (" + item.VariantName + ")", Quantity: item.Quantity, Price: item.UnitPrice})
+	}
+
+	subject, htmlBody, textBody, err := mail.RenderOrderEmail(email)
+	if err != nil {
+		return false, fmt.Errorf("failed to render draft order email: %w", err)
+	}
+	err = n.outboxRepo.Enqueue(ctx, &domain.OutboxEmail{
+		Template: domain.EmailDraftOrder,
+		Locale:   email.Locale,
+		To:       to,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	return err == nil, err
+}
+
+// orderRef is the order reference shown to customers: the order number, or a short
+// form of the ID for orders without one.
+func orderRef(order *domain.Order) string {
+	if order.OrderNumber != "" {
+		return order.OrderNumber
+	}
+	if len(order.ID) < 8 {
+		return strings.ToUpper(order.ID)
+	}
+	return "#" + strings.ToUpper(order.ID[:8])
+}
+
+const (
+	emailDispatchBatch = 50
+	emailSendLease     = 5 * time.Minute
+	maxEmailAttempts   = 5
+)
+
+// EmailDispatcher delivers queued outbox emails through a Mailer, retrying failures
+// with a growing delay.
+// L9: Same lifecycle as the RateLimiter cleanup loop — started on construction,
+// stopped via Shutdown on graceful exit.
+type EmailDispatcher struct {
+	outboxRepo domain.EmailOutboxRepository
+	mailer     domain.Mailer
+	interval   time.Duration
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+func NewEmailDispatcher(ctx context.Context, outboxRepo domain.EmailOutboxRepository, mailer domain.Mailer, interval time.Duration) *EmailDispatcher {
+	d := &EmailDispatcher{
+		outboxRepo: outboxRepo,
+		mailer:     mailer,
+		interval:   interval,
+	}
+	d.ctx, d.cancel = context.WithCancel(ctx)
+	go d.loop()
+	return d
+}
+
+func (d *EmailDispatcher) loop() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := d.Dispatch(d.ctx); err != nil {
+				slog.Error("Mail: dispatch failed", "error", err)
+			}
+		case <-d.ctx.Done():
+			return // Graceful shutdown
+		}
+	}
+}
+
+// Dispatch sends every due email and returns how many were delivered.
+func (d *EmailDispatcher) Dispatch(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		batch, err := d.outboxRepo.ClaimDue(ctx, emailDispatchBatch, emailSendLease)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, email := range batch {
+			if ctx.Err() != nil {
+				return sent, nil // Shutting down: unsent claims become due again after the lease
+			}
+			if d.deliver(ctx, email) {
+				sent++
+			}
+		}
+
+		if len(batch) < emailDispatchBatch {
+			break
+		}
+	}
+	if sent > 0 {
+		slog.Info("Mail: delivered queued emails", "count", sent)
+	}
+	return sent, nil
+}
+
+func (d *EmailDispatcher) deliver(ctx context.Context, email domain.OutboxEmail) bool {
+	err := d.mailer.Send(ctx, domain.EmailMessage{
+		To:       email.To,
+		Subject:  email.Subject,
+		HTMLBody: email.HTMLBody,
+		TextBody: email.TextBody,
+	})
+	if err == nil {
+		if err := d.outboxRepo.MarkSent(ctx, email.ID); err != nil {
+			slog.Error("Mail: failed to mark email sent", "email_id", email.ID, "error", err)
+		}
+		return true
+	}
+
+	if email.Attempts >= maxEmailAttempts {
+		slog.Error("Mail: giving up on email", "email_id", email.ID, "to", email.To, "attempts", email.Attempts, "error", err)
+		if err := d.outboxRepo.MarkFailed(ctx, email.ID, err.Error()); err != nil {
+			slog.Error("Mail: failed to mark email failed", "email_id", email.ID, "error", err)
+		}
+		return false
+	}
+
+	// 1m, 4m, 9m, 16m ...
+	retryIn := time.Duration(email.Attempts*email.Attempts) * time.Minute
+	slog.Warn("Mail: send failed, will retry", "email_id", email.ID, "attempt", email.Attempts, "retry_in", retryIn, "error", err)
+	if err := d.outboxRepo.MarkRetry(ctx, email.ID, err.Error(), retryIn); err != nil {
+		slog.Error("Mail: failed to schedule retry", "email_id", email.ID, "error", err)
+	}
+	return false
+}
+
+// Shutdown gracefully stops the dispatcher goroutine
+func (d *EmailDispatcher) Shutdown() {
+	d.cancel()
+}
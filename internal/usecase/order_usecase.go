@@ -2,8 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
 	"valancis-backend/internal/domain"
 	"valancis-backend/internal/infrastructure/facebook"
 	"valancis-backend/pkg/utils"
@@ -16,16 +22,48 @@ type OrderUsecase struct {
 	couponRepo  domain.CouponRepository
 	txManager   domain.TransactionManager
 	capiClient  *facebook.CAPIClient
+
+	// Gateway orders hold stock for reservationTTL instead of deducting it at checkout
+	reservationRepo domain.StockReservationRepository
+	reservationTTL  time.Duration
+	maxCartQuantity int
+	// Guest checkout records its customers as lightweight guest users
+	userRepo domain.UserRepository
+	// Customer emails are queued in the same transaction as the order change (nil: disabled)
+	notifier *OrderNotifier
+	// COD orders are confirmed by an SMS code to the shipping phone (nil: disabled)
+	phoneVerifier *PhoneVerifier
+	// Blocklisted customers cannot order, or only with an advance payment (nil: disabled)
+	blocklist *BlocklistUsecase
+	// Pre-order caps are checked against the units not yet covered by a release
+	preorderRepo domain.PreorderRepository
+	// Every money movement is a ledger entry; paid/refunded amounts are derived from it
+	ledgerRepo domain.PaymentLedgerRepository
+	// Orders with booked shipments cannot be edited
+	shipmentRepo domain.ShipmentRepository
+	// New orders are scored for fraud risk; high-risk COD orders need an advance deposit
+	riskPolicy domain.RiskPolicy
 }
 
-func NewOrderUsecase(repo domain.OrderRepository, pRepo domain.ProductRepository, configRepo domain.ConfigRepository, cRepo domain.CouponRepository, txManager domain.TransactionManager, capiClient *facebook.CAPIClient) *OrderUsecase {
+func NewOrderUsecase(repo domain.OrderRepository, pRepo domain.ProductRepository, configRepo domain.ConfigRepository, cRepo domain.CouponRepository, rRepo domain.StockReservationRepository, txManager domain.TransactionManager, uRepo domain.UserRepository, notifier *OrderNotifier, phoneVerifier *PhoneVerifier, blocklist *BlocklistUsecase, capiClient *facebook.CAPIClient, preorderRepo domain.PreorderRepository, ledgerRepo domain.PaymentLedgerRepository, shipmentRepo domain.ShipmentRepository, reservationTTL time.Duration, maxCartQuantity int, riskPolicy domain.RiskPolicy) *OrderUsecase {
 	return &OrderUsecase{
-		orderRepo:   repo,
-		productRepo: pRepo,
-		configRepo:  configRepo,
-		couponRepo:  cRepo,
-		txManager:   txManager,
-		capiClient:  capiClient,
+		orderRepo:       repo,
+		productRepo:     pRepo,
+		configRepo:      configRepo,
+		couponRepo:      cRepo,
+		txManager:       txManager,
+		capiClient:      capiClient,
+		reservationRepo: rRepo,
+		reservationTTL:  reservationTTL,
+		maxCartQuantity: maxCartQuantity,
+		userRepo:        uRepo,
+		notifier:        notifier,
+		phoneVerifier:   phoneVerifier,
+		blocklist:       blocklist,
+		preorderRepo:    preorderRepo,
+		ledgerRepo:      ledgerRepo,
+		shipmentRepo:    shipmentRepo,
+		riskPolicy:      riskPolicy,
 	}
 }
 
@@ -70,29 +108,21 @@ func (u *OrderUsecase) GetMyCart(ctx context.Context, userID string) (*domain.Ca
 	return cart, nil
 }
 
-func (u *OrderUsecase) AddToCart(ctx context.Context, userID string, productID string, variantID *string, quantity int) (*domain.Cart, error) {
+// AddToCart adds quantity of a variant to the user's cart. ip is the client address,
+// checked against the blocklist with the user's account.
+func (u *OrderUsecase) AddToCart(ctx context.Context, userID string, productID string, variantID *string, quantity int, ip string) (*domain.Cart, error) {
 	slog.Info("Usecase: AddToCart", "user_id", userID, "product_id", productID, "variant_id", variantID, "quantity", quantity)
 
-	// L9: Enforce "Everything is a Variant" rule
-	if variantID == nil {
-		product, err := u.productRepo.GetProductByID(ctx, productID)
-		if err != nil {
-			return nil, fmt.Errorf("product not found: %w", err)
-		}
-		if len(product.Variants) == 1 {
-			// Auto-select the only variant
-			vID := product.Variants[0].ID
-			variantID = &vID
-			slog.Info("Usecase: AddToCart - Auto-resolved single variant", "variant_id", vID)
-		} else if len(product.Variants) > 1 {
-			return nil, fmt.Errorf("please select a variant option")
-		} else {
-			return nil, fmt.Errorf("product configuration error: item has no variants")
-		}
-	} else {
-		// Validation: Verify the variant actually belongs to the product?
-		// Basic Upsert will check FK, but maybe good to be safe.
-		// For now, trust the ID if provided.
+	// Blocked customers cannot shop; 'prepay' ones are only stopped at checkout
+	if entry, err := u.checkBlocklist(ctx, userID, nil, ip); err != nil {
+		return nil, err
+	} else if entry != nil && entry.Action == domain.BlocklistActionBlock {
+		return nil, errCustomerBlocked
+	}
+
+	variantID, err := u.resolveCartVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get cart (create if not exists)
@@ -104,34 +134,7 @@ func (u *OrderUsecase) AddToCart(ctx context.Context, userID string, productID s
 	slog.Info("Usecase: AddToCart - Got Cart", "cart_id", cart.ID)
 
 	// Check existing quantity for the SPECIFIC variant
-	existingQty := 0
-	for _, item := range cart.Items {
-		if item.ProductID == productID {
-			// Check if variant matches (handling nil pointers)
-			match := false
-			v1 := "nil"
-			if item.VariantID != nil {
-				v1 = *item.VariantID
-			}
-			v2 := "nil"
-			if variantID != nil {
-				v2 = *variantID
-			}
-
-			if item.VariantID == nil && variantID == nil {
-				match = true
-			} else if item.VariantID != nil && variantID != nil && *item.VariantID == *variantID {
-				match = true
-			}
-
-			slog.Debug("Usecase: AddToCart - Matching variant", "item_variant", v1, "req_variant", v2, "match", match)
-
-			if match {
-				existingQty = item.Quantity
-				break
-			}
-		}
-	}
+	existingQty := cartItemQuantity(cart.Items, productID, variantID)
 
 	// Calculate new total
 	newTotal := existingQty + quantity
@@ -157,6 +160,42 @@ func (u *OrderUsecase) AddToCart(ctx context.Context, userID string, productID s
 	}, nil
 }
 
+// resolveCartVariant enforces the L9 "Everything is a Variant" rule: a missing
+// variant is auto-selected when the product has exactly one.
+func (u *OrderUsecase) resolveCartVariant(ctx context.Context, productID string, variantID *string) (*string, error) {
+	if variantID != nil {
+		// Basic Upsert will check FK; trust the ID if provided.
+		return variantID, nil
+	}
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+	if len(product.Variants) == 1 {
+		// Auto-select the only variant
+		vID := product.Variants[0].ID
+		slog.Info("Usecase: AddToCart - Auto-resolved single variant", "variant_id", vID)
+		return &vID, nil
+	} else if len(product.Variants) > 1 {
+		return nil, fmt.Errorf("please select a variant option")
+	}
+	return nil, fmt.Errorf("product configuration error: item has no variants")
+}
+
+// cartItemQuantity returns the quantity already in the cart for a product/variant pair.
+func cartItemQuantity(items []domain.CartItem, productID string, variantID *string) int {
+	for _, item := range items {
+		if item.ProductID != productID {
+			continue
+		}
+		if (item.VariantID == nil && variantID == nil) ||
+			(item.VariantID != nil && variantID != nil && *item.VariantID == *variantID) {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
 // RemoveFromCart removes a product from the user's cart
 func (u *OrderUsecase) RemoveFromCart(ctx context.Context, userID string, productID string, variantID string) (*domain.Cart, error) {
 	// Atomic O(1) Remove
@@ -215,6 +254,182 @@ func (u *OrderUsecase) UpdateCartItemQuantity(ctx context.Context, userID string
 	return cart, nil
 }
 
+// --- Guest Cart Logic ---
+// Guest carts are ordinary carts without an owner. Handlers address them through a
+// signed cart token; MergeGuestCart folds them into the user's cart on sign-in.
+
+// GetGuestCart loads an ownerless cart. When cartID is empty, unknown or already
+// belongs to a user it returns an empty cart without an ID; nothing is saved until
+// the first item is added.
+func (u *OrderUsecase) GetGuestCart(ctx context.Context, cartID string) (*domain.Cart, error) {
+	if cartID != "" {
+		cart, err := u.orderRepo.GetCartByID(ctx, cartID)
+		if err != nil {
+			return nil, err
+		}
+		if cart != nil && cart.UserID == nil {
+			items, err := u.orderRepo.GetCartItemsByCartID(ctx, cart.ID)
+			if err != nil {
+				return nil, err
+			}
+			cart.Items = items
+			return cart, nil
+		}
+	}
+	return &domain.Cart{Items: []domain.CartItem{}}, nil
+}
+
+// guestCartForWrite loads the guest cart a change applies to, creating it on the first
+// add. An existing cart is touched so the idle sweep leaves it alone.
+func (u *OrderUsecase) guestCartForWrite(ctx context.Context, cartID string) (*domain.Cart, error) {
+	cart, err := u.GetGuestCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart.ID != "" {
+		if err := u.orderRepo.TouchCart(ctx, cart.ID); err != nil {
+			return nil, err
+		}
+		return cart, nil
+	}
+	if err := u.orderRepo.CreateCart(ctx, cart); err != nil {
+		slog.Error("Usecase: guestCartForWrite - CreateCart failed", "error", err)
+		return nil, err
+	}
+	slog.Info("Usecase: guestCartForWrite - Created guest cart", "cart_id", cart.ID)
+	return cart, nil
+}
+
+// AddToGuestCart adds quantity of a variant to a guest cart. A guest cart has no email
+// or phone until checkout, so only the client address ip is checked against the
+// blocklist here; GuestCheckout checks the contact details it is given.
+func (u *OrderUsecase) AddToGuestCart(ctx context.Context, cartID, productID string, variantID *string, quantity int, ip string) (*domain.Cart, error) {
+	if entry, err := u.checkBlocklist(ctx, "", nil, ip); err != nil {
+		return nil, err
+	} else if entry != nil && entry.Action == domain.BlocklistActionBlock {
+		return nil, errCustomerBlocked
+	}
+
+	variantID, err := u.resolveCartVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := u.guestCartForWrite(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	newTotal := cartItemQuantity(cart.Items, productID, variantID) + quantity
+	items, err := u.orderRepo.UpsertCartItemAtomic(ctx, "", cart.ID, productID, variantID, newTotal)
+	if err != nil || len(items) == 0 {
+		if err != nil {
+			slog.Error("Usecase: AddToGuestCart - UpsertCartItemAtomic DB Error", "error", err)
+		}
+		return nil, fmt.Errorf("insufficient stock or product unavailable for variant %s", *variantID)
+	}
+
+	return u.GetGuestCart(ctx, cart.ID)
+}
+
+func (u *OrderUsecase) UpdateGuestCartItemQuantity(ctx context.Context, cartID, productID string, variantID *string, quantity int) (*domain.Cart, error) {
+	if quantity <= 0 {
+		if variantID == nil {
+			return nil, fmt.Errorf("variant_id required to remove item")
+		}
+		return u.RemoveFromGuestCart(ctx, cartID, productID, *variantID)
+	}
+
+	cart, err := u.guestCartForWrite(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := u.orderRepo.UpsertCartItemAtomic(ctx, "", cart.ID, productID, variantID, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cart: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("unable to update cart: insufficient stock or invalid item")
+	}
+
+	return u.GetGuestCart(ctx, cart.ID)
+}
+
+func (u *OrderUsecase) RemoveFromGuestCart(ctx context.Context, cartID, productID, variantID string) (*domain.Cart, error) {
+	cart, err := u.GetGuestCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart.ID == "" {
+		return cart, nil // Nothing to remove from
+	}
+	if err := u.orderRepo.TouchCart(ctx, cart.ID); err != nil {
+		return nil, err
+	}
+	if err := u.orderRepo.RemoveCartItem(ctx, cart.ID, productID, variantID); err != nil {
+		return nil, err
+	}
+	return u.GetGuestCart(ctx, cart.ID)
+}
+
+// MergeGuestCart moves a guest cart's items into the user's cart and deletes the guest cart.
+// Quantities of lines present in both are summed, then capped at maxCartQuantity and at the
+// variant's currently available stock. Lines that no longer fit at all are dropped.
+func (u *OrderUsecase) MergeGuestCart(ctx context.Context, userID, guestCartID string) error {
+	guest, err := u.orderRepo.GetCartByID(ctx, guestCartID)
+	if err != nil {
+		return err
+	}
+	if guest == nil || guest.UserID != nil {
+		return nil // Unknown or already claimed: nothing to merge
+	}
+	guestItems, err := u.orderRepo.GetCartItemsByCartID(ctx, guest.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.txManager.Do(ctx, func(txCtx context.Context) error {
+		userCart, err := u.orderRepo.GetCartByUserID(txCtx, userID)
+		if err != nil {
+			return err
+		}
+		if userCart == nil {
+			userCart = &domain.Cart{UserID: &userID}
+			if err := u.orderRepo.CreateCart(txCtx, userCart); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range guestItems {
+			if item.VariantID == nil {
+				continue
+			}
+			existing := cartItemQuantity(userCart.Items, item.ProductID, item.VariantID)
+
+			qty := existing + item.Quantity
+			if u.maxCartQuantity > 0 {
+				qty = min(qty, u.maxCartQuantity)
+			}
+			variant, err := u.productRepo.GetVariantByID(txCtx, *item.VariantID)
+			if err != nil {
+				continue // Variant was removed since it was added
+			}
+			qty = min(qty, variant.Available)
+			if qty <= existing {
+				continue
+			}
+
+			if _, err := u.orderRepo.UpsertCartItemAtomic(txCtx, userID, userCart.ID, item.ProductID, item.VariantID, qty); err != nil {
+				return fmt.Errorf("failed to merge cart item: %w", err)
+			}
+		}
+
+		slog.Info("Usecase: MergeGuestCart - Merged guest cart", "user_id", userID, "guest_cart_id", guest.ID, "items", len(guestItems))
+		return u.orderRepo.DeleteCart(txCtx, guest.ID)
+	})
+}
+
 // --- Order Logic ---
 
 type CheckoutReq struct {
@@ -224,6 +439,7 @@ type CheckoutReq struct {
 	PaymentTrxID    string       `json:"paymentTrxId,omitempty"`
 	PaymentProvider string       `json:"paymentProvider,omitempty"`
 	PaymentPhone    string       `json:"paymentPhone,omitempty"`
+	Locale          string       `json:"locale,omitempty"` // Notification language: en (default) or bn
 	// Tracking Information
 	FBP       string `json:"fbp"`
 	FBC       string `json:"fbc"`
@@ -233,14 +449,29 @@ type CheckoutReq struct {
 
 // ApplyCouponResp represents the result of applying a coupon
 type ApplyCouponResp struct {
-	Valid          bool    `json:"valid"`
-	Code           string  `json:"code"`
-	DiscountAmount float64 `json:"discountAmount"`
-	NewTotal       float64 `json:"newTotal"`
-	Message        string  `json:"message"`
+	Valid          bool         `json:"valid"`
+	Code           string       `json:"code"`
+	DiscountAmount domain.Money `json:"discountAmount"`
+	NewTotal       domain.Money `json:"newTotal"`
+	Message        string       `json:"message"`
+	Reason         string       `json:"reason,omitempty"` // Validation status when not valid, e.g. not_applicable_to_cart
+}
+
+// couponStatusMessages maps validation statuses to shopper-facing messages.
+var couponStatusMessages = map[string]string{
+	domain.CouponStatusInactive:            "This coupon is no longer active",
+	domain.CouponStatusNotStarted:          "This coupon is not active yet",
+	domain.CouponStatusExpired:             "This coupon has expired",
+	domain.CouponStatusFullyRedeemed:       "This coupon has been fully redeemed",
+	domain.CouponStatusPerUserLimitReached: "You have already used this coupon the maximum number of times",
+	domain.CouponStatusFirstOrderOnly:      "This coupon is only valid on your first order",
+	domain.CouponStatusNotApplicable:       "This coupon does not apply to any item in your cart",
+	domain.CouponStatusMinSpendNotMet:      "Your cart does not meet the minimum spend for this coupon",
 }
 
 func (u *OrderUsecase) ApplyCoupon(ctx context.Context, userID, code string) (*ApplyCouponResp, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
 	// 1. Get Cart
 	cart, err := u.GetMyCart(ctx, userID)
 	if err != nil {
@@ -248,39 +479,35 @@ func (u *OrderUsecase) ApplyCoupon(ctx context.Context, userID, code string) (*A
 	}
 
 	// 2. Calculate Subtotal
-	var subtotal float64
+	var subtotal domain.Money
+	lineTotals := make(map[string]domain.Money, len(cart.Items))
 	for _, item := range cart.Items {
 		// Use the resolved prices from CartItem
 		price := item.Price
 		if item.SalePrice != nil {
 			price = *item.SalePrice
 		}
-		subtotal += price * float64(item.Quantity)
+		subtotal += price.Times(item.Quantity)
+		lineTotals[item.ProductID] += price.Times(item.Quantity)
 	}
 
 	// 3. Validate Coupon
-	res, err := u.couponRepo.ValidateCoupon(ctx, code, subtotal)
+	res, err := u.couponRepo.ValidateCoupon(ctx, code, userID, subtotal, mapKeys(lineTotals))
 	if err != nil {
 		// If validation query returns no rows or error
 		return &ApplyCouponResp{Valid: false, Message: "Invalid coupon code"}, nil
 	}
 
-	if res.ValidationStatus != "valid" {
-		return &ApplyCouponResp{Valid: false, Message: fmt.Sprintf("Coupon is %s", res.ValidationStatus), Code: code}, nil
+	if res.ValidationStatus != domain.CouponStatusValid {
+		msg, ok := couponStatusMessages[res.ValidationStatus]
+		if !ok {
+			msg = fmt.Sprintf("Coupon is %s", res.ValidationStatus)
+		}
+		return &ApplyCouponResp{Valid: false, Message: msg, Reason: res.ValidationStatus, Code: code}, nil
 	}
 
 	// 4. Calculate Discount
-	discount := 0.0
-	if res.Type == "percentage" {
-		discount = subtotal * (res.Value / 100)
-	} else {
-		discount = res.Value
-	}
-
-	// Cap discount at subtotal (no negative total)
-	if discount > subtotal {
-		discount = subtotal
-	}
+	discount := couponDiscount(res, eligibleSubtotal(res, lineTotals))
 
 	return &ApplyCouponResp{
 		Valid:          true,
@@ -291,22 +518,226 @@ func (u *OrderUsecase) ApplyCoupon(ctx context.Context, userID, code string) (*A
 	}, nil
 }
 
+// couponDiscount computes the discount a validated coupon grants on the eligible items subtotal.
+// Shipping is never discounted; percentage discounts honour MaxDiscount and the result
+// is capped at the subtotal (no negative total).
+func couponDiscount(res *domain.CouponValidationResult, subtotal domain.Money) domain.Money {
+	var discount domain.Money
+	if res.Type == "percentage" {
+		discount = subtotal.Percent(res.Value)
+		if res.MaxDiscount != nil && *res.MaxDiscount > 0 && discount > *res.MaxDiscount {
+			discount = *res.MaxDiscount
+		}
+	} else {
+		discount = res.Value
+	}
+	return discount.Min(subtotal)
+}
+
+// eligibleSubtotal sums the line totals (keyed by product ID) the coupon applies to.
+func eligibleSubtotal(res *domain.CouponValidationResult, lineTotals map[string]domain.Money) domain.Money {
+	var total domain.Money
+	for productID, amount := range lineTotals {
+		if res.AppliesTo(productID) {
+			total += amount
+		}
+	}
+	return total
+}
+
+func mapKeys(m map[string]domain.Money) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// errCustomerBlocked is returned to blocklisted customers. It does not reveal the entry.
+var errCustomerBlocked = fmt.Errorf("this account cannot place orders; please contact support")
+
+// checkBlocklist returns the strongest blocklist entry matching a customer: their
+// account, the phone and email given for the order (the account's own when the address
+// has none) and their client IP.
+func (u *OrderUsecase) checkBlocklist(ctx context.Context, userID string, address domain.JSONB, ip string) (*domain.BlocklistEntry, error) {
+	if u.blocklist == nil {
+		return nil, nil
+	}
+	subject := domain.BlocklistSubject{
+		UserID: userID,
+		Email:  strings.ToLower(strings.TrimSpace(addressField(address, "email"))),
+		IP:     clientIP(ip),
+	}
+	if phone, ok := utils.NormalizePhone(addressField(address, "phone")); ok {
+		subject.Phone = phone
+	}
+	if userID != "" && (subject.Phone == "" || subject.Email == "") {
+		if user, err := u.userRepo.GetByID(ctx, userID); err == nil && user != nil {
+			if subject.Email == "" {
+				subject.Email = strings.ToLower(user.Email)
+			}
+			if phone, ok := utils.NormalizePhone(user.Phone); ok && subject.Phone == "" {
+				subject.Phone = phone
+			}
+		}
+	}
+	return u.blocklist.Check(ctx, subject)
+}
+
+// assessOrderRisk scores a new order from its customer's past orders. The query it
+// returns carries the normalized phone and client IP stored on the order. A failed
+// history lookup leaves the order unscored rather than blocking checkout.
+func (u *OrderUsecase) assessOrderRisk(ctx context.Context, userID string, req CheckoutReq, total domain.Money) (*domain.RiskAssessment, domain.RiskQuery) {
+	q := domain.RiskQuery{
+		UserID:   userID,
+		IP:       clientIP(req.IPAddress),
+		District: strings.TrimSpace(addressField(req.Address, "district")),
+		Since:    time.Now().Add(-u.riskPolicy.VelocityWindow),
+	}
+	if phone, ok := utils.NormalizePhone(addressField(req.Address, "phone")); ok {
+		q.Phone = phone
+	}
+
+	history, err := u.orderRepo.GetCustomerRiskHistory(ctx, q)
+	if err != nil {
+		slog.Error("Usecase: Checkout - Failed to load risk history", "user_id", userID, "error", err)
+		return nil, q
+	}
+	risk := domain.ScoreOrderRisk(q, *history, total, u.riskPolicy)
+	return &risk, q
+}
+
+// clientIP strips the port from a RemoteAddr-style address.
+func clientIP(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// checkPreorderLimits locks each capped pre-order product (in a stable order, so
+// concurrent checkouts cannot deadlock) and checks that the units still open before its
+// next release leave room for the wanted quantity.
+func (u *OrderUsecase) checkPreorderLimits(txCtx context.Context, wanted map[string]int, names map[string]string) error {
+	productIDs := make([]string, 0, len(wanted))
+	for id := range wanted {
+		productIDs = append(productIDs, id)
+	}
+	sort.Strings(productIDs)
+
+	for _, id := range productIDs {
+		limit, err := u.preorderRepo.LockProductLimit(txCtx, id)
+		if err != nil {
+			return fmt.Errorf("failed to lock pre-order product %s: %w", id, err)
+		}
+		if limit == nil {
+			continue // Cap removed since the product was loaded
+		}
+		open, err := u.preorderRepo.CountOpenUnits(txCtx, id)
+		if err != nil {
+			return fmt.Errorf("failed to count pre-ordered units: %w", err)
+		}
+		if left := *limit - open; wanted[id] > left {
+			return fmt.Errorf("pre-order of %s is out of stock: %d more units can be pre-ordered before the next release", names[id], max(left, 0))
+		}
+	}
+	return nil
+}
+
+// applyCheckoutCoupon re-validates the coupon inside the checkout transaction.
+// ValidateCoupon locks the coupon row, so concurrent checkouts queue up behind it
+// and IncrementCouponUsage can never push used_count past usage_limit.
+func (u *OrderUsecase) applyCheckoutCoupon(txCtx context.Context, order *domain.Order, code string, subtotal domain.Money) (*domain.CouponValidationResult, error) {
+	lineTotals := make(map[string]domain.Money, len(order.Items))
+	for _, item := range order.Items {
+		lineTotals[item.ProductID] += item.Price.Times(item.Quantity)
+	}
+
+	res, err := u.couponRepo.ValidateCoupon(txCtx, code, order.UserID, subtotal, mapKeys(lineTotals))
+	if err != nil {
+		return nil, fmt.Errorf("invalid coupon code: %s", code)
+	}
+	if res.ValidationStatus != domain.CouponStatusValid {
+		return nil, fmt.Errorf("coupon %s cannot be applied: %s", code, res.ValidationStatus)
+	}
+
+	if err := u.couponRepo.IncrementCouponUsage(txCtx, res.ID); err != nil {
+		return nil, fmt.Errorf("coupon %s cannot be applied: %w", code, err)
+	}
+
+	discount := couponDiscount(res, eligibleSubtotal(res, lineTotals))
+	order.DiscountAmount = discount
+	order.TotalAmount -= discount
+	order.CouponCode = &res.Code
+	return res, nil
+}
+
 func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req CheckoutReq) (*domain.Order, error) {
 	// 1. Get Cart Items
 	cart, err := u.GetMyCart(ctx, userID)
 	if err != nil || len(cart.Items) == 0 {
 		return nil, fmt.Errorf("cart is empty")
 	}
+	return u.placeOrder(ctx, userID, cart, req, nil, nil)
+}
+
+// orderOverrides are the admin adjustments of an order placed from a draft order.
+type orderOverrides struct {
+	prices   map[string]domain.Money // Unit price per variant ID, replacing the current price
+	discount domain.Money            // Manual discount off the subtotal
+	reason   string                  // Initial history entry
+	actorID  string                  // Who placed the order
+	// inTx runs in the order transaction once the order is stored
+	inTx func(txCtx context.Context, order *domain.Order) error
+}
+
+// price returns the overridden unit price of a variant, or price.
+func (o *orderOverrides) price(variantID string, price domain.Money) domain.Money {
+	if o != nil {
+		if p, ok := o.prices[variantID]; ok {
+			return p
+		}
+	}
+	return price
+}
+
+// placeOrder turns a cart into an order for userID: pricing, shipping, payment policy,
+// coupon, stock and initial history. Shared by signed-in and guest checkout, and by
+// draft orders, which pass their admin overrides (nil otherwise). Guest checkout
+// passes its new guest user as newGuest instead of a userID; it is saved in the order
+// transaction, so a failed checkout leaves no user behind.
+func (u *OrderUsecase) placeOrder(ctx context.Context, userID string, cart *domain.Cart, req CheckoutReq, overrides *orderOverrides, newGuest *domain.User) (*domain.Order, error) {
 	processItems := cart.Items
 	cartID := cart.ID
 
+	// 1b. Blocklist: blocked customers cannot order, 'prepay' ones not on COD. Draft
+	// orders are placed by an admin and skip it.
+	if overrides == nil {
+		entry, err := u.checkBlocklist(ctx, userID, req.Address, req.IPAddress)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			slog.Warn("Usecase: Checkout - Blocklisted customer", "user_id", userID, "entry_id", entry.ID, "action", entry.Action)
+			if entry.Action == domain.BlocklistActionBlock {
+				return nil, errCustomerBlocked
+			}
+			if req.Payment == domain.PaymentMethodCOD {
+				return nil, fmt.Errorf("cash on delivery is not available for this account; please pay in advance")
+			}
+		}
+	}
+
 	// 2. Calculate Total & Prepare Order Items & Determine Payment Policy
-	var total float64
+	var total domain.Money
 	var orderItems []domain.OrderItem
 
 	// Track total deposit for pre-orders
 	var isPreorder bool
-	var totalDepositRequired float64
+	var totalDepositRequired domain.Money
+	capped := make(map[string]int)         // Units per pre-order product with a cap
+	cappedNames := make(map[string]string) // Their names, for errors
 
 	for _, item := range processItems {
 		product, err := u.productRepo.GetProductByID(ctx, item.ProductID)
@@ -316,11 +747,15 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 
 		if product.IsPreorder {
 			isPreorder = true
-			totalDepositRequired += product.PreorderDepositAmount * float64(item.Quantity)
+			totalDepositRequired += product.PreorderDepositAmount.Times(item.Quantity)
+			if product.PreorderLimit != nil {
+				capped[product.ID] += item.Quantity
+				cappedNames[product.ID] = product.Name
+			}
 		}
 
 		// Verify Variant & Pricing
-		var price float64
+		var price domain.Money
 		// Default to product price
 		price = product.BasePrice
 		if product.SalePrice != nil {
@@ -363,8 +798,9 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 		if !foundVariant && len(product.Variants) > 0 {
 			return nil, fmt.Errorf("variant %s not found for product %s", targetVariantID, product.Name)
 		}
+		price = overrides.price(targetVariantID, price)
 
-		itemTotal := price * float64(item.Quantity)
+		itemTotal := price.Times(item.Quantity)
 		total += itemTotal
 
 		// Use local variable for safe pointer
@@ -391,21 +827,32 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 		return nil, fmt.Errorf("shipping configuration for %s not found", deliveryLocation)
 	}
 	shippingFee := zone.Cost
+	subtotal := total
 	total += shippingFee
 
+	// Manual discount of a draft order, capped at the subtotal
+	var manualDiscount domain.Money
+	if overrides != nil {
+		manualDiscount = overrides.discount.Min(subtotal)
+		total -= manualDiscount
+	}
+
+	// 3b. Fraud risk from the customer's history
+	risk, riskQuery := u.assessOrderRisk(ctx, userID, req, total)
+
 	// 4. Payment Policy Enforcement
 	paymentDetails := domain.JSONB{}
-	paidAmount := 0.0
+	var claimedAmount domain.Money // Sent by the customer; credited once an admin verifies it
 
-	var requiredDeposit float64
+	var requiredDeposit domain.Money
 	if isPreorder {
 		requiredDeposit = totalDepositRequired
 		if requiredDeposit > 0 {
 			// L9: Require payment info for any non-zero deposit
 			if req.PaymentTrxID == "" || req.PaymentProvider == "" || req.PaymentPhone == "" {
-				return nil, fmt.Errorf("Pre-order requires payment info (TrxID, Provider, Phone) — deposit: %.2f BDT", requiredDeposit)
+				return nil, fmt.Errorf("Pre-order requires payment info (TrxID, Provider, Phone) — deposit: %s BDT", requiredDeposit)
 			}
-			paidAmount = requiredDeposit
+			claimedAmount = requiredDeposit
 
 			paymentDetails = domain.JSONB{
 				"provider":         req.PaymentProvider,
@@ -417,7 +864,7 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 		}
 	} else if req.Payment == domain.PaymentMethodAdvance {
 		// L9: Advance payment — require trx info
-		paidAmount = total
+		claimedAmount = total
 
 		paymentDetails = domain.JSONB{
 			"provider":       req.PaymentProvider,
@@ -425,6 +872,27 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 			"sender_number":  req.PaymentPhone,
 			"shipping_fee":   shippingFee,
 		}
+	} else if req.Payment == domain.PaymentMethodCOD && overrides == nil && risk != nil && u.riskPolicy.IsHighRisk(risk.Score) {
+		// High-risk COD: an advance deposit (the shipping fee unless configured) before we ship
+		requiredDeposit = u.riskPolicy.Deposit
+		if requiredDeposit <= 0 {
+			requiredDeposit = shippingFee
+		}
+		requiredDeposit = requiredDeposit.Min(total)
+		if requiredDeposit > 0 {
+			if req.PaymentTrxID == "" || req.PaymentProvider == "" || req.PaymentPhone == "" {
+				return nil, fmt.Errorf("Cash on delivery for this order requires an advance deposit (TrxID, Provider, Phone) — deposit: %s BDT", requiredDeposit)
+			}
+			claimedAmount = requiredDeposit
+
+			paymentDetails = domain.JSONB{
+				"provider":       req.PaymentProvider,
+				"transaction_id": req.PaymentTrxID,
+				"sender_number":  req.PaymentPhone,
+				"risk_deposit":   requiredDeposit,
+				"shipping_fee":   shippingFee,
+			}
+		}
 	}
 
 	// 5. Build Initial State based on Payment Method & Pre-order
@@ -433,12 +901,19 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 		UserID:          userID,
 		TotalAmount:     total,
 		ShippingFee:     shippingFee,
+		DiscountAmount:  manualDiscount,
 		ShippingAddress: req.Address,
 		PaymentMethod:   req.Payment,
-		PaidAmount:      paidAmount,
 		IsPreorder:      isPreorder,
 		PaymentDetails:  paymentDetails,
+		Locale:          domain.NormalizeLocale(req.Locale),
 		Items:           orderItems,
+		CustomerPhone:   optionalString(riskQuery.Phone),
+		IPAddress:       optionalString(riskQuery.IP),
+	}
+	if risk != nil {
+		order.RiskScore = &risk.Score
+		order.RiskReasons = risk.Reasons
 	}
 
 	// L9: Centralized Initial Status (Single Source of Truth from constants.go)
@@ -448,33 +923,102 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 	order.Status = initialOrderStatus
 	order.PaymentStatus = initialPaymentStatus
 
-	// 6. Transaction: Create Order, Update Stock, Increment Coupon, Clear Cart
-	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+	couponCode := strings.ToUpper(strings.TrimSpace(req.CouponCode))
+
+	// 6. Transaction: Apply Coupon, Create Order, Update Stock, Record Redemption, Clear Cart
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		if newGuest != nil {
+			if err := u.userRepo.Create(txCtx, newGuest); err != nil {
+				slog.Error("Usecase: GuestCheckout - Failed to create guest user", "error", err)
+				return fmt.Errorf("failed to record guest customer")
+			}
+			order.UserID = newGuest.ID
+		}
+
+		// 6a. Coupon: lock, validate and count usage before the order total is persisted
+		var coupon *domain.CouponValidationResult
+		if couponCode != "" {
+			var err error
+			if coupon, err = u.applyCheckoutCoupon(txCtx, order, couponCode, subtotal); err != nil {
+				return err
+			}
+			// Full advance payments cover the discounted total
+			if !isPreorder && req.Payment == domain.PaymentMethodAdvance {
+				claimedAmount = order.TotalAmount
+			}
+		}
+
+		if err := u.checkPreorderLimits(txCtx, capped, cappedNames); err != nil {
+			return err
+		}
+
 		if err := u.orderRepo.CreateOrder(txCtx, order); err != nil {
 			return err
 		}
 
-		// 6a. Lock and check stock for each item (L9 Pessimistic Locking)
-		for _, item := range order.Items {
-			if item.VariantID == nil {
-				return fmt.Errorf("item %s has no variant ID", item.ProductID)
-			}
-			// Lock row for update to prevent race conditions
-			variant, err := u.productRepo.GetVariantByIDForUpdate(txCtx, *item.VariantID)
-			if err != nil {
-				return fmt.Errorf("failed to lock stock for variant %s: %v", *item.VariantID, err)
+		// The deposit / advance the customer says they sent waits in the ledger for verification
+		if order.PaymentStatus == domain.PaymentStatusPendingVerif && claimedAmount > 0 {
+			createdBy := order.UserID
+			if overrides != nil {
+				createdBy = overrides.actorID
 			}
-			if variant.Stock < item.Quantity {
-				return fmt.Errorf("insufficient stock for item %s (requested: %d, available: %d)", variant.Name, item.Quantity, variant.Stock)
+			if err := u.ledgerRepo.Record(txCtx, &domain.Payment{
+				OrderID:       order.ID,
+				Direction:     domain.PaymentDirectionIn,
+				Amount:        claimedAmount,
+				Method:        order.PaymentMethod,
+				Provider:      optionalString(req.PaymentProvider),
+				TransactionID: optionalString(req.PaymentTrxID),
+				SenderNumber:  optionalString(req.PaymentPhone),
+				Status:        domain.PaymentEntryPending,
+				CreatedBy:     &createdBy,
+			}); err != nil {
+				return fmt.Errorf("failed to record payment: %w", err)
 			}
+		}
 
-			// Update Stock
-			if err := u.productRepo.UpdateStock(txCtx, *item.VariantID, -item.Quantity, "order_placed", order.ID); err != nil {
+		// 6b. Stock. Orders paid through a gateway only hold it until the payment is
+		// confirmed (expired holds are swept back into availability); others deduct now.
+		if domain.IsGatewayPaymentMethod(order.PaymentMethod) && order.PaymentStatus == domain.PaymentStatusPending {
+			if err := u.reservationRepo.Reserve(txCtx, order.ID, order.Items, u.reservationTTL); err != nil {
 				return err
 			}
+		} else {
+			// Lock and check stock for each item (L9 Pessimistic Locking)
+			for _, item := range order.Items {
+				if item.VariantID == nil {
+					return fmt.Errorf("item %s has no variant ID", item.ProductID)
+				}
+				// Lock row for update to prevent race conditions
+				variant, err := u.productRepo.GetVariantByIDForUpdate(txCtx, *item.VariantID)
+				if err != nil {
+					return fmt.Errorf("failed to lock stock for variant %s: %v", *item.VariantID, err)
+				}
+				// Stock held for other customers' pending payments is not for sale
+				if variant.Available < item.Quantity {
+					return fmt.Errorf("insufficient stock for item %s (requested: %d, available: %d)", variant.Name, item.Quantity, variant.Available)
+				}
+
+				// Update Stock
+				if err := u.productRepo.UpdateStock(txCtx, *item.VariantID, -item.Quantity, "order_placed", order.ID); err != nil {
+					return err
+				}
+			}
 		}
 
-		// (Coupon Increment Logic Removed)
+		// 6c. Per-order redemption record (usage already counted in 6a)
+		if coupon != nil {
+			redemption := &domain.CouponRedemption{
+				CouponID:       coupon.ID,
+				OrderID:        order.ID,
+				UserID:         order.UserID,
+				Code:           coupon.Code,
+				DiscountAmount: order.DiscountAmount,
+			}
+			if err := u.couponRepo.CreateRedemption(txCtx, redemption); err != nil {
+				return fmt.Errorf("failed to record coupon redemption: %w", err)
+			}
+		}
 
 		// Clear Cart
 		if cartID != "" {
@@ -483,7 +1027,7 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 			}
 		}
 
-		// 6b. Create Initial History Entry
+		// 6d. Create Initial History Entry
 		histReason := "Order placed successfully"
 		history := &domain.OrderHistory{
 			OrderID:        order.ID,
@@ -492,17 +1036,35 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 			Reason:         &histReason,
 			CreatedBy:      &order.UserID,
 		}
+		if overrides != nil {
+			history.Reason = &overrides.reason
+			history.CreatedBy = &overrides.actorID
+		}
 		if err := u.orderRepo.CreateOrderHistory(txCtx, history); err != nil {
 			return fmt.Errorf("failed to record initial history: %w", err)
 		}
+		if overrides != nil && overrides.inTx != nil {
+			if err := overrides.inTx(txCtx, order); err != nil {
+				return err
+			}
+		}
 
-		return nil
+		// 6e. Order confirmation email (outbox)
+		return u.notifier.NotifyOrder(txCtx, order.ID, domain.EmailOrderPlaced, 0)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	// COD orders wait for the customer to confirm their phone with an SMS code
+	if u.phoneVerifier.RequiresConfirmation(order) {
+		if err := u.phoneVerifier.SendOrderCode(ctx, order); err != nil {
+			slog.Error("Usecase: Checkout - Failed to send confirmation code", "order_id", order.ID, "error", err)
+			// Non-critical error: the customer can request a new code, or an admin confirms by phone
+		}
+	}
+
 	// L9 Analytics: Send Purchase event to Facebook CAPI (async, non-blocking)
 	if u.capiClient != nil {
 		var contentItems []facebook.ContentItem
@@ -510,7 +1072,7 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 			contentItems = append(contentItems, facebook.ContentItem{
 				ID:       item.ProductID,
 				Quantity: item.Quantity,
-				Price:    item.Price,
+				Price:    item.Price.Float64(),
 			})
 		}
 
@@ -547,22 +1109,251 @@ func (u *OrderUsecase) Checkout(ctx context.Context, userID string, req Checkout
 		}
 
 		u.capiClient.SendPurchaseEvent(
-			order.ID,
-			order.TotalAmount,
+			order.OrderNumber,
+			order.TotalAmount.Float64(),
 			"BDT",
 			contentItems,
 			userData,
-			order.ID, // Use order ID as event_id for deduplication
+			order.ID, // Use order ID as event_id for deduplication (matches the browser pixel)
 		)
 	}
 
 	return order, nil
 }
 
+// --- Guest Checkout ---
+
+// errSignInRequired rejects a guest checkout with the email of a registered account.
+var errSignInRequired = fmt.Errorf("an account already exists for this email; please sign in to check out")
+
+// GuestCheckoutReq is a checkout without a login: contact details identify the customer.
+type GuestCheckoutReq struct {
+	CheckoutReq
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	FirstName string `json:"firstName,omitempty"` // Defaults to the shipping address name
+	LastName  string `json:"lastName,omitempty"`
+}
+
+// GuestCheckoutResp is the placed order plus the token for looking it up without a login.
+type GuestCheckoutResp struct {
+	*domain.Order
+	TrackingToken string `json:"trackingToken"`
+}
+
+// GuestCheckout places an order from a guest cart. The customer is recorded as a
+// lightweight guest user of its own, created with the order. An email that belongs to
+// a registered account must sign in instead, so guests cannot add orders to someone
+// else's account.
+// Gateway payments need a signed-in session to start, so guests pay COD or advance.
+func (u *OrderUsecase) GuestCheckout(ctx context.Context, cartID string, req GuestCheckoutReq) (*GuestCheckoutResp, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return nil, fmt.Errorf("a valid email is required")
+	}
+	phone, ok := utils.NormalizePhone(req.Phone)
+	if !ok {
+		return nil, fmt.Errorf("a valid phone number is required")
+	}
+	if domain.IsGatewayPaymentMethod(req.Payment) {
+		return nil, fmt.Errorf("online payment requires sign-in; choose cash on delivery or advance payment")
+	}
+
+	if cartID == "" {
+		return nil, fmt.Errorf("cart is empty")
+	}
+	cart, err := u.orderRepo.GetCartByID(ctx, cartID)
+	if err != nil || cart == nil || cart.UserID != nil {
+		return nil, fmt.Errorf("cart is empty")
+	}
+	if cart.Items, err = u.orderRepo.GetCartItemsByCartID(ctx, cart.ID); err != nil || len(cart.Items) == 0 {
+		return nil, fmt.Errorf("cart is empty")
+	}
+
+	firstName := req.FirstName
+	if firstName == "" {
+		firstName = addressField(req.Address, "firstName")
+	}
+	lastName := req.LastName
+	if lastName == "" {
+		lastName = addressField(req.Address, "lastName")
+	}
+
+	guest, err := u.guestUser(ctx, email, phone, firstName, lastName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contact details travel with the order (admin views, notifications, CAPI)
+	if req.Address == nil {
+		req.Address = domain.JSONB{}
+	}
+	if addressField(req.Address, "email") == "" {
+		req.Address["email"] = email
+	}
+	if addressField(req.Address, "phone") == "" {
+		req.Address["phone"] = phone
+	}
+
+	order, err := u.placeOrder(ctx, "", cart, req.CheckoutReq, nil, guest)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateSignedID(utils.SignedIDOrderTracking, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue tracking token: %w", err)
+	}
+	slog.Info("Usecase: GuestCheckout - Order placed", "order_id", order.ID, "user_id", guest.ID)
+
+	return &GuestCheckoutResp{Order: order, TrackingToken: token}, nil
+}
+
+// guestUser returns a new, unsaved guest user for a guest order (placeOrder saves it
+// with the order). Each checkout gets its own: a guest proves nothing about the email,
+// so guest orders are never grouped under it or handed to whoever signs in with it.
+func (u *OrderUsecase) guestUser(ctx context.Context, email, phone, firstName, lastName string) (*domain.User, error) {
+	account, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		return nil, errSignInRequired
+	}
+	return &domain.User{
+		Email:     email,
+		Role:      domain.RoleGuest,
+		FirstName: firstName,
+		LastName:  lastName,
+		Phone:     phone,
+	}, nil
+}
+
+// TrackOrder returns the order a guest tracking token was issued for.
+func (u *OrderUsecase) TrackOrder(ctx context.Context, token string) (*domain.Order, error) {
+	orderID, err := utils.ValidateSignedID(utils.SignedIDOrderTracking, token)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	return order, nil
+}
+
 func (u *OrderUsecase) GetMyOrders(ctx context.Context, userID string) ([]domain.Order, error) {
 	return u.orderRepo.GetByUserID(ctx, userID)
 }
 
+// GetMyOrder returns one of the user's orders. Orders of other users are reported as not found.
+func (u *OrderUsecase) GetMyOrder(ctx context.Context, userID, orderID string) (*domain.Order, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+	return order, nil
+}
+
+// GetMyOrderTimeline returns the status changes of one of the user's orders, oldest first.
+// Note-only history entries (same status) are left out.
+func (u *OrderUsecase) GetMyOrderTimeline(ctx context.Context, userID, orderID string) ([]domain.OrderTimelineEntry, error) {
+	if _, err := u.GetMyOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	history, err := u.orderRepo.GetOrderHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]domain.OrderTimelineEntry, 0, len(history))
+	for _, h := range history {
+		if h.PreviousStatus != nil && *h.PreviousStatus == h.NewStatus {
+			continue
+		}
+		timeline = append(timeline, domain.OrderTimelineEntry{
+			Status:         h.NewStatus,
+			PreviousStatus: h.PreviousStatus,
+			CreatedAt:      h.CreatedAt,
+		})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].CreatedAt.Before(timeline[j].CreatedAt)
+	})
+	return timeline, nil
+}
+
+// CancelMyOrder lets a customer cancel their own order before it is confirmed.
+// It goes through UpdateOrderStatus, so held/deducted stock is restored and the
+// change is recorded in the order history like any other transition.
+func (u *OrderUsecase) CancelMyOrder(ctx context.Context, userID, orderID, reason string) (*domain.Order, error) {
+	order, err := u.GetMyOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsCustomerCancellable(order.Status) {
+		return nil, fmt.Errorf("order can no longer be cancelled (status: %s)", order.Status)
+	}
+	// Confirmed payments need a refund, which is handled by support
+	if order.PaymentStatus == domain.PaymentStatusPaid || order.PaymentStatus == domain.PaymentStatusPartialPaid {
+		return nil, fmt.Errorf("order is already paid and cannot be cancelled online; please contact support")
+	}
+
+	note := "Customer: Cancelled by customer"
+	if reason = strings.TrimSpace(reason); reason != "" {
+		note += " — " + reason
+	}
+	if err := u.UpdateOrderStatus(ctx, orderID, domain.OrderStatusCancelled, note, userID); err != nil {
+		return nil, err
+	}
+	return u.orderRepo.GetByID(ctx, orderID)
+}
+
+// --- COD Phone Confirmation ---
+
+// ConfirmMyOrderPhone confirms one of the user's COD orders with the SMS code sent at checkout.
+func (u *OrderUsecase) ConfirmMyOrderPhone(ctx context.Context, userID, orderID, code string) (*domain.Order, error) {
+	order, err := u.GetMyOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.phoneVerifier.ConfirmOrder(ctx, order, code, userID); err != nil {
+		return nil, err
+	}
+	return u.orderRepo.GetByID(ctx, orderID)
+}
+
+// ResendMyOrderCode sends a new confirmation code for one of the user's COD orders.
+func (u *OrderUsecase) ResendMyOrderCode(ctx context.Context, userID, orderID string) error {
+	order, err := u.GetMyOrder(ctx, userID, orderID)
+	if err != nil {
+		return err
+	}
+	return u.phoneVerifier.ResendOrderCode(ctx, order)
+}
+
+// ConfirmTrackedOrderPhone confirms a COD order identified by its tracking token (guest checkout).
+// The order's customer is recorded as the confirming actor.
+func (u *OrderUsecase) ConfirmTrackedOrderPhone(ctx context.Context, token, code string) (*domain.Order, error) {
+	order, err := u.TrackOrder(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.phoneVerifier.ConfirmOrder(ctx, order, code, order.UserID); err != nil {
+		return nil, err
+	}
+	return u.orderRepo.GetByID(ctx, order.ID)
+}
+
+// ResendTrackedOrderCode sends a new confirmation code for a COD order identified by its tracking token.
+func (u *OrderUsecase) ResendTrackedOrderCode(ctx context.Context, token string) error {
+	order, err := u.TrackOrder(ctx, token)
+	if err != nil {
+		return err
+	}
+	return u.phoneVerifier.ResendOrderCode(ctx, order)
+}
+
 // --- Admin Usecase ---
 
 func (u *OrderUsecase) GetAllOrders(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, int64, error) {
@@ -574,6 +1365,76 @@ func (u *OrderUsecase) GetOrder(ctx context.Context, id string) (*domain.Order,
 }
 
 func (u *OrderUsecase) UpdateOrderStatus(ctx context.Context, orderID, newStatus, note, actorID string) error {
+	return u.updateOrderStatus(ctx, orderID, newStatus, note, actorID, nil, nil)
+}
+
+// UpdateOrderStatusForShipment is UpdateOrderStatus for a change driven by a shipment;
+// the history entry points at the shipment.
+func (u *OrderUsecase) UpdateOrderStatusForShipment(ctx context.Context, orderID, shipmentID, newStatus, note, actorID string) error {
+	return u.updateOrderStatus(ctx, orderID, newStatus, note, actorID, &shipmentID, nil)
+}
+
+// SettleCODOrder moves a delivered COD order to paid for cash a courier remitted, in
+// one payout per parcel. Together they must match the order's outstanding amount; each
+// becomes a ledger entry carrying the courier and its reference.
+func (u *OrderUsecase) SettleCODOrder(ctx context.Context, orderID string, remittances []domain.CODRemittance, note, actorID string) error {
+	if len(remittances) == 0 {
+		return fmt.Errorf("no remittance to settle")
+	}
+	return u.updateOrderStatus(ctx, orderID, domain.OrderStatusPaid, note, actorID, nil, remittances)
+}
+
+// RecordCODRemittance books a courier's payout for one parcel of a split order whose
+// other parcels are not paid yet. The order keeps its status; SettleCODOrder moves it
+// to paid with the last payouts.
+func (u *OrderUsecase) RecordCODRemittance(ctx context.Context, orderID string, remittance domain.CODRemittance, note, actorID string) error {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.PaymentMethod != domain.PaymentMethodCOD {
+		return fmt.Errorf("order is not cash on delivery")
+	}
+	if outstanding := order.TotalAmount - order.PaidAmount; remittance.Amount > outstanding {
+		return fmt.Errorf("remitted %s exceeds the outstanding %s", remittance.Amount, outstanding)
+	}
+	if remittance.Amount <= 0 {
+		return nil // A parcel without COD
+	}
+
+	return u.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := u.ledgerRepo.Record(txCtx, codRemittanceEntry(order.ID, remittance, actorID)); err != nil {
+			return fmt.Errorf("failed to record COD collection: %w", err)
+		}
+		return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &order.Status,
+			NewStatus:      order.Status,
+			Reason:         &note,
+			CreatedBy:      &actorID,
+		})
+	})
+}
+
+// codRemittanceEntry is the verified ledger entry of a courier's COD payout.
+func codRemittanceEntry(orderID string, remittance domain.CODRemittance, actorID string) *domain.Payment {
+	now := time.Now()
+	return &domain.Payment{
+		OrderID:       orderID,
+		Direction:     domain.PaymentDirectionIn,
+		Amount:        remittance.Amount,
+		Method:        domain.PaymentMethodCOD,
+		Provider:      optionalString(remittance.Courier),
+		TransactionID: optionalString(remittance.Reference),
+		Status:        domain.PaymentEntryVerified,
+		Note:          optionalString(fmt.Sprintf("COD settlement %s", remittance.Source)),
+		VerifiedBy:    &actorID,
+		VerifiedAt:    &now,
+		CreatedBy:     &actorID,
+	}
+}
+
+func (u *OrderUsecase) updateOrderStatus(ctx context.Context, orderID, newStatus, note, actorID string, shipmentID *string, remittances []domain.CODRemittance) error {
 	// 1. Get existing order
 	order, err := u.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
@@ -598,7 +1459,7 @@ func (u *OrderUsecase) UpdateOrderStatus(ctx context.Context, orderID, newStatus
 
 	return u.txManager.Do(ctx, func(txCtx context.Context) error {
 		// 3. Handle Side Effects (Stock, Payment, Analytics triggers)
-		if err := u.handleOrderStateSideEffects(txCtx, order, newStatus, actorID); err != nil {
+		if err := u.handleOrderStateSideEffects(txCtx, order, newStatus, actorID, remittances); err != nil {
 			return err
 		}
 
@@ -625,6 +1486,7 @@ func (u *OrderUsecase) UpdateOrderStatus(ctx context.Context, orderID, newStatus
 			NewStatus:      newStatus,
 			Reason:         reasonPtr,
 			CreatedBy:      &actorID,
+			ShipmentID:     shipmentID,
 		}
 		if err := u.orderRepo.CreateOrderHistory(txCtx, &history); err != nil {
 			return fmt.Errorf("failed to record history: %w", err)
@@ -646,7 +1508,8 @@ func (u *OrderUsecase) validateOrderTransition(order *domain.Order, newStatus st
 
 // L9: Declarative Side-Effect Engine
 // Reads from GetSideEffects() in constants.go — no inline business rules.
-func (u *OrderUsecase) handleOrderStateSideEffects(ctx context.Context, order *domain.Order, newStatus, actorID string) error {
+// remittances, when set, are the courier payouts the paid transition records.
+func (u *OrderUsecase) handleOrderStateSideEffects(ctx context.Context, order *domain.Order, newStatus, actorID string, remittances []domain.CODRemittance) error {
 	effects := domain.GetSideEffects(order.Status, newStatus)
 	if len(effects) == 0 {
 		return nil
@@ -669,13 +1532,59 @@ func (u *OrderUsecase) handleOrderStateSideEffects(ctx context.Context, order *d
 				return err
 			}
 
+		case domain.SideEffectCommitStock:
+			// Confirmed order: stock still only reserved for it becomes a real deduction
+			if n, err := u.reservationRepo.Commit(ctx, order.ID, "reservation_committed"); err != nil {
+				return fmt.Errorf("failed to commit reserved stock: %w", err)
+			} else if n > 0 {
+				slog.Info("L9 Side-Effect: Committed reserved stock", "order_id", order.ID, "holds", n)
+			}
+
 		case domain.SideEffectSyncPaymentPaid:
 			slog.Info("L9 Side-Effect: Syncing payment → paid", "order_id", order.ID)
 			if err := u.orderRepo.UpdatePaymentStatus(ctx, order.ID, domain.PaymentStatusPaid); err != nil {
 				return fmt.Errorf("failed to sync payment status to paid: %w", err)
 			}
-			if err := u.orderRepo.UpdatePaidAmount(ctx, order.ID, order.TotalAmount); err != nil {
-				return fmt.Errorf("failed to sync paid amount: %w", err)
+			// The cash the courier collected is the rest of the order
+			collected := order.TotalAmount - order.PaidAmount
+			if remittances != nil {
+				var remitted domain.Money
+				for _, r := range remittances {
+					remitted += r.Amount
+				}
+				if remitted != collected {
+					return fmt.Errorf("remitted %s does not match the outstanding %s", remitted, collected)
+				}
+				for _, r := range remittances {
+					if r.Amount == 0 {
+						continue // A parcel without COD
+					}
+					if err := u.ledgerRepo.Record(ctx, codRemittanceEntry(order.ID, r, actorID)); err != nil {
+						return fmt.Errorf("failed to record COD collection: %w", err)
+					}
+				}
+			} else if collected > 0 {
+				now := time.Now()
+				if err := u.ledgerRepo.Record(ctx, &domain.Payment{
+					OrderID:    order.ID,
+					Direction:  domain.PaymentDirectionIn,
+					Amount:     collected,
+					Method:     domain.PaymentMethodCOD,
+					Status:     domain.PaymentEntryVerified,
+					VerifiedBy: &actorID,
+					VerifiedAt: &now,
+					CreatedBy:  &actorID,
+				}); err != nil {
+					return fmt.Errorf("failed to record COD collection: %w", err)
+				}
+			}
+			// A released pre-order's balance was collected on delivery
+			if balanceReq, err := u.preorderRepo.GetPendingBalanceRequest(ctx, order.ID); err == nil {
+				if err := u.preorderRepo.RecordBalancePayment(ctx, balanceReq.ID, collected, domain.PaymentMethodCOD, nil, true); err != nil {
+					return fmt.Errorf("failed to settle pre-order balance: %w", err)
+				}
+			} else if !errors.Is(err, domain.ErrNotFound) {
+				return err
 			}
 
 		case domain.SideEffectSyncPaymentRefund:
@@ -683,20 +1592,42 @@ func (u *OrderUsecase) handleOrderStateSideEffects(ctx context.Context, order *d
 			if err := u.orderRepo.UpdatePaymentStatus(ctx, order.ID, domain.PaymentStatusRefunded); err != nil {
 				return fmt.Errorf("failed to sync payment status to refunded: %w", err)
 			}
+
+		case domain.SideEffectNotifyCustomer:
+			if template, ok := domain.OrderStatusEmails[newStatus]; ok {
+				if err := u.notifier.NotifyOrder(ctx, order.ID, template, order.RefundedAmount); err != nil {
+					return fmt.Errorf("failed to queue customer email: %w", err)
+				}
+			}
 		}
 	}
 	return nil
 }
 
-// restoreOrderStock adds stock back to inventory for all items in an order.
+// restoreOrderStock puts back the stock an order still has deducted: per variant, what
+// was deducted for it minus what returns, refunds and edits already restocked. Exchange
+// movements count too, so a replacement that went out is restored instead of the
+// original it replaced. Stock that was only reserved is released instead.
 func (u *OrderUsecase) restoreOrderStock(ctx context.Context, order *domain.Order, reason string) error {
-	for _, item := range order.Items {
-		targetID := item.ProductID
-		if item.VariantID != nil {
-			targetID = *item.VariantID
+	if _, err := u.reservationRepo.Release(ctx, order.ID); err != nil {
+		return fmt.Errorf("failed to release reserved stock: %w", err)
+	}
+	changes, err := u.productRepo.GetOrderNetStockChanges(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read stock movements: %w", err)
+	}
+
+	// Stable order, so concurrent restores lock variants alike
+	variantIDs := make([]string, 0, len(changes))
+	for variantID, net := range changes {
+		if net < 0 {
+			variantIDs = append(variantIDs, variantID)
 		}
-		if err := u.productRepo.UpdateStock(ctx, targetID, item.Quantity, reason, order.ID); err != nil {
-			return fmt.Errorf("failed to restore stock for item %s: %w", targetID, err)
+	}
+	sort.Strings(variantIDs)
+	for _, variantID := range variantIDs {
+		if err := u.productRepo.UpdateStock(ctx, variantID, -changes[variantID], reason, order.ID); err != nil {
+			return fmt.Errorf("failed to restore stock for variant %s: %w", variantID, err)
 		}
 	}
 	return nil
@@ -715,58 +1646,124 @@ func (u *OrderUsecase) deductOrderStock(ctx context.Context, order *domain.Order
 	return nil
 }
 
-// VerifyOrderPayment verifies an advance/pre-order payment.
+// VerifyOrderPayment verifies the order's pending ledger entries — the ones listed in
+// paymentIDs, or all of them when it is empty — and derives the payment status from
+// the verified total. The first verification also confirms the order.
 // L9: Uses constants, validates both order and payment FSM transitions.
-func (u *OrderUsecase) VerifyOrderPayment(ctx context.Context, orderID, adminID string) error {
+func (u *OrderUsecase) VerifyOrderPayment(ctx context.Context, orderID string, paymentIDs []string, adminID string) error {
 	order, err := u.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return err
 	}
 
-	// L9: Reject COD orders
-	if order.PaymentMethod == domain.PaymentMethodCOD {
+	// L9: Reject COD orders, unless a high-risk one sent its advance deposit
+	if order.PaymentMethod == domain.PaymentMethodCOD && order.PaymentStatus != domain.PaymentStatusPendingVerif {
 		return fmt.Errorf("order is COD — no advance payment to verify")
 	}
 
-	// L9: Validate order status transition
-	if !domain.IsValidTransition(order.Status, domain.OrderStatusProcessing) {
+	// L9: Validate order status transition (only while awaiting confirmation)
+	confirm := order.Status == domain.OrderStatusPendingVerification || order.Status == domain.OrderStatusPending
+	if confirm && !domain.IsValidTransition(order.Status, domain.OrderStatusProcessing) {
 		return fmt.Errorf("cannot verify payment: order status is '%s', expected '%s'",
 			order.Status, domain.OrderStatusPendingVerification)
 	}
 
-	// L9: Validate payment status transition
-	newPaymentStatus := domain.PaymentStatusPartialPaid
-	if !order.IsPreorder {
-		// Full advance payment → mark as paid
-		newPaymentStatus = domain.PaymentStatusPaid
-	}
-	if !domain.IsValidPaymentTransition(order.PaymentStatus, newPaymentStatus) {
-		return fmt.Errorf("cannot verify payment: payment status transition '%s' → '%s' is forbidden",
-			order.PaymentStatus, newPaymentStatus)
-	}
-
 	oldStatus := order.Status
 
 	return u.txManager.Do(ctx, func(txCtx context.Context) error {
-		if err := u.orderRepo.UpdateStatus(txCtx, orderID, domain.OrderStatusProcessing); err != nil {
-			return err
+		pending, err := u.ledgerRepo.ListPendingForUpdate(txCtx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to load pending payments: %w", err)
+		}
+		toVerify := pending
+		if len(paymentIDs) > 0 {
+			byID := make(map[string]domain.Payment, len(pending))
+			for _, p := range pending {
+				byID[p.ID] = p
+			}
+			toVerify = make([]domain.Payment, 0, len(paymentIDs))
+			for _, id := range paymentIDs {
+				p, ok := byID[id]
+				if !ok {
+					return fmt.Errorf("payment %s is not awaiting verification", id)
+				}
+				toVerify = append(toVerify, p)
+			}
+		}
+		if len(toVerify) == 0 {
+			return fmt.Errorf("no pending payment to verify")
 		}
-		if err := u.orderRepo.UpdatePaymentStatus(txCtx, orderID, newPaymentStatus); err != nil {
+
+		var verified domain.Money
+		for _, p := range toVerify {
+			ok, err := u.ledgerRepo.Verify(txCtx, p.ID, orderID, adminID)
+			if err != nil {
+				return fmt.Errorf("failed to verify payment %s: %w", p.ID, err)
+			}
+			if !ok {
+				return fmt.Errorf("payment %s is not awaiting verification", p.ID)
+			}
+			verified += p.Amount
+		}
+
+		// Paid amount is re-derived from the ledger by Verify.
+		current, err := u.orderRepo.GetByID(txCtx, orderID)
+		if err != nil {
 			return err
 		}
+		newPaymentStatus := domain.PaymentStatusPartialPaid
+		if current.PaidAmount >= current.TotalAmount {
+			newPaymentStatus = domain.PaymentStatusPaid
+		}
+		if newPaymentStatus != order.PaymentStatus {
+			// L9: Validate payment status transition
+			if !domain.IsValidPaymentTransition(order.PaymentStatus, newPaymentStatus) {
+				return fmt.Errorf("cannot verify payment: payment status transition '%s' → '%s' is forbidden",
+					order.PaymentStatus, newPaymentStatus)
+			}
+			if err := u.orderRepo.UpdatePaymentStatus(txCtx, orderID, newPaymentStatus); err != nil {
+				return err
+			}
+		}
+
+		newStatus := oldStatus
+		if confirm {
+			if _, err := u.reservationRepo.Commit(txCtx, orderID, "reservation_committed"); err != nil {
+				return fmt.Errorf("failed to commit reserved stock: %w", err)
+			}
+			if err := u.orderRepo.UpdateStatus(txCtx, orderID, domain.OrderStatusProcessing); err != nil {
+				return err
+			}
+			newStatus = domain.OrderStatusProcessing
+		}
 
-		reason := fmt.Sprintf("Payment verified by admin. Payment: %s → %s", order.PaymentStatus, newPaymentStatus)
+		reason := fmt.Sprintf("Payment verified by admin: %s across %d ledger entries. Payment: %s → %s",
+			verified, len(toVerify), order.PaymentStatus, newPaymentStatus)
 		history := &domain.OrderHistory{
 			OrderID:        orderID,
 			PreviousStatus: &oldStatus,
-			NewStatus:      domain.OrderStatusProcessing,
+			NewStatus:      newStatus,
 			Reason:         &reason,
 			CreatedBy:      &adminID,
 		}
-		return u.orderRepo.CreateOrderHistory(txCtx, history)
+		if err := u.orderRepo.CreateOrderHistory(txCtx, history); err != nil {
+			return err
+		}
+		if !confirm {
+			return nil
+		}
+		return u.notifier.NotifyOrder(txCtx, orderID, domain.EmailOrderConfirmed, 0)
 	})
 }
 
+// GetPayments returns the order's payment ledger, oldest first.
+func (u *OrderUsecase) GetPayments(ctx context.Context, orderID string) ([]domain.Payment, error) {
+	if _, err := u.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return u.ledgerRepo.ListByOrder(ctx, orderID)
+}
+
 // UpdatePaymentStatus updates the payment status of an order manually.
 // L9: Enforces ValidPaymentTransitions FSM — rejects invalid changes.
 func (u *OrderUsecase) UpdatePaymentStatus(ctx context.Context, orderID, newStatus, actorID string) error {
@@ -803,50 +1800,119 @@ func (u *OrderUsecase) UpdatePaymentStatus(ctx context.Context, orderID, newStat
 	})
 }
 
+// RefundItemReq is a quantity of one order item to refund.
+type RefundItemReq struct {
+	OrderItemID string `json:"orderItemId"`
+	Quantity    int    `json:"quantity"`
+}
+
+// RefundReq describes a refund. With Items (and/or IncludeShipping) the amount defaults
+// to the price paid for those quantities (plus the shipping fee not yet refunded), and
+// Restock puts back exactly those quantities. Without items, Amount is required and
+// Restock restores the whole order.
+type RefundReq struct {
+	Amount          domain.Money    `json:"amount"`
+	Reason          string          `json:"reason"`
+	Restock         bool            `json:"restock"`
+	Items           []RefundItemReq `json:"items,omitempty"`
+	IncludeShipping bool            `json:"includeShipping,omitempty"`
+	Method          string          `json:"method"` // domain.RefundMethod*, defaults to cash
+	Reference       string          `json:"reference,omitempty"`
+}
+
 // ProcessRefund handles the refund logic.
 // L9: Validates FSM before auto-transitioning, uses shared stock helpers.
-func (u *OrderUsecase) ProcessRefund(ctx context.Context, orderID string, amount float64, reason string, restock bool, adminID string) error {
-	// 1. Get Order
+func (u *OrderUsecase) ProcessRefund(ctx context.Context, orderID string, req RefundReq, adminID string) (*domain.Refund, error) {
+	// 1. Get Order and its refund ledger
 	order, err := u.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	ledger, err := u.orderRepo.GetRefunds(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. Validate Refund Amount
-	if amount <= 0 {
-		return fmt.Errorf("refund amount must be positive")
+	if req.Method == "" {
+		req.Method = domain.RefundMethodCash
 	}
-	remainingRefundable := order.PaidAmount - order.RefundedAmount
-	if amount > remainingRefundable {
-		return fmt.Errorf("cannot refund %.2f (max refundable: %.2f)", amount, remainingRefundable)
+	if !domain.IsValidRefundMethod(req.Method) {
+		return nil, fmt.Errorf("invalid refund method: %s", req.Method)
 	}
 
-	// 3. Determine if this is a full refund (triggers status change)
-	isFullRefund := restock && amount >= remainingRefundable
-
-	// L9: If full refund, validate FSM allows the transition BEFORE entering the transaction
-	if isFullRefund {
-		if !domain.IsValidTransition(order.Status, domain.OrderStatusRefunded) {
-			return fmt.Errorf("cannot auto-refund: transition from '%s' to 'refunded' is forbidden by FSM", order.Status)
+	// 2. Resolve refunded items and shipping
+	refund := &domain.Refund{
+		OrderID:   orderID,
+		Method:    req.Method,
+		Reference: optionalString(strings.TrimSpace(req.Reference)),
+		Reason:    optionalString(req.Reason),
+		Restock:   req.Restock,
+		CreatedBy: &adminID,
+	}
+	refund.Items, err = refundItems(order, ledger, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	var itemsValue domain.Money
+	for _, item := range refund.Items {
+		itemsValue += item.Amount
+	}
+	if req.IncludeShipping {
+		refund.ShippingAmount = order.ShippingFee
+		for _, prev := range ledger {
+			refund.ShippingAmount -= prev.ShippingAmount
+		}
+		if refund.ShippingAmount <= 0 {
+			return nil, fmt.Errorf("shipping fee is already refunded")
 		}
 	}
 
-	// 4. Execute Transaction
-	return u.txManager.Do(ctx, func(txCtx context.Context) error {
-		// Create Refund record
-		if err := u.orderRepo.CreateRefund(txCtx, orderID, amount, reason, restock, &adminID); err != nil {
+	// 3. Validate Refund Amount
+	refund.Amount = req.Amount
+	if refund.Amount == 0 && (len(refund.Items) > 0 || refund.ShippingAmount > 0) {
+		refund.Amount = itemsValue + refund.ShippingAmount
+	}
+	if refund.Amount <= 0 {
+		return nil, fmt.Errorf("refund amount must be positive")
+	}
+	remainingRefundable := order.PaidAmount - order.RefundedAmount
+	if refund.Amount > remainingRefundable {
+		return nil, fmt.Errorf("cannot refund %s (max refundable: %s)", refund.Amount, remainingRefundable)
+	}
+
+	// 4. A refund of everything still refundable is a full refund: it moves the order to
+	// refunded, where the state machine allows it (a returned order keeps its status)
+	moveToRefunded := refund.Amount >= remainingRefundable && domain.IsValidTransition(order.Status, domain.OrderStatusRefunded)
+
+	// 5. Execute Transaction
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		// Create Refund record (with its items)
+		if err := u.orderRepo.CreateRefund(txCtx, refund); err != nil {
 			return err
 		}
 
-		// Restock if requested (using shared helper)
-		if restock {
-			if err := u.restoreOrderStock(txCtx, order, "refund_restock"); err != nil {
+		// Restock if requested: only the refunded items, or the whole order for amount refunds
+		if req.Restock {
+			if len(refund.Items) > 0 {
+				if err := u.restockRefundItems(txCtx, order, refund.Items); err != nil {
+					return err
+				}
+			} else if err := u.restoreOrderStock(txCtx, order, "refund_restock"); err != nil {
 				return err
 			}
 		}
 
 		// Build history entry
-		histReason := fmt.Sprintf("Refunded %.2f BDT: %s", amount, reason)
+		histReason := fmt.Sprintf("Refunded %s BDT via %s: %s", refund.Amount, refund.Method, req.Reason)
+		if len(refund.Items) > 0 {
+			histReason += fmt.Sprintf(" (%d items", refundItemCount(refund.Items))
+			if refund.ShippingAmount > 0 {
+				histReason += " + shipping"
+			}
+			histReason += ")"
+		} else if refund.ShippingAmount > 0 {
+			histReason += " (shipping)"
+		}
 		history := &domain.OrderHistory{
 			OrderID:        orderID,
 			PreviousStatus: &order.Status,
@@ -855,20 +1921,116 @@ func (u *OrderUsecase) ProcessRefund(ctx context.Context, orderID string, amount
 			CreatedBy:      &adminID,
 		}
 
-		// L9: Full refund + restock → auto-transition to refunded (FSM already validated above)
-		if isFullRefund {
-			history.NewStatus = domain.OrderStatusRefunded
-			if err := u.orderRepo.UpdateStatus(txCtx, orderID, domain.OrderStatusRefunded); err != nil {
+		// L9: Full refund → transition to refunded through the side-effect engine, which
+		// also syncs the payment status and sends the refund email
+		if moveToRefunded {
+			order.RefundedAmount += refund.Amount
+			if err := u.handleOrderStateSideEffects(txCtx, order, domain.OrderStatusRefunded, adminID, nil); err != nil {
 				return err
 			}
-			// Sync payment status via side-effect engine
-			if err := u.orderRepo.UpdatePaymentStatus(txCtx, orderID, domain.PaymentStatusRefunded); err != nil {
+			if err := u.orderRepo.UpdateStatus(txCtx, orderID, domain.OrderStatusRefunded); err != nil {
 				return err
 			}
+			history.NewStatus = domain.OrderStatusRefunded
 		}
 
-		return u.orderRepo.CreateOrderHistory(txCtx, history)
+		if err := u.orderRepo.CreateOrderHistory(txCtx, history); err != nil {
+			return err
+		}
+		if moveToRefunded {
+			return nil
+		}
+		// Every other refund is announced to the customer on its own
+		return u.notifier.NotifyOrder(txCtx, orderID, domain.EmailOrderRefunded, refund.Amount)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// GetRefunds returns the order's refund ledger, newest first.
+func (u *OrderUsecase) GetRefunds(ctx context.Context, orderID string) ([]domain.Refund, error) {
+	if _, err := u.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	return u.orderRepo.GetRefunds(ctx, orderID)
+}
+
+// refundableQuantities returns, per order item ID, the quantity not yet refunded.
+func refundableQuantities(order *domain.Order, ledger []domain.Refund) map[string]int {
+	left := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		left[item.ID] += item.Quantity
+	}
+	for _, refund := range ledger {
+		for _, item := range refund.Items {
+			left[item.OrderItemID] -= item.Quantity
+		}
+	}
+	return left
+}
+
+// refundItems validates the requested quantities against what is not yet refunded and
+// prices them at the price paid.
+func refundItems(order *domain.Order, ledger []domain.Refund, reqItems []RefundItemReq) ([]domain.RefundItem, error) {
+	left := refundableQuantities(order, ledger)
+	prices := make(map[string]domain.Money, len(order.Items))
+	for _, item := range order.Items {
+		prices[item.ID] = item.Price
+	}
+
+	items := make([]domain.RefundItem, 0, len(reqItems))
+	seen := make(map[string]bool, len(reqItems))
+	for _, req := range reqItems {
+		qty, ok := left[req.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %s is not part of this order", req.OrderItemID)
+		}
+		if seen[req.OrderItemID] {
+			return nil, fmt.Errorf("item %s is listed twice", req.OrderItemID)
+		}
+		seen[req.OrderItemID] = true
+		if req.Quantity <= 0 || req.Quantity > qty {
+			return nil, fmt.Errorf("item %s: refund quantity must be between 1 and %d", req.OrderItemID, qty)
+		}
+		items = append(items, domain.RefundItem{
+			OrderItemID: req.OrderItemID,
+			Quantity:    req.Quantity,
+			Amount:      prices[req.OrderItemID].Times(req.Quantity),
+		})
+	}
+	return items, nil
+}
+
+func refundItemCount(items []domain.RefundItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.Quantity
+	}
+	return total
+}
+
+// restockRefundItems puts the refunded quantities back in stock.
+func (u *OrderUsecase) restockRefundItems(ctx context.Context, order *domain.Order, items []domain.RefundItem) error {
+	quantities := make(map[string]int, len(items))
+	for _, item := range items {
+		quantities[item.OrderItemID] = item.Quantity
+	}
+	for _, item := range order.Items {
+		qty := quantities[item.ID]
+		if qty == 0 {
+			continue
+		}
+		targetID := item.ProductID
+		if item.VariantID != nil {
+			targetID = *item.VariantID
+		}
+		if err := u.productRepo.UpdateStock(ctx, targetID, qty, "refund_restock", order.ID); err != nil {
+			return fmt.Errorf("failed to restock item %s: %w", item.ID, err)
+		}
+	}
+	return nil
 }
 
 // GetOrderHistory retrieves the history logs for an order
@@ -921,7 +2083,7 @@ func (u *OrderUsecase) UpdateShippingZone(ctx context.Context, orderID string, n
 		}
 
 		// Build history entry
-		histReason := fmt.Sprintf("Shipping zone changed from %s to %s (Fee difference: %.2f)", currentZone, newZoneKey, diff)
+		histReason := fmt.Sprintf("Shipping zone changed from %s to %s (Fee difference: %s)", currentZone, newZoneKey, diff)
 		history := &domain.OrderHistory{
 			OrderID:        orderID,
 			PreviousStatus: &order.Status,
@@ -932,3 +2094,319 @@ func (u *OrderUsecase) UpdateShippingZone(ctx context.Context, orderID string, n
 		return u.orderRepo.CreateOrderHistory(txCtx, history)
 	})
 }
+
+// --- Admin Order Editing ---
+
+// EditOrderItemReq changes one line of an order: an existing item by OrderItemID
+// (quantity 0 removes it), or a product to add.
+type EditOrderItemReq struct {
+	OrderItemID string  `json:"orderItemId,omitempty"`
+	ProductID   string  `json:"productId,omitempty"`
+	VariantID   *string `json:"variantId,omitempty"` // Defaults to the product's first variant
+	Quantity    int     `json:"quantity"`
+}
+
+type EditOrderReq struct {
+	Items   []EditOrderItemReq `json:"items"`   // Items not listed are kept as they are
+	Reprice bool               `json:"reprice"` // Re-price kept items at current prices instead of the price paid
+	Note    string             `json:"note"`
+}
+
+// EditOrder changes the items of an order before dispatch. Stock moves by the net change
+// per variant (inventory log reason "order_edit"), added items are priced at current
+// prices, and the total and pre-order deposit are recomputed. The coupon discount is
+// kept, capped at the new subtotal. Payments are not touched: a changed balance is
+// noted in the order history along with the item diff. Once a parcel is booked its
+// items and COD amount are fixed, so the shipment has to be cancelled first.
+func (u *OrderUsecase) EditOrder(ctx context.Context, orderID string, req EditOrderReq, adminID string) (*domain.Order, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	if !domain.IsEditableOrderStatus(order.Status) {
+		return nil, fmt.Errorf("order is %s and cannot be edited", order.Status)
+	}
+	if order.RefundedAmount > 0 {
+		return nil, fmt.Errorf("order has refunds and cannot be edited")
+	}
+	// Stock only held for a pending gateway payment has not been deducted yet
+	holds, err := u.reservationRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hold := range holds {
+		if hold.Status == domain.ReservationStatusActive || hold.Status == domain.ReservationStatusExpired {
+			return nil, fmt.Errorf("order is awaiting its online payment and cannot be edited")
+		}
+	}
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shipments {
+		if s.Status != domain.ShipmentStatusCancelled {
+			return nil, fmt.Errorf("order has a booked shipment (%s) and cannot be edited; cancel the shipment first", s.ConsignmentID)
+		}
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("no item changes given")
+	}
+
+	products := make(map[string]*domain.Product)
+	getProduct := func(id string) (*domain.Product, error) {
+		if p, ok := products[id]; ok {
+			return p, nil
+		}
+		p, err := u.productRepo.GetProductByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("product %s not found", id)
+		}
+		products[id] = p
+		return p, nil
+	}
+
+	// 1. Apply the changes: new quantity per existing item, plus added lines
+	quantities := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		quantities[item.ID] = item.Quantity
+	}
+	listed := make(map[string]bool, len(req.Items))
+	var added []domain.OrderItem
+	for _, change := range req.Items {
+		if change.Quantity < 0 {
+			return nil, fmt.Errorf("quantity cannot be negative")
+		}
+		if change.OrderItemID != "" {
+			if _, ok := quantities[change.OrderItemID]; !ok {
+				return nil, fmt.Errorf("item %s is not part of this order", change.OrderItemID)
+			}
+			if listed[change.OrderItemID] {
+				return nil, fmt.Errorf("item %s is listed twice", change.OrderItemID)
+			}
+			listed[change.OrderItemID] = true
+			quantities[change.OrderItemID] = change.Quantity
+			continue
+		}
+
+		if change.ProductID == "" {
+			return nil, fmt.Errorf("each change needs an orderItemId or a productId")
+		}
+		if change.Quantity == 0 {
+			return nil, fmt.Errorf("quantity of an added item must be positive")
+		}
+		product, err := getProduct(change.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if len(product.Variants) == 0 {
+			return nil, fmt.Errorf("product %s has no inventory variants", product.Name)
+		}
+		variantID := product.Variants[0].ID
+		if change.VariantID != nil && *change.VariantID != "" {
+			variantID = *change.VariantID
+		}
+		variant, price, ok := productVariantPrice(product, variantID)
+		if !ok {
+			return nil, fmt.Errorf("variant %s not found for product %s", variantID, product.Name)
+		}
+		// A variant already on the order grows its line instead of adding another
+		merged := false
+		for _, item := range order.Items {
+			if item.VariantID != nil && *item.VariantID == variant.ID {
+				quantities[item.ID] += change.Quantity
+				merged = true
+				break
+			}
+		}
+		for i := range added {
+			if !merged && *added[i].VariantID == variant.ID {
+				added[i].Quantity += change.Quantity
+				merged = true
+			}
+		}
+		if !merged {
+			added = append(added, domain.OrderItem{
+				ProductID:   product.ID,
+				Product:     domain.Product{ID: product.ID, Name: product.Name},
+				VariantID:   &variant.ID,
+				VariantName: &variant.Name,
+				Quantity:    change.Quantity,
+				Price:       price,
+			})
+		}
+	}
+
+	// 2. Re-price, recompute subtotal and deposit, and collect the stock movements
+	var subtotal, deposit domain.Money
+	var isPreorder bool
+	var changes []string
+	prices := make(map[string]domain.Money, len(order.Items))
+	stockDelta := make(map[string]int)
+	cappedDelta := make(map[string]int) // Net unit change per pre-order product with a cap
+	cappedNames := make(map[string]string)
+	lines := 0
+	for _, item := range order.Items {
+		qty := quantities[item.ID]
+		price := item.Price
+		product, err := getProduct(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if req.Reprice && item.VariantID != nil && qty > 0 {
+			if _, current, ok := productVariantPrice(product, *item.VariantID); ok {
+				price = current
+			}
+		}
+		prices[item.ID] = price
+
+		switch {
+		case qty == 0:
+			changes = append(changes, fmt.Sprintf("removed %s x%d", itemLabel(&item), item.Quantity))
+		case qty != item.Quantity:
+			changes = append(changes, fmt.Sprintf("%s x%d → x%d", itemLabel(&item), item.Quantity, qty))
+		}
+		if qty > 0 && price != item.Price {
+			changes = append(changes, fmt.Sprintf("%s price %s → %s", itemLabel(&item), item.Price, price))
+		}
+		if qty != item.Quantity {
+			if item.VariantID == nil {
+				return nil, fmt.Errorf("item %s has no variant ID, cannot adjust stock", item.ProductID)
+			}
+			stockDelta[*item.VariantID] += qty - item.Quantity
+			if product.IsPreorder && product.PreorderLimit != nil {
+				cappedDelta[product.ID] += qty - item.Quantity
+				cappedNames[product.ID] = product.Name
+			}
+		}
+
+		if qty > 0 {
+			lines++
+			subtotal += price.Times(qty)
+			if product.IsPreorder {
+				isPreorder = true
+				deposit += product.PreorderDepositAmount.Times(qty)
+			}
+		}
+	}
+	for _, item := range added {
+		lines++
+		subtotal += item.Price.Times(item.Quantity)
+		stockDelta[*item.VariantID] += item.Quantity
+		if product := products[item.ProductID]; product.IsPreorder {
+			isPreorder = true
+			deposit += product.PreorderDepositAmount.Times(item.Quantity)
+			if product.PreorderLimit != nil {
+				cappedDelta[product.ID] += item.Quantity
+				cappedNames[product.ID] = product.Name
+			}
+		}
+		changes = append(changes, fmt.Sprintf("added %s x%d @ %s", itemLabel(&item), item.Quantity, item.Price))
+	}
+	if lines == 0 {
+		return nil, fmt.Errorf("an order needs at least one item; cancel it instead")
+	}
+	capped := make(map[string]int) // Only growing products count against their cap
+	for id, delta := range cappedDelta {
+		if delta > 0 {
+			capped[id] = delta
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("the edit does not change the order")
+	}
+
+	discount := order.DiscountAmount.Min(subtotal)
+	total := subtotal - discount + order.ShippingFee
+	if total != order.TotalAmount {
+		changes = append(changes, fmt.Sprintf("total %s → %s", order.TotalAmount, total))
+	}
+	if discount != order.DiscountAmount {
+		changes = append(changes, fmt.Sprintf("discount %s → %s", order.DiscountAmount, discount))
+	}
+
+	paymentDetails := domain.JSONB{}
+	for k, v := range order.PaymentDetails {
+		paymentDetails[k] = v
+	}
+	oldDeposit, hadDeposit := domain.MoneyFromJSON(paymentDetails["deposit_required"])
+	if hadDeposit || deposit > 0 {
+		paymentDetails["deposit_required"] = deposit
+		if deposit != oldDeposit {
+			changes = append(changes, fmt.Sprintf("pre-order deposit %s → %s", oldDeposit, deposit))
+		}
+	}
+	if order.PaidAmount > 0 && total != order.TotalAmount {
+		changes = append(changes, fmt.Sprintf("paid %s, balance %s", order.PaidAmount, total-order.PaidAmount))
+	}
+
+	// 3. Transaction: pre-order caps, stock, items, amounts and history
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := u.checkPreorderLimits(txCtx, capped, cappedNames); err != nil {
+			return err
+		}
+
+		// Lock variants in a stable order so concurrent edits/checkouts cannot deadlock
+		variantIDs := make([]string, 0, len(stockDelta))
+		for id := range stockDelta {
+			variantIDs = append(variantIDs, id)
+		}
+		sort.Strings(variantIDs)
+		for _, variantID := range variantIDs {
+			delta := stockDelta[variantID]
+			if delta == 0 {
+				continue
+			}
+			if delta > 0 {
+				variant, err := u.productRepo.GetVariantByIDForUpdate(txCtx, variantID)
+				if err != nil {
+					return fmt.Errorf("failed to lock stock for variant %s: %v", variantID, err)
+				}
+				if variant.Available < delta {
+					return fmt.Errorf("insufficient stock for item %s (requested: %d, available: %d)", variant.Name, delta, variant.Available)
+				}
+			}
+			if err := u.productRepo.UpdateStock(txCtx, variantID, -delta, "order_edit", order.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range order.Items {
+			qty, price := quantities[item.ID], prices[item.ID]
+			switch {
+			case qty == 0:
+				if err := u.orderRepo.DeleteOrderItem(txCtx, item.ID); err != nil {
+					return err
+				}
+			case qty != item.Quantity || price != item.Price:
+				if err := u.orderRepo.UpdateOrderItem(txCtx, item.ID, qty, price); err != nil {
+					return err
+				}
+			}
+		}
+		for i := range added {
+			if err := u.orderRepo.AddOrderItem(txCtx, order.ID, &added[i]); err != nil {
+				return err
+			}
+		}
+		if err := u.orderRepo.UpdateOrderAmounts(txCtx, order.ID, total, discount, isPreorder, paymentDetails); err != nil {
+			return err
+		}
+
+		histReason := "Order edited: " + strings.Join(changes, "; ")
+		if note := strings.TrimSpace(req.Note); note != "" {
+			histReason += " — " + note
+		}
+		return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &order.Status,
+			NewStatus:      order.Status,
+			Reason:         &histReason,
+			CreatedBy:      &adminID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Orders: order edited", "order_id", order.ID, "total", total, "admin_id", adminID)
+	return u.orderRepo.GetByID(ctx, order.ID)
+}
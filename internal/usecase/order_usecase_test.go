@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"valancis-backend/internal/domain"
+)
+
+// A refund of everything refundable moves the order to refunded whenever the state
+// machine allows it, with or without a restock.
+func TestProcessRefundFullAmount(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            string
+		req               RefundReq
+		wantStatus        string
+		wantPaymentStatus string
+	}{
+		{
+			name:              "full item refund without restock (returns)",
+			status:            domain.OrderStatusDelivered,
+			req:               RefundReq{Items: []RefundItemReq{{OrderItemID: "item-1", Quantity: 2}}},
+			wantStatus:        domain.OrderStatusRefunded,
+			wantPaymentStatus: domain.PaymentStatusRefunded,
+		},
+		{
+			name:              "full amount refund with restock",
+			status:            domain.OrderStatusPaid,
+			req:               RefundReq{Amount: domain.Taka(1000), Restock: true},
+			wantStatus:        domain.OrderStatusRefunded,
+			wantPaymentStatus: domain.PaymentStatusRefunded,
+		},
+		{
+			name:              "partial refund",
+			status:            domain.OrderStatusDelivered,
+			req:               RefundReq{Items: []RefundItemReq{{OrderItemID: "item-1", Quantity: 1}}},
+			wantStatus:        domain.OrderStatusDelivered,
+			wantPaymentStatus: domain.PaymentStatusPartialRefund,
+		},
+		{
+			name:              "full refund of a returned order keeps its status",
+			status:            domain.OrderStatusReturned,
+			req:               RefundReq{Amount: domain.Taka(1000)},
+			wantStatus:        domain.OrderStatusReturned,
+			wantPaymentStatus: domain.PaymentStatusRefunded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			variantID := "variant-a"
+			orders := &fakeOrderRepo{order: &domain.Order{
+				ID:            "order-1",
+				Status:        tt.status,
+				PaymentStatus: domain.PaymentStatusPaid,
+				TotalAmount:   domain.Taka(1000),
+				PaidAmount:    domain.Taka(1000),
+				Items: []domain.OrderItem{
+					{ID: "item-1", OrderID: "order-1", ProductID: "product-1", VariantID: &variantID, Quantity: 2, Price: domain.Taka(500)},
+				},
+			}}
+			products := &fakeProductRepo{stock: map[string]int{variantID: 10}}
+			products.UpdateStock(ctx, variantID, -2, "order_placed", "order-1")
+			orderUC := &OrderUsecase{
+				orderRepo:       orders,
+				productRepo:     products,
+				txManager:       fakeTxManager{},
+				reservationRepo: fakeReservationRepo{},
+			}
+
+			if _, err := orderUC.ProcessRefund(ctx, "order-1", tt.req, "admin-1"); err != nil {
+				t.Fatalf("ProcessRefund: %v", err)
+			}
+			if orders.order.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", orders.order.Status, tt.wantStatus)
+			}
+			if orders.order.PaymentStatus != tt.wantPaymentStatus {
+				t.Errorf("payment status = %s, want %s", orders.order.PaymentStatus, tt.wantPaymentStatus)
+			}
+			if got := products.stock[variantID]; tt.req.Restock && got != 10 {
+				t.Errorf("stock after restock = %d, want 10", got)
+			}
+		})
+	}
+}
+
+// Adds to a cart are refused to blocklisted customers, before the cart is touched.
+func TestAddToCartChecksBlocklist(t *testing.T) {
+	entries := []domain.BlocklistEntry{
+		{ID: "entry-1", Kind: domain.BlocklistKindUser, Value: "user-blocked", Action: domain.BlocklistActionBlock},
+		{ID: "entry-2", Kind: domain.BlocklistKindEmail, Value: "blocked@example.com", Action: domain.BlocklistActionBlock},
+		{ID: "entry-3", Kind: domain.BlocklistKindIP, Value: "203.0.113.7", Action: domain.BlocklistActionBlock},
+	}
+	tests := []struct {
+		name   string
+		userID string // Empty: a guest cart
+		ip     string
+	}{
+		{name: "blocked account", userID: "user-blocked", ip: "198.51.100.1"},
+		{name: "account with a blocked email", userID: "user-email", ip: "198.51.100.1"},
+		{name: "account from a blocked address", userID: "user-ok", ip: "203.0.113.7"},
+		{name: "guest from a blocked address", ip: "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUC := &OrderUsecase{
+				blocklist: &BlocklistUsecase{blocklistRepo: &fakeBlocklistRepo{entries: entries}},
+				userRepo: &fakeUserRepo{users: map[string]*domain.User{
+					"user-email": {ID: "user-email", Email: "Blocked@example.com"},
+					"user-ok":    {ID: "user-ok", Email: "ok@example.com"},
+				}},
+			}
+			variantID := "variant-a"
+			var err error
+			if tt.userID != "" {
+				_, err = orderUC.AddToCart(context.Background(), tt.userID, "product-1", &variantID, 1, tt.ip)
+			} else {
+				_, err = orderUC.AddToGuestCart(context.Background(), "cart-1", "product-1", &variantID, 1, tt.ip)
+			}
+			if err != errCustomerBlocked {
+				t.Fatalf("got %v, want %v", err, errCustomerBlocked)
+			}
+		})
+	}
+}
+
+// Every guest checkout gets a new guest user, even when an earlier guest used the
+// email; an account's email needs a sign-in.
+func TestGuestUserPerCheckout(t *testing.T) {
+	users := &fakeUserRepo{users: map[string]*domain.User{
+		"guest-1":    {ID: "guest-1", Email: "guest@example.com", Role: domain.RoleGuest},
+		"customer-1": {ID: "customer-1", Email: "customer@example.com", Role: domain.RoleCustomer},
+	}}
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{name: "new email", email: "new@example.com"},
+		{name: "email of an earlier guest", email: "guest@example.com"},
+		{name: "email of an account", email: "customer@example.com", wantErr: errSignInRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderUC := &OrderUsecase{userRepo: users}
+			guest, err := orderUC.guestUser(context.Background(), tt.email, "01712345678", "Guest", "Shopper")
+			if err != tt.wantErr {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if guest.ID != "" || guest.Role != domain.RoleGuest || guest.Email != tt.email {
+				t.Errorf("got %+v, want a new guest user for %s", guest, tt.email)
+			}
+		})
+	}
+}
+
+func TestCouponDiscount(t *testing.T) {
+	maxDiscount := domain.Taka(150)
+	tests := []struct {
+		name     string
+		coupon   domain.CouponValidationResult
+		subtotal domain.Money
+		want     domain.Money
+	}{
+		{name: "percentage", coupon: domain.CouponValidationResult{Type: "percentage", Value: domain.Taka(10)}, subtotal: domain.Taka(1000), want: domain.Taka(100)},
+		{name: "fractional percentage rounds", coupon: domain.CouponValidationResult{Type: "percentage", Value: 1250}, subtotal: domain.MoneyFromFloat(99.99), want: 1250},
+		{name: "percentage under the cap", coupon: domain.CouponValidationResult{Type: "percentage", Value: domain.Taka(10), MaxDiscount: &maxDiscount}, subtotal: domain.Taka(1000), want: domain.Taka(100)},
+		{name: "percentage capped", coupon: domain.CouponValidationResult{Type: "percentage", Value: domain.Taka(20), MaxDiscount: &maxDiscount}, subtotal: domain.Taka(1000), want: domain.Taka(150)},
+		{name: "fixed", coupon: domain.CouponValidationResult{Type: "fixed", Value: domain.Taka(200)}, subtotal: domain.Taka(1000), want: domain.Taka(200)},
+		{name: "fixed ignores the cap", coupon: domain.CouponValidationResult{Type: "fixed", Value: domain.Taka(200), MaxDiscount: &maxDiscount}, subtotal: domain.Taka(1000), want: domain.Taka(200)},
+		{name: "fixed above the subtotal", coupon: domain.CouponValidationResult{Type: "fixed", Value: domain.Taka(500)}, subtotal: domain.Taka(300), want: domain.Taka(300)},
+		{name: "nothing eligible", coupon: domain.CouponValidationResult{Type: "fixed", Value: domain.Taka(200)}, subtotal: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := couponDiscount(&tt.coupon, tt.subtotal); got != tt.want {
+				t.Errorf("couponDiscount = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEligibleSubtotal(t *testing.T) {
+	lineTotals := map[string]domain.Money{
+		"product-1": domain.Taka(500),
+		"product-2": domain.Taka(300),
+		"product-3": domain.Taka(200),
+	}
+	tests := []struct {
+		name   string
+		coupon domain.CouponValidationResult
+		want   domain.Money
+	}{
+		{name: "unscoped", coupon: domain.CouponValidationResult{}, want: domain.Taka(1000)},
+		{name: "scoped to some products", coupon: domain.CouponValidationResult{IsScoped: true, EligibleProductIDs: []string{"product-1", "product-3"}}, want: domain.Taka(700)},
+		{name: "scoped to products not in the cart", coupon: domain.CouponValidationResult{IsScoped: true, EligibleProductIDs: []string{"product-9"}}, want: 0},
+		{name: "scoped with nothing eligible", coupon: domain.CouponValidationResult{IsScoped: true}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eligibleSubtotal(&tt.coupon, lineTotals); got != tt.want {
+				t.Errorf("eligibleSubtotal = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
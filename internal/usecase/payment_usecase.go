@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+// PaymentUsecase drives online gateway payments: it opens hosted payment sessions
+// and applies verified gateway callbacks to orders.
+// L9: Gateways are pluggable via domain.PaymentGateway; order/payment changes still go
+// through the FSMs in constants.go.
+type PaymentUsecase struct {
+	paymentRepo     domain.PaymentRepository
+	orderRepo       domain.OrderRepository
+	reservationRepo domain.StockReservationRepository
+	preorderRepo    domain.PreorderRepository
+	ledgerRepo      domain.PaymentLedgerRepository
+	orderUC         *OrderUsecase
+	txManager       domain.TransactionManager
+	gateways        map[string]domain.PaymentGateway
+	reservationTTL  time.Duration
+	apiBaseURL      string
+	frontendURL     string
+}
+
+func NewPaymentUsecase(paymentRepo domain.PaymentRepository, orderRepo domain.OrderRepository, reservationRepo domain.StockReservationRepository, preorderRepo domain.PreorderRepository, ledgerRepo domain.PaymentLedgerRepository, orderUC *OrderUsecase, txManager domain.TransactionManager, reservationTTL time.Duration, apiBaseURL, frontendURL string, gateways ...domain.PaymentGateway) *PaymentUsecase {
+	registry := make(map[string]domain.PaymentGateway, len(gateways))
+	for _, g := range gateways {
+		registry[g.Provider()] = g
+	}
+	return &PaymentUsecase{
+		paymentRepo:     paymentRepo,
+		orderRepo:       orderRepo,
+		reservationRepo: reservationRepo,
+		preorderRepo:    preorderRepo,
+		ledgerRepo:      ledgerRepo,
+		orderUC:         orderUC,
+		txManager:       txManager,
+		gateways:        registry,
+		reservationTTL:  reservationTTL,
+		apiBaseURL:      strings.TrimSuffix(apiBaseURL, "/"),
+		frontendURL:     strings.TrimSuffix(frontendURL, "/"),
+	}
+}
+
+// StartPayment opens a gateway session for the outstanding balance of a customer's order.
+func (u *PaymentUsecase) StartPayment(ctx context.Context, userID, orderID, provider string) (*domain.PaymentSession, error) {
+	gateway, ok := u.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %s is not available", provider)
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+	if order.PaymentMethod != provider {
+		return nil, fmt.Errorf("order payment method is %s, cannot pay with %s", order.PaymentMethod, provider)
+	}
+	if order.Status != domain.OrderStatusPending && order.Status != domain.OrderStatusPendingVerification {
+		return nil, fmt.Errorf("order is %s and cannot be paid online", order.Status)
+	}
+
+	amount := order.TotalAmount - order.PaidAmount
+	if amount <= 0 {
+		return nil, fmt.Errorf("order has no outstanding balance")
+	}
+
+	// Keep the checkout's stock hold alive for this attempt (re-held if it already expired)
+	if _, err := u.reservationRepo.Renew(ctx, orderID, u.reservationTTL); err != nil {
+		return nil, err
+	}
+	return u.openSession(ctx, order, gateway, amount)
+}
+
+// StartBalancePayment opens a gateway session for the balance of a released pre-order,
+// following the customer's signed pay link. Any available gateway may be used.
+func (u *PaymentUsecase) StartBalancePayment(ctx context.Context, token, provider string) (*domain.PaymentSession, error) {
+	requestID, err := utils.ValidateSignedID(utils.SignedIDPreorderBalance, token)
+	if err != nil {
+		return nil, fmt.Errorf("balance request not found")
+	}
+	gateway, ok := u.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %s is not available", provider)
+	}
+
+	req, err := u.preorderRepo.GetBalanceRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != domain.PreorderBalancePending {
+		return nil, fmt.Errorf("balance request is %s and cannot be paid", req.Status)
+	}
+	order, err := u.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	if !domain.IsOpenPreorderStatus(order.Status) {
+		return nil, fmt.Errorf("order is %s and cannot be paid online", order.Status)
+	}
+
+	amount := order.TotalAmount - order.PaidAmount
+	if amount <= 0 {
+		return nil, fmt.Errorf("order has no outstanding balance")
+	}
+	return u.openSession(ctx, order, gateway, amount)
+}
+
+// openSession records a payment session for amount and opens it at the gateway.
+func (u *PaymentUsecase) openSession(ctx context.Context, order *domain.Order, gateway domain.PaymentGateway, amount domain.Money) (*domain.PaymentSession, error) {
+	orderID := order.ID
+	provider := gateway.Provider()
+
+	// A previous attempt failed: reset so the next success is a valid pending → paid transition
+	if order.PaymentStatus == domain.PaymentStatusFailed {
+		if err := u.orderRepo.UpdatePaymentStatus(ctx, orderID, domain.PaymentStatusPending); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &domain.PaymentSession{
+		OrderID:  orderID,
+		Provider: provider,
+		Amount:   amount,
+		Currency: "BDT",
+	}
+	if err := u.paymentRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create payment session: %w", err)
+	}
+
+	gwSession, err := gateway.CreateSession(ctx, domain.GatewaySessionRequest{
+		SessionID:     session.ID,
+		OrderID:       orderID,
+		Amount:        amount,
+		Currency:      session.Currency,
+		CustomerName:  strings.TrimSpace(addressField(order.ShippingAddress, "firstName") + " " + addressField(order.ShippingAddress, "lastName")),
+		CustomerEmail: addressField(order.ShippingAddress, "email"),
+		CustomerPhone: addressField(order.ShippingAddress, "phone"),
+		Address:       addressField(order.ShippingAddress, "addressLine"),
+		CallbackURL:   fmt.Sprintf("%s/api/v1/payments/%s/callback", u.apiBaseURL, provider),
+		IPNURL:        fmt.Sprintf("%s/api/v1/payments/%s/ipn", u.apiBaseURL, provider),
+	})
+	if err != nil {
+		slog.Error("Payment: gateway session failed", "provider", provider, "order_id", orderID, "error", err)
+		if cErr := u.paymentRepo.CompleteSession(ctx, session.ID, domain.PaymentSessionFailed, nil); cErr != nil {
+			slog.Error("Payment: failed to close session", "session_id", session.ID, "error", cErr)
+		}
+		return nil, fmt.Errorf("payment gateway unavailable, please try again")
+	}
+
+	if err := u.paymentRepo.SetSessionGateway(ctx, session.ID, gwSession.GatewayRef, gwSession.RedirectURL); err != nil {
+		return nil, err
+	}
+	session.GatewayRef = &gwSession.GatewayRef
+	session.RedirectURL = &gwSession.RedirectURL
+
+	slog.Info("Payment: session started", "provider", provider, "order_id", orderID, "session_id", session.ID, "amount", amount)
+	return session, nil
+}
+
+// ListPayments returns the gateway sessions of a customer's order, newest first.
+func (u *PaymentUsecase) ListPayments(ctx context.Context, userID, orderID string) ([]domain.PaymentSession, error) {
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found")
+	}
+	return u.paymentRepo.ListSessionsByOrder(ctx, orderID)
+}
+
+// HandleCallback verifies a gateway callback/IPN and applies it to the session and order.
+// Replayed callbacks and callbacks for already-completed sessions are acknowledged but ignored.
+func (u *PaymentUsecase) HandleCallback(ctx context.Context, provider string, fields map[string]string) (*domain.PaymentSession, error) {
+	gateway, ok := u.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("payment provider %s is not available", provider)
+	}
+
+	cb, err := gateway.VerifyCallback(ctx, fields)
+	if err != nil {
+		slog.Warn("Payment: rejected callback", "provider", provider, "error", err)
+		return nil, fmt.Errorf("invalid payment callback")
+	}
+
+	if cb.SessionID == "" {
+		session, err := u.paymentRepo.GetSessionByGatewayRef(ctx, provider, cb.GatewayRef)
+		if err != nil {
+			return nil, fmt.Errorf("payment session not found")
+		}
+		cb.SessionID = session.ID
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		session, err := u.paymentRepo.GetSessionForUpdate(txCtx, cb.SessionID)
+		if err != nil || session.Provider != provider {
+			return fmt.Errorf("payment session not found")
+		}
+
+		fresh, err := u.paymentRepo.RecordCallback(txCtx, provider, cb)
+		if err != nil {
+			return fmt.Errorf("failed to record callback: %w", err)
+		}
+		if !fresh {
+			slog.Info("Payment: replayed callback ignored", "provider", provider, "session_id", session.ID, "event", cb.EventKey)
+			return nil
+		}
+		if session.Status != domain.PaymentSessionInitiated {
+			slog.Info("Payment: callback for completed session ignored", "session_id", session.ID, "status", session.Status, "callback_status", cb.Status)
+			return nil
+		}
+
+		switch cb.Status {
+		case domain.PaymentSessionSucceeded:
+			return u.applySuccessfulPayment(txCtx, session, cb)
+		case domain.PaymentSessionFailed:
+			if err := u.paymentRepo.CompleteSession(txCtx, session.ID, domain.PaymentSessionFailed, nil); err != nil {
+				return err
+			}
+			order, err := u.orderRepo.GetByID(txCtx, session.OrderID)
+			if err != nil {
+				return err
+			}
+			if domain.IsValidPaymentTransition(order.PaymentStatus, domain.PaymentStatusFailed) {
+				return u.orderRepo.UpdatePaymentStatus(txCtx, order.ID, domain.PaymentStatusFailed)
+			}
+			return nil
+		default:
+			return u.paymentRepo.CompleteSession(txCtx, session.ID, domain.PaymentSessionCancelled, nil)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.paymentRepo.GetSessionByID(ctx, cb.SessionID)
+}
+
+// applySuccessfulPayment records captured money on the order and advances it like an
+// admin payment verification would (VerifyOrderPayment), but without a human in the loop.
+func (u *PaymentUsecase) applySuccessfulPayment(txCtx context.Context, session *domain.PaymentSession, cb *domain.PaymentCallback) error {
+	order, err := u.orderRepo.GetByID(txCtx, session.OrderID)
+	if err != nil {
+		return err
+	}
+
+	// Never trust an amount that differs from what we asked for
+	if cb.Amount != session.Amount {
+		slog.Error("Payment: amount mismatch, session failed", "session_id", session.ID, "expected", session.Amount, "received", cb.Amount)
+		if err := u.paymentRepo.CompleteSession(txCtx, session.ID, domain.PaymentSessionFailed, &cb.TransactionID); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("Gateway payment amount mismatch via %s (expected %s, received %s, trx %s). Needs manual review.",
+			session.Provider, session.Amount, cb.Amount, cb.TransactionID)
+		return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &order.Status,
+			NewStatus:      order.Status,
+			Reason:         &reason,
+		})
+	}
+
+	if err := u.paymentRepo.CompleteSession(txCtx, session.ID, domain.PaymentSessionSucceeded, &cb.TransactionID); err != nil {
+		return err
+	}
+
+	// A verified gateway capture needs no admin review
+	now := time.Now()
+	trxID := cb.TransactionID
+	if err := u.ledgerRepo.Record(txCtx, &domain.Payment{
+		OrderID:       order.ID,
+		Direction:     domain.PaymentDirectionIn,
+		Amount:        cb.Amount,
+		Method:        session.Provider,
+		Provider:      &session.Provider,
+		TransactionID: &trxID,
+		Status:        domain.PaymentEntryVerified,
+		SessionID:     &session.ID,
+		VerifiedAt:    &now,
+	}); err != nil {
+		return fmt.Errorf("failed to record payment: %w", err)
+	}
+	paid := order.PaidAmount + cb.Amount
+
+	// Money towards a released pre-order is recorded on its balance request
+	balanceReq, err := u.preorderRepo.GetPendingBalanceRequest(txCtx, order.ID)
+	if err == nil {
+		if err := u.preorderRepo.RecordBalancePayment(txCtx, balanceReq.ID, cb.Amount, session.Provider, &cb.TransactionID, paid >= order.TotalAmount); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	newPaymentStatus := domain.PaymentStatusPartialPaid
+	if paid >= order.TotalAmount {
+		newPaymentStatus = domain.PaymentStatusPaid
+	}
+	if domain.IsValidPaymentTransition(order.PaymentStatus, newPaymentStatus) {
+		if err := u.orderRepo.UpdatePaymentStatus(txCtx, order.ID, newPaymentStatus); err != nil {
+			return err
+		}
+	} else {
+		slog.Warn("Payment: payment status transition skipped", "order_id", order.ID, "from", order.PaymentStatus, "to", newPaymentStatus)
+	}
+
+	// Confirming the order runs the same side effects as an admin transition: the stock
+	// held at checkout is committed and the customer notified. The money is already
+	// captured, so if they fail the payment is kept and the order stays unconfirmed, on
+	// hold until an admin resolves it and moves it to processing.
+	newStatus := order.Status
+	holdNote := ""
+	if (order.Status == domain.OrderStatusPending || order.Status == domain.OrderStatusPendingVerification) &&
+		domain.IsValidTransition(order.Status, domain.OrderStatusProcessing) {
+		if err := u.orderUC.handleOrderStateSideEffects(txCtx, order, domain.OrderStatusProcessing, "", nil); err != nil {
+			slog.Error("Payment: order confirmation failed, order on hold", "order_id", order.ID, "error", err)
+			holdNote = fmt.Sprintf(" Order not confirmed (%v), on hold for manual review.", err)
+		} else {
+			if err := u.orderRepo.UpdateStatus(txCtx, order.ID, domain.OrderStatusProcessing); err != nil {
+				return err
+			}
+			newStatus = domain.OrderStatusProcessing
+		}
+	}
+
+	reason := fmt.Sprintf("Payment of %s %s confirmed via %s (trx %s). Payment: %s → %s.%s",
+		cb.Amount, session.Currency, session.Provider, cb.TransactionID, order.PaymentStatus, newPaymentStatus, holdNote)
+	return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      newStatus,
+		Reason:         &reason,
+	})
+}
+
+// ResultURL is where the shopper's browser lands after a gateway redirect.
+func (u *PaymentUsecase) ResultURL(session *domain.PaymentSession) string {
+	q := url.Values{}
+	if session == nil {
+		q.Set("status", "error")
+	} else {
+		q.Set("orderId", session.OrderID)
+		q.Set("status", session.Status)
+	}
+	return u.frontendURL + "/checkout/payment-result?" + q.Encode()
+}
+
+// addressField reads a string field from a shipping address JSONB.
+func addressField(addr domain.JSONB, key string) string {
+	if v, ok := addr[key].(string); ok {
+		return v
+	}
+	return ""
+}
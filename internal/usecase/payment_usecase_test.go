@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"valancis-backend/internal/domain"
+)
+
+// A captured gateway payment confirms the order through the status side effects; when
+// they fail the payment is kept and the order is held for review instead.
+func TestSuccessfulPaymentConfirmsOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		commitErr   error
+		wantStatus  string
+		wantOnHold  bool
+		wantPayment string
+	}{
+		{name: "stock committed", wantStatus: domain.OrderStatusProcessing, wantPayment: domain.PaymentStatusPaid},
+		{
+			name:        "stock commit fails",
+			commitErr:   errors.New("insufficient stock for variant: variant-a"),
+			wantStatus:  domain.OrderStatusPendingVerification,
+			wantOnHold:  true,
+			wantPayment: domain.PaymentStatusPaid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			orders := &fakeOrderRepo{order: &domain.Order{
+				ID:            "order-1",
+				Status:        domain.OrderStatusPendingVerification,
+				PaymentStatus: domain.PaymentStatusPending,
+				PaymentMethod: domain.PaymentMethodSSLCommerz,
+				TotalAmount:   domain.Taka(1000),
+			}}
+			reservations := fakeReservationRepo{commitErr: tt.commitErr}
+			paymentUC := &PaymentUsecase{
+				paymentRepo:     fakePaymentRepo{},
+				orderRepo:       orders,
+				reservationRepo: reservations,
+				preorderRepo:    fakePreorderRepo{},
+				ledgerRepo:      &fakeLedgerRepo{orders: orders},
+				orderUC: &OrderUsecase{
+					orderRepo:       orders,
+					reservationRepo: reservations,
+					txManager:       fakeTxManager{},
+				},
+				txManager: fakeTxManager{},
+			}
+
+			session := &domain.PaymentSession{ID: "session-1", OrderID: "order-1", Provider: domain.PaymentMethodSSLCommerz, Amount: domain.Taka(1000), Currency: "BDT"}
+			cb := &domain.PaymentCallback{SessionID: "session-1", TransactionID: "trx-1", Amount: domain.Taka(1000), Status: domain.PaymentSessionSucceeded}
+			if err := paymentUC.applySuccessfulPayment(ctx, session, cb); err != nil {
+				t.Fatalf("applySuccessfulPayment: %v", err)
+			}
+
+			if got := orders.order.Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			if got := orders.order.PaymentStatus; got != tt.wantPayment {
+				t.Errorf("payment status = %s, want %s", got, tt.wantPayment)
+			}
+			if got := orders.order.PaidAmount; got != domain.Taka(1000) {
+				t.Errorf("paid amount = %s, want 1000.00", got)
+			}
+			if len(orders.history) != 1 {
+				t.Fatalf("got %d history entries, want 1", len(orders.history))
+			}
+			if onHold := strings.Contains(*orders.history[0].Reason, "on hold"); onHold != tt.wantOnHold {
+				t.Errorf("history reason %q, on hold = %v, want %v", *orders.history[0].Reason, onHold, tt.wantOnHold)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+const (
+	maxOrderCodeSends = 5           // Codes per order, including the one sent at checkout
+	orderCodeCooldown = time.Minute // Minimum gap between two codes for the same order
+)
+
+// PhoneVerifier confirms COD orders by SMS: a one-time code goes to the phone in the
+// shipping address at checkout, and entering it moves the order from pending to
+// pending_verification without waiting for an admin call.
+type PhoneVerifier struct {
+	otpRepo     domain.OrderOTPRepository
+	orderRepo   domain.OrderRepository
+	txManager   domain.TransactionManager
+	sms         domain.SMSProvider
+	ttl         time.Duration
+	maxAttempts int
+}
+
+func NewPhoneVerifier(otpRepo domain.OrderOTPRepository, orderRepo domain.OrderRepository, txManager domain.TransactionManager, sms domain.SMSProvider, ttl time.Duration, maxAttempts int) *PhoneVerifier {
+	return &PhoneVerifier{
+		otpRepo:     otpRepo,
+		orderRepo:   orderRepo,
+		txManager:   txManager,
+		sms:         sms,
+		ttl:         ttl,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// RequiresConfirmation reports whether the order still waits for its phone to be confirmed.
+// Always false on a nil verifier (phone confirmation disabled).
+func (v *PhoneVerifier) RequiresConfirmation(order *domain.Order) bool {
+	return v != nil && order.PaymentMethod == domain.PaymentMethodCOD && order.Status == domain.OrderStatusPending
+}
+
+// SendOrderCode texts a new confirmation code to the order's shipping phone.
+func (v *PhoneVerifier) SendOrderCode(ctx context.Context, order *domain.Order) error {
+	if v == nil {
+		return fmt.Errorf("phone confirmation is not enabled")
+	}
+	phone, ok := utils.NormalizePhone(addressField(order.ShippingAddress, "phone"))
+	if !ok {
+		return fmt.Errorf("order has no valid phone number")
+	}
+
+	code, err := utils.GenerateOTP()
+	if err != nil {
+		return fmt.Errorf("failed to generate confirmation code: %w", err)
+	}
+	otp := &domain.OrderOTP{
+		OrderID:     order.ID,
+		Phone:       phone,
+		CodeHash:    utils.HashOTP(order.ID, code),
+		MaxAttempts: v.maxAttempts,
+	}
+	if err := v.otpRepo.Create(ctx, otp, v.ttl); err != nil {
+		return fmt.Errorf("failed to store confirmation code: %w", err)
+	}
+
+	minutes := int(v.ttl.Minutes())
+	message := fmt.Sprintf("Your Valancis order %s confirmation code is %s. It expires in %d minutes.", orderRef(order), code, minutes)
+	if domain.NormalizeLocale(order.Locale) == domain.LocaleBangla {
+		message = fmt.Sprintf("আপনার Valancis অর্ডার %s নিশ্চিত করার কোড %s। কোডটি %d মিনিট পর্যন্ত কার্যকর।", orderRef(order), code, minutes)
+	}
+	if err := v.sms.Send(ctx, phone, message); err != nil {
+		return fmt.Errorf("failed to send confirmation code: %w", err)
+	}
+	slog.Info("PhoneVerifier: confirmation code sent", "order_id", order.ID)
+	return nil
+}
+
+// ResendOrderCode sends a fresh code, limited to maxOrderCodeSends per order and one per
+// orderCodeCooldown. Earlier codes stop working.
+func (v *PhoneVerifier) ResendOrderCode(ctx context.Context, order *domain.Order) error {
+	if v == nil {
+		return fmt.Errorf("phone confirmation is not enabled")
+	}
+	if !v.RequiresConfirmation(order) {
+		return fmt.Errorf("order does not need phone confirmation (status: %s)", order.Status)
+	}
+
+	sent, sinceLast, err := v.otpRepo.SendStats(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if sent >= maxOrderCodeSends {
+		return fmt.Errorf("too many confirmation codes requested; please contact support")
+	}
+	if sent > 0 && sinceLast < orderCodeCooldown {
+		return fmt.Errorf("please wait %d seconds before requesting a new code", int((orderCodeCooldown-sinceLast).Seconds())+1)
+	}
+	return v.SendOrderCode(ctx, order)
+}
+
+// ConfirmOrder checks a code against the latest one sent for the order. On a match the
+// order moves to pending_verification and actorID is recorded in its history.
+// Each code allows maxAttempts guesses before it expires.
+func (v *PhoneVerifier) ConfirmOrder(ctx context.Context, order *domain.Order, code, actorID string) error {
+	if v == nil {
+		return fmt.Errorf("phone confirmation is not enabled")
+	}
+	if !v.RequiresConfirmation(order) {
+		return fmt.Errorf("order does not need phone confirmation (status: %s)", order.Status)
+	}
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return fmt.Errorf("confirmation code is required")
+	}
+
+	otp, err := v.otpRepo.GetLatest(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if otp == nil {
+		return fmt.Errorf("no confirmation code was sent for this order")
+	}
+	if otp.VerifiedAt != nil {
+		return fmt.Errorf("order phone is already confirmed")
+	}
+	if otp.Expired {
+		return fmt.Errorf("confirmation code has expired; request a new one")
+	}
+
+	// L9: Spend the attempt before comparing so concurrent guesses can't exceed the limit
+	attempts, ok, err := v.otpRepo.UseAttempt(ctx, otp.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("too many incorrect attempts; request a new code")
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashOTP(order.ID, code)), []byte(otp.CodeHash)) != 1 {
+		slog.Warn("PhoneVerifier: incorrect confirmation code", "order_id", order.ID, "attempt", attempts)
+		return fmt.Errorf("invalid confirmation code (%d attempts left)", otp.MaxAttempts-attempts)
+	}
+
+	if !domain.IsValidTransition(order.Status, domain.OrderStatusPendingVerification) {
+		return fmt.Errorf("invalid status transition: %s -> %s", order.Status, domain.OrderStatusPendingVerification)
+	}
+	oldStatus := order.Status
+
+	return v.txManager.Do(ctx, func(txCtx context.Context) error {
+		verified, err := v.otpRepo.MarkVerified(txCtx, otp.ID)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return fmt.Errorf("order phone is already confirmed")
+		}
+		if err := v.orderRepo.UpdateStatus(txCtx, order.ID, domain.OrderStatusPendingVerification); err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("Customer: Phone %s confirmed via SMS code", otp.Phone)
+		history := &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &oldStatus,
+			NewStatus:      domain.OrderStatusPendingVerification,
+			Reason:         &reason,
+			CreatedBy:      &actorID,
+		}
+		return v.orderRepo.CreateOrderHistory(txCtx, history)
+	})
+}
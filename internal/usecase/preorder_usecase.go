@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+// PreorderUsecase schedules pre-order products. Releasing a batch covers every open
+// pre-ordered item of the product (freeing its pre-order cap for the next batch),
+// notifies the customers and issues a balance request for each order that still owes
+// money. Balances are paid through a signed link (PaymentUsecase.StartBalancePayment),
+// recorded by an admin, or collected on delivery for COD orders.
+type PreorderUsecase struct {
+	preorderRepo domain.PreorderRepository
+	orderRepo    domain.OrderRepository
+	productRepo  domain.ProductRepository
+	ledgerRepo   domain.PaymentLedgerRepository
+	txManager    domain.TransactionManager
+	notifier     *OrderNotifier
+	frontendURL  string
+}
+
+func NewPreorderUsecase(preorderRepo domain.PreorderRepository, orderRepo domain.OrderRepository, productRepo domain.ProductRepository, ledgerRepo domain.PaymentLedgerRepository, txManager domain.TransactionManager, notifier *OrderNotifier, frontendURL string) *PreorderUsecase {
+	return &PreorderUsecase{
+		preorderRepo: preorderRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		ledgerRepo:   ledgerRepo,
+		txManager:    txManager,
+		notifier:     notifier,
+		frontendURL:  strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+type ReleasePreorderReq struct {
+	Note string `json:"note"`
+}
+
+// PreorderReleaseResp is a release with the balance requests it issued.
+type PreorderReleaseResp struct {
+	Release         *domain.PreorderRelease         `json:"release"`
+	BalanceRequests []domain.PreorderBalanceRequest `json:"balanceRequests"`
+}
+
+// RecordBalancePaymentReq records balance money collected outside the gateways.
+type RecordBalancePaymentReq struct {
+	Amount    domain.Money `json:"amount"` // Defaults to the outstanding balance
+	Method    string       `json:"method"` // cash, bkash, nagad, bank…
+	Reference string       `json:"reference,omitempty"`
+}
+
+// BalanceLinkResp is what the customer's pay link shows.
+type BalanceLinkResp struct {
+	Request     *domain.PreorderBalanceRequest `json:"request"`
+	OrderNumber string                         `json:"orderNumber"`
+	TotalAmount domain.Money                   `json:"totalAmount"`
+	PaidAmount  domain.Money                   `json:"paidAmount"`
+	BalanceDue  domain.Money                   `json:"balanceDue"`
+}
+
+// --- Admin ---
+
+// ReleasePreorder releases the arrived batch of a pre-order product.
+func (u *PreorderUsecase) ReleasePreorder(ctx context.Context, productID string, req ReleasePreorderReq, adminID string) (*PreorderReleaseResp, error) {
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil || product == nil {
+		return nil, fmt.Errorf("product not found")
+	}
+	if !product.IsPreorder {
+		return nil, fmt.Errorf("product is not a pre-order product")
+	}
+
+	release := &domain.PreorderRelease{ProductID: product.ID, ProductName: product.Name, ReleasedBy: &adminID}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		release.Note = &note
+	}
+
+	resp := &PreorderReleaseResp{Release: release, BalanceRequests: []domain.PreorderBalanceRequest{}}
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		// Checkouts of the product wait until the release is committed
+		if _, err := u.preorderRepo.LockProductLimit(txCtx, product.ID); err != nil {
+			return err
+		}
+		orderIDs, err := u.preorderRepo.CreateRelease(txCtx, release)
+		if err != nil {
+			return fmt.Errorf("failed to create pre-order release: %w", err)
+		}
+		if len(orderIDs) == 0 {
+			return fmt.Errorf("product has no open pre-orders to release")
+		}
+
+		for _, orderID := range orderIDs {
+			balanceReq, err := u.releaseOrder(txCtx, orderID, release, adminID)
+			if err != nil {
+				return err
+			}
+			if balanceReq != nil {
+				resp.BalanceRequests = append(resp.BalanceRequests, *balanceReq)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Preorders: batch released", "release_id", release.ID, "product_id", product.ID, "orders", release.OrderCount, "balance_requests", len(resp.BalanceRequests), "admin_id", adminID)
+	return resp, nil
+}
+
+// releaseOrder asks one released order for its balance (reusing a request still pending
+// from an earlier release of another item) and notifies the customer.
+func (u *PreorderUsecase) releaseOrder(txCtx context.Context, orderID string, release *domain.PreorderRelease, adminID string) (*domain.PreorderBalanceRequest, error) {
+	order, err := u.orderRepo.GetByID(txCtx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := order.TotalAmount - order.PaidAmount
+	var balanceReq *domain.PreorderBalanceRequest
+	if balance > 0 {
+		balanceReq, err = u.preorderRepo.GetPendingBalanceRequest(txCtx, order.ID)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				return nil, err
+			}
+			balanceReq = &domain.PreorderBalanceRequest{
+				OrderID:     order.ID,
+				OrderNumber: order.OrderNumber,
+				ReleaseID:   release.ID,
+				Amount:      balance,
+			}
+			if err := u.preorderRepo.CreateBalanceRequest(txCtx, balanceReq); err != nil {
+				return nil, fmt.Errorf("failed to create balance request: %w", err)
+			}
+		}
+		balanceReq.OrderNumber = order.OrderNumber
+	}
+
+	reason := fmt.Sprintf("Pre-order of %s released", release.ProductName)
+	payURL := ""
+	if balanceReq != nil {
+		token, err := utils.GenerateSignedID(utils.SignedIDPreorderBalance, balanceReq.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue balance pay link: %w", err)
+		}
+		payURL = u.frontendURL + "/preorder-balance/" + token
+		reason += fmt.Sprintf("; balance due %s", balance)
+	}
+	if err := u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      order.Status,
+		Reason:         &reason,
+		CreatedBy:      &adminID,
+	}); err != nil {
+		return nil, err
+	}
+	if err := u.notifier.NotifyPreorderRelease(txCtx, order.ID, max(balance, 0), payURL); err != nil {
+		return nil, err
+	}
+	return balanceReq, nil
+}
+
+func (u *PreorderUsecase) ListReleases(ctx context.Context, productID string) ([]domain.PreorderRelease, error) {
+	return u.preorderRepo.ListReleases(ctx, productID)
+}
+
+func (u *PreorderUsecase) GetRelease(ctx context.Context, id string) (*PreorderReleaseResp, error) {
+	release, err := u.preorderRepo.GetRelease(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := u.preorderRepo.ListBalanceRequests(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &PreorderReleaseResp{Release: release, BalanceRequests: requests}, nil
+}
+
+// RecordBalancePayment records balance money an admin collected by hand. Once the order
+// is fully paid the request is settled and the order moves partial_paid → paid.
+func (u *PreorderUsecase) RecordBalancePayment(ctx context.Context, requestID string, req RecordBalancePaymentReq, adminID string) (*domain.PreorderBalanceRequest, error) {
+	method := strings.ToLower(strings.TrimSpace(req.Method))
+	if method == "" {
+		return nil, fmt.Errorf("payment method is required")
+	}
+	if req.Amount < 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	var reference *string
+	if ref := strings.TrimSpace(req.Reference); ref != "" {
+		reference = &ref
+	}
+
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		balanceReq, err := u.preorderRepo.GetBalanceRequestForUpdate(txCtx, requestID)
+		if err != nil {
+			return err
+		}
+		if balanceReq.Status != domain.PreorderBalancePending {
+			return fmt.Errorf("balance request is %s and cannot be paid", balanceReq.Status)
+		}
+		order, err := u.orderRepo.GetByID(txCtx, balanceReq.OrderID)
+		if err != nil {
+			return err
+		}
+
+		outstanding := order.TotalAmount - order.PaidAmount
+		if outstanding <= 0 {
+			return fmt.Errorf("order has no outstanding balance")
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = outstanding
+		}
+		if amount > outstanding {
+			return fmt.Errorf("amount %s exceeds the outstanding balance %s", amount, outstanding)
+		}
+
+		paid := order.PaidAmount + amount
+		settled := paid >= order.TotalAmount
+		newPaymentStatus := order.PaymentStatus
+		if settled && order.PaymentStatus != domain.PaymentStatusPaid {
+			newPaymentStatus = domain.PaymentStatusPaid
+			if !domain.IsValidPaymentTransition(order.PaymentStatus, newPaymentStatus) {
+				return fmt.Errorf("cannot record balance: payment status transition '%s' → '%s' is forbidden",
+					order.PaymentStatus, newPaymentStatus)
+			}
+		}
+
+		now := time.Now()
+		if err := u.ledgerRepo.Record(txCtx, &domain.Payment{
+			OrderID:       order.ID,
+			Direction:     domain.PaymentDirectionIn,
+			Amount:        amount,
+			Method:        method,
+			TransactionID: reference,
+			Status:        domain.PaymentEntryVerified,
+			VerifiedBy:    &adminID,
+			VerifiedAt:    &now,
+			CreatedBy:     &adminID,
+		}); err != nil {
+			return fmt.Errorf("failed to record payment: %w", err)
+		}
+		if err := u.preorderRepo.RecordBalancePayment(txCtx, balanceReq.ID, amount, method, reference, settled); err != nil {
+			return err
+		}
+		if newPaymentStatus != order.PaymentStatus {
+			if err := u.orderRepo.UpdatePaymentStatus(txCtx, order.ID, newPaymentStatus); err != nil {
+				return err
+			}
+		}
+
+		reason := fmt.Sprintf("Pre-order balance of %s recorded by admin via %s", amount, method)
+		if reference != nil {
+			reason += fmt.Sprintf(" (ref %s)", *reference)
+		}
+		reason += fmt.Sprintf(". Paid %s of %s. Payment: %s → %s", paid, order.TotalAmount, order.PaymentStatus, newPaymentStatus)
+		return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &order.Status,
+			NewStatus:      order.Status,
+			Reason:         &reason,
+			CreatedBy:      &adminID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Preorders: balance payment recorded", "request_id", requestID, "admin_id", adminID)
+	return u.preorderRepo.GetBalanceRequest(ctx, requestID)
+}
+
+// CancelBalanceRequest withdraws a pending balance request; its pay link stops working.
+func (u *PreorderUsecase) CancelBalanceRequest(ctx context.Context, requestID string) (*domain.PreorderBalanceRequest, error) {
+	balanceReq, err := u.preorderRepo.GetBalanceRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := u.preorderRepo.CancelBalanceRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("balance request is %s and cannot be cancelled", balanceReq.Status)
+	}
+	return u.preorderRepo.GetBalanceRequest(ctx, requestID)
+}
+
+// --- Customer (pay link) ---
+
+// GetBalanceByToken returns the balance request behind a customer's pay link.
+func (u *PreorderUsecase) GetBalanceByToken(ctx context.Context, token string) (*BalanceLinkResp, error) {
+	requestID, err := utils.ValidateSignedID(utils.SignedIDPreorderBalance, token)
+	if err != nil {
+		return nil, fmt.Errorf("balance request not found")
+	}
+	balanceReq, err := u.preorderRepo.GetBalanceRequest(ctx, requestID)
+	if err != nil || balanceReq.Status == domain.PreorderBalanceCancelled {
+		return nil, fmt.Errorf("balance request not found")
+	}
+	order, err := u.orderRepo.GetByID(ctx, balanceReq.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("balance request not found")
+	}
+	balanceReq.PaymentMethod = nil
+	balanceReq.Reference = nil
+	return &BalanceLinkResp{
+		Request:     balanceReq,
+		OrderNumber: order.OrderNumber,
+		TotalAmount: order.TotalAmount,
+		PaidAmount:  order.PaidAmount,
+		BalanceDue:  max(order.TotalAmount-order.PaidAmount, 0),
+	}, nil
+}
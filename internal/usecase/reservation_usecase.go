@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"valancis-backend/internal/domain"
+)
+
+const reservationSweepBatch = 200
+
+// StockReservationSweeper expires stock holds whose payment window has passed,
+// putting the quantities back into availability.
+// L9: Same lifecycle as the RateLimiter cleanup loop — started on construction,
+// stopped via Shutdown on graceful exit.
+type StockReservationSweeper struct {
+	reservationRepo domain.StockReservationRepository
+	orderRepo       domain.OrderRepository
+	interval        time.Duration
+	ctx             context.Context
+	cancel          context.CancelFunc
+}
+
+func NewStockReservationSweeper(ctx context.Context, reservationRepo domain.StockReservationRepository, orderRepo domain.OrderRepository, interval time.Duration) *StockReservationSweeper {
+	s := &StockReservationSweeper{
+		reservationRepo: reservationRepo,
+		orderRepo:       orderRepo,
+		interval:        interval,
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	go s.loop()
+	return s
+}
+
+func (s *StockReservationSweeper) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Sweep(s.ctx); err != nil {
+				slog.Error("Reservations: sweep failed", "error", err)
+			}
+		case <-s.ctx.Done():
+			return // Graceful shutdown
+		}
+	}
+}
+
+// Sweep expires every timed-out hold and notes it on the affected orders.
+// Order status is left alone: the customer may still pay (stock is re-held on the
+// next payment attempt) or the order can be cancelled.
+func (s *StockReservationSweeper) Sweep(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		expired, err := s.reservationRepo.ExpireDue(ctx, reservationSweepBatch)
+		if err != nil {
+			return total, err
+		}
+		total += len(expired)
+
+		units := make(map[string]int)
+		for _, res := range expired {
+			units[res.OrderID] += res.Quantity
+		}
+		for orderID, qty := range units {
+			s.noteExpiry(ctx, orderID, qty)
+		}
+
+		if len(expired) < reservationSweepBatch {
+			break
+		}
+	}
+	if total > 0 {
+		slog.Info("Reservations: expired stale holds", "count", total)
+	}
+	return total, nil
+}
+
+func (s *StockReservationSweeper) noteExpiry(ctx context.Context, orderID string, units int) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		slog.Error("Reservations: order lookup failed", "order_id", orderID, "error", err)
+		return
+	}
+	reason := fmt.Sprintf("System: Stock reservation expired before payment, %d unit(s) returned to availability", units)
+	if err := s.orderRepo.CreateOrderHistory(ctx, &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      order.Status,
+		Reason:         &reason,
+	}); err != nil {
+		slog.Error("Reservations: failed to record history", "order_id", orderID, "error", err)
+	}
+}
+
+// Shutdown gracefully stops the sweeper goroutine
+func (s *StockReservationSweeper) Shutdown() {
+	s.cancel()
+}
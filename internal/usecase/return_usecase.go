@@ -0,0 +1,478 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/storage"
+	"valancis-backend/pkg/utils"
+)
+
+const maxReturnPhotos = 5
+
+// ReturnUsecase runs customer return requests (RMA): the customer asks to return some
+// delivered items, an admin approves or rejects them per item, marks what came back
+// as received (restocking exactly that) and optionally refunds it through ProcessRefund.
+type ReturnUsecase struct {
+	returnRepo   domain.ReturnRepository
+	exchangeRepo domain.ExchangeRepository
+	orderRepo    domain.OrderRepository
+	productRepo  domain.ProductRepository
+	orderUC      *OrderUsecase
+	txManager    domain.TransactionManager
+	storage      *storage.R2Storage
+}
+
+func NewReturnUsecase(returnRepo domain.ReturnRepository, exchangeRepo domain.ExchangeRepository, orderRepo domain.OrderRepository, productRepo domain.ProductRepository, orderUC *OrderUsecase, txManager domain.TransactionManager, storage *storage.R2Storage) *ReturnUsecase {
+	return &ReturnUsecase{
+		returnRepo:   returnRepo,
+		exchangeRepo: exchangeRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		orderUC:      orderUC,
+		txManager:    txManager,
+		storage:      storage,
+	}
+}
+
+// ReturnItemReq is a quantity of one order item, as requested, approved or received.
+type ReturnItemReq struct {
+	OrderItemID string `json:"orderItemId"`
+	Quantity    int    `json:"quantity"`
+}
+
+type RequestReturnReq struct {
+	Reason string          `json:"reason"` // domain.ReturnReason* code
+	Note   string          `json:"note"`
+	Photos []string        `json:"photos"` // URLs from POST /api/v1/returns/photos
+	Items  []ReturnItemReq `json:"items"`
+}
+
+// --- Customer ---
+
+// UploadPhoto stores a customer photo for a return request and returns its URL.
+func (u *ReturnUsecase) UploadPhoto(ctx context.Context, file multipart.File, filename string) (string, error) {
+	data, contentType, err := utils.ProcessImage(file, filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to process image: %w", err)
+	}
+	return u.storage.UploadBuffer(ctx, data, contentType)
+}
+
+// RequestReturn opens a return request for items of one of the user's delivered orders.
+func (u *ReturnUsecase) RequestReturn(ctx context.Context, userID, orderID string, req RequestReturnReq) (*domain.OrderReturn, error) {
+	order, err := u.orderUC.GetMyOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsReturnable(order.Status) {
+		return nil, fmt.Errorf("order cannot be returned (status: %s)", order.Status)
+	}
+	if !domain.IsValidReturnReason(req.Reason) {
+		return nil, fmt.Errorf("invalid return reason: %s", req.Reason)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("select at least one item to return")
+	}
+	if len(req.Photos) > maxReturnPhotos {
+		return nil, fmt.Errorf("at most %d photos can be attached", maxReturnPhotos)
+	}
+	for _, photo := range req.Photos {
+		if !u.storage.OwnsURL(photo) {
+			return nil, fmt.Errorf("invalid photo URL: upload photos first")
+		}
+	}
+
+	ordered := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		ordered[item.ID] += item.Quantity
+	}
+
+	ret := &domain.OrderReturn{
+		OrderID: orderID,
+		UserID:  &userID,
+		Reason:  req.Reason,
+		Note:    optionalString(strings.TrimSpace(req.Note)),
+		Photos:  req.Photos,
+	}
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		qty, ok := ordered[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %s is not part of this order", item.OrderItemID)
+		}
+		if seen[item.OrderItemID] {
+			return nil, fmt.Errorf("item %s is listed twice", item.OrderItemID)
+		}
+		seen[item.OrderItemID] = true
+		if item.Quantity <= 0 || item.Quantity > qty {
+			return nil, fmt.Errorf("item %s: quantity must be between 1 and %d", item.OrderItemID, qty)
+		}
+		ret.Items = append(ret.Items, domain.ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		// Units already returned or exchanged cannot be returned. Checked under the order
+		// lock, which exchanges take too, so no unit is claimed twice.
+		if err := u.orderRepo.LockForUpdate(txCtx, orderID); err != nil {
+			return err
+		}
+		returns, err := u.returnRepo.ListByOrder(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		exchanges, err := u.exchangeRepo.ListByOrder(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		returned, exchanged := domain.ReturnedQuantities(returns), domain.ExchangedQuantities(exchanges)
+		for _, item := range ret.Items {
+			if left := ordered[item.OrderItemID] - returned[item.OrderItemID] - exchanged[item.OrderItemID]; item.Quantity > left {
+				return fmt.Errorf("item %s: quantity must be between 1 and %d", item.OrderItemID, left)
+			}
+		}
+
+		if err := u.returnRepo.Create(txCtx, ret); err != nil {
+			return err
+		}
+		return u.addHistory(txCtx, order, fmt.Sprintf("Return: Requested by customer (%s, %d items)", req.Reason, returnItemCount(ret.Items, requestedQuantity)), userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Returns: return requested", "order_id", orderID, "return_id", ret.ID, "reason", req.Reason)
+	return ret, nil
+}
+
+// ListMyReturns returns the return requests of one of the user's orders.
+func (u *ReturnUsecase) ListMyReturns(ctx context.Context, userID, orderID string) ([]domain.OrderReturn, error) {
+	if _, err := u.orderUC.GetMyOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return u.returnRepo.ListByOrder(ctx, orderID)
+}
+
+// --- Admin ---
+
+func (u *ReturnUsecase) ListReturns(ctx context.Context, status string, page, limit int) ([]domain.OrderReturn, int64, error) {
+	return u.returnRepo.List(ctx, status, limit, (page-1)*limit)
+}
+
+func (u *ReturnUsecase) GetReturn(ctx context.Context, id string) (*domain.OrderReturn, error) {
+	return u.returnRepo.GetByID(ctx, id)
+}
+
+func (u *ReturnUsecase) ListOrderReturns(ctx context.Context, orderID string) ([]domain.OrderReturn, error) {
+	return u.returnRepo.ListByOrder(ctx, orderID)
+}
+
+// ApproveReturn approves a requested return. items sets the approved quantity per order
+// item (0 rejects that item); without items everything requested is approved.
+func (u *ReturnUsecase) ApproveReturn(ctx context.Context, id string, items []ReturnItemReq, note, adminID string) (*domain.OrderReturn, error) {
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		ret, err := u.returnRepo.GetForUpdate(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if ret.Status != domain.ReturnStatusRequested {
+			return fmt.Errorf("return is %s and cannot be approved", ret.Status)
+		}
+
+		approved := make(map[string]int, len(ret.Items))
+		for _, item := range ret.Items {
+			approved[item.OrderItemID] = item.Quantity
+		}
+		if len(items) > 0 {
+			decided, err := returnQuantities(items, ret.Items, requestedQuantity)
+			if err != nil {
+				return err
+			}
+			for itemID := range approved {
+				approved[itemID] = decided[itemID]
+			}
+		}
+
+		total := 0
+		for i := range ret.Items {
+			ret.Items[i].ApprovedQuantity = approved[ret.Items[i].OrderItemID]
+			total += ret.Items[i].ApprovedQuantity
+			if err := u.returnRepo.UpdateItem(txCtx, ret.ID, ret.Items[i]); err != nil {
+				return err
+			}
+		}
+		if total == 0 {
+			return fmt.Errorf("approve at least one item, or reject the return")
+		}
+		if err := u.returnRepo.UpdateStatus(txCtx, ret.ID, domain.ReturnStatusApproved, note, adminID); err != nil {
+			return err
+		}
+		return u.addReturnHistory(txCtx, ret, fmt.Sprintf("Return: Approved %d of %d items", total, returnItemCount(ret.Items, requestedQuantity)), note, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.returnRepo.GetByID(ctx, id)
+}
+
+// RejectReturn rejects a requested return as a whole.
+func (u *ReturnUsecase) RejectReturn(ctx context.Context, id, note, adminID string) (*domain.OrderReturn, error) {
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		ret, err := u.returnRepo.GetForUpdate(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if ret.Status != domain.ReturnStatusRequested {
+			return fmt.Errorf("return is %s and cannot be rejected", ret.Status)
+		}
+		if err := u.returnRepo.UpdateStatus(txCtx, ret.ID, domain.ReturnStatusRejected, note, adminID); err != nil {
+			return err
+		}
+		return u.addReturnHistory(txCtx, ret, "Return: Rejected", note, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.returnRepo.GetByID(ctx, id)
+}
+
+type ReceiveReturnReq struct {
+	Items        []ReturnItemReq `json:"items"` // Defaults to every approved quantity
+	Note         string          `json:"note"`
+	Refund       bool            `json:"refund"`
+	RefundAmount *domain.Money   `json:"refundAmount,omitempty"` // Defaults to the price of the received items
+	RefundMethod string          `json:"refundMethod,omitempty"` // domain.RefundMethod*, defaults to cash
+	Reference    string          `json:"reference,omitempty"`
+}
+
+// RefundReturnReq refunds a received return.
+type RefundReturnReq struct {
+	Amount    *domain.Money `json:"amount,omitempty"` // Defaults to the price of the received items
+	Method    string        `json:"method,omitempty"` // domain.RefundMethod*, defaults to cash
+	Reference string        `json:"reference,omitempty"`
+}
+
+// ReceiveReturn records the items that came back and restocks exactly those
+// (inventory log reason "return"). With Refund set, the received items are then
+// refunded through ProcessRefund.
+func (u *ReturnUsecase) ReceiveReturn(ctx context.Context, id string, req ReceiveReturnReq, adminID string) (*domain.OrderReturn, error) {
+	ret, err := u.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	order, err := u.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	received := make(map[string]int, len(ret.Items))
+	for _, item := range ret.Items {
+		received[item.OrderItemID] = item.ApprovedQuantity
+	}
+	if len(req.Items) > 0 {
+		if received, err = returnQuantities(req.Items, ret.Items, approvedQuantity); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the refund up front so items are never received with an impossible refund
+	var refundAmount domain.Money
+	if req.Refund {
+		refundAmount = receivedValue(order, ret.Items, received)
+		if req.RefundAmount != nil {
+			refundAmount = *req.RefundAmount
+		}
+		if refundable := order.PaidAmount - order.RefundedAmount; refundAmount <= 0 || refundAmount > refundable {
+			return nil, fmt.Errorf("cannot refund %s (max refundable: %s)", refundAmount, refundable)
+		}
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		locked, err := u.returnRepo.GetForUpdate(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if locked.Status != domain.ReturnStatusApproved {
+			return fmt.Errorf("return is %s and cannot be received", locked.Status)
+		}
+
+		total := 0
+		for i, item := range locked.Items {
+			qty := min(received[item.OrderItemID], item.ApprovedQuantity)
+			locked.Items[i].ReceivedQuantity = qty
+			if err := u.returnRepo.UpdateItem(txCtx, locked.ID, locked.Items[i]); err != nil {
+				return err
+			}
+			if qty > 0 {
+				if err := u.restock(txCtx, order, item.OrderItemID, qty); err != nil {
+					return err
+				}
+			}
+			total += qty
+		}
+		if err := u.returnRepo.UpdateStatus(txCtx, locked.ID, domain.ReturnStatusReceived, req.Note, adminID); err != nil {
+			return err
+		}
+		return u.addReturnHistory(txCtx, locked, fmt.Sprintf("Return: Received and restocked %d items", total), req.Note, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ProcessRefund runs its own transaction; a failed refund can be retried with RefundReturn
+	if req.Refund {
+		refundReq := RefundReturnReq{Amount: &refundAmount, Method: req.RefundMethod, Reference: req.Reference}
+		if _, err := u.RefundReturn(ctx, id, refundReq, adminID); err != nil {
+			return nil, fmt.Errorf("items received, but the refund failed: %w", err)
+		}
+	}
+	return u.returnRepo.GetByID(ctx, id)
+}
+
+// RefundReturn refunds the received items of a return, by default at the price paid.
+// The items are recorded in the order's refund ledger; they were restocked on receipt.
+func (u *ReturnUsecase) RefundReturn(ctx context.Context, id string, req RefundReturnReq, adminID string) (*domain.OrderReturn, error) {
+	ret, err := u.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != domain.ReturnStatusReceived {
+		return nil, fmt.Errorf("return is %s and cannot be refunded", ret.Status)
+	}
+	order, err := u.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	refundReq := RefundReq{
+		Reason:    fmt.Sprintf("Return %s (%s)", shortID(ret.ID), ret.Reason),
+		Method:    req.Method,
+		Reference: req.Reference,
+	}
+	for _, item := range ret.Items {
+		if item.ReceivedQuantity > 0 {
+			refundReq.Items = append(refundReq.Items, RefundItemReq{OrderItemID: item.OrderItemID, Quantity: item.ReceivedQuantity})
+		}
+	}
+	if req.Amount != nil {
+		refundReq.Amount = *req.Amount
+	} else {
+		refundReq.Amount = receivedValue(order, ret.Items, nil)
+	}
+
+	refund, err := u.orderUC.ProcessRefund(ctx, ret.OrderID, refundReq, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.returnRepo.AddRefund(ctx, ret.ID, refund.Amount); err != nil {
+		// The refund is booked on the order; only the return's own total is stale
+		slog.Error("Returns: failed to record refund on return", "return_id", ret.ID, "amount", refund.Amount, "error", err)
+		return nil, err
+	}
+	return u.returnRepo.GetByID(ctx, id)
+}
+
+// restock puts received quantities of an order item back in stock, logged against the order.
+func (u *ReturnUsecase) restock(ctx context.Context, order *domain.Order, orderItemID string, qty int) error {
+	for _, item := range order.Items {
+		if item.ID != orderItemID {
+			continue
+		}
+		targetID := item.ProductID
+		if item.VariantID != nil {
+			targetID = *item.VariantID
+		}
+		if err := u.productRepo.UpdateStock(ctx, targetID, qty, "return", order.ID); err != nil {
+			return fmt.Errorf("failed to restock item %s: %w", orderItemID, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("item %s is not part of this order", orderItemID)
+}
+
+func (u *ReturnUsecase) addReturnHistory(ctx context.Context, ret *domain.OrderReturn, reason, note, actorID string) error {
+	order, err := u.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return err
+	}
+	reason += fmt.Sprintf(" [return %s]", shortID(ret.ID))
+	if note = strings.TrimSpace(note); note != "" {
+		reason += " — " + note
+	}
+	return u.addHistory(ctx, order, reason, actorID)
+}
+
+// addHistory records a note-only order history entry.
+func (u *ReturnUsecase) addHistory(ctx context.Context, order *domain.Order, reason, actorID string) error {
+	history := &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      order.Status,
+		Reason:         &reason,
+	}
+	if actorID != "" {
+		history.CreatedBy = &actorID
+	}
+	return u.orderRepo.CreateOrderHistory(ctx, history)
+}
+
+func requestedQuantity(item domain.ReturnItem) int { return item.Quantity }
+func approvedQuantity(item domain.ReturnItem) int  { return item.ApprovedQuantity }
+
+// returnQuantities validates per-item quantities against the return's items, each
+// capped by limit (requested or approved quantity). Items left out get 0.
+func returnQuantities(reqItems []ReturnItemReq, items []domain.ReturnItem, limit func(domain.ReturnItem) int) (map[string]int, error) {
+	limits := make(map[string]int, len(items))
+	for _, item := range items {
+		limits[item.OrderItemID] = limit(item)
+	}
+	result := make(map[string]int, len(reqItems))
+	for _, item := range reqItems {
+		capQty, ok := limits[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %s is not part of this return", item.OrderItemID)
+		}
+		if _, dup := result[item.OrderItemID]; dup {
+			return nil, fmt.Errorf("item %s is listed twice", item.OrderItemID)
+		}
+		if item.Quantity < 0 || item.Quantity > capQty {
+			return nil, fmt.Errorf("item %s: quantity must be between 0 and %d", item.OrderItemID, capQty)
+		}
+		result[item.OrderItemID] = item.Quantity
+	}
+	return result, nil
+}
+
+func returnItemCount(items []domain.ReturnItem, qty func(domain.ReturnItem) int) int {
+	total := 0
+	for _, item := range items {
+		total += qty(item)
+	}
+	return total
+}
+
+// receivedValue is the price paid for the received quantities. received overrides the
+// stored received quantities when set.
+func receivedValue(order *domain.Order, items []domain.ReturnItem, received map[string]int) domain.Money {
+	prices := make(map[string]domain.Money, len(order.Items))
+	for _, item := range order.Items {
+		prices[item.ID] = item.Price
+	}
+	var total domain.Money
+	for _, item := range items {
+		qty := item.ReceivedQuantity
+		if received != nil {
+			qty = received[item.OrderItemID]
+		}
+		total += prices[item.OrderItemID].Times(qty)
+	}
+	return total
+}
+
+// shortID is the first block of a UUID, for references in notes.
+func shortID(id string) string {
+	if len(id) < 8 {
+		return strings.ToUpper(id)
+	}
+	return strings.ToUpper(id[:8])
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"valancis-backend/internal/domain"
+)
+
+// Units restocked when a return is received must not be restocked again when the
+// order later moves to returned.
+func TestReceiveReturnThenReturnedRestocksOnce(t *testing.T) {
+	tests := []struct {
+		name     string
+		received []ReturnItemReq // Empty: nothing received before the status change
+	}{
+		{name: "no return received"},
+		{name: "part of a line received", received: []ReturnItemReq{{OrderItemID: "item-1", Quantity: 1}}},
+		{name: "whole line received", received: []ReturnItemReq{{OrderItemID: "item-1", Quantity: 2}}},
+		{name: "every line received", received: []ReturnItemReq{
+			{OrderItemID: "item-1", Quantity: 2},
+			{OrderItemID: "item-2", Quantity: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			variantA, variantB := "variant-a", "variant-b"
+			order := &domain.Order{
+				ID:     "order-1",
+				Status: domain.OrderStatusDelivered,
+				Items: []domain.OrderItem{
+					{ID: "item-1", OrderID: "order-1", ProductID: "product-1", VariantID: &variantA, Quantity: 2, Price: domain.Taka(500)},
+					{ID: "item-2", OrderID: "order-1", ProductID: "product-1", VariantID: &variantB, Quantity: 1, Price: domain.Taka(500)},
+				},
+			}
+			products := &fakeProductRepo{stock: map[string]int{variantA: 10, variantB: 10}}
+			// Stock deducted at checkout
+			products.UpdateStock(ctx, variantA, -2, "order_placed", order.ID)
+			products.UpdateStock(ctx, variantB, -1, "order_placed", order.ID)
+
+			orders := &fakeOrderRepo{order: order}
+			orderUC := &OrderUsecase{
+				orderRepo:       orders,
+				productRepo:     products,
+				txManager:       fakeTxManager{},
+				reservationRepo: fakeReservationRepo{},
+			}
+			returnUC := &ReturnUsecase{
+				returnRepo: &fakeReturnRepo{ret: &domain.OrderReturn{
+					ID:      "return-1",
+					OrderID: order.ID,
+					Status:  domain.ReturnStatusApproved,
+					Items: []domain.ReturnItem{
+						{OrderItemID: "item-1", Quantity: 2, ApprovedQuantity: 2},
+						{OrderItemID: "item-2", Quantity: 1, ApprovedQuantity: 1},
+					},
+				}},
+				orderRepo:   orders,
+				productRepo: products,
+				orderUC:     orderUC,
+				txManager:   fakeTxManager{},
+			}
+
+			if len(tt.received) > 0 {
+				if _, err := returnUC.ReceiveReturn(ctx, "return-1", ReceiveReturnReq{Items: tt.received}, "admin-1"); err != nil {
+					t.Fatalf("ReceiveReturn: %v", err)
+				}
+			}
+			if err := orderUC.UpdateOrderStatus(ctx, order.ID, domain.OrderStatusReturned, "", "admin-1"); err != nil {
+				t.Fatalf("UpdateOrderStatus: %v", err)
+			}
+
+			for variantID, want := range map[string]int{variantA: 10, variantB: 10} {
+				if got := products.stock[variantID]; got != want {
+					t.Errorf("stock of %s = %d, want %d", variantID, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+// settlementHeaderScan is how many leading rows may hold a statement's title block
+// before its header row.
+const settlementHeaderScan = 10
+
+// SettlementUsecase reconciles courier COD payout statements with orders. Rows are
+// read through pluggable column mappings, matched by order number, parcel reference or
+// tracking ID, and every delivered order whose payouts match its outstanding amount is
+// moved to paid through OrderUsecase (so SideEffectSyncPaymentPaid records the collection).
+type SettlementUsecase struct {
+	orderRepo    domain.OrderRepository
+	shipmentRepo domain.ShipmentRepository
+	// Parcel payouts booked by earlier statements are found in the ledger
+	ledgerRepo domain.PaymentLedgerRepository
+	orderUC    *OrderUsecase
+	mappings   []domain.SettlementColumnMapping
+}
+
+func NewSettlementUsecase(orderRepo domain.OrderRepository, shipmentRepo domain.ShipmentRepository, ledgerRepo domain.PaymentLedgerRepository, orderUC *OrderUsecase, mappings ...domain.SettlementColumnMapping) *SettlementUsecase {
+	return &SettlementUsecase{
+		orderRepo:    orderRepo,
+		shipmentRepo: shipmentRepo,
+		ledgerRepo:   ledgerRepo,
+		orderUC:      orderUC,
+		mappings:     mappings,
+	}
+}
+
+// Mappings returns the registered statement layouts.
+func (u *SettlementUsecase) Mappings() []domain.SettlementColumnMapping {
+	return u.mappings
+}
+
+type ImportSettlementReq struct {
+	FileName string
+	Data     []byte
+	// Mapping names a registered layout; empty detects it from the header row.
+	Mapping string
+	// CustomMapping overrides Mapping for statements without a registered layout.
+	CustomMapping *domain.SettlementColumnMapping
+	// DryRun reconciles without settling anything.
+	DryRun bool
+}
+
+// ImportStatement reconciles a statement and, unless it is a dry run, settles the
+// matching orders. Rows are matched first, then settled per order: the parcels of a
+// split order are each checked against their own COD and the order settles once their
+// payouts cover what it owes. Each order settles in its own transaction: one bad row
+// does not hold back the rest, and re-importing a statement only reports the rows as
+// already paid.
+func (u *SettlementUsecase) ImportStatement(ctx context.Context, req ImportSettlementReq, adminID string) (*domain.SettlementReport, error) {
+	rows, err := utils.ReadSpreadsheet(req.FileName, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	mapping, headerIdx, err := u.resolveMapping(rows, req)
+	if err != nil {
+		return nil, err
+	}
+	orderCol, trackingCol, amountCol, chargeCol, _ := mapping.Columns(rows[headerIdx])
+
+	report := &domain.SettlementReport{
+		Mapping:    mapping.Name,
+		FileName:   req.FileName,
+		DryRun:     req.DryRun,
+		Rows:       []domain.SettlementRow{},
+		Exceptions: []domain.SettlementRow{},
+		Counts:     map[string]int{},
+	}
+
+	// 1. Match every row to its order (and parcel); rows that pass wait per order
+	var statementRows []domain.SettlementRow
+	pending := make(map[string][]settlementMatch) // Order ID → its payable rows
+	var orderIDs []string                         // In statement order
+	claims := make(map[string]int)                // Order or parcel → statement row that claimed it
+	for i := headerIdx + 1; i < len(rows); i++ {
+		cells := rows[i]
+		if isBlankRow(cells) {
+			continue
+		}
+		row := domain.SettlementRow{
+			Row:         i + 1,
+			OrderNumber: cell(cells, orderCol),
+			TrackingID:  cell(cells, trackingCol),
+		}
+		match := u.matchRow(ctx, &row, cells, amountCol, chargeCol, mapping, claims)
+		statementRows = append(statementRows, row)
+		if match != nil {
+			match.index = len(statementRows) - 1
+			if _, ok := pending[match.order.ID]; !ok {
+				orderIDs = append(orderIDs, match.order.ID)
+			}
+			pending[match.order.ID] = append(pending[match.order.ID], *match)
+		}
+	}
+
+	// 2. Settle order by order
+	for _, orderID := range orderIDs {
+		u.settleOrder(ctx, statementRows, pending[orderID], mapping, req, adminID)
+	}
+
+	for _, row := range statementRows {
+		report.StatementTotal += row.Amount
+		report.ChargeTotal += row.Charge
+		switch row.Status {
+		case domain.SettlementRowSettled, domain.SettlementRowRecorded, domain.SettlementRowMatched:
+			report.SettledTotal += row.Amount
+		}
+		report.Counts[row.Status]++
+		report.Rows = append(report.Rows, row)
+		if domain.IsSettlementException(row.Status) {
+			report.Exceptions = append(report.Exceptions, row)
+		}
+	}
+
+	slog.Info("COD settlement imported", "file", req.FileName, "mapping", mapping.Name, "dry_run", req.DryRun,
+		"rows", len(report.Rows), "exceptions", len(report.Exceptions), "settled_total", report.SettledTotal, "admin_id", adminID)
+	return report, nil
+}
+
+// resolveMapping picks the statement layout and finds its header row.
+func (u *SettlementUsecase) resolveMapping(rows [][]string, req ImportSettlementReq) (domain.SettlementColumnMapping, int, error) {
+	var candidates []domain.SettlementColumnMapping
+	switch {
+	case req.CustomMapping != nil:
+		custom := *req.CustomMapping
+		if custom.Name == "" {
+			custom.Name = "custom"
+		}
+		candidates = append(candidates, custom)
+	case req.Mapping != "":
+		for _, m := range u.mappings {
+			if strings.EqualFold(m.Name, req.Mapping) {
+				candidates = append(candidates, m)
+			}
+		}
+		if len(candidates) == 0 {
+			return domain.SettlementColumnMapping{}, 0, fmt.Errorf("unknown settlement mapping: %s", req.Mapping)
+		}
+	default:
+		candidates = u.mappings
+	}
+
+	for i := 0; i < len(rows) && i < settlementHeaderScan; i++ {
+		for _, m := range candidates {
+			if _, _, _, _, ok := m.Columns(rows[i]); ok {
+				return m, i, nil
+			}
+		}
+	}
+	if len(candidates) == 1 {
+		return domain.SettlementColumnMapping{}, 0, fmt.Errorf("statement does not match the %s mapping: no order number/tracking ID and amount columns", candidates[0].Name)
+	}
+	return domain.SettlementColumnMapping{}, 0, fmt.Errorf("could not recognise the statement columns; choose a mapping or send a custom one")
+}
+
+// settlementMatch is a statement row that matched an order and passed the row checks.
+type settlementMatch struct {
+	index    int // Into the statement rows
+	order    *domain.Order
+	shipment *domain.Shipment // Nil when the row names only the order
+}
+
+// matchRow reads a statement row and matches it to its order, and to its parcel when the
+// row names one. A row paying for a parcel is compared with the parcel's COD; a row
+// naming only the order with the order's outstanding amount. It returns nil once the
+// row has its final status.
+func (u *SettlementUsecase) matchRow(ctx context.Context, row *domain.SettlementRow, cells []string, amountCol, chargeCol int, mapping domain.SettlementColumnMapping, claims map[string]int) *settlementMatch {
+	amount, err := parseStatementAmount(cell(cells, amountCol))
+	if err != nil {
+		row.Status, row.Message = domain.SettlementRowInvalid, err.Error()
+		return nil
+	}
+	row.Amount = amount
+	if raw := cell(cells, chargeCol); raw != "" {
+		if charge, err := parseStatementAmount(raw); err == nil {
+			row.Charge = charge
+		}
+	}
+	if row.OrderNumber == "" && row.TrackingID == "" {
+		row.Status, row.Message = domain.SettlementRowInvalid, "row has neither an order number nor a tracking ID"
+		return nil
+	}
+
+	order, shipment, err := u.matchOrder(ctx, row.OrderNumber, row.TrackingID, mapping.Courier)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			row.Status, row.Message = domain.SettlementRowUnmatched, "no order with this order number or tracking ID"
+		} else {
+			row.Status, row.Message = domain.SettlementRowInvalid, err.Error()
+		}
+		return nil
+	}
+	row.OrderID = &order.ID
+	if row.OrderNumber == "" {
+		row.OrderNumber = order.OrderNumber
+	}
+	if shipment != nil {
+		row.ShipmentID = &shipment.ID
+		if shipment.ExchangeID != nil {
+			row.Status, row.Message = domain.SettlementRowInvalid, "parcel is the replacement of an exchange; record its payout on the exchange"
+			return nil
+		}
+	}
+
+	// An order is paid for either as a whole or parcel by parcel, each only once
+	wholeKey, parcelsKey := "order:"+order.ID, "parcels:"+order.ID
+	if first, dup := claims[wholeKey]; dup {
+		row.Status, row.Message = domain.SettlementRowDuplicate, fmt.Sprintf("order already paid for by row %d", first)
+		return nil
+	}
+	if shipment == nil {
+		if first, dup := claims[parcelsKey]; dup {
+			row.Status, row.Message = domain.SettlementRowDuplicate, fmt.Sprintf("order already paid for by parcel in row %d", first)
+			return nil
+		}
+		claims[wholeKey] = row.Row
+	} else {
+		if first, dup := claims["shipment:"+shipment.ID]; dup {
+			row.Status, row.Message = domain.SettlementRowDuplicate, fmt.Sprintf("parcel already paid for by row %d", first)
+			return nil
+		}
+		claims["shipment:"+shipment.ID] = row.Row
+		if _, ok := claims[parcelsKey]; !ok {
+			claims[parcelsKey] = row.Row
+		}
+	}
+
+	what := "the outstanding"
+	row.Expected = order.TotalAmount - order.PaidAmount
+	if shipment != nil {
+		what, row.Expected = "the parcel's COD", shipment.CODAmount
+	}
+	row.Difference = row.Amount - row.Expected
+	switch {
+	case order.Status == domain.OrderStatusPaid || order.PaymentStatus == domain.PaymentStatusPaid:
+		row.Status, row.Expected, row.Difference = domain.SettlementRowAlreadyPaid, 0, 0
+		return nil
+	case shipment == nil && order.Status != domain.OrderStatusDelivered:
+		row.Status, row.Message = domain.SettlementRowNotDelivered, fmt.Sprintf("order is %s", order.Status)
+		return nil
+	case shipment != nil && shipment.Status != domain.ShipmentStatusDelivered:
+		row.Status, row.Message = domain.SettlementRowNotDelivered, fmt.Sprintf("parcel is %s", shipment.Status)
+		return nil
+	case row.Difference > 0:
+		row.Status, row.Message = domain.SettlementRowOverpaid, fmt.Sprintf("courier paid %s more than %s %s", row.Difference, what, row.Expected)
+		return nil
+	case row.Difference < 0:
+		row.Status, row.Message = domain.SettlementRowUnderpaid, fmt.Sprintf("courier paid %s less than %s %s", row.Difference.Abs(), what, row.Expected)
+		return nil
+	}
+	return &settlementMatch{order: order, shipment: shipment}
+}
+
+// settleOrder settles one order from its payable rows. A whole-order row settles it
+// directly. Parcel payouts not booked by an earlier statement are added up: once they
+// cover what the order owes it moves to paid with all of them, until then each is
+// booked on its own so a later statement can finish the order.
+func (u *SettlementUsecase) settleOrder(ctx context.Context, rows []domain.SettlementRow, matches []settlementMatch, mapping domain.SettlementColumnMapping, req ImportSettlementReq, adminID string) {
+	order := matches[0].order
+	note := func(m settlementMatch) string {
+		return fmt.Sprintf("COD settled from %s statement %s (row %d)", mapping.Name, req.FileName, rows[m.index].Row)
+	}
+	fail := func(ms []settlementMatch, err error) {
+		slog.Error("COD settlement: failed to settle order", "order_id", order.ID, "error", err)
+		for _, m := range ms {
+			rows[m.index].Status, rows[m.index].Message = domain.SettlementRowFailed, err.Error()
+		}
+	}
+	setStatus := func(ms []settlementMatch, status, message string) {
+		for _, m := range ms {
+			rows[m.index].Status, rows[m.index].Message = status, message
+		}
+	}
+
+	if matches[0].shipment == nil {
+		m := matches[0] // A whole-order row never shares its order with another row
+		if req.DryRun {
+			setStatus(matches, domain.SettlementRowMatched, "")
+			return
+		}
+		reference := rows[m.index].TrackingID
+		if reference == "" {
+			reference = rows[m.index].OrderNumber
+		}
+		remittance := domain.CODRemittance{Courier: mapping.Name, Reference: reference, Amount: rows[m.index].Amount, Source: req.FileName}
+		if err := u.orderUC.SettleCODOrder(ctx, order.ID, []domain.CODRemittance{remittance}, note(m), adminID); err != nil {
+			fail(matches, err)
+			return
+		}
+		setStatus(matches, domain.SettlementRowSettled, "")
+		return
+	}
+
+	// Parcel payouts already in the ledger came from an earlier statement
+	ledger, err := u.ledgerRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		fail(matches, err)
+		return
+	}
+	booked := make(map[string]bool)
+	for _, p := range ledger {
+		if p.Direction == domain.PaymentDirectionIn && p.Method == domain.PaymentMethodCOD && p.Status == domain.PaymentEntryVerified && p.TransactionID != nil {
+			booked[*p.TransactionID] = true
+		}
+	}
+	var fresh []settlementMatch
+	var remitted domain.Money
+	paying := make(map[string]bool)
+	for _, m := range matches {
+		if booked[m.shipment.ConsignmentID] {
+			rows[m.index].Status, rows[m.index].Message = domain.SettlementRowAlreadyPaid, "payout for this parcel was already recorded"
+			continue
+		}
+		fresh = append(fresh, m)
+		remitted += rows[m.index].Amount
+		paying[m.shipment.ID] = true
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	// Parcels of the order still to be paid for after this statement
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		fail(fresh, err)
+		return
+	}
+	unpaid := 0
+	for _, s := range shipments {
+		if s.ExchangeID != nil || s.CODAmount <= 0 || paying[s.ID] || booked[s.ConsignmentID] ||
+			s.Status == domain.ShipmentStatusCancelled || s.Status == domain.ShipmentStatusReturned {
+			continue
+		}
+		unpaid++
+	}
+
+	outstanding := order.TotalAmount - order.PaidAmount
+	switch {
+	case remitted > outstanding:
+		setStatus(fresh, domain.SettlementRowOverpaid, fmt.Sprintf("parcel payouts of %s exceed the order's outstanding %s", remitted, outstanding))
+		return
+	case unpaid == 0 && remitted < outstanding:
+		setStatus(fresh, domain.SettlementRowUnderpaid, fmt.Sprintf("parcel payouts of %s leave %s of the order's outstanding %s unpaid", remitted, outstanding-remitted, outstanding))
+		return
+	case unpaid == 0 && order.Status != domain.OrderStatusDelivered:
+		// Every parcel is paid for, but the order cannot move to paid yet
+		setStatus(fresh, domain.SettlementRowNotDelivered, fmt.Sprintf("order is %s", order.Status))
+		return
+	}
+	if req.DryRun {
+		setStatus(fresh, domain.SettlementRowMatched, "")
+		return
+	}
+
+	remittances := make([]domain.CODRemittance, len(fresh))
+	for i, m := range fresh {
+		remittances[i] = domain.CODRemittance{Courier: mapping.Name, Reference: m.shipment.ConsignmentID, Amount: rows[m.index].Amount, Source: req.FileName}
+	}
+	if remitted == outstanding && order.Status == domain.OrderStatusDelivered {
+		if err := u.orderUC.SettleCODOrder(ctx, order.ID, remittances, note(fresh[0]), adminID); err != nil {
+			fail(fresh, err)
+			return
+		}
+		setStatus(fresh, domain.SettlementRowSettled, "")
+		return
+	}
+
+	waiting := fmt.Sprintf("order settles once its %d other parcel(s) are paid", unpaid)
+	for i, m := range fresh {
+		if err := u.orderUC.RecordCODRemittance(ctx, order.ID, remittances[i], note(m), adminID); err != nil {
+			fail([]settlementMatch{m}, err)
+			continue
+		}
+		rows[m.index].Status, rows[m.index].Message = domain.SettlementRowRecorded, waiting
+	}
+}
+
+// matchOrder finds the order a row refers to, and the parcel when the row names one by
+// tracking ID or parcel reference (order number plus sequence). When the row carries
+// both references they must agree.
+func (u *SettlementUsecase) matchOrder(ctx context.Context, orderNumber, trackingID, courier string) (*domain.Order, *domain.Shipment, error) {
+	var byNumber *domain.Order
+	var parcel *domain.Shipment
+	if orderNumber != "" {
+		order, err := u.orderRepo.GetByOrderNumber(ctx, orderNumber)
+		if errors.Is(err, domain.ErrNotFound) {
+			// Parcels are booked under the order number plus their sequence
+			if number, sequence, ok := splitParcelRef(orderNumber); ok {
+				if order, err = u.orderRepo.GetByOrderNumber(ctx, number); err == nil {
+					parcel, err = u.parcelBySequence(ctx, order.ID, sequence)
+				}
+			}
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, err
+		}
+		if err == nil {
+			byNumber = order
+		}
+	}
+	if trackingID == "" {
+		if byNumber == nil {
+			return nil, nil, fmt.Errorf("order %w", domain.ErrNotFound)
+		}
+		return byNumber, parcel, nil
+	}
+
+	shipment, err := u.shipmentRepo.GetByTracking(ctx, courier, trackingID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) && byNumber != nil {
+			return byNumber, parcel, nil
+		}
+		return nil, nil, err
+	}
+	if byNumber != nil && byNumber.ID != shipment.OrderID {
+		return nil, nil, fmt.Errorf("order number %s and tracking ID %s belong to different orders", orderNumber, trackingID)
+	}
+	if parcel != nil && parcel.ID != shipment.ID {
+		return nil, nil, fmt.Errorf("parcel %s and tracking ID %s belong to different parcels", orderNumber, trackingID)
+	}
+	if byNumber != nil {
+		return byNumber, shipment, nil
+	}
+	order, err := u.orderRepo.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, shipment, nil
+}
+
+// parcelBySequence returns the order's nth shipment, counted in booking order as
+// parcelRef numbers them.
+func (u *SettlementUsecase) parcelBySequence(ctx context.Context, orderID string, sequence int) (*domain.Shipment, error) {
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if sequence > len(shipments) {
+		return nil, fmt.Errorf("shipment %w", domain.ErrNotFound)
+	}
+	sort.SliceStable(shipments, func(i, j int) bool { return shipments[i].CreatedAt.Before(shipments[j].CreatedAt) })
+	return &shipments[sequence-1], nil
+}
+
+// parseStatementAmount reads an amount as couriers print it ("৳1,250.00", "Tk 980").
+func parseStatementAmount(s string) (domain.Money, error) {
+	cleaned := strings.NewReplacer(",", "", "৳", "", " ", "").Replace(strings.TrimSpace(s))
+	for _, prefix := range []string{"BDT", "bdt", "Tk.", "Tk", "TK"} {
+		cleaned = strings.TrimPrefix(cleaned, prefix)
+	}
+	if cleaned == "" {
+		return 0, fmt.Errorf("amount is missing")
+	}
+	return domain.ParseMoney(cleaned)
+}
+
+func cell(cells []string, i int) string {
+	if i < 0 || i >= len(cells) {
+		return ""
+	}
+	return strings.TrimSpace(cells[i])
+}
+
+func isBlankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
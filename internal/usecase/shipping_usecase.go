@@ -0,0 +1,475 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"valancis-backend/internal/domain"
+	"valancis-backend/pkg/utils"
+)
+
+// ShippingUsecase books courier consignments for orders and applies courier webhooks.
+// L9: Couriers are pluggable via domain.CourierProvider; order changes driven by a
+// courier go through OrderUsecase.UpdateOrderStatus, so ValidTransitions and the
+// side-effect engine apply exactly as for an admin.
+type ShippingUsecase struct {
+	shipmentRepo domain.ShipmentRepository
+	orderRepo    domain.OrderRepository
+	orderUC      *OrderUsecase
+	txManager    domain.TransactionManager
+	couriers     map[string]domain.CourierProvider
+}
+
+func NewShippingUsecase(shipmentRepo domain.ShipmentRepository, orderRepo domain.OrderRepository, orderUC *OrderUsecase, txManager domain.TransactionManager, couriers ...domain.CourierProvider) *ShippingUsecase {
+	registry := make(map[string]domain.CourierProvider, len(couriers))
+	for _, c := range couriers {
+		registry[c.Provider()] = c
+	}
+	return &ShippingUsecase{
+		shipmentRepo: shipmentRepo,
+		orderRepo:    orderRepo,
+		orderUC:      orderUC,
+		txManager:    txManager,
+		couriers:     registry,
+	}
+}
+
+// Couriers lists the configured courier providers.
+func (u *ShippingUsecase) Couriers() []string {
+	names := make([]string, 0, len(u.couriers))
+	for name := range u.couriers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BookShipmentReq is an admin's shipment booking. Location IDs are only needed by
+// couriers that cannot resolve the shipping address on their own.
+type BookShipmentReq struct {
+	Provider       string                `json:"provider"`                 // Courier* or ShipmentProviderManual
+	Items          []domain.ShipmentItem `json:"items,omitempty"`          // Defaults to every item not yet shipped
+	CODAmount      *domain.Money         `json:"codAmount,omitempty"`      // Defaults to the balance not yet assigned to a shipment
+	TrackingNumber string                `json:"trackingNumber,omitempty"` // Manual shipments only
+	WeightKg       float64               `json:"weightKg,omitempty"`
+	Note           string                `json:"note,omitempty"`
+	Area           string                `json:"area,omitempty"` // Defaults to the shipping address area
+	CityID         int                   `json:"cityId,omitempty"`
+	AreaID         int                   `json:"areaId,omitempty"`
+}
+
+// BookShipment ships some or all of the order's remaining items, with a courier or
+// manually. The order moves on once the parcel is picked up (courier webhook or admin
+// update), to partially_shipped while items remain.
+func (u *ShippingUsecase) BookShipment(ctx context.Context, orderID string, req BookShipmentReq, adminID string) (*domain.Shipment, error) {
+	var courier domain.CourierProvider
+	if req.Provider != domain.ShipmentProviderManual {
+		var ok bool
+		if courier, ok = u.couriers[req.Provider]; !ok {
+			return nil, fmt.Errorf("courier %s is not available", req.Provider)
+		}
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	if !domain.IsValidTransition(order.Status, domain.OrderStatusShipped) {
+		return nil, fmt.Errorf("order is %s and cannot be shipped", order.Status)
+	}
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := shipmentItems(req.Items, domain.UnshippedQuantities(order.Items, shipments))
+	if err != nil {
+		return nil, err
+	}
+	itemCount := 0
+	for _, item := range items {
+		itemCount += item.Quantity
+	}
+
+	// The outstanding balance is collected once, by default on the first parcel
+	unassignedCOD := order.TotalAmount - order.PaidAmount
+	for _, s := range shipments {
+		if s.Status != domain.ShipmentStatusCancelled && s.Status != domain.ShipmentStatusReturned {
+			unassignedCOD -= s.CODAmount
+		}
+	}
+	unassignedCOD = unassignedCOD.Max(0)
+	codAmount := unassignedCOD
+	if req.CODAmount != nil {
+		if *req.CODAmount < 0 || *req.CODAmount > unassignedCOD {
+			return nil, fmt.Errorf("cod amount must be between 0 and %s", unassignedCOD)
+		}
+		codAmount = *req.CODAmount
+	}
+
+	shipment := &domain.Shipment{
+		OrderID:   order.ID,
+		Provider:  req.Provider,
+		CODAmount: codAmount,
+		Items:     items,
+	}
+	describe := func(consignmentID string) string {
+		return fmt.Sprintf("Shipment: Booked with %s (consignment %s, %d items, COD %s)", req.Provider, consignmentID, itemCount, codAmount)
+	}
+	if err := u.dispatch(ctx, courier, order, req, shipment, itemCount, len(shipments)+1, describe, adminID); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// BookReplacementShipment ships the replacement variant of an exchange. The shipment
+// carries no order items and collects the price difference the customer owes, if any.
+func (u *ShippingUsecase) BookReplacementShipment(ctx context.Context, order *domain.Order, exchange *domain.OrderExchange, req BookShipmentReq, adminID string) (*domain.Shipment, error) {
+	var courier domain.CourierProvider
+	if req.Provider != domain.ShipmentProviderManual {
+		var ok bool
+		if courier, ok = u.couriers[req.Provider]; !ok {
+			return nil, fmt.Errorf("courier %s is not available", req.Provider)
+		}
+	}
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shipments {
+		if s.ExchangeID != nil && *s.ExchangeID == exchange.ID && s.Status != domain.ShipmentStatusCancelled {
+			return nil, fmt.Errorf("exchange already has a replacement shipment")
+		}
+	}
+
+	codAmount := exchange.PriceDifference.Max(0)
+	if req.CODAmount != nil {
+		if *req.CODAmount < 0 {
+			return nil, fmt.Errorf("cod amount must not be negative")
+		}
+		codAmount = *req.CODAmount
+	}
+	if req.Note == "" {
+		req.Note = "Exchange: " + exchange.NewVariantName
+	}
+
+	shipment := &domain.Shipment{
+		OrderID:    order.ID,
+		Provider:   req.Provider,
+		CODAmount:  codAmount,
+		ExchangeID: &exchange.ID,
+	}
+	describe := func(consignmentID string) string {
+		return fmt.Sprintf("Shipment: Replacement for exchange %s booked with %s (consignment %s, %d items, COD %s)", shortID(exchange.ID), req.Provider, consignmentID, exchange.Quantity, codAmount)
+	}
+	if err := u.dispatch(ctx, courier, order, req, shipment, exchange.Quantity, len(shipments)+1, describe, adminID); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// dispatch books the shipment with the courier (or numbers a manual one) and stores it
+// with an order history entry, described once the consignment ID is known.
+func (u *ShippingUsecase) dispatch(ctx context.Context, courier domain.CourierProvider, order *domain.Order, req BookShipmentReq, shipment *domain.Shipment, itemCount, sequence int, describe func(consignmentID string) string, adminID string) error {
+	if courier == nil {
+		shipment.ConsignmentID = strings.TrimSpace(req.TrackingNumber)
+		if shipment.ConsignmentID == "" {
+			shipment.ConsignmentID = parcelRef(order, sequence)
+		} else {
+			shipment.TrackingCode = &shipment.ConsignmentID
+		}
+	} else {
+		booking, err := u.book(ctx, courier, order, req, parcelRef(order, sequence), shipment.CODAmount, itemCount)
+		if err != nil {
+			return err
+		}
+		shipment.ConsignmentID = booking.ConsignmentID
+		shipment.TrackingCode = optionalString(booking.TrackingCode)
+		shipment.TrackingURL = optionalString(booking.TrackingURL)
+		shipment.LabelURL = optionalString(booking.LabelURL)
+		shipment.CourierStatus = optionalString(booking.CourierStatus)
+	}
+
+	err := u.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := u.shipmentRepo.Create(txCtx, shipment); err != nil {
+			return err
+		}
+		reason := describe(shipment.ConsignmentID)
+		return u.orderRepo.CreateOrderHistory(txCtx, &domain.OrderHistory{
+			OrderID:        order.ID,
+			PreviousStatus: &order.Status,
+			NewStatus:      order.Status,
+			Reason:         &reason,
+			CreatedBy:      &adminID,
+			ShipmentID:     &shipment.ID,
+		})
+	})
+	if err != nil {
+		// The consignment exists at the courier; keep its ID so it can be reconciled
+		slog.Error("Shipping: failed to record booked consignment", "order_id", order.ID, "courier", req.Provider, "consignment_id", shipment.ConsignmentID, "error", err)
+		return err
+	}
+
+	slog.Info("Shipping: consignment booked", "order_id", order.ID, "courier", req.Provider, "consignment_id", shipment.ConsignmentID, "items", itemCount)
+	return nil
+}
+
+// shipmentItems validates the requested items against what is left to ship.
+// No request means everything that is left.
+func shipmentItems(requested []domain.ShipmentItem, remaining map[string]int) ([]domain.ShipmentItem, error) {
+	if len(requested) == 0 {
+		for id, qty := range remaining {
+			if qty > 0 {
+				requested = append(requested, domain.ShipmentItem{OrderItemID: id, Quantity: qty})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("all items of this order are already shipped")
+		}
+		sort.Slice(requested, func(i, j int) bool { return requested[i].OrderItemID < requested[j].OrderItemID })
+		return requested, nil
+	}
+
+	seen := make(map[string]bool, len(requested))
+	for _, item := range requested {
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %s is not part of this order", item.OrderItemID)
+		}
+		if seen[item.OrderItemID] {
+			return nil, fmt.Errorf("item %s is listed twice", item.OrderItemID)
+		}
+		seen[item.OrderItemID] = true
+		if item.Quantity <= 0 || item.Quantity > left {
+			return nil, fmt.Errorf("item %s: quantity must be between 1 and %d", item.OrderItemID, left)
+		}
+	}
+	return requested, nil
+}
+
+// parcelRef numbers the order's nth parcel, e.g. VAL-26-000123-2. Couriers get it as
+// the merchant reference, which they expect to be unique per consignment.
+func parcelRef(order *domain.Order, sequence int) string {
+	return fmt.Sprintf("%s-%d", strings.TrimPrefix(orderRef(order), "#"), sequence)
+}
+
+// splitParcelRef splits a parcel reference into its order number and sequence; ok is
+// false when ref does not end in a parcel sequence.
+func splitParcelRef(ref string) (orderNumber string, sequence int, ok bool) {
+	i := strings.LastIndex(ref, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	sequence, err := strconv.Atoi(ref[i+1:])
+	if err != nil || sequence < 1 {
+		return "", 0, false
+	}
+	return ref[:i], sequence, true
+}
+
+// book creates the consignment at the courier for the order's shipping address.
+func (u *ShippingUsecase) book(ctx context.Context, courier domain.CourierProvider, order *domain.Order, req BookShipmentReq, reference string, codAmount domain.Money, itemCount int) (*domain.CourierBooking, error) {
+	phone, ok := utils.NormalizePhone(addressField(order.ShippingAddress, "phone"))
+	if !ok {
+		return nil, fmt.Errorf("order has no valid phone number")
+	}
+	addressLine := addressField(order.ShippingAddress, "addressLine")
+	if addressLine == "" {
+		return nil, fmt.Errorf("order has no shipping address")
+	}
+	area := req.Area
+	if area == "" {
+		area = addressField(order.ShippingAddress, "area")
+	}
+	var parts []string
+	for _, part := range []string{addressLine, area, addressField(order.ShippingAddress, "district"), addressField(order.ShippingAddress, "zip")} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	booking, err := courier.Book(ctx, domain.CourierBookingRequest{
+		Reference:      reference,
+		RecipientName:  strings.TrimSpace(addressField(order.ShippingAddress, "firstName") + " " + addressField(order.ShippingAddress, "lastName")),
+		RecipientPhone: phone,
+		Address:        strings.Join(parts, ", "),
+		Area:           area,
+		District:       addressField(order.ShippingAddress, "district"),
+		CityID:         req.CityID,
+		AreaID:         req.AreaID,
+		CODAmount:      codAmount,
+		ItemCount:      itemCount,
+		WeightKg:       req.WeightKg,
+		Note:           req.Note,
+	})
+	if err != nil {
+		slog.Error("Shipping: booking failed", "order_id", order.ID, "courier", req.Provider, "error", err)
+		return nil, fmt.Errorf("courier unavailable: %w", err)
+	}
+	return booking, nil
+}
+
+// ListShipments returns every consignment booked for an order, newest first.
+func (u *ShippingUsecase) ListShipments(ctx context.Context, orderID string) ([]domain.Shipment, error) {
+	return u.shipmentRepo.ListByOrder(ctx, orderID)
+}
+
+// HandleWebhook verifies a courier webhook, records it against its shipment and moves
+// the order along. Replayed webhooks are acknowledged without being applied twice.
+func (u *ShippingUsecase) HandleWebhook(ctx context.Context, provider string, hook domain.CourierWebhook) error {
+	courier, ok := u.couriers[provider]
+	if !ok {
+		return fmt.Errorf("courier %s is not available", provider)
+	}
+
+	ev, err := courier.ParseWebhook(ctx, hook)
+	if err != nil {
+		slog.Warn("Shipping: rejected webhook", "courier", provider, "error", err)
+		return fmt.Errorf("invalid courier webhook")
+	}
+	if ev == nil {
+		return nil
+	}
+
+	shipment, err := u.shipmentRepo.GetByConsignment(ctx, provider, ev.ConsignmentID)
+	if err != nil {
+		return err
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		locked, err := u.shipmentRepo.GetForUpdate(txCtx, shipment.ID)
+		if err != nil {
+			return err
+		}
+
+		fresh, err := u.shipmentRepo.RecordEvent(txCtx, provider, locked.ID, ev)
+		if err != nil {
+			return fmt.Errorf("failed to record courier event: %w", err)
+		}
+		if !fresh {
+			slog.Info("Shipping: replayed webhook ignored", "courier", provider, "consignment_id", ev.ConsignmentID, "event", ev.EventKey)
+			return nil
+		}
+		return u.applyShipmentStatus(txCtx, locked, ev.Status, ev.CourierStatus, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	// Derived from the stored shipment statuses, so a retry after a failed order update heals it
+	return u.syncOrderStatus(ctx, shipment, "")
+}
+
+// UpdateShipmentStatus lets an admin move a shipment along: manual shipments, or a
+// courier whose webhook never arrived. The order follows like for a courier update.
+func (u *ShippingUsecase) UpdateShipmentStatus(ctx context.Context, shipmentID, status, adminID string) (*domain.Shipment, error) {
+	shipment, err := u.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("shipment not found")
+	}
+	if !domain.IsShipmentProgress(shipment.Status, status) {
+		return nil, fmt.Errorf("cannot move shipment from %s to %s", shipment.Status, status)
+	}
+
+	err = u.txManager.Do(ctx, func(txCtx context.Context) error {
+		locked, err := u.shipmentRepo.GetForUpdate(txCtx, shipmentID)
+		if err != nil {
+			return err
+		}
+		if !domain.IsShipmentProgress(locked.Status, status) {
+			return fmt.Errorf("cannot move shipment from %s to %s", locked.Status, status)
+		}
+		courierStatus := ""
+		if locked.CourierStatus != nil {
+			courierStatus = *locked.CourierStatus
+		}
+		return u.applyShipmentStatus(txCtx, locked, status, courierStatus, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := u.syncOrderStatus(ctx, shipment, adminID); err != nil {
+		return nil, err
+	}
+	return u.shipmentRepo.GetByID(ctx, shipmentID)
+}
+
+// applyShipmentStatus stores a shipment update and records status changes in the order
+// history. Updates that would move the shipment backwards only refresh the courier status.
+func (u *ShippingUsecase) applyShipmentStatus(txCtx context.Context, shipment *domain.Shipment, status, courierStatus, actorID string) error {
+	if !domain.IsShipmentProgress(shipment.Status, status) {
+		return u.shipmentRepo.UpdateStatus(txCtx, shipment.ID, shipment.Status, courierStatus)
+	}
+	if err := u.shipmentRepo.UpdateStatus(txCtx, shipment.ID, status, courierStatus); err != nil {
+		return err
+	}
+
+	order, err := u.orderRepo.GetByID(txCtx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("Shipment: %s %s %s → %s", shipment.Provider, shipment.ConsignmentID, shipment.Status, status)
+	if courierStatus != "" && actorID == "" {
+		reason += fmt.Sprintf(" (courier: %s)", courierStatus)
+	}
+	history := &domain.OrderHistory{
+		OrderID:        order.ID,
+		PreviousStatus: &order.Status,
+		NewStatus:      order.Status,
+		Reason:         &reason,
+		ShipmentID:     &shipment.ID,
+	}
+	if actorID != "" {
+		history.CreatedBy = &actorID
+	}
+	return u.orderRepo.CreateOrderHistory(txCtx, history)
+}
+
+// syncOrderStatus moves the order to the status derived from all its shipments
+// (OrderFulfillmentStatus), stepping through shipped when a courier skipped the pickup
+// notification. Transitions ValidTransitions forbids are logged and left for an admin.
+func (u *ShippingUsecase) syncOrderStatus(ctx context.Context, shipment *domain.Shipment, actorID string) error {
+	order, err := u.orderRepo.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	shipments, err := u.shipmentRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	target := domain.OrderFulfillmentStatus(order.Items, shipments)
+	if target == "" || order.Status == target {
+		return nil
+	}
+
+	steps := []string{target}
+	if target != domain.OrderStatusShipped && !domain.IsValidTransition(order.Status, target) &&
+		domain.IsValidTransition(order.Status, domain.OrderStatusShipped) && domain.IsValidTransition(domain.OrderStatusShipped, target) {
+		steps = []string{domain.OrderStatusShipped, target}
+	}
+
+	current := order.Status
+	for _, next := range steps {
+		if !domain.IsValidTransition(current, next) {
+			slog.Info("Shipping: order transition skipped", "order_id", order.ID, "from", current, "to", next, "shipment_id", shipment.ID)
+			return nil
+		}
+		note := fmt.Sprintf("Shipment: %s %s updated, order is now %s", shipment.Provider, shipment.ConsignmentID, next)
+		if err := u.orderUC.UpdateOrderStatusForShipment(ctx, order.ID, shipment.ID, next, note, actorID); err != nil {
+			return err
+		}
+		current = next
+	}
+	return nil
+}
+
+// optionalString returns nil for an empty string.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
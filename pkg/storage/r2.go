@@ -76,7 +76,7 @@ func (s *R2Storage) UploadFile(ctx context.Context, file multipart.File, fileHea
 	return fmt.Sprintf("%s/%s", s.publicURL, filename), nil
 }
 
-// UploadBuffer uploads a byte slice as a file (used for processed images)
+// UploadBuffer uploads a byte slice as a file (used for processed images and archived invoices)
 func (s *R2Storage) UploadBuffer(ctx context.Context, data []byte, contentType string) (string, error) {
 	// 1. Determine Extension from Content-Type
 	ext := ".bin"
@@ -87,6 +87,8 @@ func (s *R2Storage) UploadBuffer(ctx context.Context, data []byte, contentType s
 		ext = ".jpg"
 	case "image/png":
 		ext = ".png"
+	case "application/pdf":
+		ext = ".pdf"
 	}
 
 	// 2. Generate Filename
@@ -113,6 +115,11 @@ func (s *R2Storage) UploadBuffer(ctx context.Context, data []byte, contentType s
 	return fmt.Sprintf("%s/%s", s.publicURL, filename), nil
 }
 
+// OwnsURL reports whether fileURL points at an object served from this bucket
+func (s *R2Storage) OwnsURL(fileURL string) bool {
+	return s.publicURL != "" && strings.HasPrefix(fileURL, s.publicURL+"/")
+}
+
 // DeleteFile deletes a file from R2/S3 by its full URL
 func (s *R2Storage) DeleteFile(ctx context.Context, fileURL string) error {
 	// 1. Extract Key from URL
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []netip.Prefix
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "10.0.0.0/8", want: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		{in: " 10.1.2.3/8 , 192.168.1.1", want: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.1/32"),
+		}},
+		{in: "::ffff:172.16.0.1", want: []netip.Prefix{netip.MustParsePrefix("172.16.0.1/32")}},
+		{in: "2001:db8::/32", want: []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")}},
+		{in: "10.0.0.0/8,proxy.local", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTrustedProxies(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("prefix %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string // X-Forwarded-For headers
+		trusted   bool     // Use the trusted proxies above
+		want      string
+	}{
+		{name: "no trusted proxies", remote: "203.0.113.5:4000", forwarded: []string{"198.51.100.9"}, want: "203.0.113.5"},
+		{name: "untrusted peer cannot forward", remote: "203.0.113.5:4000", forwarded: []string{"198.51.100.9"}, trusted: true, want: "203.0.113.5"},
+		{name: "trusted proxy without header", remote: "10.0.0.2:4000", trusted: true, want: "10.0.0.2"},
+		{name: "trusted proxy", remote: "10.0.0.2:4000", forwarded: []string{"198.51.100.9"}, trusted: true, want: "198.51.100.9"},
+		{name: "spoofed leftmost hop", remote: "10.0.0.2:4000", forwarded: []string{"6.6.6.6, 198.51.100.9"}, trusted: true, want: "198.51.100.9"},
+		{name: "proxy chain", remote: "10.0.0.2:4000", forwarded: []string{"198.51.100.9, 192.168.1.1, 10.0.0.3"}, trusted: true, want: "198.51.100.9"},
+		{name: "every hop trusted", remote: "10.0.0.2:4000", forwarded: []string{"10.0.0.4, 10.0.0.3"}, trusted: true, want: "10.0.0.4"},
+		{name: "malformed hop", remote: "10.0.0.2:4000", forwarded: []string{"unknown, 10.0.0.3"}, trusted: true, want: "10.0.0.3"},
+		{name: "several headers", remote: "10.0.0.2:4000", forwarded: []string{"6.6.6.6", "198.51.100.9, 10.0.0.3"}, trusted: true, want: "198.51.100.9"},
+		{name: "mapped IPv6 peer", remote: "[::ffff:10.0.0.2]:4000", forwarded: []string{"198.51.100.9"}, trusted: true, want: "198.51.100.9"},
+		{name: "IPv6 client", remote: "10.0.0.2:4000", forwarded: []string{"2001:db8::1"}, trusted: true, want: "2001:db8::1"},
+		{name: "address without port", remote: "203.0.113.5", trusted: true, want: "203.0.113.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			var proxies []netip.Prefix
+			if tt.trusted {
+				proxies = trusted
+			}
+			if got := ClientIP(r, proxies); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// GenerateOTP returns a random 6-digit numeric code.
+func GenerateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// HashOTP binds a code to its order so stored hashes can't be reused elsewhere.
+func HashOTP(orderID, code string) string {
+	return signID("order_otp", orderID+":"+code)
+}
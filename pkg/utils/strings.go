@@ -41,3 +41,19 @@ func ParseInt(s string, defaultVal int) int {
 	}
 	return val
 }
+
+var bdMobilePattern = regexp.MustCompile(`^01[3-9][0-9]{8}$`)
+
+// NormalizePhone converts a Bangladeshi mobile number to its local 11-digit form.
+// e.g. "+880 1712-345678" -> "01712345678". ok is false for anything else.
+func NormalizePhone(phone string) (string, bool) {
+	s := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	s = strings.TrimPrefix(s, "+")
+	if strings.HasPrefix(s, "880") {
+		s = "0" + strings.TrimPrefix(s, "880")
+	}
+	if !bdMobilePattern.MatchString(s) {
+		return "", false
+	}
+	return s, true
+}